@@ -7,6 +7,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"time"
 
 	awsv1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
 	"github.com/openshift/osdctl/pkg/printer"
@@ -20,8 +21,18 @@ import (
 func newCmdPool(client client.Client) *cobra.Command {
 	ops := newPoolOptions(client)
 	poolCmd := &cobra.Command{
-		Use:               "pool",
-		Short:             "Get the status of the AWS Account Operator AccountPool",
+		Use:   "pool",
+		Short: "Get the status of the AWS Account Operator AccountPool",
+		Long: `Get the status of the AWS Account Operator AccountPool.
+
+Besides the accounts per legal entity, the claim rate of the default AccountPool over the --window is computed from
+the creation of the AccountClaims and Accounts, to project when the available accounts and the reused accounts of
+each legal entity run out. Accounts which have not been Ready for longer than --stuck-after are listed with their age.`,
+		Example: `  # Get the status of the AccountPool, with the claim rate over the last week
+  osdctl aao pool
+
+  # Project the pool exhaustion from the claims of the last day
+  osdctl aao pool --window 24h`,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -29,6 +40,8 @@ func newCmdPool(client client.Client) *cobra.Command {
 			cmdutil.CheckErr(ops.run())
 		},
 	}
+	poolCmd.Flags().DurationVar(&ops.window, "window", defaultClaimRateWindow, "window over which the claim rate is computed")
+	poolCmd.Flags().DurationVar(&ops.stuckAfter, "stuck-after", defaultStuckAfter, "duration after which an account not Ready is reported as stuck")
 
 	return poolCmd
 }
@@ -37,6 +50,9 @@ func newCmdPool(client client.Client) *cobra.Command {
 type poolOptions struct {
 	genericclioptions.IOStreams
 	kubeCli client.Client
+
+	window     time.Duration
+	stuckAfter time.Duration
 }
 
 func newPoolOptions(client client.Client) *poolOptions {
@@ -46,6 +62,9 @@ func newPoolOptions(client client.Client) *poolOptions {
 }
 
 func (o *poolOptions) complete(cmd *cobra.Command) error {
+	if o.window <= 0 {
+		return fmt.Errorf("--window must be greater than 0")
+	}
 	return nil
 }
 
@@ -81,7 +100,7 @@ func (o *poolOptions) run() error {
 			continue
 		}
 
-		if account.Spec.AccountPool == "fm-accountpool" { // non-default accountpool
+		if account.Spec.AccountPool == fmAccountPool { // non-default accountpool
 			handlePoolCounting(fmMap, account)
 		} else {
 			handlePoolCounting(defaultMap, account)
@@ -98,6 +117,12 @@ func (o *poolOptions) run() error {
 	fmt.Fprintln(o.IOStreams.Out, "========================================================================================================================")
 	printSortedCount(getSortedCount(fmMap, 10), o.IOStreams.Out)
 
+	var claims awsv1alpha1.AccountClaimList
+	if err := o.kubeCli.List(ctx, &claims); err != nil {
+		return err
+	}
+	printPoolForecast(forecastPool(accounts.Items, claims.Items, time.Now(), o.window, o.stuckAfter), o.IOStreams.Out)
+
 	return nil
 }
 
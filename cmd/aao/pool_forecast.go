@@ -0,0 +1,225 @@
+package aao
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	awsv1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
+	"github.com/openshift/osdctl/pkg/printer"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	fmAccountPool = "fm-accountpool"
+
+	defaultClaimRateWindow = 7 * 24 * time.Hour
+	defaultStuckAfter      = 2 * time.Hour
+)
+
+// legalEntityForecast projects when the reused accounts of a legal entity run out
+type legalEntityForecast struct {
+	id   string
+	name string
+	// reusable is the number of unclaimed Ready accounts kept for the legal entity
+	reusable     int
+	claims       int
+	claimsPerDay float64
+	// daysToExhaustion is +Inf when the legal entity did not claim any account over the window
+	daysToExhaustion float64
+}
+
+// stuckAccount is an account which has not been Ready for longer than expected
+type stuckAccount struct {
+	name        string
+	state       string
+	legalEntity string
+	age         time.Duration
+}
+
+// poolForecast projects when the default AccountPool runs out of accounts, from the claims over a window
+type poolForecast struct {
+	window    time.Duration
+	available int
+	// claims is the number of AccountClaims created over the window, poolClaims the ones not served by a reused account
+	claims          int
+	poolClaims      int
+	accountsCreated int
+	claimsPerDay    float64
+	// netDrainPerDay is the rate at which the pool shrinks, as new accounts are created to replace the claimed ones
+	netDrainPerDay   float64
+	daysToExhaustion float64
+	legalEntities    []legalEntityForecast
+	stuck            []stuckAccount
+}
+
+// forecastPool computes the claim rate of the default AccountPool over the window before now, and projects when the
+// pool and the reused accounts of each legal entity are exhausted at that rate
+func forecastPool(accounts []awsv1alpha1.Account, claims []awsv1alpha1.AccountClaim, now time.Time, window time.Duration, stuckAfter time.Duration) poolForecast {
+	forecast := poolForecast{window: window, daysToExhaustion: math.Inf(1)}
+	since := now.Add(-window)
+
+	accountsByName := map[string]awsv1alpha1.Account{}
+	legalEntities := map[string]*legalEntityForecast{}
+	for _, account := range accounts {
+		accountsByName[account.Name] = account
+
+		if age, stuck := accountStuckFor(account, now, stuckAfter); stuck {
+			forecast.stuck = append(forecast.stuck, stuckAccount{
+				name:        account.Name,
+				state:       account.Status.State,
+				legalEntity: account.Spec.LegalEntity.ID,
+				age:         age,
+			})
+		}
+
+		if account.Spec.BYOC || account.Spec.AccountPool == fmAccountPool {
+			continue
+		}
+		if account.CreationTimestamp.After(since) {
+			forecast.accountsCreated++
+		}
+		if account.Status.Claimed || account.Status.State != "Ready" {
+			continue
+		}
+		if account.Spec.LegalEntity.ID == "" {
+			forecast.available++
+			continue
+		}
+		legalEntityFor(legalEntities, account.Spec.LegalEntity).reusable++
+	}
+
+	for _, claim := range claims {
+		if claim.Spec.BYOC || claim.Spec.AccountPool == fmAccountPool || !claim.CreationTimestamp.After(since) {
+			continue
+		}
+		forecast.claims++
+
+		// Claims of a legal entity are served by its reused accounts first, then by the pool
+		if account, ok := accountsByName[claim.Spec.AccountLink]; !ok || !account.Status.Reused {
+			forecast.poolClaims++
+		}
+		if claim.Spec.LegalEntity.ID != "" {
+			legalEntityFor(legalEntities, claim.Spec.LegalEntity).claims++
+		}
+	}
+
+	days := window.Hours() / 24
+	if days > 0 {
+		forecast.claimsPerDay = float64(forecast.claims) / days
+		forecast.netDrainPerDay = float64(forecast.poolClaims-forecast.accountsCreated) / days
+	}
+	if forecast.netDrainPerDay > 0 {
+		forecast.daysToExhaustion = float64(forecast.available) / forecast.netDrainPerDay
+	}
+
+	for _, legalEntity := range legalEntities {
+		if legalEntity.claims == 0 {
+			continue
+		}
+		legalEntity.daysToExhaustion = math.Inf(1)
+		if days > 0 {
+			legalEntity.claimsPerDay = float64(legalEntity.claims) / days
+			legalEntity.daysToExhaustion = float64(legalEntity.reusable) / legalEntity.claimsPerDay
+		}
+		forecast.legalEntities = append(forecast.legalEntities, *legalEntity)
+	}
+	sort.Slice(forecast.legalEntities, func(i, j int) bool {
+		if forecast.legalEntities[i].daysToExhaustion != forecast.legalEntities[j].daysToExhaustion {
+			return forecast.legalEntities[i].daysToExhaustion < forecast.legalEntities[j].daysToExhaustion
+		}
+		return forecast.legalEntities[i].id < forecast.legalEntities[j].id
+	})
+
+	sort.Slice(forecast.stuck, func(i, j int) bool {
+		return forecast.stuck[i].age > forecast.stuck[j].age
+	})
+
+	return forecast
+}
+
+func legalEntityFor(legalEntities map[string]*legalEntityForecast, legalEntity awsv1alpha1.LegalEntity) *legalEntityForecast {
+	if _, ok := legalEntities[legalEntity.ID]; !ok {
+		legalEntities[legalEntity.ID] = &legalEntityForecast{id: legalEntity.ID, name: legalEntity.Name}
+	}
+	return legalEntities[legalEntity.ID]
+}
+
+// accountStuckFor returns for how long an account has been in its current non-Ready state, and whether that is
+// longer than stuckAfter. The state is assumed to have started with the latest condition transition, or with the
+// creation of the account when it has no condition yet.
+func accountStuckFor(account awsv1alpha1.Account, now time.Time, stuckAfter time.Duration) (time.Duration, bool) {
+	if account.Status.State == "Ready" {
+		return 0, false
+	}
+
+	since := account.CreationTimestamp.Time
+	for _, condition := range account.Status.Conditions {
+		if condition.LastTransitionTime.After(since) {
+			since = condition.LastTransitionTime.Time
+		}
+	}
+	age := now.Sub(since)
+
+	return age, age > stuckAfter
+}
+
+func formatDays(days float64) string {
+	if math.IsInf(days, 1) {
+		return "never"
+	}
+	return strconv.FormatFloat(days, 'f', 1, 64)
+}
+
+func printPoolForecast(forecast poolForecast, out io.Writer) {
+	window := duration.HumanDuration(forecast.window)
+	fmt.Fprintln(out, "========================================================================================================================")
+	fmt.Fprintf(out, "Default Account Pool Forecast (last %s)\n", window)
+	fmt.Fprintln(out, "========================================================================================================================")
+	fmt.Fprintf(out, "Claims: %d (%.1f/day), %d served by the pool\n", forecast.claims, forecast.claimsPerDay, forecast.poolClaims)
+	fmt.Fprintf(out, "Accounts Created: %d\n", forecast.accountsCreated)
+	if forecast.netDrainPerDay > 0 {
+		fmt.Fprintf(out, "Available Accounts: %d, draining by %.1f/day, exhausted in %s days\n", forecast.available, forecast.netDrainPerDay, formatDays(forecast.daysToExhaustion))
+	} else {
+		fmt.Fprintf(out, "Available Accounts: %d, not draining\n", forecast.available)
+	}
+	fmt.Fprintln(out)
+
+	if len(forecast.legalEntities) > 0 {
+		table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+		table.AddRow([]string{"Reusable", "Claims", "Claims/Day", "Days Left", "ID", "Name"})
+		for _, legalEntity := range forecast.legalEntities {
+			table.AddRow([]string{
+				strconv.Itoa(legalEntity.reusable),
+				strconv.Itoa(legalEntity.claims),
+				strconv.FormatFloat(legalEntity.claimsPerDay, 'f', 1, 64),
+				formatDays(legalEntity.daysToExhaustion),
+				legalEntity.id, legalEntity.name,
+			})
+		}
+		table.AddRow([]string{})
+		if err := table.Flush(); err != nil {
+			fmt.Fprintln(out, "error while flushing table: ", err.Error())
+		}
+	}
+
+	fmt.Fprintf(out, "Stuck Accounts: %d\n", len(forecast.stuck))
+	if len(forecast.stuck) == 0 {
+		return
+	}
+	table := printer.NewTablePrinter(out, 20, 1, 3, ' ')
+	table.AddRow([]string{"Name", "State", "Age", "Legal Entity"})
+	for _, account := range forecast.stuck {
+		state := account.state
+		if state == "" {
+			state = "<none>"
+		}
+		table.AddRow([]string{account.name, state, duration.HumanDuration(account.age), account.legalEntity})
+	}
+	if err := table.Flush(); err != nil {
+		fmt.Fprintln(out, "error while flushing table: ", err.Error())
+	}
+}
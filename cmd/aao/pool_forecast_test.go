@@ -0,0 +1,137 @@
+package aao
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	v1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var forecastNow = time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+func newForecastAccount(name string, created time.Duration, state string, claimed bool, reused bool, legalEntityID string) v1alpha1.Account {
+	return v1alpha1.Account{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "aws-account-operator",
+			CreationTimestamp: metav1.NewTime(forecastNow.Add(-created)),
+		},
+		Spec: v1alpha1.AccountSpec{
+			LegalEntity: v1alpha1.LegalEntity{ID: legalEntityID, Name: legalEntityID + "-name"},
+		},
+		Status: v1alpha1.AccountStatus{State: state, Claimed: claimed, Reused: reused},
+	}
+}
+
+func newForecastClaim(name string, created time.Duration, accountLink string, legalEntityID string) v1alpha1.AccountClaim {
+	return v1alpha1.AccountClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "uhc-production-" + name,
+			CreationTimestamp: metav1.NewTime(forecastNow.Add(-created)),
+		},
+		Spec: v1alpha1.AccountClaimSpec{
+			AccountLink: accountLink,
+			LegalEntity: v1alpha1.LegalEntity{ID: legalEntityID, Name: legalEntityID + "-name"},
+		},
+	}
+}
+
+func TestForecastPool(t *testing.T) {
+	day := 24 * time.Hour
+	accounts := []v1alpha1.Account{
+		// Available in the pool, with the created account below
+		newForecastAccount("available1", 30*day, "Ready", false, false, ""),
+		newForecastAccount("available2", 30*day, "Ready", false, false, ""),
+		newForecastAccount("available3", 30*day, "Ready", false, false, ""),
+		newForecastAccount("available4", 30*day, "Ready", false, false, ""),
+		// Created over the window to replace a claimed account
+		newForecastAccount("created", 2*day, "Ready", false, false, ""),
+		// Claimed from the pool and by reuse
+		newForecastAccount("claimed1", 30*day, "Ready", true, false, "le1"),
+		newForecastAccount("claimed2", 30*day, "Ready", true, false, "le1"),
+		newForecastAccount("claimed3", 30*day, "Ready", true, true, "le2"),
+		// Reusable by legal entities
+		newForecastAccount("reusable1", 30*day, "Ready", false, true, "le2"),
+		newForecastAccount("reusable2", 30*day, "Ready", false, true, "le2"),
+		newForecastAccount("reusable3", 30*day, "Ready", false, true, "le3"),
+		// Not Ready, for shorter and longer than --stuck-after
+		newForecastAccount("creating", 30*time.Minute, "Creating", false, false, ""),
+		newForecastAccount("failed", 30*day, "Failed", false, false, ""),
+	}
+	accounts[len(accounts)-1].Status.Conditions = []v1alpha1.AccountCondition{
+		{Type: v1alpha1.AccountFailed, LastTransitionTime: metav1.NewTime(forecastNow.Add(-5 * time.Hour))},
+	}
+	fmAccount := newForecastAccount("fm", day, "Ready", false, false, "")
+	fmAccount.Spec.AccountPool = fmAccountPool
+	accounts = append(accounts, fmAccount)
+
+	claims := []v1alpha1.AccountClaim{
+		newForecastClaim("claim1", day, "claimed1", "le1"),
+		newForecastClaim("claim2", 3*day, "claimed2", "le1"),
+		newForecastClaim("claim3", 6*day, "claimed3", "le2"),
+		newForecastClaim("pending", time.Hour, "", "le1"),
+		// Outside of the window
+		newForecastClaim("old", 10*day, "claimed1", "le3"),
+	}
+	byocClaim := newForecastClaim("byoc", day, "", "le1")
+	byocClaim.Spec.BYOC = true
+	claims = append(claims, byocClaim)
+
+	forecast := forecastPool(accounts, claims, forecastNow, 7*day, 2*time.Hour)
+
+	assert.Equal(t, 5, forecast.available)
+	assert.Equal(t, 4, forecast.claims)
+	assert.Equal(t, 3, forecast.poolClaims)
+	// The created and creating accounts
+	assert.Equal(t, 2, forecast.accountsCreated)
+	assert.InDelta(t, 4.0/7, forecast.claimsPerDay, 0.0001)
+	assert.InDelta(t, 1.0/7, forecast.netDrainPerDay, 0.0001)
+	assert.InDelta(t, 35, forecast.daysToExhaustion, 0.0001)
+
+	require.Len(t, forecast.legalEntities, 2)
+	assert.Equal(t, "le1", forecast.legalEntities[0].id)
+	assert.Equal(t, 0, forecast.legalEntities[0].reusable)
+	assert.Equal(t, 3, forecast.legalEntities[0].claims)
+	assert.Zero(t, forecast.legalEntities[0].daysToExhaustion)
+	assert.Equal(t, "le2", forecast.legalEntities[1].id)
+	assert.Equal(t, 2, forecast.legalEntities[1].reusable)
+	assert.InDelta(t, 14, forecast.legalEntities[1].daysToExhaustion, 0.0001)
+
+	require.Len(t, forecast.stuck, 1)
+	assert.Equal(t, "failed", forecast.stuck[0].name)
+	assert.Equal(t, "Failed", forecast.stuck[0].state)
+	assert.Equal(t, 5*time.Hour, forecast.stuck[0].age)
+
+	var out bytes.Buffer
+	printPoolForecast(forecast, &out)
+	assert.Contains(t, out.String(), "Claims: 4 (0.6/day), 3 served by the pool")
+	assert.Contains(t, out.String(), "Available Accounts: 5, draining by 0.1/day, exhausted in 35.0 days")
+	assert.Contains(t, out.String(), "Stuck Accounts: 1")
+	assert.Contains(t, out.String(), "failed")
+}
+
+func TestForecastPoolNotDraining(t *testing.T) {
+	accounts := []v1alpha1.Account{
+		newForecastAccount("available", time.Hour, "Ready", false, false, ""),
+		newForecastAccount("reusable", 30*24*time.Hour, "Ready", false, true, "le1"),
+	}
+
+	forecast := forecastPool(accounts, nil, forecastNow, 24*time.Hour, 2*time.Hour)
+
+	assert.Equal(t, 1, forecast.available)
+	assert.Zero(t, forecast.claims)
+	assert.True(t, math.IsInf(forecast.daysToExhaustion, 1))
+	assert.Empty(t, forecast.legalEntities)
+	assert.Empty(t, forecast.stuck)
+
+	var out bytes.Buffer
+	printPoolForecast(forecast, &out)
+	assert.Contains(t, out.String(), "Available Accounts: 1, not draining")
+	assert.Contains(t, out.String(), "Stuck Accounts: 0")
+}
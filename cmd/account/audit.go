@@ -0,0 +1,468 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	organizationTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	stsTypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	awsv1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
+	"github.com/openshift/osdctl/cmd/common"
+	outputflag "github.com/openshift/osdctl/cmd/getoutput"
+	"github.com/openshift/osdctl/internal/utils/globalflags"
+	"github.com/openshift/osdctl/pkg/printer"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	"github.com/openshift/osdctl/pkg/utils"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// clusterIDLabel is set on AccountClaims to the internal ID of their cluster
+	clusterIDLabel = "api.openshift.com/id"
+
+	// ocmClusterSearchSize is the number of clusters looked up in OCM at once
+	ocmClusterSearchSize = 100
+)
+
+type auditFindingType string
+
+const (
+	auditAccountWithoutCR        auditFindingType = "AccountWithoutCR"
+	auditCRWithoutClaim          auditFindingType = "CRWithoutClaim"
+	auditClaimForDeletedCluster  auditFindingType = "ClaimForDeletedCluster"
+	auditInstancesWithoutCluster auditFindingType = "RunningInstancesWithoutCluster"
+)
+
+// newCmdAudit implements the audit command which looks for leaked and orphaned AWS accounts
+func newCmdAudit(streams genericclioptions.IOStreams, client client.Client, globalOpts *globalflags.GlobalOptions) *cobra.Command {
+	ops := newAuditOptions(streams, client, globalOpts)
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Find leaked and orphaned AWS accounts",
+		Long: `Find leaked and orphaned AWS accounts.
+
+The AWS accounts under the given OU of the payer account are cross-referenced with the Account CRs and AccountClaims
+of the current hive cluster, and with the clusters in OCM, to report:
+  - AccountWithoutCR: an active AWS account with no Account CR
+  - CRWithoutClaim: an Account CR linked to an AccountClaim which does not exist
+  - ClaimForDeletedCluster: an AccountClaim for a cluster which no longer exists in OCM
+  - RunningInstancesWithoutCluster: an AWS account not used by any cluster, with running EC2 instances
+
+The EC2 instances are looked up in all the regions enabled in the accounts, through the OrganizationAccountAccessRole.`,
+		Example: `  # Audit the accounts under an OU of the payer account
+  osdctl account audit --ou ${OU_ID} -p osd-staging-1
+
+  # Audit the accounts without looking up their EC2 instances
+  osdctl account audit --ou ${OU_ID} -p osd-staging-1 --check-instances=false`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(ops.complete(cmd, args))
+			cmdutil.CheckErr(ops.run())
+		},
+	}
+
+	auditCmd.Flags().StringVar(&ops.ou, "ou", "", "ID of the OU of the payer account holding the AWS accounts")
+	auditCmd.Flags().StringVarP(&ops.profile, "aws-profile", "p", "", "specify AWS profile of the payer account")
+	auditCmd.Flags().StringVar(&ops.accountNamespace, "account-namespace", common.AWSAccountNamespace,
+		"The namespace to keep AWS accounts. The default value is aws-account-operator.")
+	auditCmd.Flags().BoolVar(&ops.checkInstances, "check-instances", true, "look up the running EC2 instances of the accounts not used by any cluster")
+	_ = auditCmd.MarkFlagRequired("ou")
+
+	return auditCmd
+}
+
+// auditOptions defines the struct for running the audit command
+type auditOptions struct {
+	ou               string
+	profile          string
+	accountNamespace string
+	checkInstances   bool
+	output           string
+
+	genericclioptions.IOStreams
+	kubeCli       client.Client
+	GlobalOptions *globalflags.GlobalOptions
+
+	// Injected for testability
+	payerClientFunc      func() (awsprovider.Client, error)
+	accountClientFunc    func(payerClient awsprovider.Client, accountID string, region string) (awsprovider.Client, error)
+	existingClustersFunc func(clusterIDs []string) (map[string]bool, error)
+
+	// accountCredentials caches the credentials of the OrganizationAccountAccessRole of each account
+	accountCredentials map[string]*stsTypes.Credentials
+}
+
+// auditFinding is a leaked or orphaned AWS account
+type auditFinding struct {
+	Type      auditFindingType `json:"type" yaml:"type"`
+	AccountID string           `json:"accountId" yaml:"accountId"`
+	AccountCR string           `json:"accountCR,omitempty" yaml:"accountCR,omitempty"`
+	Claim     string           `json:"claim,omitempty" yaml:"claim,omitempty"`
+	ClusterID string           `json:"clusterId,omitempty" yaml:"clusterId,omitempty"`
+	Detail    string           `json:"detail,omitempty" yaml:"detail,omitempty"`
+}
+
+type auditResponse struct {
+	OU       string         `json:"ou" yaml:"ou"`
+	Accounts int            `json:"accounts" yaml:"accounts"`
+	Findings []auditFinding `json:"findings" yaml:"findings"`
+}
+
+func (r auditResponse) String() string {
+	if len(r.Findings) == 0 {
+		return fmt.Sprintf("No leaked or orphaned accounts found among the %d accounts under %s\n", r.Accounts, r.OU)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d findings among the %d accounts under %s\n\n", len(r.Findings), r.Accounts, r.OU)
+	p := printer.NewTablePrinter(&sb, 20, 1, 3, ' ')
+	p.AddRow([]string{"TYPE", "ACCOUNT ID", "ACCOUNT CR", "CLAIM", "CLUSTER ID", "DETAIL"})
+	for _, finding := range r.Findings {
+		p.AddRow([]string{string(finding.Type), finding.AccountID, finding.AccountCR, finding.Claim, finding.ClusterID, finding.Detail})
+	}
+	if err := p.Flush(); err != nil {
+		fmt.Fprintf(&sb, "error while flushing table: %v\n", err)
+	}
+
+	return sb.String()
+}
+
+func newAuditOptions(streams genericclioptions.IOStreams, client client.Client, globalOpts *globalflags.GlobalOptions) *auditOptions {
+	ops := &auditOptions{
+		IOStreams:            streams,
+		kubeCli:              client,
+		GlobalOptions:        globalOpts,
+		existingClustersFunc: getExistingClusters,
+		accountCredentials:   map[string]*stsTypes.Credentials{},
+	}
+	ops.payerClientFunc = func() (awsprovider.Client, error) {
+		return awsprovider.NewAwsClient(ops.profile, common.DefaultRegion, "")
+	}
+	ops.accountClientFunc = ops.newAccountClient
+
+	return ops
+}
+
+func (o *auditOptions) complete(cmd *cobra.Command, _ []string) error {
+	if o.ou == "" {
+		return cmdutil.UsageErrorf(cmd, "The OU ID is required")
+	}
+	o.output = o.GlobalOptions.Output
+
+	return nil
+}
+
+func (o *auditOptions) run() error {
+	resp, err := o.audit(context.TODO())
+	if err != nil {
+		return err
+	}
+
+	return outputflag.PrintResponse(o.output, resp)
+}
+
+// audit cross-references the AWS accounts under the OU with the Account CRs, AccountClaims and OCM clusters
+func (o *auditOptions) audit(ctx context.Context) (*auditResponse, error) {
+	payerClient, err := o.payerClientFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	awsAccounts, err := listOUAccounts(payerClient, o.ou)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the accounts under %s: %w", o.ou, err)
+	}
+
+	var accounts awsv1alpha1.AccountList
+	if err := o.kubeCli.List(ctx, &accounts, &client.ListOptions{Namespace: o.accountNamespace}); err != nil {
+		return nil, err
+	}
+
+	var claims awsv1alpha1.AccountClaimList
+	if err := o.kubeCli.List(ctx, &claims); err != nil {
+		return nil, err
+	}
+
+	var clusterIDs []string
+	claimsByName := map[string]awsv1alpha1.AccountClaim{}
+	for _, claim := range claims.Items {
+		claimsByName[claim.Namespace+"/"+claim.Name] = claim
+		if clusterID := claim.Labels[clusterIDLabel]; clusterID != "" {
+			clusterIDs = append(clusterIDs, clusterID)
+		}
+	}
+	existingClusters, err := o.existingClustersFunc(clusterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &auditResponse{OU: o.ou, Accounts: len(awsAccounts), Findings: []auditFinding{}}
+
+	accountsByName := map[string]awsv1alpha1.Account{}
+	accountsByID := map[string]awsv1alpha1.Account{}
+	for _, account := range accounts.Items {
+		accountsByName[account.Name] = account
+		if account.Spec.AwsAccountID != "" {
+			accountsByID[account.Spec.AwsAccountID] = account
+		}
+	}
+
+	for _, claim := range claims.Items {
+		clusterID := claim.Labels[clusterIDLabel]
+		if clusterID == "" || existingClusters[clusterID] {
+			continue
+		}
+		resp.Findings = append(resp.Findings, auditFinding{
+			Type:      auditClaimForDeletedCluster,
+			AccountID: accountsByName[claim.Spec.AccountLink].Spec.AwsAccountID,
+			AccountCR: claim.Spec.AccountLink,
+			Claim:     claim.Namespace + "/" + claim.Name,
+			ClusterID: clusterID,
+			Detail:    "cluster not found in OCM",
+		})
+	}
+
+	// The accounts used by an existing cluster, by AWS account ID
+	usedAccounts := map[string]bool{}
+	for _, account := range accounts.Items {
+		if account.Spec.ClaimLink == "" {
+			continue
+		}
+
+		claimName := account.Spec.ClaimLinkNamespace + "/" + account.Spec.ClaimLink
+		claim, ok := claimsByName[claimName]
+		if !ok {
+			resp.Findings = append(resp.Findings, auditFinding{
+				Type:      auditCRWithoutClaim,
+				AccountID: account.Spec.AwsAccountID,
+				AccountCR: account.Name,
+				Claim:     claimName,
+				Detail:    "AccountClaim not found",
+			})
+			continue
+		}
+
+		// Claims without the cluster ID label are assumed to belong to an existing cluster
+		if clusterID := claim.Labels[clusterIDLabel]; clusterID == "" || existingClusters[clusterID] {
+			usedAccounts[account.Spec.AwsAccountID] = true
+		}
+	}
+
+	for _, awsAccount := range awsAccounts {
+		accountID := awsSdk.ToString(awsAccount.Id)
+		account, hasCR := accountsByID[accountID]
+		if !hasCR {
+			resp.Findings = append(resp.Findings, auditFinding{
+				Type:      auditAccountWithoutCR,
+				AccountID: accountID,
+				Detail:    awsSdk.ToString(awsAccount.Name),
+			})
+		}
+
+		if !o.checkInstances || usedAccounts[accountID] {
+			continue
+		}
+		instances, err := o.countRunningInstances(payerClient, accountID)
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "Warning: failed to look up the EC2 instances of account %s: %v\n", accountID, err)
+			continue
+		}
+		if len(instances) == 0 {
+			continue
+		}
+		finding := auditFinding{
+			Type:      auditInstancesWithoutCluster,
+			AccountID: accountID,
+			Detail:    formatRegionCounts(instances),
+		}
+		if hasCR {
+			finding.AccountCR = account.Name
+		}
+		resp.Findings = append(resp.Findings, finding)
+	}
+
+	sort.SliceStable(resp.Findings, func(i, j int) bool {
+		if resp.Findings[i].Type != resp.Findings[j].Type {
+			return resp.Findings[i].Type < resp.Findings[j].Type
+		}
+		return resp.Findings[i].AccountID < resp.Findings[j].AccountID
+	})
+
+	return resp, nil
+}
+
+// listOUAccounts lists the active accounts under the OU and its child OUs
+func listOUAccounts(awsClient awsprovider.Client, ouID string) ([]organizationTypes.Account, error) {
+	var accounts []organizationTypes.Account
+	var nextToken *string
+	for {
+		output, err := awsClient.ListAccountsForParent(&organizations.ListAccountsForParentInput{
+			ParentId:  &ouID,
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range output.Accounts {
+			if account.Status == organizationTypes.AccountStatusActive {
+				accounts = append(accounts, account)
+			}
+		}
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	nextToken = nil
+	for {
+		output, err := awsClient.ListOrganizationalUnitsForParent(&organizations.ListOrganizationalUnitsForParentInput{
+			ParentId:  &ouID,
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, childOU := range output.OrganizationalUnits {
+			childAccounts, err := listOUAccounts(awsClient, awsSdk.ToString(childOU.Id))
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, childAccounts...)
+		}
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return accounts, nil
+}
+
+// countRunningInstances counts the running and pending EC2 instances of an account in each of its enabled regions
+func (o *auditOptions) countRunningInstances(payerClient awsprovider.Client, accountID string) (map[string]int, error) {
+	accountClient, err := o.accountClientFunc(payerClient, accountID, common.DefaultRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := accountClient.DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe regions: %w", err)
+	}
+
+	instances := map[string]int{}
+	for _, region := range regions.Regions {
+		regionName := awsSdk.ToString(region.RegionName)
+		regionClient, err := o.accountClientFunc(payerClient, accountID, regionName)
+		if err != nil {
+			return nil, err
+		}
+
+		var nextToken *string
+		for {
+			output, err := regionClient.DescribeInstances(&ec2.DescribeInstancesInput{
+				Filters: []ec2Types.Filter{
+					{Name: awsSdk.String("instance-state-name"), Values: []string{"pending", "running"}},
+				},
+				NextToken: nextToken,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe instances in %s: %w", regionName, err)
+			}
+			for _, reservation := range output.Reservations {
+				instances[regionName] += len(reservation.Instances)
+			}
+			if output.NextToken == nil {
+				break
+			}
+			nextToken = output.NextToken
+		}
+		if instances[regionName] == 0 {
+			delete(instances, regionName)
+		}
+	}
+
+	return instances, nil
+}
+
+// newAccountClient creates an AWS client for a region of an account, assuming its OrganizationAccountAccessRole
+func (o *auditOptions) newAccountClient(payerClient awsprovider.Client, accountID string, region string) (awsprovider.Client, error) {
+	credentials, ok := o.accountCredentials[accountID]
+	if !ok {
+		roleArn := awsSdk.String(fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, awsv1alpha1.AccountOperatorIAMRole))
+		var err error
+		credentials, err = awsprovider.GetAssumeRoleCredentials(payerClient, awsSdk.Int32(900), awsSdk.String("osdctl-account-audit"), roleArn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assume OrganizationAccountAccessRole: %w", err)
+		}
+		o.accountCredentials[accountID] = credentials
+	}
+
+	return awsprovider.NewAwsClientWithInput(&awsprovider.ClientInput{
+		AccessKeyID:     *credentials.AccessKeyId,
+		SecretAccessKey: *credentials.SecretAccessKey,
+		SessionToken:    *credentials.SessionToken,
+		Region:          region,
+	})
+}
+
+// getExistingClusters looks up which of the clusters still exist in OCM
+func getExistingClusters(clusterIDs []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	if len(clusterIDs) == 0 {
+		return existing, nil
+	}
+
+	ocmClient, err := utils.CreateConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer ocmClient.Close()
+
+	for start := 0; start < len(clusterIDs); start += ocmClusterSearchSize {
+		end := min(start+ocmClusterSearchSize, len(clusterIDs))
+		ids := make([]string, 0, end-start)
+		for _, id := range clusterIDs[start:end] {
+			ids = append(ids, fmt.Sprintf("'%s'", id))
+		}
+
+		response, err := ocmClient.ClustersMgmt().V1().Clusters().List().
+			Search(fmt.Sprintf("id in (%s)", strings.Join(ids, ","))).
+			Size(ocmClusterSearchSize).
+			Send()
+		if err != nil {
+			return nil, fmt.Errorf("failed to search clusters in OCM: %w", err)
+		}
+		for _, cluster := range response.Items().Slice() {
+			existing[cluster.ID()] = true
+		}
+	}
+
+	return existing, nil
+}
+
+func formatRegionCounts(counts map[string]int) string {
+	regions := make([]string, 0, len(counts))
+	for region := range counts {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	details := make([]string, 0, len(regions))
+	for _, region := range regions {
+		details = append(details, fmt.Sprintf("%s: %d", region, counts[region]))
+	}
+
+	return "running instances " + strings.Join(details, ", ")
+}
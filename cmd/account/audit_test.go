@@ -0,0 +1,139 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	organizationTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	awsv1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	"github.com/openshift/osdctl/pkg/provider/aws/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newAuditAccount(name, accountID, claimNamespace, claimName string) *awsv1alpha1.Account {
+	return &awsv1alpha1.Account{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "aws-account-operator"},
+		Spec: awsv1alpha1.AccountSpec{
+			AwsAccountID:       accountID,
+			ClaimLink:          claimName,
+			ClaimLinkNamespace: claimNamespace,
+		},
+	}
+}
+
+func newAuditClaim(namespace, name, accountLink, clusterID string) *awsv1alpha1.AccountClaim {
+	return &awsv1alpha1.AccountClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{clusterIDLabel: clusterID},
+		},
+		Spec: awsv1alpha1.AccountClaimSpec{AccountLink: accountLink},
+	}
+}
+
+func orgAccount(id string) organizationTypes.Account {
+	return organizationTypes.Account{Id: awsSdk.String(id), Name: awsSdk.String("name-" + id), Status: organizationTypes.AccountStatusActive}
+}
+
+func TestAudit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, awsv1alpha1.AddToScheme(scheme))
+	kubeCli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		// Used by a live cluster
+		newAuditAccount("osd-live", "111111111111", "uhc-production-live", "live"),
+		newAuditClaim("uhc-production-live", "live", "osd-live", "live-id"),
+		// Claim of a deleted cluster
+		newAuditAccount("osd-deleted", "222222222222", "uhc-production-deleted", "deleted"),
+		newAuditClaim("uhc-production-deleted", "deleted", "osd-deleted", "deleted-id"),
+		// Claim gone
+		newAuditAccount("osd-orphan", "333333333333", "uhc-production-gone", "gone"),
+		// In the pool
+		newAuditAccount("osd-pool", "444444444444", "", ""),
+	).Build()
+
+	ctrl := gomock.NewController(t)
+	payerClient := mock.NewMockClient(ctrl)
+	payerClient.EXPECT().ListAccountsForParent(&organizations.ListAccountsForParentInput{ParentId: awsSdk.String("ou-root")}).Return(&organizations.ListAccountsForParentOutput{
+		Accounts: []organizationTypes.Account{orgAccount("111111111111"), orgAccount("222222222222")},
+	}, nil)
+	payerClient.EXPECT().ListOrganizationalUnitsForParent(&organizations.ListOrganizationalUnitsForParentInput{ParentId: awsSdk.String("ou-root")}).Return(&organizations.ListOrganizationalUnitsForParentOutput{
+		OrganizationalUnits: []organizationTypes.OrganizationalUnit{{Id: awsSdk.String("ou-child")}},
+	}, nil)
+	suspended := orgAccount("666666666666")
+	suspended.Status = organizationTypes.AccountStatusSuspended
+	payerClient.EXPECT().ListAccountsForParent(&organizations.ListAccountsForParentInput{ParentId: awsSdk.String("ou-child")}).Return(&organizations.ListAccountsForParentOutput{
+		Accounts: []organizationTypes.Account{orgAccount("333333333333"), orgAccount("444444444444"), orgAccount("555555555555"), suspended},
+	}, nil)
+	payerClient.EXPECT().ListOrganizationalUnitsForParent(&organizations.ListOrganizationalUnitsForParentInput{ParentId: awsSdk.String("ou-child")}).Return(&organizations.ListOrganizationalUnitsForParentOutput{}, nil)
+
+	// Only the accounts not used by a live cluster are looked up, and only 222222222222 has running instances
+	runningInstances := map[string]map[string]int{
+		"222222222222": {"us-east-1": 2, "eu-west-1": 1},
+		"333333333333": {},
+		"444444444444": {},
+		"555555555555": {},
+	}
+	accountClientFunc := func(_ awsprovider.Client, accountID string, region string) (awsprovider.Client, error) {
+		instances, ok := runningInstances[accountID]
+		if !ok {
+			return nil, fmt.Errorf("unexpected lookup of account %s", accountID)
+		}
+		accountClient := mock.NewMockClient(ctrl)
+		accountClient.EXPECT().DescribeRegions(gomock.Any()).Return(&ec2.DescribeRegionsOutput{
+			Regions: []ec2Types.Region{{RegionName: awsSdk.String("us-east-1")}, {RegionName: awsSdk.String("eu-west-1")}},
+		}, nil).AnyTimes()
+		var reservations []ec2Types.Reservation
+		for range instances[region] {
+			reservations = append(reservations, ec2Types.Reservation{Instances: []ec2Types.Instance{{}}})
+		}
+		accountClient.EXPECT().DescribeInstances(gomock.Any()).Return(&ec2.DescribeInstancesOutput{Reservations: reservations}, nil).AnyTimes()
+		return accountClient, nil
+	}
+
+	o := &auditOptions{
+		ou:               "ou-root",
+		accountNamespace: "aws-account-operator",
+		checkInstances:   true,
+		IOStreams:        genericclioptions.IOStreams{ErrOut: &bytes.Buffer{}},
+		kubeCli:          kubeCli,
+		payerClientFunc: func() (awsprovider.Client, error) {
+			return payerClient, nil
+		},
+		accountClientFunc: accountClientFunc,
+		existingClustersFunc: func(clusterIDs []string) (map[string]bool, error) {
+			assert.ElementsMatch(t, []string{"live-id", "deleted-id"}, clusterIDs)
+			return map[string]bool{"live-id": true}, nil
+		},
+	}
+
+	resp, err := o.audit(context.TODO())
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, resp.Accounts)
+	assert.Equal(t, []auditFinding{
+		{Type: auditAccountWithoutCR, AccountID: "555555555555", Detail: "name-555555555555"},
+		{Type: auditCRWithoutClaim, AccountID: "333333333333", AccountCR: "osd-orphan", Claim: "uhc-production-gone/gone", Detail: "AccountClaim not found"},
+		{Type: auditClaimForDeletedCluster, AccountID: "222222222222", AccountCR: "osd-deleted", Claim: "uhc-production-deleted/deleted", ClusterID: "deleted-id", Detail: "cluster not found in OCM"},
+		{Type: auditInstancesWithoutCluster, AccountID: "222222222222", AccountCR: "osd-deleted", Detail: "running instances eu-west-1: 1, us-east-1: 2"},
+	}, resp.Findings)
+	assert.Contains(t, resp.String(), "4 findings among the 5 accounts under ou-root")
+}
+
+func TestAuditResponseStringNoFindings(t *testing.T) {
+	resp := auditResponse{OU: "ou-root", Accounts: 3, Findings: []auditFinding{}}
+	assert.Equal(t, "No leaked or orphaned accounts found among the 3 accounts under ou-root\n", resp.String())
+}
@@ -0,0 +1,158 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	awsv1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
+	"github.com/openshift/osdctl/cmd/common"
+	"github.com/openshift/osdctl/pkg/controller"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type awsCredsAgeReportOptions struct {
+	awsCredsOptions
+	accountNamespace string
+	olderThan        string
+	maxAge           time.Duration
+
+	kubeCli client.Client
+}
+
+// newCmdAWSCredsAgeReport creates the "aws-creds age-report" subcommand reporting the age of the IAM access keys of all Account CRs.
+func newCmdAWSCredsAgeReport(streams genericclioptions.IOStreams, kubeCli client.Client) *cobra.Command {
+	ops := &awsCredsAgeReportOptions{
+		awsCredsOptions: awsCredsOptions{IOStreams: streams, log: newAWSCredsLogger()},
+		kubeCli:         kubeCli,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "age-report [flags]",
+		Short: "Report the age and last use of the IAM access keys of all Account CRs",
+		Long: `Scans all the Account CRs of the current Hive cluster and reports the age and last use
+of the access keys of their osdManagedAdmin, osdManagedAdminSRE and, on CCS accounts,
+osdCcsAdmin IAM users. Keys older than --older-than are flagged for rotation, see
+"osdctl account aws-creds rotate --older-than".
+
+STS accounts have no IAM user credentials and are skipped.
+
+Use -o json or -o yaml to print the access keys in a machine-readable format.
+
+This is a read-only operation — no credentials are modified.
+
+The AWS accounts are accessed by role chaining from --aws-profile, from the
+environment with --aws-use-env, or else from the default AWS credential chain.`,
+		Example: `  # Report the access keys older than 90 days
+  osdctl account aws-creds age-report --aws-profile rhcontrol --older-than 90d
+
+  # List the access keys due for rotation from automation
+  osdctl account aws-creds age-report --aws-profile rhcontrol -o json | jq '.[] | select(.rotate)'
+
+  # Using rh-aws-saml-login credentials
+  eval $(rh-aws-saml-login --output env rhcontrol)
+  export AWS_ACCESS_KEY_ID AWS_SECRET_ACCESS_KEY AWS_SESSION_TOKEN
+  osdctl account aws-creds age-report --aws-use-env`,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(ops.validateAgeReport(cmd, args))
+			cmdutil.CheckErr(runAgeReport(cmd.Context(), ops))
+		},
+	}
+
+	cmd.Flags().StringVarP(&ops.profile, "aws-profile", "p", "", "AWS profile for role chaining. If omitted, uses the default AWS credential chain")
+	cmd.Flags().BoolVar(&ops.awsUseEnv, "aws-use-env", false, "Use AWS credentials from environment variables (e.g. after rh-aws-saml-login)")
+	cmd.Flags().StringVar(&ops.accountNamespace, "account-namespace", common.AWSAccountNamespace, "The namespace of the Account CRs")
+	cmd.Flags().StringVar(&ops.olderThan, "older-than", "90d", "Flag the access keys older than this age for rotation, e.g. 90d")
+	cmd.Flags().StringVarP(&ops.logLevel, "log-level", "l", "info", "Log level: debug, info, warn, error")
+	ops.addOutputFlag(cmd)
+
+	hideIrrelevantGlobalFlags(cmd)
+
+	return cmd
+}
+
+func (o *awsCredsAgeReportOptions) validateAgeReport(cmd *cobra.Command, args []string) error {
+	if err := o.validate(cmd, args); err != nil {
+		return err
+	}
+
+	maxAge, err := parseKeyAge(o.olderThan)
+	if err != nil {
+		return cmdutil.UsageErrorf(cmd, "invalid --older-than: %v", err)
+	}
+	o.maxAge = maxAge
+
+	return nil
+}
+
+// runAgeReport collects the access keys of every Account CR and renders them. Accounts which cannot be
+// inspected are reported once all the other accounts are.
+func runAgeReport(ctx context.Context, o *awsCredsAgeReportOptions) error {
+	var accounts awsv1alpha1.AccountList
+	if err := o.kubeCli.List(ctx, &accounts, &client.ListOptions{Namespace: o.accountNamespace}); err != nil {
+		return fmt.Errorf("failed to list Account CRs: %w", err)
+	}
+
+	awsSetupClient, err := o.buildAWSSetupClient()
+	if err != nil {
+		return err
+	}
+
+	keys, failed := o.collectAccessKeyAges(ctx, accounts.Items, func(account *awsv1alpha1.Account) (awsprovider.Client, error) {
+		return o.roleChainToSupport(ctx, awsSetupClient, o.kubeCli, account, account.Spec.AwsAccountID, account.Labels["iamUserId"])
+	}, time.Now())
+
+	if o.output != "" {
+		if err := controller.WriteAgeReport(keys, o.maxAge, o.output, o.Out); err != nil {
+			return err
+		}
+	} else {
+		controller.RenderAgeReport(keys, o.maxAge, time.Now(), o.Out)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to report the access keys of %d account(s): %v", len(failed), failed)
+	}
+	return nil
+}
+
+// collectAccessKeyAges collects the access keys of the Account CRs which have IAM users, returning the names of
+// the accounts which could not be inspected
+func (o *awsCredsAgeReportOptions) collectAccessKeyAges(ctx context.Context, accounts []awsv1alpha1.Account, accountClient func(*awsv1alpha1.Account) (awsprovider.Client, error), now time.Time) ([]controller.AccessKeyAge, []string) {
+	var keys []controller.AccessKeyAge
+	var failed []string
+	for i := range accounts {
+		account := &accounts[i]
+		if account.Spec.ManualSTSMode || account.Spec.AwsAccountID == "" {
+			o.log.WithField("account", account.Name).Debug("Skipping account without IAM user credentials")
+			continue
+		}
+
+		log := o.log.WithFields(logrus.Fields{"account": account.Name, "aws_account": account.Spec.AwsAccountID})
+		log.Debug("Collecting access keys")
+
+		awsClient, err := accountClient(account)
+		if err != nil {
+			log.WithError(err).Warn("Could not access the AWS account")
+			failed = append(failed, account.Name)
+			continue
+		}
+
+		accountKeys, err := controller.CollectAccessKeyAges(ctx, awsClient, account, now)
+		if err != nil {
+			log.WithError(err).Warn("Could not collect the access keys")
+			failed = append(failed, account.Name)
+			continue
+		}
+		keys = append(keys, accountKeys...)
+	}
+
+	return keys, failed
+}
@@ -0,0 +1,88 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	awsv1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	"github.com/openshift/osdctl/pkg/provider/aws/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseKeyAge(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		expectedErr bool
+	}{
+		{value: "90d", expected: 90 * 24 * time.Hour},
+		{value: "0d", expected: 0},
+		{value: "36h", expected: 36 * time.Hour},
+		{value: "-1d", expectedErr: true},
+		{value: "ninetyd", expectedErr: true},
+		{value: "90", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			age, err := parseKeyAge(tt.value)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, age)
+		})
+	}
+}
+
+func TestAgeReportCollectAccessKeyAges(t *testing.T) {
+	newAccount := func(name, accountID string) awsv1alpha1.Account {
+		return awsv1alpha1.Account{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"iamUserId": "abcd"}},
+			Spec:       awsv1alpha1.AccountSpec{AwsAccountID: accountID},
+		}
+	}
+	sts := newAccount("osd-sts", "333333333333")
+	sts.Spec.ManualSTSMode = true
+	accounts := []awsv1alpha1.Account{
+		newAccount("osd-ok", "111111111111"),
+		newAccount("osd-denied", "222222222222"),
+		sts,
+		newAccount("osd-creating", ""),
+	}
+
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	ctrl := gomock.NewController(t)
+	okClient := mock.NewMockClient(ctrl)
+	okClient.EXPECT().ListAccessKeys(&iam.ListAccessKeysInput{UserName: awsSdk.String("osdManagedAdmin-abcd")}).Return(&iam.ListAccessKeysOutput{
+		AccessKeyMetadata: []iamTypes.AccessKeyMetadata{{AccessKeyId: awsSdk.String("AKIA0001"), CreateDate: awsSdk.Time(now.Add(-100 * 24 * time.Hour))}},
+	}, nil)
+	okClient.EXPECT().ListAccessKeys(&iam.ListAccessKeysInput{UserName: awsSdk.String("osdManagedAdminSRE-abcd")}).Return(nil, &iamTypes.NoSuchEntityException{})
+	okClient.EXPECT().GetAccessKeyLastUsed(gomock.Any(), gomock.Any()).Return(&iam.GetAccessKeyLastUsedOutput{}, nil)
+
+	o := &awsCredsAgeReportOptions{awsCredsOptions: awsCredsOptions{log: newAWSCredsLogger()}}
+	keys, failed := o.collectAccessKeyAges(context.TODO(), accounts, func(account *awsv1alpha1.Account) (awsprovider.Client, error) {
+		switch account.Name {
+		case "osd-ok":
+			return okClient, nil
+		case "osd-denied":
+			return nil, fmt.Errorf("access denied")
+		}
+		return nil, fmt.Errorf("unexpected account %s", account.Name)
+	}, now)
+
+	require.Len(t, keys, 1)
+	assert.Equal(t, "osd-ok", keys[0].AccountCRName)
+	assert.Equal(t, 100*24*time.Hour, keys[0].Age)
+	assert.Equal(t, []string{"osd-denied"}, failed)
+}
@@ -2,10 +2,14 @@ package account
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	osdctlio "github.com/openshift/osdctl/internal/io"
 	"github.com/openshift/osdctl/pkg/controller"
 	"github.com/openshift/osdctl/pkg/utils"
 	"github.com/spf13/cobra"
@@ -20,6 +24,9 @@ type awsCredsRotateOptions struct {
 	refreshSecrets     bool
 	dryRun             bool
 	force              bool
+	clustersFile       string
+	olderThan          string
+	maxAge             time.Duration
 }
 
 // newCmdAWSCredsRotate creates the "aws-creds rotate" subcommand for IAM credential rotation.
@@ -46,7 +53,12 @@ environment credentials directly (e.g. after rh-aws-saml-login).
 
 Pre-flight checks (IAM permissions, secret existence) block rotation by
 default. Use --force to allow proceeding past errors with explicit YES
-confirmation — only when you are certain the errors are benign.`,
+confirmation — only when you are certain the errors are benign.
+
+Use --older-than to only rotate the credentials whose access keys are older
+than the given age, and --clusters-file to rotate the credentials of several
+clusters in a row. Each cluster is confirmed before its rotation, and a
+failure does not stop the rotation of the next clusters.`,
 		Example: `  # Rotate osdManagedAdmin credentials
   osdctl account aws-creds rotate -C $CLUSTER_ID --reason "$JIRA_TICKET" --managed-admin
 
@@ -69,10 +81,21 @@ confirmation — only when you are certain the errors are benign.`,
   osdctl account aws-creds rotate -C $CLUSTER_ID --reason "$JIRA_TICKET" --managed-admin --aws-use-env
 
   # With staging cluster and production hive
-  osdctl account aws-creds rotate -C $CLUSTER_ID --reason "$JIRA_TICKET" --managed-admin --hive-ocm-url production`,
+  osdctl account aws-creds rotate -C $CLUSTER_ID --reason "$JIRA_TICKET" --managed-admin --hive-ocm-url production
+
+  # Rotate the osdManagedAdmin credentials older than 90 days of the clusters of a file
+  # (format: {"clusters":["$CLUSTERID1", "$CLUSTERID2"]})
+  osdctl account aws-creds rotate --clusters-file clusters.json --reason "$JIRA_TICKET" --managed-admin --older-than 90d
+
+  # Dry-run the batch rotation
+  osdctl account aws-creds rotate --clusters-file clusters.json --reason "$JIRA_TICKET" --managed-admin --older-than 90d --dry-run`,
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(ops.validateRotate(cmd, args))
+			if ops.clustersFile != "" {
+				cmdutil.CheckErr(runRotateBatch(cmd.Context(), ops))
+				return
+			}
 			cmdutil.CheckErr(runRotate(cmd.Context(), ops))
 		},
 	}
@@ -83,6 +106,10 @@ confirmation — only when you are certain the errors are benign.`,
 	cmd.Flags().BoolVar(&ops.refreshSecrets, "refresh-secrets", false, "Only delete and recreate CredentialRequest secrets (no key rotation)")
 	cmd.Flags().BoolVar(&ops.dryRun, "dry-run", false, "Preview rotation actions without making changes")
 	cmd.Flags().BoolVar(&ops.force, "force", false, "Allow proceeding past pre-flight errors with YES confirmation. Use only when certain the errors are benign (e.g., known SCP restrictions that won't affect rotation)")
+	cmd.Flags().StringVar(&ops.clustersFile, "clusters-file", "", `Rotate the credentials of all the clusters listed in the given JSON file (format: {"clusters":["$CLUSTERID1", "$CLUSTERID2"]})`)
+	cmd.Flags().StringVar(&ops.olderThan, "older-than", "", "Only rotate the credentials whose access key is older than this age, e.g. 90d")
+	cmd.MarkFlagsOneRequired("cluster-id", "clusters-file")
+	cmd.MarkFlagsMutuallyExclusive("cluster-id", "clusters-file")
 
 	return cmd
 }
@@ -98,6 +125,16 @@ func (o *awsCredsRotateOptions) validateRotate(cmd *cobra.Command, args []string
 	if !o.rotateManagedAdmin && !o.rotateCcsAdmin && !o.refreshSecrets {
 		return cmdutil.UsageErrorf(cmd, "at least one of --managed-admin, --ccs-admin, or --refresh-secrets is required")
 	}
+	if o.olderThan != "" {
+		if o.refreshSecrets {
+			return cmdutil.UsageErrorf(cmd, "--older-than cannot be combined with --refresh-secrets")
+		}
+		maxAge, err := parseKeyAge(o.olderThan)
+		if err != nil {
+			return cmdutil.UsageErrorf(cmd, "invalid --older-than: %v", err)
+		}
+		o.maxAge = maxAge
+	}
 	return nil
 }
 
@@ -149,6 +186,11 @@ func runRotate(ctx context.Context, o *awsCredsRotateOptions) error {
 	}
 	controller.RenderReport(report, o.Out)
 
+	if o.maxAge > 0 && !o.rotationDue(report) {
+		o.log.WithField("older_than", o.olderThan).Info("Access keys are not older than --older-than, skipping rotation")
+		return nil
+	}
+
 	if !report.AllPermissionsOK {
 		o.log.Warn("IAM permission check detected issues")
 		red := color.New(color.FgRed).SprintFunc()
@@ -204,6 +246,54 @@ func runRotate(ctx context.Context, o *awsCredsRotateOptions) error {
 	return nil
 }
 
+// rotationDue returns whether an access key of a user to rotate is older than --older-than. For osdManagedAdmin,
+// the key referenced by the Hive account secret is the one in use, other keys are left over from past rotations.
+func (o *awsCredsRotateOptions) rotationDue(report *controller.DiagnosticReport) bool {
+	for _, key := range report.Keys {
+		if key.Age <= o.maxAge {
+			continue
+		}
+		if o.rotateManagedAdmin && key.UserName == report.ManagedAdminUser && key.HiveMatch {
+			return true
+		}
+		if o.rotateCcsAdmin && key.UserName == report.CcsAdminUser {
+			return true
+		}
+	}
+	return false
+}
+
+// runRotateBatch runs the rotation workflow for each cluster of --clusters-file, continuing with the next
+// clusters when the rotation of one fails or is cancelled.
+func runRotateBatch(ctx context.Context, o *awsCredsRotateOptions) error {
+	clusterIDs, err := osdctlio.ParseAndValidateClustersFile(o.clustersFile)
+	if err != nil {
+		return err
+	}
+
+	var failed, cancelled []string
+	for i, clusterID := range clusterIDs {
+		fmt.Fprintf(o.Out, "\n[%d/%d] Cluster %s\n", i+1, len(clusterIDs), clusterID)
+		o.clusterID = clusterID
+		err := runRotate(ctx, o)
+		if errors.Is(err, errOperationCancelled) {
+			o.log.WithField("cluster", clusterID).Info("Cluster skipped")
+			cancelled = append(cancelled, clusterID)
+			continue
+		}
+		if err != nil {
+			o.log.WithError(err).WithField("cluster", clusterID).Error("Credential rotation failed")
+			failed = append(failed, clusterID)
+		}
+	}
+
+	fmt.Fprintf(o.Out, "\nProcessed %d cluster(s): %d failed, %d skipped\n", len(clusterIDs), len(failed), len(cancelled))
+	if len(failed) > 0 {
+		return fmt.Errorf("credential rotation failed for %d cluster(s): %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
 // runRefreshSecrets deletes and recreates CredentialRequest secrets without rotating AWS keys.
 func runRefreshSecrets(ctx context.Context, o *awsCredsRotateOptions, rc *resolvedCluster, report *controller.DiagnosticReport) error {
 
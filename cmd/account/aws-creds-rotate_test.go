@@ -0,0 +1,43 @@
+package account
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openshift/osdctl/pkg/controller"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotationDue(t *testing.T) {
+	day := 24 * time.Hour
+	report := &controller.DiagnosticReport{
+		ManagedAdminUser: "osdManagedAdmin-abcd",
+		CcsAdminUser:     "osdCcsAdmin",
+		Keys: []controller.KeyStatus{
+			// The current osdManagedAdmin key, and a key left over from a past rotation
+			{UserName: "osdManagedAdmin-abcd", AccessKeyID: "AKIACURRENT", Age: 30 * day, HiveMatch: true},
+			{UserName: "osdManagedAdmin-abcd", AccessKeyID: "AKIAOLD", Age: 200 * day},
+			{UserName: "osdCcsAdmin", AccessKeyID: "AKIACCS", Age: 120 * day},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		rotateManagedAdmin bool
+		rotateCcsAdmin     bool
+		maxAge             time.Duration
+		expected           bool
+	}{
+		{name: "managed admin key recent", rotateManagedAdmin: true, maxAge: 90 * day, expected: false},
+		{name: "managed admin key old", rotateManagedAdmin: true, maxAge: 20 * day, expected: true},
+		{name: "ccs admin key old", rotateCcsAdmin: true, maxAge: 90 * day, expected: true},
+		{name: "ccs admin key recent", rotateCcsAdmin: true, maxAge: 150 * day, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &awsCredsRotateOptions{rotateManagedAdmin: tt.rotateManagedAdmin, rotateCcsAdmin: tt.rotateCcsAdmin, maxAge: tt.maxAge}
+			assert.Equal(t, tt.expected, o.rotationDue(report))
+		})
+	}
+}
@@ -2,6 +2,8 @@ package account
 
 import (
 	"context"
+	"fmt"
+	"io"
 
 	"github.com/openshift/osdctl/pkg/controller"
 	"github.com/spf13/cobra"
@@ -30,6 +32,10 @@ func newCmdAWSCredsSnapshot(streams genericclioptions.IOStreams) *cobra.Command
 
 Use --cr-secrets to show only the CredentialRequest secrets table.
 
+Use -o json or -o yaml to print the report in a machine-readable format, e.g.
+for automation. Progress messages and prompts then go to stderr. The command
+exits non-zero when the report has error-level (FAIL) findings.
+
 This is a read-only operation — no credentials are modified.
 
 AWS credentials are obtained via backplane by default, falling back to the
@@ -42,6 +48,9 @@ environment credentials directly (e.g. after rh-aws-saml-login).`,
   # Only show CredentialRequest secret status
   osdctl account aws-creds snapshot -C $CLUSTER_ID --reason "$JIRA_TICKET" --cr-secrets
 
+  # Machine-readable report, exiting non-zero on error-level findings
+  osdctl account aws-creds snapshot -C $CLUSTER_ID --reason "$JIRA_TICKET" -o json
+
   # Using rh-aws-saml-login credentials (no backplane)
   kinit $USER@IPA.REDHAT.COM
   eval $(rh-aws-saml-login --output env rhcontrol)
@@ -58,7 +67,9 @@ environment credentials directly (e.g. after rh-aws-saml-login).`,
 	}
 
 	ops.addFlags(cmd)
+	_ = cmd.MarkFlagRequired("cluster-id")
 	cmd.Flags().BoolVar(&ops.crSecretsOnly, "cr-secrets", false, "Only show CredentialRequest secrets status")
+	ops.addOutputFlag(cmd)
 	return cmd
 }
 
@@ -75,23 +86,38 @@ func runSnapshot(ctx context.Context, o *awsCredsSnapshotOptions) error {
 		if err := o.resolveForCRSecrets(ctx, rc); err != nil {
 			return err
 		}
-		report, err := controller.DiagnoseCRSecrets(ctx, rc.hiveClient, rc.managedClient, rc.claimName, rc.account, o.Out)
+		report, err := controller.DiagnoseCRSecrets(ctx, rc.hiveClient, rc.managedClient, rc.claimName, rc.account, o.progressOut())
 		if err != nil {
 			return err
 		}
-		controller.RenderCredRequestTable(report, o.Out)
-		return nil
+		return o.printReport(report, controller.RenderCredRequestTable)
 	}
 
 	if err := o.resolveCluster(ctx, rc); err != nil {
 		return err
 	}
 
-	input := rc.toCredsInput(o.log, o.Out)
+	input := rc.toCredsInput(o.log, o.progressOut())
 	report, err := controller.DiagnoseCredentials(ctx, input)
 	if err != nil {
 		return err
 	}
-	controller.RenderReport(report, o.Out)
+	return o.printReport(report, controller.RenderReport)
+}
+
+// printReport prints the report with render, or serialized in the --output format. It fails when the report has
+// error-level findings, so that automation can alert on them.
+func (o *awsCredsSnapshotOptions) printReport(report *controller.DiagnosticReport, render func(*controller.DiagnosticReport, io.Writer)) error {
+	if o.output != "" {
+		if err := controller.WriteReport(report, o.output, o.Out); err != nil {
+			return err
+		}
+	} else {
+		render(report, o.Out)
+	}
+
+	if failed := report.FailedFindings(); failed > 0 {
+		return fmt.Errorf("credential diagnostics found %d error-level finding(s)", failed)
+	}
 	return nil
 }
@@ -0,0 +1,61 @@
+package account
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/openshift/osdctl/pkg/controller"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestSnapshotPrintReport(t *testing.T) {
+	healthy := &controller.DiagnosticReport{
+		AWSAccountID:  "123456789012",
+		AccountCRName: "test-account",
+		Findings:      []controller.Finding{{Severity: "WARN", Message: "osdManagedAdmin-abcd has 2 access keys (max 2)"}},
+	}
+	failing := &controller.DiagnosticReport{
+		AWSAccountID:  "123456789012",
+		AccountCRName: "test-account",
+		Findings:      []controller.Finding{{Severity: "FAIL", Message: "Hive secret aws/uhc-production-test not found"}},
+	}
+
+	tests := []struct {
+		name        string
+		output      string
+		report      *controller.DiagnosticReport
+		expectedErr bool
+	}{
+		{name: "table without errors", report: healthy},
+		{name: "table with errors", report: failing, expectedErr: true},
+		{name: "json without errors", output: "json", report: healthy},
+		{name: "json with errors", output: "json", report: failing, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := &awsCredsSnapshotOptions{awsCredsOptions: awsCredsOptions{IOStreams: streams, output: tt.output}}
+
+			rendered := false
+			err := o.printReport(tt.report, func(*controller.DiagnosticReport, io.Writer) { rendered = true })
+			if tt.expectedErr {
+				assert.ErrorContains(t, err, "1 error-level finding(s)")
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.output == "" {
+				assert.True(t, rendered)
+				return
+			}
+			assert.False(t, rendered)
+			var decoded map[string]any
+			require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+			assert.Equal(t, "test-account", decoded["accountCRName"])
+		})
+	}
+}
@@ -3,11 +3,14 @@ package account
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
@@ -67,7 +70,7 @@ import (
 //   - This path does NOT read osdCcsAdmin or osdManagedAdmin credentials.
 //   - The dependency is indirect: osdCcsAdmin created the infrastructure (roles)
 //     that backplane later assumes.
-func newCmdAWSCreds(streams genericclioptions.IOStreams) *cobra.Command {
+func newCmdAWSCreds(streams genericclioptions.IOStreams, kubeCli client.Client) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:               "aws-creds",
 		Short:             "Diagnose and manage AWS IAM credentials for a cluster",
@@ -77,6 +80,7 @@ func newCmdAWSCreds(streams genericclioptions.IOStreams) *cobra.Command {
 
 	cmd.AddCommand(newCmdAWSCredsSnapshot(streams))
 	cmd.AddCommand(newCmdAWSCredsRotate(streams))
+	cmd.AddCommand(newCmdAWSCredsAgeReport(streams, kubeCli))
 
 	return cmd
 }
@@ -89,6 +93,7 @@ type awsCredsOptions struct {
 	adminUsername string
 	hiveOcmUrl    string
 	logLevel      string
+	output        string
 
 	log *logrus.Logger
 	genericclioptions.IOStreams
@@ -115,12 +120,26 @@ func (o *awsCredsOptions) addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.hiveOcmUrl, "hive-ocm-url", "", "OCM environment for Hive operations (aliases: production, staging, integration)")
 	cmd.Flags().StringVarP(&o.logLevel, "log-level", "l", "info", "Log level: debug, info, warn, error")
 
-	_ = cmd.MarkFlagRequired("cluster-id")
 	_ = cmd.MarkFlagRequired("reason")
 
 	hideIrrelevantGlobalFlags(cmd)
 }
 
+// addOutputFlag adds the -o/--output flag of the subcommands which can print their report in a machine-readable format.
+// It shadows the global --output flag, whose formats do not apply.
+func (o *awsCredsOptions) addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "Output format: json or yaml. If omitted, prints a human-readable report")
+}
+
+// progressOut returns the writer for the progress messages and prompts, which go to stderr when the report is printed
+// in a machine-readable format so that stdout can be parsed.
+func (o *awsCredsOptions) progressOut() io.Writer {
+	if o.output != "" {
+		return o.ErrOut
+	}
+	return o.Out
+}
+
 const subcommandUsageTemplate = `Usage:{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
   {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
@@ -151,6 +170,9 @@ func hideIrrelevantGlobalFlags(cmd *cobra.Command) {
 	cmd.SetUsageTemplate(subcommandUsageTemplate)
 }
 
+// errOperationCancelled is returned when the user does not confirm the cluster to operate on
+var errOperationCancelled = errors.New("operation cancelled by user")
+
 var (
 	jiraTicketPattern    = regexp.MustCompile(`(OHSS|SREP|ROSAENG|OSD|SDE|CSSRE|MNGT)-\d+`)
 	pdIncidentIDPattern  = regexp.MustCompile(`(?:pagerduty\.com/incidents/|^|\s)([A-Z0-9]{10,})`)
@@ -273,23 +295,24 @@ func (o *awsCredsOptions) validatePDIncidents(rc *resolvedCluster, incidents []s
 
 // confirmCluster displays cluster details and prompts the user to confirm they are operating on the correct cluster.
 func (o *awsCredsOptions) confirmCluster(rc *resolvedCluster) bool {
+	out := o.progressOut()
 	ccsStr := "No"
 	if rc.isCCS {
 		ccsStr = "Yes"
 	}
-	fmt.Fprintf(o.Out, "\n==========================================================================\n")
-	fmt.Fprintf(o.Out, " Cluster: %s (%s)\n", rc.cluster.Name(), rc.internalID)
-	fmt.Fprintf(o.Out, " External ID: %s\n", rc.cluster.ExternalID())
-	fmt.Fprintf(o.Out, " CCS/BYOC: %s\n", ccsStr)
-	fmt.Fprintf(o.Out, " Reason: %s\n", o.reason)
-	fmt.Fprintf(o.Out, "==========================================================================\n")
+	fmt.Fprintf(out, "\n==========================================================================\n")
+	fmt.Fprintf(out, " Cluster: %s (%s)\n", rc.cluster.Name(), rc.internalID)
+	fmt.Fprintf(out, " External ID: %s\n", rc.cluster.ExternalID())
+	fmt.Fprintf(out, " CCS/BYOC: %s\n", ccsStr)
+	fmt.Fprintf(out, " Reason: %s\n", o.reason)
+	fmt.Fprintf(out, "==========================================================================\n")
 
 	warned := o.validateReason(rc)
 
 	if warned {
-		fmt.Fprintf(o.Out, "\nThe ticket/incident in --reason was not found to be associated with this cluster.\nAre you sure you want to continue? ")
+		fmt.Fprintf(out, "\nThe ticket/incident in --reason was not found to be associated with this cluster.\nAre you sure you want to continue? ")
 	} else {
-		fmt.Fprintf(o.Out, "\nIs this the correct cluster? ")
+		fmt.Fprintf(out, "\nIs this the correct cluster? ")
 	}
 	return utils.ConfirmPrompt()
 }
@@ -302,6 +325,10 @@ func (o *awsCredsOptions) validate(cmd *cobra.Command, _ []string) error {
 	}
 	o.log.SetLevel(level)
 
+	if o.output != "" && o.output != "json" && o.output != "yaml" {
+		return cmdutil.UsageErrorf(cmd, "invalid --output '%s': valid formats are json, yaml", o.output)
+	}
+
 	if o.awsUseEnv && o.profile != "" {
 		return cmdutil.UsageErrorf(cmd, "--aws-use-env and --aws-profile are mutually exclusive")
 	}
@@ -373,7 +400,7 @@ func (o *awsCredsOptions) identifyCluster() (*resolvedCluster, error) {
 
 	if !o.confirmCluster(rc) {
 		ocmConn.Close()
-		return nil, fmt.Errorf("operation cancelled by user")
+		return nil, errOperationCancelled
 	}
 
 	return rc, nil
@@ -724,6 +751,17 @@ func (o *awsCredsOptions) resolveForCRSecrets(ctx context.Context, rc *resolvedC
 // Reads env vars directly and feeds them to roleChainToSupport, bypassing NewAwsClient
 // which applies proxy config that interferes with credential chain resolution.
 func (o *awsCredsOptions) buildAWSClientViaEnv(ctx context.Context, hiveClient client.Client, account *awsv1alpha1.Account, accountID, suffixLabel string) (awsprovider.Client, error) {
+	awsSetupClient, err := newAWSClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return o.roleChainToSupport(ctx, awsSetupClient, hiveClient, account, accountID, suffixLabel)
+}
+
+// newAWSClientFromEnv creates an AWS client from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+// AWS_SESSION_TOKEN environment variables.
+func newAWSClientFromEnv() (awsprovider.Client, error) {
 	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
 	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
 	sessionToken := os.Getenv("AWS_SESSION_TOKEN")
@@ -732,7 +770,7 @@ func (o *awsCredsOptions) buildAWSClientViaEnv(ctx context.Context, hiveClient c
 		return nil, fmt.Errorf("--aws-use-env requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables (e.g. from rh-aws-saml-login --output env)")
 	}
 
-	awsSetupClient, err := awsprovider.NewAwsClientWithInput(&awsprovider.ClientInput{
+	awsClient, err := awsprovider.NewAwsClientWithInput(&awsprovider.ClientInput{
 		AccessKeyID:     accessKey,
 		SecretAccessKey: secretKey,
 		SessionToken:    sessionToken,
@@ -741,8 +779,45 @@ func (o *awsCredsOptions) buildAWSClientViaEnv(ctx context.Context, hiveClient c
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS client from environment credentials: %w", err)
 	}
+	return awsClient, nil
+}
 
-	return o.roleChainToSupport(ctx, awsSetupClient, hiveClient, account, accountID, suffixLabel)
+// buildAWSSetupClient creates the initial AWS client for role chaining without backplane: from --aws-profile,
+// from the environment with --aws-use-env, or else from the default AWS credential chain.
+func (o *awsCredsOptions) buildAWSSetupClient() (awsprovider.Client, error) {
+	if o.profile != "" {
+		awsSetupClient, err := awsprovider.NewAwsClient(o.profile, "us-east-1", "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create initial AWS client with profile '%s': %w", o.profile, err)
+		}
+		return awsSetupClient, nil
+	}
+	if o.awsUseEnv {
+		return newAWSClientFromEnv()
+	}
+
+	awsSetupClient, err := awsprovider.NewAwsClient("", "us-east-1", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client from default credential chain: %w", err)
+	}
+	return awsSetupClient, nil
+}
+
+// parseKeyAge parses an access key age given in days, e.g. 90d, or as a duration accepted by time.ParseDuration.
+func parseKeyAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age '%s': expected a number of days, e.g. 90d", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age '%s': expected a number of days, e.g. 90d, or a duration, e.g. 2160h", value)
+	}
+	return age, nil
 }
 
 // buildAWSClientViaDefaultChain uses the default AWS credential chain (env vars, ~/.aws/config)
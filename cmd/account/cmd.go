@@ -34,8 +34,9 @@ func NewCmdAccount(streams genericclioptions.IOStreams, client *k8s.LazyClient,
 	accountCmd.AddCommand(newCmdCleanVeleroSnapshots(streams))
 	accountCmd.AddCommand(newCmdVerifySecrets(streams, client))
 	accountCmd.AddCommand(newCmdRotateSecret(streams, client))
-	accountCmd.AddCommand(newCmdAWSCreds(streams))
+	accountCmd.AddCommand(newCmdAWSCreds(streams, client))
 	accountCmd.AddCommand(newCmdGenerateSecret(streams, client))
+	accountCmd.AddCommand(newCmdAudit(streams, client, globalOpts))
 
 	return accountCmd
 }
@@ -0,0 +1,494 @@
+package cost
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	costExplorerTypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	outputflag "github.com/openshift/osdctl/cmd/getoutput"
+	"github.com/openshift/osdctl/internal/utils/globalflags"
+	"github.com/openshift/osdctl/pkg/printer"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	costDateFormat = "2006-01-02"
+
+	comparisonDayOverDay   = "day-over-day"
+	comparisonWeekOverWeek = "week-over-week"
+
+	// Number of most recent days the month end forecast is fitted on
+	forecastFitDays = 14
+)
+
+// anomaliesOptions defines the struct for running the anomalies command
+type anomaliesOptions struct {
+	ou           string
+	recursive    bool
+	days         int
+	baselineDays int
+	threshold    float64
+	minCost      float64
+	topServices  int
+	output       string
+
+	genericclioptions.IOStreams
+	GlobalOptions *globalflags.GlobalOptions
+}
+
+// dailyCost is the cost of an account on a day, by service
+type dailyCost struct {
+	date     time.Time
+	total    float64
+	services map[string]float64
+}
+
+type serviceCostChange struct {
+	Service string          `json:"service" yaml:"service"`
+	Change  decimal.Decimal `json:"change" yaml:"change"`
+}
+
+// costAnomaly is a day on which the cost of an account spiked compared to the previous day or week
+type costAnomaly struct {
+	AccountId     string              `json:"accountId" yaml:"accountId"`
+	Date          string              `json:"date" yaml:"date"`
+	Comparison    string              `json:"comparison" yaml:"comparison"`
+	Cost          decimal.Decimal     `json:"cost" yaml:"cost"`
+	PreviousCost  decimal.Decimal     `json:"previousCost" yaml:"previousCost"`
+	ChangePercent decimal.Decimal     `json:"changePercent" yaml:"changePercent"`
+	Baseline      decimal.Decimal     `json:"baseline" yaml:"baseline"`
+	TopServices   []serviceCostChange `json:"topServices" yaml:"topServices"`
+}
+
+// accountForecast is the month end cost forecast of an account
+type accountForecast struct {
+	AccountId        string          `json:"accountId" yaml:"accountId"`
+	Baseline         decimal.Decimal `json:"baseline" yaml:"baseline"`
+	MonthToDate      decimal.Decimal `json:"monthToDate" yaml:"monthToDate"`
+	MonthEndForecast decimal.Decimal `json:"monthEndForecast" yaml:"monthEndForecast"`
+}
+
+type anomaliesResponse struct {
+	OuId      string            `json:"ouid" yaml:"ouid"`
+	Start     string            `json:"start" yaml:"start"`
+	End       string            `json:"end" yaml:"end"`
+	Threshold float64           `json:"thresholdPercent" yaml:"thresholdPercent"`
+	Unit      string            `json:"unit" yaml:"unit"`
+	Anomalies []costAnomaly     `json:"anomalies" yaml:"anomalies"`
+	Forecasts []accountForecast `json:"forecasts" yaml:"forecasts"`
+}
+
+func (r anomaliesResponse) String() string {
+	var b bytes.Buffer
+
+	if len(r.Anomalies) == 0 {
+		fmt.Fprintf(&b, "No cost anomalies above %s%% between %s and %s\n", strconv.FormatFloat(r.Threshold, 'f', -1, 64), r.Start, r.End)
+	} else {
+		p := printer.NewTablePrinter(&b, 20, 1, 3, ' ')
+		p.AddRow([]string{"ACCOUNT", "DATE", "COMPARISON", "COST", "PREVIOUS", "CHANGE", "BASELINE", "TOP SERVICES"})
+		for _, anomaly := range r.Anomalies {
+			var services []string
+			for _, service := range anomaly.TopServices {
+				services = append(services, fmt.Sprintf("%s (+%s)", service.Service, service.Change.StringFixed(2)))
+			}
+			p.AddRow([]string{
+				anomaly.AccountId,
+				anomaly.Date,
+				anomaly.Comparison,
+				anomaly.Cost.StringFixed(2),
+				anomaly.PreviousCost.StringFixed(2),
+				"+" + anomaly.ChangePercent.StringFixed(0) + "%",
+				anomaly.Baseline.StringFixed(2),
+				strings.Join(services, ", "),
+			})
+		}
+		_ = p.Flush()
+	}
+
+	fmt.Fprintln(&b)
+	p := printer.NewTablePrinter(&b, 20, 1, 3, ' ')
+	p.AddRow([]string{"ACCOUNT", "BASELINE/DAY", "MONTH TO DATE", "MONTH END FORECAST"})
+	for _, forecast := range r.Forecasts {
+		p.AddRow([]string{
+			forecast.AccountId,
+			forecast.Baseline.StringFixed(2),
+			forecast.MonthToDate.StringFixed(2),
+			forecast.MonthEndForecast.StringFixed(2),
+		})
+	}
+	_ = p.Flush()
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// newCmdAnomalies represents the anomalies command
+func newCmdAnomalies(streams genericclioptions.IOStreams, globalOpts *globalflags.GlobalOptions) *cobra.Command {
+	ops := newAnomaliesOptions(streams, globalOpts)
+	anomaliesCmd := &cobra.Command{
+		Use:   "anomalies",
+		Short: "Detect cost spikes of the accounts under given OU and forecast their month end cost",
+		Long: `Detect cost spikes of the accounts under given OU and forecast their month end cost.
+
+The daily cost of each account is retrieved from Cost Explorer for the last --days days, along with the
+--baseline-days days before them. The baseline of an account is its mean daily cost over the baseline days.
+
+A day is reported as an anomaly when its cost exceeds the baseline of the account by more than --threshold
+percent, and grew by more than --threshold percent compared to the previous day (day-over-day) or to the same
+day of the previous week (week-over-week). Previous costs below --min-cost are raised to it, so that new spend does not produce
+infinite changes and small accounts do not produce noise. The services whose cost grew the most are listed
+for each anomaly.
+
+The month end cost of each account is forecast by fitting a line to its last 14 daily costs, and adding
+the projected cost of the remaining days of the month to the cost of the month to date.`,
+		Example: `  # Detect the cost spikes of the last week of the accounts directly under an OU
+  osdctl cost anomalies --ou ${OU_ID}
+
+  # Detect the cost spikes above 100% of the last 2 weeks of all accounts under an OU, as JSON
+  osdctl cost anomalies --ou ${OU_ID} --recursive --days 14 --threshold 100 -o json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(ops.checkArgs(cmd, args))
+			cmdutil.CheckErr(ops.run())
+		},
+	}
+	anomaliesCmd.Flags().StringVar(&ops.ou, "ou", "", "set OU ID")
+	anomaliesCmd.Flags().BoolVarP(&ops.recursive, "recursive", "r", false, "recurse through OUs")
+	anomaliesCmd.Flags().IntVar(&ops.days, "days", 7, "number of most recent days to check for spikes")
+	anomaliesCmd.Flags().IntVar(&ops.baselineDays, "baseline-days", 28, "number of days before the checked days used for the baseline of each account")
+	anomaliesCmd.Flags().Float64Var(&ops.threshold, "threshold", 50, "minimum day-over-day or week-over-week cost increase, in percent, to report a spike")
+	anomaliesCmd.Flags().Float64Var(&ops.minCost, "min-cost", 10, "minimum daily cost, in USD, considered when comparing costs")
+	anomaliesCmd.Flags().IntVar(&ops.topServices, "top-services", 3, "number of top contributing services listed per spike")
+
+	return anomaliesCmd
+}
+
+func newAnomaliesOptions(streams genericclioptions.IOStreams, globalOpts *globalflags.GlobalOptions) *anomaliesOptions {
+	return &anomaliesOptions{
+		IOStreams:     streams,
+		GlobalOptions: globalOpts,
+	}
+}
+
+func (o *anomaliesOptions) checkArgs(cmd *cobra.Command, _ []string) error {
+	if o.ou == "" {
+		return cmdutil.UsageErrorf(cmd, "Please provide OU")
+	}
+	if o.days < 1 {
+		return cmdutil.UsageErrorf(cmd, "--days must be at least 1")
+	}
+	// The week-over-week comparison of the first checked day needs the costs of the previous week
+	if o.baselineDays < 7 {
+		return cmdutil.UsageErrorf(cmd, "--baseline-days must be at least 7")
+	}
+	if o.threshold <= 0 {
+		return cmdutil.UsageErrorf(cmd, "--threshold must be greater than 0")
+	}
+	if o.minCost < 0 {
+		return cmdutil.UsageErrorf(cmd, "--min-cost must not be negative")
+	}
+	if o.topServices < 0 {
+		return cmdutil.UsageErrorf(cmd, "--top-services must not be negative")
+	}
+
+	if o.GlobalOptions != nil {
+		o.output = o.GlobalOptions.Output
+	}
+
+	return nil
+}
+
+func (o *anomaliesOptions) run() error {
+	awsClient, err := opsCost.initAWSClients()
+	if err != nil {
+		return err
+	}
+
+	resp, err := o.detectAnomalies(awsClient, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	return outputflag.PrintResponse(o.output, resp)
+}
+
+// detectAnomalies retrieves the daily costs of the accounts under the OU up to today, excluded as its costs are partial
+func (o *anomaliesOptions) detectAnomalies(awsClient awsprovider.Client, now time.Time) (*anomaliesResponse, error) {
+	OU := getOU(awsClient, o.ou)
+
+	var accounts []*string
+	var err error
+	if o.recursive {
+		accounts, err = getAccountsRecursive(OU, awsClient)
+	} else {
+		accounts, err = getAccounts(OU, awsClient)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the accounts under OU %s: %w", o.ou, err)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	checkStart := today.AddDate(0, 0, -o.days)
+	start := checkStart.AddDate(0, 0, -o.baselineDays)
+	if monthStart.Before(start) {
+		start = monthStart
+	}
+
+	resp := &anomaliesResponse{
+		OuId:      o.ou,
+		Start:     checkStart.Format(costDateFormat),
+		End:       today.AddDate(0, 0, -1).Format(costDateFormat),
+		Threshold: o.threshold,
+		Anomalies: []costAnomaly{},
+		Forecasts: []accountForecast{},
+	}
+
+	for _, account := range accounts {
+		costs, unit, err := getAccountDailyCosts(awsClient, *account, start, today)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the daily costs of account %s: %w", *account, err)
+		}
+		if unit != "" {
+			resp.Unit = unit
+		}
+
+		baseline := baselineCost(costs, checkStart.AddDate(0, 0, -o.baselineDays), checkStart)
+		resp.Anomalies = append(resp.Anomalies, o.accountAnomalies(*account, costs, baseline, checkStart)...)
+		resp.Forecasts = append(resp.Forecasts, accountForecast{
+			AccountId:        *account,
+			Baseline:         decimal.NewFromFloat(baseline).Round(2),
+			MonthToDate:      decimal.NewFromFloat(monthToDateCost(costs, monthStart)).Round(2),
+			MonthEndForecast: decimal.NewFromFloat(forecastMonthEnd(costs, monthStart, today)).Round(2),
+		})
+	}
+
+	sort.SliceStable(resp.Anomalies, func(i, j int) bool {
+		if resp.Anomalies[i].Date != resp.Anomalies[j].Date {
+			return resp.Anomalies[i].Date > resp.Anomalies[j].Date
+		}
+		return resp.Anomalies[j].ChangePercent.LessThan(resp.Anomalies[i].ChangePercent)
+	})
+	sort.SliceStable(resp.Forecasts, func(i, j int) bool {
+		return resp.Forecasts[j].MonthEndForecast.LessThan(resp.Forecasts[i].MonthEndForecast)
+	})
+
+	return resp, nil
+}
+
+// getAccountDailyCosts returns the daily costs of the account by service between start and end (excluded), sorted by date
+func getAccountDailyCosts(awsClient awsprovider.Client, accountID string, start time.Time, end time.Time) ([]dailyCost, string, error) {
+	startDate := start.Format(costDateFormat)
+	endDate := end.Format(costDateFormat)
+
+	costsByDate := map[string]*dailyCost{}
+	var unit string
+	var nextPageToken *string
+	for {
+		costs, err := awsClient.GetCostAndUsage(&costexplorer.GetCostAndUsageInput{
+			Filter: &costExplorerTypes.Expression{
+				Dimensions: &costExplorerTypes.DimensionValues{
+					Key:    "LINKED_ACCOUNT",
+					Values: []string{accountID},
+				},
+			},
+			TimePeriod: &costExplorerTypes.DateInterval{
+				Start: &startDate,
+				End:   &endDate,
+			},
+			Granularity: costExplorerTypes.GranularityDaily,
+			Metrics:     []string{"NetUnblendedCost"},
+			GroupBy: []costExplorerTypes.GroupDefinition{{
+				Type: costExplorerTypes.GroupDefinitionTypeDimension,
+				Key:  aws.String("SERVICE"),
+			}},
+			NextPageToken: nextPageToken,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, result := range costs.ResultsByTime {
+			if result.TimePeriod == nil || result.TimePeriod.Start == nil {
+				continue
+			}
+			date, err := time.Parse(costDateFormat, *result.TimePeriod.Start)
+			if err != nil {
+				return nil, "", err
+			}
+			day, ok := costsByDate[*result.TimePeriod.Start]
+			if !ok {
+				day = &dailyCost{date: date, services: map[string]float64{}}
+				costsByDate[*result.TimePeriod.Start] = day
+			}
+
+			for _, group := range result.Groups {
+				metric, ok := group.Metrics["NetUnblendedCost"]
+				if !ok || metric.Amount == nil || len(group.Keys) == 0 {
+					continue
+				}
+				amount, err := strconv.ParseFloat(*metric.Amount, 64)
+				if err != nil {
+					return nil, "", err
+				}
+				if metric.Unit != nil {
+					unit = *metric.Unit
+				}
+				day.services[group.Keys[0]] += amount
+				day.total += amount
+			}
+		}
+
+		if costs.NextPageToken == nil {
+			break
+		}
+		nextPageToken = costs.NextPageToken
+	}
+
+	daily := make([]dailyCost, 0, len(costsByDate))
+	for _, day := range costsByDate {
+		daily = append(daily, *day)
+	}
+	sort.Slice(daily, func(i, j int) bool {
+		return daily[i].date.Before(daily[j].date)
+	})
+
+	return daily, unit, nil
+}
+
+// baselineCost returns the mean daily cost between start and end (excluded)
+func baselineCost(costs []dailyCost, start time.Time, end time.Time) float64 {
+	var sum float64
+	var days int
+	for _, day := range costs {
+		if !day.date.Before(start) && day.date.Before(end) {
+			sum += day.total
+			days++
+		}
+	}
+	if days == 0 {
+		return 0
+	}
+	return sum / float64(days)
+}
+
+// accountAnomalies returns the days since checkStart on which the cost of the account spiked
+func (o *anomaliesOptions) accountAnomalies(accountID string, costs []dailyCost, baseline float64, checkStart time.Time) []costAnomaly {
+	costsByDate := make(map[time.Time]dailyCost, len(costs))
+	for _, day := range costs {
+		costsByDate[day.date] = day
+	}
+
+	var anomalies []costAnomaly
+	for _, day := range costs {
+		if day.date.Before(checkStart) || day.total < o.minCost || day.total <= baseline*(1+o.threshold/100) {
+			continue
+		}
+
+		// Keep the comparison with the largest increase
+		var previous dailyCost
+		var comparison string
+		var change float64
+		for _, c := range []struct {
+			name string
+			days int
+		}{{comparisonDayOverDay, 1}, {comparisonWeekOverWeek, 7}} {
+			previousDay := costsByDate[day.date.AddDate(0, 0, -c.days)]
+			previousCost := math.Max(previousDay.total, o.minCost)
+			increase := (day.total - previousCost) / previousCost * 100
+			if increase > o.threshold && increase > change {
+				previous, comparison, change = previousDay, c.name, increase
+			}
+		}
+		if comparison == "" {
+			continue
+		}
+
+		anomalies = append(anomalies, costAnomaly{
+			AccountId:     accountID,
+			Date:          day.date.Format(costDateFormat),
+			Comparison:    comparison,
+			Cost:          decimal.NewFromFloat(day.total).Round(2),
+			PreviousCost:  decimal.NewFromFloat(previous.total).Round(2),
+			ChangePercent: decimal.NewFromFloat(change).Round(2),
+			Baseline:      decimal.NewFromFloat(baseline).Round(2),
+			TopServices:   topServiceCostChanges(previous, day, o.topServices),
+		})
+	}
+
+	return anomalies
+}
+
+// topServiceCostChanges returns the services whose cost grew the most between the two days
+func topServiceCostChanges(previous dailyCost, current dailyCost, limit int) []serviceCostChange {
+	var changes []serviceCostChange
+	for service, cost := range current.services {
+		change := cost - previous.services[service]
+		if change <= 0 {
+			continue
+		}
+		changes = append(changes, serviceCostChange{Service: service, Change: decimal.NewFromFloat(change).Round(2)})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].Change.Equal(changes[j].Change) {
+			return changes[j].Change.LessThan(changes[i].Change)
+		}
+		return changes[i].Service < changes[j].Service
+	})
+
+	return changes[:min(limit, len(changes))]
+}
+
+func monthToDateCost(costs []dailyCost, monthStart time.Time) float64 {
+	var sum float64
+	for _, day := range costs {
+		if !day.date.Before(monthStart) {
+			sum += day.total
+		}
+	}
+	return sum
+}
+
+// forecastMonthEnd returns the cost of the month to date, plus the cost of the remaining days of the month (today
+// included) projected by a least squares line fitted on the last daily costs
+func forecastMonthEnd(costs []dailyCost, monthStart time.Time, today time.Time) float64 {
+	forecast := monthToDateCost(costs, monthStart)
+
+	fitted := costs[max(0, len(costs)-forecastFitDays):]
+	if len(fitted) == 0 {
+		return forecast
+	}
+
+	// x is the number of days since today, so that the projected days are 0, 1, ...
+	var sumX, sumY, sumXY, sumXX float64
+	for _, day := range fitted {
+		x := day.date.Sub(today).Hours() / 24
+		sumX += x
+		sumY += day.total
+		sumXY += x * day.total
+		sumXX += x * x
+	}
+	n := float64(len(fitted))
+	slope := 0.0
+	if denominator := n*sumXX - sumX*sumX; denominator != 0 {
+		slope = (n*sumXY - sumX*sumY) / denominator
+	}
+	intercept := (sumY - slope*sumX) / n
+
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	for x := 0; today.AddDate(0, 0, x).Before(monthEnd); x++ {
+		forecast += math.Max(0, intercept+slope*float64(x))
+	}
+
+	return forecast
+}
@@ -0,0 +1,198 @@
+package cost
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	costExplorerTypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/openshift/osdctl/pkg/provider/aws/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func date(day string) time.Time {
+	d, _ := time.Parse(costDateFormat, day)
+	return d
+}
+
+// dailyCostResults returns daily Cost Explorer results grouped by service, between start and end (excluded)
+func dailyCostResults(start time.Time, end time.Time, servicesCost func(day time.Time) map[string]float64) []costExplorerTypes.ResultByTime {
+	var results []costExplorerTypes.ResultByTime
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		result := costExplorerTypes.ResultByTime{
+			TimePeriod: &costExplorerTypes.DateInterval{
+				Start: aws.String(day.Format(costDateFormat)),
+				End:   aws.String(day.AddDate(0, 0, 1).Format(costDateFormat)),
+			},
+		}
+		for service, cost := range servicesCost(day) {
+			result.Groups = append(result.Groups, costExplorerTypes.Group{
+				Keys: []string{service},
+				Metrics: map[string]costExplorerTypes.MetricValue{
+					"NetUnblendedCost": {Amount: aws.String(strconv.FormatFloat(cost, 'f', -1, 64)), Unit: aws.String("USD")},
+				},
+			})
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestAnomaliesCheckArgs(t *testing.T) {
+	valid := func() *anomaliesOptions {
+		return &anomaliesOptions{ou: "ou-1234", days: 7, baselineDays: 28, threshold: 50, minCost: 10, topServices: 3}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(o *anomaliesOptions)
+		wantErr string
+	}{
+		{name: "valid", modify: func(o *anomaliesOptions) {}},
+		{name: "no top services", modify: func(o *anomaliesOptions) { o.topServices = 0 }},
+		{name: "missing OU", modify: func(o *anomaliesOptions) { o.ou = "" }, wantErr: "Please provide OU"},
+		{name: "short baseline", modify: func(o *anomaliesOptions) { o.baselineDays = 6 }, wantErr: "--baseline-days must be at least 7"},
+		{name: "negative top services", modify: func(o *anomaliesOptions) { o.topServices = -1 }, wantErr: "--top-services must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := valid()
+			tt.modify(o)
+			err := o.checkArgs(newCmdAnomalies(genericclioptions.IOStreams{}, nil), nil)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockClient(ctrl)
+
+	now := time.Date(2026, 3, 20, 15, 4, 5, 0, time.UTC)
+	mockClient.EXPECT().DescribeOrganizationalUnit(gomock.Any()).Return(&organizations.DescribeOrganizationalUnitOutput{
+		OrganizationalUnit: &types.OrganizationalUnit{Id: aws.String("ou-123"), Name: aws.String("Test")},
+	}, nil)
+	mockClient.EXPECT().ListAccountsForParent(gomock.Any()).Return(&organizations.ListAccountsForParentOutput{
+		Accounts: []types.Account{{Id: aws.String("111111111111")}, {Id: aws.String("222222222222")}},
+	}, nil)
+
+	costsByAccount := map[string]func(day time.Time) map[string]float64{
+		// Spikes on 2026-03-18
+		"111111111111": func(day time.Time) map[string]float64 {
+			if day.Equal(date("2026-03-18")) {
+				return map[string]float64{"Amazon EC2": 230, "Amazon S3": 60, "AWS KMS": 10}
+			}
+			return map[string]float64{"Amazon EC2": 80, "Amazon S3": 10, "AWS KMS": 10}
+		},
+		// Doubles every day, but stays below --min-cost
+		"222222222222": func(day time.Time) map[string]float64 {
+			if day.Day()%2 == 0 {
+				return map[string]float64{"Amazon EC2": 5}
+			}
+			return map[string]float64{"Amazon EC2": 2.5}
+		},
+	}
+	mockClient.EXPECT().GetCostAndUsage(gomock.Any()).Times(2).DoAndReturn(func(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+		assert.Equal(t, costExplorerTypes.GranularityDaily, input.Granularity)
+		assert.Equal(t, "2026-02-13", *input.TimePeriod.Start)
+		assert.Equal(t, "2026-03-20", *input.TimePeriod.End)
+		require.Len(t, input.GroupBy, 1)
+		assert.Equal(t, "SERVICE", *input.GroupBy[0].Key)
+
+		account := input.Filter.Dimensions.Values[0]
+		return &costexplorer.GetCostAndUsageOutput{
+			ResultsByTime: dailyCostResults(date("2026-02-13"), date("2026-03-20"), costsByAccount[account]),
+		}, nil
+	})
+
+	o := &anomaliesOptions{ou: "ou-123", days: 7, baselineDays: 28, threshold: 50, minCost: 10, topServices: 2}
+	resp, err := o.detectAnomalies(mockClient, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2026-03-13", resp.Start)
+	assert.Equal(t, "2026-03-19", resp.End)
+	assert.Equal(t, "USD", resp.Unit)
+
+	require.Len(t, resp.Anomalies, 1)
+	anomaly := resp.Anomalies[0]
+	assert.Equal(t, "111111111111", anomaly.AccountId)
+	assert.Equal(t, "2026-03-18", anomaly.Date)
+	assert.Equal(t, comparisonDayOverDay, anomaly.Comparison)
+	assert.Equal(t, "300.00", anomaly.Cost.StringFixed(2))
+	assert.Equal(t, "100.00", anomaly.PreviousCost.StringFixed(2))
+	assert.Equal(t, "200.00", anomaly.ChangePercent.StringFixed(2))
+	assert.Equal(t, "100.00", anomaly.Baseline.StringFixed(2))
+	require.Len(t, anomaly.TopServices, 2)
+	assert.Equal(t, "Amazon EC2", anomaly.TopServices[0].Service)
+	assert.Equal(t, "150.00", anomaly.TopServices[0].Change.StringFixed(2))
+	assert.Equal(t, "Amazon S3", anomaly.TopServices[1].Service)
+	assert.Equal(t, "50.00", anomaly.TopServices[1].Change.StringFixed(2))
+
+	require.Len(t, resp.Forecasts, 2)
+	assert.Equal(t, "111111111111", resp.Forecasts[0].AccountId)
+	assert.Equal(t, "2100.00", resp.Forecasts[0].MonthToDate.StringFixed(2))
+	assert.Equal(t, "222222222222", resp.Forecasts[1].AccountId)
+
+	output := resp.String()
+	assert.Contains(t, output, "Amazon EC2 (+150.00), Amazon S3 (+50.00)")
+	assert.Contains(t, output, "MONTH END FORECAST")
+}
+
+func TestAccountAnomaliesWeekOverWeek(t *testing.T) {
+	var costs []dailyCost
+	for day := date("2026-03-01"); day.Before(date("2026-03-15")); day = day.AddDate(0, 0, 1) {
+		cost := 100.0
+		switch day.Weekday() {
+		case time.Saturday, time.Sunday:
+			cost = 20
+		}
+		// The second weekend is busier than the first, but not than the previous weekday
+		if day.Equal(date("2026-03-14")) {
+			cost = 150
+		}
+		costs = append(costs, dailyCost{date: day, total: cost, services: map[string]float64{"Amazon EC2": cost}})
+	}
+
+	o := &anomaliesOptions{threshold: 50, minCost: 10, topServices: 3}
+	anomalies := o.accountAnomalies("111111111111", costs, 80, date("2026-03-08"))
+
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "2026-03-14", anomalies[0].Date)
+	assert.Equal(t, comparisonWeekOverWeek, anomalies[0].Comparison)
+	assert.Equal(t, "20.00", anomalies[0].PreviousCost.StringFixed(2))
+	assert.Equal(t, "650.00", anomalies[0].ChangePercent.StringFixed(2))
+}
+
+func TestForecastMonthEnd(t *testing.T) {
+	var costs []dailyCost
+	for day := date("2026-03-01"); day.Before(date("2026-03-20")); day = day.AddDate(0, 0, 1) {
+		costs = append(costs, dailyCost{date: day, total: float64(day.Day())})
+	}
+
+	// 1 + ... + 19 to date, and 20 + ... + 31 projected
+	assert.InDelta(t, 496, forecastMonthEnd(costs, date("2026-03-01"), date("2026-03-20")), 0.0001)
+
+	// Declining costs are not projected below zero
+	declining := []dailyCost{{date: date("2026-03-18"), total: 20}, {date: date("2026-03-19"), total: 10}}
+	assert.InDelta(t, 30, forecastMonthEnd(declining, date("2026-03-01"), date("2026-03-20")), 0.0001)
+
+	assert.Zero(t, forecastMonthEnd(nil, date("2026-03-01"), date("2026-03-20")))
+}
+
+func TestAnomaliesResponseStringNoAnomalies(t *testing.T) {
+	resp := anomaliesResponse{Start: "2026-03-13", End: "2026-03-19", Threshold: 50, Anomalies: []costAnomaly{}}
+	assert.True(t, strings.HasPrefix(resp.String(), "No cost anomalies above 50% between 2026-03-13 and 2026-03-19\n"))
+}
@@ -35,6 +35,7 @@ platforms may be added in the future)`,
 	costCmd.AddCommand(newCmdCreate(streams))
 	costCmd.AddCommand(newCmdList(streams, globalOpts))
 	costCmd.AddCommand(newCmdCarbonReport(streams, globalOpts))
+	costCmd.AddCommand(newCmdAnomalies(streams, globalOpts))
 
 	return costCmd
 }
@@ -55,25 +55,20 @@ func createCostCategory(OUid *string, OU *organizationTypes.OrganizationalUnit,
 		return err
 	}
 
-	accounts := make([]string, len(accountsRecursiveResults))
+	accounts := make([]string, 0, len(accountsRecursiveResults))
 	for _, account := range accountsRecursiveResults {
 		accounts = append(accounts, *account)
 	}
 
-	_, err = awsClient.CreateCostCategoryDefinition(&costexplorer.CreateCostCategoryDefinitionInput{
-		Name:        OUid,
-		RuleVersion: "CostCategoryExpression.v1",
-		Rules: []costExplorerTypes.CostCategoryRule{
-			{
-				Rule: &costExplorerTypes.Expression{
-					Dimensions: &costExplorerTypes.DimensionValues{
-						Key:    "LINKED_ACCOUNT",
-						Values: accounts,
-					},
-				},
-				Value: OUid,
-			},
-		},
+	return createCostCategoryDefinition(&organizationTypes.OrganizationalUnit{Id: OUid, Name: OU.Name}, accounts, awsClient)
+}
+
+// Create the Cost Category of the OU, giving the OU ID as value to the accounts
+func createCostCategoryDefinition(OU *organizationTypes.OrganizationalUnit, accounts []string, awsClient awsprovider.Client) error {
+	_, err := awsClient.CreateCostCategoryDefinition(&costexplorer.CreateCostCategoryDefinitionInput{
+		Name:        OU.Id,
+		RuleVersion: costExplorerTypes.CostCategoryRuleVersionCostCategoryExpressionV1,
+		Rules:       costCategoryRules(*OU.Id, accounts),
 	})
 	if err != nil {
 		return err
@@ -83,3 +78,18 @@ func createCostCategory(OUid *string, OU *organizationTypes.OrganizationalUnit,
 
 	return nil
 }
+
+// Get the rules of the Cost Category of an OU
+func costCategoryRules(OUid string, accounts []string) []costExplorerTypes.CostCategoryRule {
+	return []costExplorerTypes.CostCategoryRule{
+		{
+			Rule: &costExplorerTypes.Expression{
+				Dimensions: &costExplorerTypes.DimensionValues{
+					Key:    costExplorerTypes.DimensionLinkedAccount,
+					Values: accounts,
+				},
+			},
+			Value: &OUid,
+		},
+	}
+}
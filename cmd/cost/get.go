@@ -1,9 +1,12 @@
 package cost
 
 import (
+	"encoding/csv"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
@@ -12,6 +15,7 @@ import (
 	organizationTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	outputflag "github.com/openshift/osdctl/cmd/getoutput"
 	"github.com/openshift/osdctl/internal/utils/globalflags"
+	"github.com/openshift/osdctl/pkg/printer"
 	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
 	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
@@ -25,6 +29,20 @@ func newCmdGet(streams genericclioptions.IOStreams, globalOpts *globalflags.Glob
 	getCmd := &cobra.Command{
 		Use:   "get",
 		Short: "Get total cost of a given OU",
+		Long: `Get total cost of a given OU.
+
+With --group-by, the cost of the OU and of each of its accounts is broken down by AWS service, usage type,
+region or cost allocation tag value. The --top largest groups are listed, the others being summed as "Other".
+With --recursive, the costs of the child OUs are broken down the same way under their parent OU.
+--sum=false hides the rows summing the cost of each OU.`,
+		Example: `  # Get the cost of the accounts directly under an OU for the current month
+  osdctl cost get --ou ${OU_ID} --time MTD
+
+  # Get the cost of all accounts under an OU last month, by service
+  osdctl cost get --ou ${OU_ID} --recursive --time LM --group-by service --top 5
+
+  # Get the cost of an OU by value of the cluster tag, as CSV
+  osdctl cost get --ou ${OU_ID} --time MTD --group-by tag:api.openshift.com/id --csv`,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(ops.checkArgs(cmd, args))
 			cmdutil.CheckErr(ops.run())
@@ -37,6 +55,8 @@ func newCmdGet(streams genericclioptions.IOStreams, globalOpts *globalflags.Glob
 	getCmd.Flags().StringVar(&ops.end, "end", "", "set end date range")
 	getCmd.Flags().BoolVar(&ops.csv, "csv", false, "output result as csv")
 	getCmd.Flags().BoolVar(&ops.sum, "sum", true, "Hide sum rows")
+	getCmd.Flags().StringVar(&ops.groupBy, "group-by", "", "break down the cost by one of 'service', 'usage-type', 'region' or 'tag:<key>'")
+	getCmd.Flags().IntVar(&ops.top, "top", 0, "with --group-by, number of largest groups listed per OU and account, the others being summed as 'Other'. 0 lists all groups")
 
 	return getCmd
 }
@@ -61,6 +81,14 @@ func (o *getOptions) checkArgs(cmd *cobra.Command, _ []string) error {
 	if o.ou == "" {
 		return cmdutil.UsageErrorf(cmd, "Please provide OU")
 	}
+	if o.groupBy != "" {
+		if _, err := costGroupDefinition(o.groupBy); err != nil {
+			return cmdutil.UsageErrorf(cmd, "%s", err.Error())
+		}
+	}
+	if o.top < 0 {
+		return cmdutil.UsageErrorf(cmd, "--top must not be negative")
+	}
 
 	o.output = o.GlobalOptions.Output
 
@@ -76,6 +104,8 @@ type getOptions struct {
 	end       string
 	csv       bool
 	sum       bool
+	groupBy   string
+	top       int
 	output    string
 
 	genericclioptions.IOStreams
@@ -111,6 +141,18 @@ func (o *getOptions) run() error {
 	//Get information regarding Organizational Unit
 	OU := getOU(awsClient, o.ou)
 
+	if o.groupBy != "" {
+		resp, err := o.getGroupedCost(OU, awsClient)
+		if err != nil {
+			return err
+		}
+		if o.csv {
+			fmt.Print(resp.csv())
+			return nil
+		}
+		return outputflag.PrintResponse(o.output, resp)
+	}
+
 	var cost decimal.Decimal
 	var unit string
 
@@ -235,10 +277,8 @@ func getOUsRecursive(OU *organizationTypes.OrganizationalUnit, awsClient awsprov
 	return OUs, nil
 }
 
-// Get cost of given account
-func (o *getOptions) getAccountCost(accountID *string, unit *string, awsClient awsprovider.Client, cost *decimal.Decimal) error {
-
-	var start, end, granularity string
+// Get the time period and granularity of the cost queries based on the time or date range flags
+func (o *getOptions) costTimePeriod() (start string, end string, granularity string) {
 	if o.time != "" {
 		start, end = getTimePeriod(&o.time)
 		granularity = "MONTHLY"
@@ -250,6 +290,14 @@ func (o *getOptions) getAccountCost(accountID *string, unit *string, awsClient a
 		granularity = "DAILY"
 	}
 
+	return start, end, granularity
+}
+
+// Get cost of given account
+func (o *getOptions) getAccountCost(accountID *string, unit *string, awsClient awsprovider.Client, cost *decimal.Decimal) error {
+
+	start, end, granularity := o.costTimePeriod()
+
 	metrics := []string{
 		"NetUnblendedCost",
 	}
@@ -389,3 +437,300 @@ func (o *getOptions) getCostOutput(cost decimal.Decimal, unit string, ops *getOp
 
 	return response, nil
 }
+
+const (
+	otherCostGroup    = "Other"
+	untaggedCostGroup = "(untagged)"
+)
+
+// costGroupDimensions maps the --group-by values to Cost Explorer dimensions
+var costGroupDimensions = map[string]string{
+	"service":    "SERVICE",
+	"usage-type": "USAGE_TYPE",
+	"region":     "REGION",
+}
+
+// Get the Cost Explorer group definition of a --group-by value
+func costGroupDefinition(groupBy string) (*costExplorerTypes.GroupDefinition, error) {
+	if tagKey, ok := strings.CutPrefix(groupBy, "tag:"); ok {
+		if tagKey == "" {
+			return nil, fmt.Errorf("missing tag key in --group-by %s", groupBy)
+		}
+		return &costExplorerTypes.GroupDefinition{Type: costExplorerTypes.GroupDefinitionTypeTag, Key: &tagKey}, nil
+	}
+
+	dimension, ok := costGroupDimensions[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid --group-by %s. One of 'service', 'usage-type', 'region' or 'tag:<key>'", groupBy)
+	}
+	return &costExplorerTypes.GroupDefinition{Type: costExplorerTypes.GroupDefinitionTypeDimension, Key: &dimension}, nil
+}
+
+type groupCost struct {
+	Group   string          `json:"group" yaml:"group"`
+	CostUSD decimal.Decimal `json:"costUSD" yaml:"costUSD"`
+}
+
+type accountGroupedCost struct {
+	AccountId string          `json:"accountid" yaml:"accountid"`
+	CostUSD   decimal.Decimal `json:"costUSD" yaml:"costUSD"`
+	Groups    []groupCost     `json:"groups" yaml:"groups"`
+}
+
+type getGroupedCostResponse struct {
+	OuId     string               `json:"ouid" yaml:"ouid"`
+	OuName   string               `json:"ouname" yaml:"ouname"`
+	GroupBy  string               `json:"groupBy" yaml:"groupBy"`
+	Unit     string               `json:"unit" yaml:"unit"`
+	CostUSD  decimal.Decimal      `json:"costUSD" yaml:"costUSD"`
+	Groups   []groupCost          `json:"groups" yaml:"groups"`
+	Accounts []accountGroupedCost `json:"accounts" yaml:"accounts"`
+	// With --recursive, the child OUs, whose costs are included in the cost of the OU
+	OUs []*getGroupedCostResponse `json:"ous,omitempty" yaml:"ous,omitempty"`
+
+	// Whether the rows summing the cost of the OU are printed
+	sum bool
+}
+
+// String prints the cost of the OU, then of each of its accounts, broken down by group, then the costs of its child OUs
+func (f getGroupedCostResponse) String() string {
+	var b strings.Builder
+	f.write(&b, f.OuName)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// write prints the costs of the OU and of its child OUs, the OUs being named by their path from the top-level OU
+func (f getGroupedCostResponse) write(b *strings.Builder, path string) {
+	fmt.Fprintf(b, "OU %s (%s): %s %s\n\n", f.OuId, path, f.CostUSD.StringFixed(2), f.Unit)
+
+	p := printer.NewTablePrinter(b, 20, 1, 3, ' ')
+	p.AddRow([]string{"ACCOUNT", strings.ToUpper(f.GroupBy), "COST"})
+	if f.sum {
+		p.AddRow([]string{"ALL", "", f.CostUSD.StringFixed(2)})
+		for _, group := range f.Groups {
+			p.AddRow([]string{"", group.Group, group.CostUSD.StringFixed(2)})
+		}
+	}
+	for _, account := range f.Accounts {
+		p.AddRow([]string{account.AccountId, "", account.CostUSD.StringFixed(2)})
+		for _, group := range account.Groups {
+			p.AddRow([]string{"", group.Group, group.CostUSD.StringFixed(2)})
+		}
+	}
+	_ = p.Flush()
+
+	for _, child := range f.OUs {
+		fmt.Fprintln(b)
+		child.write(b, path+"/"+child.OuName)
+	}
+}
+
+// csv prints a row per group of the OU, with account ALL, then per group of each account, then the rows of its child OUs
+func (f getGroupedCostResponse) csv() string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	_ = w.Write([]string{"OU", "AccountID", f.GroupBy, "Cost", "Unit"})
+	f.writeCSV(w)
+	w.Flush()
+	return b.String()
+}
+
+func (f getGroupedCostResponse) writeCSV(w *csv.Writer) {
+	if f.sum {
+		for _, group := range f.Groups {
+			_ = w.Write([]string{f.OuId, "ALL", group.Group, group.CostUSD.StringFixed(2), f.Unit})
+		}
+	}
+	for _, account := range f.Accounts {
+		for _, group := range account.Groups {
+			_ = w.Write([]string{f.OuId, account.AccountId, group.Group, group.CostUSD.StringFixed(2), f.Unit})
+		}
+	}
+	for _, child := range f.OUs {
+		child.writeCSV(w)
+	}
+}
+
+// Get the cost of given OU and of each of its accounts, broken down by the --group-by groups.
+// With --recursive, the costs of the child OUs are broken down the same way and included in the cost of the OU.
+func (o *getOptions) getGroupedCost(OU *organizationTypes.OrganizationalUnit, awsClient awsprovider.Client) (*getGroupedCostResponse, error) {
+	resp, _, err := o.getGroupedCostTree(OU, awsClient)
+	return resp, err
+}
+
+// getGroupedCostTree returns the grouped cost of the OU, along with the cost of each group before the --top truncation
+func (o *getOptions) getGroupedCostTree(OU *organizationTypes.OrganizationalUnit, awsClient awsprovider.Client) (*getGroupedCostResponse, map[string]decimal.Decimal, error) {
+	accounts, err := getAccounts(OU, awsClient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp := &getGroupedCostResponse{
+		OuId:     *OU.Id,
+		OuName:   *OU.Name,
+		GroupBy:  o.groupBy,
+		Groups:   []groupCost{},
+		Accounts: []accountGroupedCost{},
+		sum:      o.sum,
+	}
+
+	setUnit := func(unit string) error {
+		if unit != "" {
+			if resp.Unit != "" && resp.Unit != unit {
+				return fmt.Errorf("can't sum up different currencies: %s and %s", resp.Unit, unit)
+			}
+			resp.Unit = unit
+		}
+		return nil
+	}
+
+	ouCosts := map[string]decimal.Decimal{}
+	for _, account := range accounts {
+		costs, unit, err := o.getAccountGroupedCost(*account, awsClient)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := setUnit(unit); err != nil {
+			return nil, nil, err
+		}
+
+		accountCost := accountGroupedCost{AccountId: *account}
+		for group, cost := range costs {
+			accountCost.CostUSD = accountCost.CostUSD.Add(cost)
+			ouCosts[group] = ouCosts[group].Add(cost)
+		}
+		accountCost.Groups = topGroupCosts(costs, o.top)
+		resp.CostUSD = resp.CostUSD.Add(accountCost.CostUSD)
+		resp.Accounts = append(resp.Accounts, accountCost)
+	}
+
+	if o.recursive {
+		childOUs, err := getOUs(OU, awsClient)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, childOU := range childOUs {
+			child, childCosts, err := o.getGroupedCostTree(childOU, awsClient)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := setUnit(child.Unit); err != nil {
+				return nil, nil, err
+			}
+			for group, cost := range childCosts {
+				ouCosts[group] = ouCosts[group].Add(cost)
+			}
+			resp.CostUSD = resp.CostUSD.Add(child.CostUSD)
+			resp.OUs = append(resp.OUs, child)
+		}
+		sort.SliceStable(resp.OUs, func(i, j int) bool {
+			return resp.OUs[j].CostUSD.LessThan(resp.OUs[i].CostUSD)
+		})
+	}
+	resp.Groups = topGroupCosts(ouCosts, o.top)
+
+	sort.SliceStable(resp.Accounts, func(i, j int) bool {
+		return resp.Accounts[j].CostUSD.LessThan(resp.Accounts[i].CostUSD)
+	})
+
+	return resp, ouCosts, nil
+}
+
+// Get the cost of given account by group
+func (o *getOptions) getAccountGroupedCost(accountID string, awsClient awsprovider.Client) (map[string]decimal.Decimal, string, error) {
+	groupDefinition, err := costGroupDefinition(o.groupBy)
+	if err != nil {
+		return nil, "", err
+	}
+	start, end, granularity := o.costTimePeriod()
+
+	costs := map[string]decimal.Decimal{}
+	var unit string
+	var nextPageToken *string
+	for {
+		output, err := awsClient.GetCostAndUsage(&costexplorer.GetCostAndUsageInput{
+			Filter: &costExplorerTypes.Expression{
+				Dimensions: &costExplorerTypes.DimensionValues{
+					Key:    "LINKED_ACCOUNT",
+					Values: []string{accountID},
+				},
+			},
+			TimePeriod: &costExplorerTypes.DateInterval{
+				Start: &start,
+				End:   &end,
+			},
+			Granularity:   costExplorerTypes.Granularity(granularity),
+			Metrics:       []string{"NetUnblendedCost"},
+			GroupBy:       []costExplorerTypes.GroupDefinition{*groupDefinition},
+			NextPageToken: nextPageToken,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, result := range output.ResultsByTime {
+			for _, group := range result.Groups {
+				metric, ok := group.Metrics["NetUnblendedCost"]
+				if !ok || metric.Amount == nil || len(group.Keys) == 0 {
+					continue
+				}
+				cost, err := decimal.NewFromString(*metric.Amount)
+				if err != nil {
+					return nil, "", err
+				}
+				if metric.Unit != nil {
+					unit = *metric.Unit
+				}
+				name := costGroupName(groupDefinition, group.Keys[0])
+				costs[name] = costs[name].Add(cost)
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+		nextPageToken = output.NextPageToken
+	}
+
+	return costs, unit, nil
+}
+
+// Get the name of a group from its Cost Explorer key. Tag groups are keyed "<tag key>$<tag value>"
+func costGroupName(groupDefinition *costExplorerTypes.GroupDefinition, key string) string {
+	if groupDefinition.Type != costExplorerTypes.GroupDefinitionTypeTag {
+		return key
+	}
+	value := strings.TrimPrefix(key, *groupDefinition.Key+"$")
+	if value == "" {
+		return untaggedCostGroup
+	}
+	return value
+}
+
+// Get the groups sorted by decreasing cost, without the zero ones. If top is set, only the top groups are kept and
+// the cost of the others is summed up in an Other group
+func topGroupCosts(costs map[string]decimal.Decimal, top int) []groupCost {
+	groups := []groupCost{}
+	for group, cost := range costs {
+		if cost.IsZero() {
+			continue
+		}
+		groups = append(groups, groupCost{Group: group, CostUSD: cost})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if !groups[i].CostUSD.Equal(groups[j].CostUSD) {
+			return groups[j].CostUSD.LessThan(groups[i].CostUSD)
+		}
+		return groups[i].Group < groups[j].Group
+	})
+
+	if top == 0 || len(groups) <= top {
+		return groups
+	}
+
+	other := groupCost{Group: otherCostGroup}
+	for _, group := range groups[top:] {
+		other.CostUSD = other.CostUSD.Add(group.CostUSD)
+	}
+	return append(groups[:top], other)
+}
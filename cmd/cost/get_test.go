@@ -7,10 +7,15 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	costExplorerTypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/onsi/gomega"
+	"github.com/openshift/osdctl/pkg/provider/aws/mock"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestGetTimePeriod(t *testing.T) {
@@ -165,3 +170,163 @@ func Test_getCostOutput(t *testing.T) {
 		g.Expect(result).To(gomega.BeEmpty())
 	})
 }
+
+func TestCostGroupDefinition(t *testing.T) {
+	definition, err := costGroupDefinition("usage-type")
+	require.NoError(t, err)
+	require.Equal(t, costExplorerTypes.GroupDefinitionTypeDimension, definition.Type)
+	require.Equal(t, "USAGE_TYPE", *definition.Key)
+
+	definition, err = costGroupDefinition("tag:api.openshift.com/id")
+	require.NoError(t, err)
+	require.Equal(t, costExplorerTypes.GroupDefinitionTypeTag, definition.Type)
+	require.Equal(t, "api.openshift.com/id", *definition.Key)
+
+	_, err = costGroupDefinition("tag:")
+	require.Error(t, err)
+	_, err = costGroupDefinition("instance-type")
+	require.ErrorContains(t, err, "invalid --group-by instance-type")
+}
+
+func TestTopGroupCosts(t *testing.T) {
+	costs := map[string]decimal.Decimal{
+		"Amazon EC2":      decimal.NewFromInt(100),
+		"Amazon S3":       decimal.NewFromInt(20),
+		"AWS KMS":         decimal.NewFromInt(5),
+		"Amazon Route 53": decimal.NewFromInt(1),
+		"Tax":             decimal.Zero,
+	}
+
+	all := topGroupCosts(costs, 0)
+	require.Len(t, all, 4)
+	require.Equal(t, "Amazon EC2", all[0].Group)
+	require.Equal(t, "Amazon Route 53", all[3].Group)
+
+	top := topGroupCosts(costs, 2)
+	require.Len(t, top, 3)
+	require.Equal(t, "Amazon S3", top[1].Group)
+	require.Equal(t, otherCostGroup, top[2].Group)
+	require.Equal(t, "6", top[2].CostUSD.String())
+}
+
+func TestGetGroupedCost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockClient(ctrl)
+
+	ou := &types.OrganizationalUnit{Id: aws.String("ou-1234"), Name: aws.String("Dev-Ou")}
+	mockClient.EXPECT().ListAccountsForParent(gomock.Any()).Return(&organizations.ListAccountsForParentOutput{
+		Accounts: []types.Account{{Id: aws.String("111111111111")}, {Id: aws.String("222222222222")}},
+	}, nil)
+
+	group := func(key string, amount string) costExplorerTypes.Group {
+		return costExplorerTypes.Group{
+			Keys:    []string{key},
+			Metrics: map[string]costExplorerTypes.MetricValue{"NetUnblendedCost": {Amount: aws.String(amount), Unit: aws.String("USD")}},
+		}
+	}
+	mockClient.EXPECT().GetCostAndUsage(gomock.Any()).Times(3).DoAndReturn(func(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+		require.Equal(t, costExplorerTypes.GroupDefinitionTypeTag, input.GroupBy[0].Type)
+		require.Equal(t, "2024-01-01", *input.TimePeriod.Start)
+
+		switch input.Filter.Dimensions.Values[0] {
+		case "111111111111":
+			if input.NextPageToken == nil {
+				return &costexplorer.GetCostAndUsageOutput{
+					ResultsByTime: []costExplorerTypes.ResultByTime{{Groups: []costExplorerTypes.Group{group("cluster$abc", "10"), group("cluster$", "2.5")}}},
+					NextPageToken: aws.String("page2"),
+				}, nil
+			}
+			return &costexplorer.GetCostAndUsageOutput{
+				ResultsByTime: []costExplorerTypes.ResultByTime{{Groups: []costExplorerTypes.Group{group("cluster$abc", "5")}}},
+			}, nil
+		default:
+			return &costexplorer.GetCostAndUsageOutput{
+				ResultsByTime: []costExplorerTypes.ResultByTime{{Groups: []costExplorerTypes.Group{group("cluster$def", "40"), group("cluster$abc", "1")}}},
+			}, nil
+		}
+	})
+
+	o := &getOptions{start: "2024-01-01", end: "2024-01-31", groupBy: "tag:cluster", sum: true}
+	resp, err := o.getGroupedCost(ou, mockClient)
+	require.NoError(t, err)
+
+	require.Equal(t, "USD", resp.Unit)
+	require.Equal(t, "58.5", resp.CostUSD.String())
+	require.Equal(t, []string{"def", "abc", untaggedCostGroup}, []string{resp.Groups[0].Group, resp.Groups[1].Group, resp.Groups[2].Group})
+	require.Equal(t, "16", resp.Groups[1].CostUSD.String())
+
+	require.Len(t, resp.Accounts, 2)
+	require.Equal(t, "222222222222", resp.Accounts[0].AccountId)
+	require.Equal(t, "111111111111", resp.Accounts[1].AccountId)
+	require.Equal(t, "17.5", resp.Accounts[1].CostUSD.String())
+	require.Equal(t, "15", resp.Accounts[1].Groups[0].CostUSD.String())
+
+	require.Equal(t, "OU,AccountID,tag:cluster,Cost,Unit\n"+
+		"ou-1234,ALL,def,40.00,USD\n"+
+		"ou-1234,ALL,abc,16.00,USD\n"+
+		"ou-1234,ALL,(untagged),2.50,USD\n"+
+		"ou-1234,222222222222,def,40.00,USD\n"+
+		"ou-1234,222222222222,abc,1.00,USD\n"+
+		"ou-1234,111111111111,abc,15.00,USD\n"+
+		"ou-1234,111111111111,(untagged),2.50,USD\n", resp.csv())
+
+	table := resp.String()
+	require.Contains(t, table, "OU ou-1234 (Dev-Ou): 58.50 USD")
+	require.Contains(t, table, "TAG:CLUSTER")
+}
+
+func TestGetGroupedCostRecursive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockClient(ctrl)
+
+	ou := &types.OrganizationalUnit{Id: aws.String("ou-1234"), Name: aws.String("Dev-Ou")}
+	childOU := types.OrganizationalUnit{Id: aws.String("ou-5678"), Name: aws.String("Sub-Ou")}
+	mockClient.EXPECT().ListAccountsForParent(gomock.Any()).DoAndReturn(func(input *organizations.ListAccountsForParentInput) (*organizations.ListAccountsForParentOutput, error) {
+		if *input.ParentId == "ou-1234" {
+			return &organizations.ListAccountsForParentOutput{Accounts: []types.Account{{Id: aws.String("111111111111")}}}, nil
+		}
+		return &organizations.ListAccountsForParentOutput{Accounts: []types.Account{{Id: aws.String("222222222222")}}}, nil
+	}).Times(2)
+	mockClient.EXPECT().ListOrganizationalUnitsForParent(gomock.Any()).DoAndReturn(func(input *organizations.ListOrganizationalUnitsForParentInput) (*organizations.ListOrganizationalUnitsForParentOutput, error) {
+		if *input.ParentId == "ou-1234" {
+			return &organizations.ListOrganizationalUnitsForParentOutput{OrganizationalUnits: []types.OrganizationalUnit{childOU}}, nil
+		}
+		return &organizations.ListOrganizationalUnitsForParentOutput{}, nil
+	}).Times(2)
+
+	mockClient.EXPECT().GetCostAndUsage(gomock.Any()).Times(2).DoAndReturn(func(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+		amount := "10"
+		if input.Filter.Dimensions.Values[0] == "222222222222" {
+			amount = "30"
+		}
+		return &costexplorer.GetCostAndUsageOutput{
+			ResultsByTime: []costExplorerTypes.ResultByTime{{Groups: []costExplorerTypes.Group{{
+				Keys:    []string{"AmazonEC2"},
+				Metrics: map[string]costExplorerTypes.MetricValue{"NetUnblendedCost": {Amount: aws.String(amount), Unit: aws.String("USD")}},
+			}}}},
+		}, nil
+	})
+
+	o := &getOptions{start: "2024-01-01", end: "2024-01-31", groupBy: "service", recursive: true}
+	resp, err := o.getGroupedCost(ou, mockClient)
+	require.NoError(t, err)
+
+	require.Equal(t, "40", resp.CostUSD.String())
+	require.Equal(t, "40", resp.Groups[0].CostUSD.String())
+	require.Len(t, resp.Accounts, 1)
+	require.Equal(t, "111111111111", resp.Accounts[0].AccountId)
+	require.Len(t, resp.OUs, 1)
+	require.Equal(t, "ou-5678", resp.OUs[0].OuId)
+	require.Equal(t, "30", resp.OUs[0].CostUSD.String())
+	require.Equal(t, "222222222222", resp.OUs[0].Accounts[0].AccountId)
+
+	// Without --sum, the ALL rows are hidden
+	require.Equal(t, "OU,AccountID,service,Cost,Unit\n"+
+		"ou-1234,111111111111,AmazonEC2,10.00,USD\n"+
+		"ou-5678,222222222222,AmazonEC2,30.00,USD\n", resp.csv())
+
+	table := resp.String()
+	require.Contains(t, table, "OU ou-1234 (Dev-Ou): 40.00 USD")
+	require.Contains(t, table, "OU ou-5678 (Dev-Ou/Sub-Ou): 30.00 USD")
+	require.NotContains(t, table, "ALL")
+}
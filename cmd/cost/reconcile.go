@@ -1,25 +1,79 @@
 package cost
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	costExplorerTypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	organizationTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
-	"github.com/deckarep/golang-set"
+	"github.com/openshift/osdctl/pkg/printer"
 	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	"github.com/openshift/osdctl/pkg/utils"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
+type costCategoryAction string
+
+const (
+	costCategoryCreate costCategoryAction = "create"
+	costCategoryUpdate costCategoryAction = "update"
+	costCategoryDelete costCategoryAction = "delete"
+)
+
+// reconcileOptions defines the struct for running the reconcile command
+type reconcileOptions struct {
+	dryRun bool
+	yes    bool
+
+	// Injected for testability
+	confirmFunc func() bool
+}
+
+// costCategoryChange is a change of the cost category of an OU needed to match the OU tree
+type costCategoryChange struct {
+	Action costCategoryAction
+	OUid   string
+	OUName string
+	Arn    string
+	// Accounts under the OU, for created and updated cost categories
+	Accounts        []string
+	AddedAccounts   []string
+	RemovedAccounts []string
+}
+
+// costCategoryPlan holds the changes of the cost categories, in the order they are applied
+type costCategoryPlan struct {
+	Changes []costCategoryChange
+}
+
 // reconcileCmd represents the reconcile command
 func newCmdReconcile(streams genericclioptions.IOStreams) *cobra.Command {
+	ops := &reconcileOptions{confirmFunc: utils.ConfirmPrompt}
 	reconcileCmd := &cobra.Command{
 		Use:   "reconcile",
-		Short: "Checks if there's a cost category for every OU. If an OU is missing a cost category, creates the cost category",
+		Short: "Reconciles the cost categories with the OUs under the given OU",
+		Long: `Reconciles the cost categories with the OUs under the given OU.
+
+A plan is computed and shown before being applied, upon confirmation:
+  - create: an OU has no cost category
+  - update: the accounts of the cost category of an OU differ from the accounts under the OU, e.g. after
+    accounts were moved between OUs
+  - delete: a cost category is named after an OU which no longer exists
+
+This should be ran every 24 hours.`,
 		Example: `  # Reconcile cost categories for an OU
-  osdctl cost reconcile --ou ${OU_ID}`,
+  osdctl cost reconcile --ou ${OU_ID}
+
+  # Show the changes needed to reconcile the cost categories of an OU without applying them
+  osdctl cost reconcile --ou ${OU_ID} --dry-run`,
 		Run: func(cmd *cobra.Command, args []string) {
 
 			awsClient, err := opsCost.initAWSClients()
@@ -34,12 +88,14 @@ func newCmdReconcile(streams genericclioptions.IOStreams) *cobra.Command {
 			//Get information regarding Organizational Unit
 			OU := getOU(awsClient, OUid)
 
-			if err := reconcileCostCategories(OU, awsClient); err != nil {
+			if err := reconcileCostCategories(OU, awsClient, ops); err != nil {
 				log.Fatalln("Error reconciling cost categories:", err)
 			}
 		},
 	}
 	reconcileCmd.Flags().String("ou", "", "get OU ID")
+	reconcileCmd.Flags().BoolVar(&ops.dryRun, "dry-run", false, "only show the changes to the cost categories")
+	reconcileCmd.Flags().BoolVarP(&ops.yes, "yes", "y", false, "apply the changes to the cost categories without confirmation")
 	if err := reconcileCmd.MarkFlagRequired("ou"); err != nil {
 		log.Fatalln("OU flag:", err)
 	}
@@ -47,26 +103,143 @@ func newCmdReconcile(streams genericclioptions.IOStreams) *cobra.Command {
 	return reconcileCmd
 }
 
-// Checks if there's a cost category for every OU. If not, creates the missing cost category. This should be ran every 24 hours.
-func reconcileCostCategories(OU *organizationTypes.OrganizationalUnit, awsClient awsprovider.Client) error {
-	costCategoryCreated := false
-	costCategoriesSet := mapset.NewSet()
+// Computes the changes needed for the cost categories to match the OUs under the given OU, shows them and applies them upon confirmation
+func reconcileCostCategories(OU *organizationTypes.OrganizationalUnit, awsClient awsprovider.Client, ops *reconcileOptions) error {
+	plan, err := planCostCategories(OU, awsClient)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Println("Cost categories are up-to-date. No cost category changed.")
+		return nil
+	}
+
+	if err := printCostCategoryPlan(plan); err != nil {
+		return err
+	}
+
+	if ops.dryRun {
+		return nil
+	}
+	if !ops.yes && !ops.confirmFunc() {
+		fmt.Println("Cost categories not changed.")
+		return nil
+	}
+
+	return applyCostCategoryPlan(plan, awsClient)
+}
+
+// Computes the changes needed for the cost categories to match the OUs under the given OU. The cost category of the
+// given OU itself is left as is.
+func planCostCategories(OU *organizationTypes.OrganizationalUnit, awsClient awsprovider.Client) (*costCategoryPlan, error) {
+	costCategories, err := listCostCategories(awsClient)
+	if err != nil {
+		return nil, err
+	}
+
+	OUs, err := getOUsRecursive(OU, awsClient)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &costCategoryPlan{}
+	reconciledOUs := map[string]bool{*OU.Id: true}
+	for _, childOU := range OUs {
+		reconciledOUs[*childOU.Id] = true
+
+		accounts, err := getOUAccountIDs(childOU, awsClient)
+		if err != nil {
+			return nil, err
+		}
+
+		costCategory, ok := costCategories[*childOU.Id]
+		if !ok {
+			plan.Changes = append(plan.Changes, costCategoryChange{
+				Action:        costCategoryCreate,
+				OUid:          *childOU.Id,
+				OUName:        *childOU.Name,
+				Accounts:      accounts,
+				AddedAccounts: accounts,
+			})
+			continue
+		}
 
+		definition, err := awsClient.DescribeCostCategoryDefinition(&costexplorer.DescribeCostCategoryDefinitionInput{
+			CostCategoryArn: costCategory.CostCategoryArn,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		categoryAccounts := costCategoryAccounts(definition.CostCategory, *childOU.Id)
+		change := costCategoryChange{
+			Action:   costCategoryUpdate,
+			OUid:     *childOU.Id,
+			OUName:   *childOU.Name,
+			Arn:      *costCategory.CostCategoryArn,
+			Accounts: accounts,
+		}
+		for _, account := range accounts {
+			if !slices.Contains(categoryAccounts, account) {
+				change.AddedAccounts = append(change.AddedAccounts, account)
+			}
+		}
+		for _, account := range categoryAccounts {
+			if !slices.Contains(accounts, account) {
+				change.RemovedAccounts = append(change.RemovedAccounts, account)
+			}
+		}
+		if len(change.AddedAccounts) > 0 || len(change.RemovedAccounts) > 0 {
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+
+	// Cost categories of OUs outside of the given OU are only deleted once the OU is gone
+	var names []string
+	for name := range costCategories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if reconciledOUs[name] || !strings.HasPrefix(name, "ou-") {
+			continue
+		}
+
+		_, err := awsClient.DescribeOrganizationalUnit(&organizations.DescribeOrganizationalUnitInput{OrganizationalUnitId: &name})
+		var notFound *organizationTypes.OrganizationalUnitNotFoundException
+		if errors.As(err, &notFound) {
+			plan.Changes = append(plan.Changes, costCategoryChange{
+				Action: costCategoryDelete,
+				OUid:   name,
+				Arn:    *costCategories[name].CostCategoryArn,
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe OU %s: %w", name, err)
+		}
+	}
+
+	return plan, nil
+}
+
+// Gets the cost categories by name
+func listCostCategories(awsClient awsprovider.Client) (map[string]costExplorerTypes.CostCategoryReference, error) {
+	costCategories := map[string]costExplorerTypes.CostCategoryReference{}
 	var nextToken *string
 
-	//Populate costCategoriesSet with cost categories by looping until existingCostCategories.NextToken is null
+	//Populate costCategories by looping until existingCostCategories.NextToken is null
 	for {
 		existingCostCategories, err := awsClient.ListCostCategoryDefinitions(&costexplorer.ListCostCategoryDefinitionsInput{
 			NextToken: nextToken,
 		})
-
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		//Loop through and add to costCategoriesSet. Set makes lookup easier
 		for _, costCategory := range existingCostCategories.CostCategoryReferences {
-			costCategoriesSet.Add(*costCategory.Name)
+			costCategories[*costCategory.Name] = costCategory
 		}
 
 		if existingCostCategories.NextToken == nil {
@@ -75,22 +248,91 @@ func reconcileCostCategories(OU *organizationTypes.OrganizationalUnit, awsClient
 		nextToken = existingCostCategories.NextToken //If NextToken != nil, keep looping
 	}
 
-	OUs, err := getOUsRecursive(OU, awsClient)
+	return costCategories, nil
+}
+
+// Gets the sorted IDs of all (not only immediate) accounts under OU
+func getOUAccountIDs(OU *organizationTypes.OrganizationalUnit, awsClient awsprovider.Client) ([]string, error) {
+	accountIDs, err := getAccountsRecursive(OU, awsClient)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	//Loop through every OU under OpenShift and create cost category if missing
-	for _, OU := range OUs {
-		if !costCategoriesSet.Contains(*OU.Id) {
-			if err := createCostCategory(OU.Id, OU, awsClient); err != nil {
-				return err
+
+	accounts := make([]string, 0, len(accountIDs))
+	for _, accountID := range accountIDs {
+		accounts = append(accounts, *accountID)
+	}
+	slices.Sort(accounts)
+
+	return accounts, nil
+}
+
+// Gets the sorted accounts of the rules of a cost category giving the value
+func costCategoryAccounts(costCategory *costExplorerTypes.CostCategory, value string) []string {
+	var accounts []string
+	if costCategory == nil {
+		return accounts
+	}
+
+	for _, rule := range costCategory.Rules {
+		if rule.Value == nil || *rule.Value != value || rule.Rule == nil || rule.Rule.Dimensions == nil ||
+			rule.Rule.Dimensions.Key != costExplorerTypes.DimensionLinkedAccount {
+			continue
+		}
+		for _, account := range rule.Rule.Dimensions.Values {
+			if account != "" && !slices.Contains(accounts, account) {
+				accounts = append(accounts, account)
 			}
-			costCategoryCreated = true
 		}
 	}
+	slices.Sort(accounts)
 
-	if !costCategoryCreated {
-		fmt.Println("Cost categories are up-to-date. No cost category created.")
+	return accounts
+}
+
+func printCostCategoryPlan(plan *costCategoryPlan) error {
+	p := printer.NewTablePrinter(os.Stdout, 20, 1, 3, ' ')
+	p.AddRow([]string{"ACTION", "COST CATEGORY", "OU NAME", "ADDED ACCOUNTS", "REMOVED ACCOUNTS"})
+	for _, change := range plan.Changes {
+		p.AddRow([]string{
+			string(change.Action),
+			change.OUid,
+			change.OUName,
+			strings.Join(change.AddedAccounts, ","),
+			strings.Join(change.RemovedAccounts, ","),
+		})
+	}
+
+	return p.Flush()
+}
+
+func applyCostCategoryPlan(plan *costCategoryPlan, awsClient awsprovider.Client) error {
+	for _, change := range plan.Changes {
+		switch change.Action {
+		case costCategoryCreate:
+			OU := &organizationTypes.OrganizationalUnit{Id: &change.OUid, Name: &change.OUName}
+			if err := createCostCategoryDefinition(OU, change.Accounts, awsClient); err != nil {
+				return fmt.Errorf("failed to create cost category %s: %w", change.OUid, err)
+			}
+		case costCategoryUpdate:
+			_, err := awsClient.UpdateCostCategoryDefinition(&costexplorer.UpdateCostCategoryDefinitionInput{
+				CostCategoryArn: &change.Arn,
+				RuleVersion:     costExplorerTypes.CostCategoryRuleVersionCostCategoryExpressionV1,
+				Rules:           costCategoryRules(change.OUid, change.Accounts),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update cost category %s: %w", change.OUid, err)
+			}
+			fmt.Printf("Updated Cost Category for %s (%s) OU\n", change.OUName, change.OUid)
+		case costCategoryDelete:
+			_, err := awsClient.DeleteCostCategoryDefinition(&costexplorer.DeleteCostCategoryDefinitionInput{
+				CostCategoryArn: &change.Arn,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to delete cost category %s: %w", change.OUid, err)
+			}
+			fmt.Printf("Deleted Cost Category of removed %s OU\n", change.OUid)
+		}
 	}
 
 	return nil
@@ -153,7 +153,7 @@ func TestReconcileCostCategories(t *testing.T) {
 
 			OU := &organizationTypes.OrganizationalUnit{Id: tc.OUid, Name: tc.name}
 
-			err := reconcileCostCategories(OU, mocks.mockAWSClient)
+			err := reconcileCostCategories(OU, mocks.mockAWSClient, &reconcileOptions{yes: true})
 
 			if tc.errExpected {
 				g.Expect(err).Should(gomega.HaveOccurred())
@@ -163,3 +163,124 @@ func TestReconcileCostCategories(t *testing.T) {
 		})
 	}
 }
+
+// setupDriftMocks mocks an OU tree with two child OUs, ou-a with account 111 and ou-b with accounts 222 and 333.
+// Account 333 was moved from ou-a to ou-b, ou-c is a new OU and ou-gone was removed.
+func setupDriftMocks(r *mock.MockClientMockRecorder) {
+	r.ListCostCategoryDefinitions(gomock.Any()).Return(&costexplorer.ListCostCategoryDefinitionsOutput{
+		CostCategoryReferences: []costExplorerTypes.CostCategoryReference{
+			{Name: awsSdk.String("ou-a"), CostCategoryArn: awsSdk.String("arn:a")},
+			{Name: awsSdk.String("ou-b"), CostCategoryArn: awsSdk.String("arn:b")},
+			{Name: awsSdk.String("ou-gone"), CostCategoryArn: awsSdk.String("arn:gone")},
+			{Name: awsSdk.String("ou-elsewhere"), CostCategoryArn: awsSdk.String("arn:elsewhere")},
+			{Name: awsSdk.String("Team"), CostCategoryArn: awsSdk.String("arn:team")},
+		},
+	}, nil)
+
+	childOUs := map[string][]organizationTypes.OrganizationalUnit{
+		"ou-root": {
+			{Id: awsSdk.String("ou-a"), Name: awsSdk.String("A")},
+			{Id: awsSdk.String("ou-b"), Name: awsSdk.String("B")},
+			{Id: awsSdk.String("ou-c"), Name: awsSdk.String("C")},
+		},
+	}
+	r.ListOrganizationalUnitsForParent(gomock.Any()).AnyTimes().DoAndReturn(func(input *organizations.ListOrganizationalUnitsForParentInput) (*organizations.ListOrganizationalUnitsForParentOutput, error) {
+		return &organizations.ListOrganizationalUnitsForParentOutput{OrganizationalUnits: childOUs[*input.ParentId]}, nil
+	})
+
+	accounts := map[string][]organizationTypes.Account{
+		"ou-a": {{Id: awsSdk.String("111")}},
+		"ou-b": {{Id: awsSdk.String("333")}, {Id: awsSdk.String("222")}},
+		"ou-c": {{Id: awsSdk.String("444")}},
+	}
+	r.ListAccountsForParent(gomock.Any()).AnyTimes().DoAndReturn(func(input *organizations.ListAccountsForParentInput) (*organizations.ListAccountsForParentOutput, error) {
+		return &organizations.ListAccountsForParentOutput{Accounts: accounts[*input.ParentId]}, nil
+	})
+
+	costCategoryRule := func(value string, accounts ...string) costExplorerTypes.CostCategoryRule {
+		return costExplorerTypes.CostCategoryRule{
+			Value: awsSdk.String(value),
+			Rule: &costExplorerTypes.Expression{Dimensions: &costExplorerTypes.DimensionValues{
+				Key:    costExplorerTypes.DimensionLinkedAccount,
+				Values: accounts,
+			}},
+		}
+	}
+	definitions := map[string]*costExplorerTypes.CostCategory{
+		"arn:a": {Rules: []costExplorerTypes.CostCategoryRule{costCategoryRule("ou-a", "", "111", "333")}},
+		"arn:b": {Rules: []costExplorerTypes.CostCategoryRule{costCategoryRule("ou-b", "222")}},
+	}
+	r.DescribeCostCategoryDefinition(gomock.Any()).Times(2).DoAndReturn(func(input *costexplorer.DescribeCostCategoryDefinitionInput) (*costexplorer.DescribeCostCategoryDefinitionOutput, error) {
+		return &costexplorer.DescribeCostCategoryDefinitionOutput{CostCategory: definitions[*input.CostCategoryArn]}, nil
+	})
+
+	r.DescribeOrganizationalUnit(gomock.Any()).Times(2).DoAndReturn(func(input *organizations.DescribeOrganizationalUnitInput) (*organizations.DescribeOrganizationalUnitOutput, error) {
+		if *input.OrganizationalUnitId == "ou-gone" {
+			return nil, &organizationTypes.OrganizationalUnitNotFoundException{}
+		}
+		return &organizations.DescribeOrganizationalUnitOutput{OrganizationalUnit: &organizationTypes.OrganizationalUnit{Id: input.OrganizationalUnitId}}, nil
+	})
+}
+
+func TestPlanCostCategories(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	mocks := setupDefaultMocks(t)
+	setupDriftMocks(mocks.mockAWSClient.EXPECT())
+
+	plan, err := planCostCategories(&organizationTypes.OrganizationalUnit{Id: awsSdk.String("ou-root"), Name: awsSdk.String("Root")}, mocks.mockAWSClient)
+	g.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+	g.Expect(plan.Changes).To(gomega.Equal([]costCategoryChange{
+		{Action: costCategoryUpdate, OUid: "ou-a", OUName: "A", Arn: "arn:a", Accounts: []string{"111"}, RemovedAccounts: []string{"333"}},
+		{Action: costCategoryUpdate, OUid: "ou-b", OUName: "B", Arn: "arn:b", Accounts: []string{"222", "333"}, AddedAccounts: []string{"333"}},
+		{Action: costCategoryCreate, OUid: "ou-c", OUName: "C", Accounts: []string{"444"}, AddedAccounts: []string{"444"}},
+		{Action: costCategoryDelete, OUid: "ou-gone", Arn: "arn:gone"},
+	}))
+}
+
+func TestReconcileCostCategoriesApply(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	root := &organizationTypes.OrganizationalUnit{Id: awsSdk.String("ou-root"), Name: awsSdk.String("Root")}
+
+	t.Run("changes are not applied without confirmation", func(t *testing.T) {
+		mocks := setupDefaultMocks(t)
+		setupDriftMocks(mocks.mockAWSClient.EXPECT())
+
+		err := reconcileCostCategories(root, mocks.mockAWSClient, &reconcileOptions{confirmFunc: func() bool { return false }})
+		g.Expect(err).ShouldNot(gomega.HaveOccurred())
+	})
+
+	t.Run("changes are not applied on dry run", func(t *testing.T) {
+		mocks := setupDefaultMocks(t)
+		setupDriftMocks(mocks.mockAWSClient.EXPECT())
+
+		err := reconcileCostCategories(root, mocks.mockAWSClient, &reconcileOptions{dryRun: true, yes: true})
+		g.Expect(err).ShouldNot(gomega.HaveOccurred())
+	})
+
+	t.Run("changes are applied upon confirmation", func(t *testing.T) {
+		mocks := setupDefaultMocks(t)
+		r := mocks.mockAWSClient.EXPECT()
+		setupDriftMocks(r)
+
+		r.UpdateCostCategoryDefinition(gomock.Any()).Times(2).DoAndReturn(func(input *costexplorer.UpdateCostCategoryDefinitionInput) (*costexplorer.UpdateCostCategoryDefinitionOutput, error) {
+			if *input.CostCategoryArn == "arn:b" {
+				g.Expect(input.Rules[0].Rule.Dimensions.Values).To(gomega.Equal([]string{"222", "333"}))
+				g.Expect(*input.Rules[0].Value).To(gomega.Equal("ou-b"))
+			}
+			return &costexplorer.UpdateCostCategoryDefinitionOutput{}, nil
+		})
+		r.CreateCostCategoryDefinition(gomock.Any()).Times(1).DoAndReturn(func(input *costexplorer.CreateCostCategoryDefinitionInput) (*costexplorer.CreateCostCategoryDefinitionOutput, error) {
+			g.Expect(*input.Name).To(gomega.Equal("ou-c"))
+			g.Expect(input.Rules[0].Rule.Dimensions.Values).To(gomega.Equal([]string{"444"}))
+			return &costexplorer.CreateCostCategoryDefinitionOutput{}, nil
+		})
+		r.DeleteCostCategoryDefinition(gomock.Any()).Times(1).DoAndReturn(func(input *costexplorer.DeleteCostCategoryDefinitionInput) (*costexplorer.DeleteCostCategoryDefinitionOutput, error) {
+			g.Expect(*input.CostCategoryArn).To(gomega.Equal("arn:gone"))
+			return &costexplorer.DeleteCostCategoryDefinitionOutput{}, nil
+		})
+
+		err := reconcileCostCategories(root, mocks.mockAWSClient, &reconcileOptions{confirmFunc: func() bool { return true }})
+		g.Expect(err).ShouldNot(gomega.HaveOccurred())
+	})
+}
@@ -0,0 +1,354 @@
+package dynatrace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/openshift/osdctl/pkg/utils"
+)
+
+const (
+	DefaultQueryPollInterval = time.Second
+	DefaultQueryTimeout      = 10 * time.Minute
+
+	// Note: Currently we are setting a limit of 20,000 lines to pull from Dynatrace
+	// due to a limitation in dynatrace to pull all logs. This limitation can be revoked
+	// once https://community.dynatrace.com/t5/Product-ideas/Pagination-in-DQL-results/idi-p/248282#M45818
+	// is addressed. Then we can implement https://issues.redhat.com/browse/OSD-24349 to get rid of this limitation.
+	maxResultRecords = 20000
+)
+
+const (
+	QueryStateRunning   = "RUNNING"
+	QueryStateSucceeded = "SUCCEEDED"
+)
+
+// QueryStatus is the state of a query execution as returned by the Grail query API
+type QueryStatus struct {
+	State    string `json:"state"`
+	Progress int    `json:"progress"`
+}
+
+// RecordHandler is called with every record of a query result, in order
+type RecordHandler func(record json.RawMessage) error
+
+// Client runs DQL queries against the Grail storage of a Dynatrace environment
+type Client interface {
+	// ExecuteQuery starts the given query and returns the request token to poll it with
+	ExecuteQuery(ctx context.Context, query string) (requestToken string, err error)
+	// PollQuery returns the status of the query. Once the query succeeded, handleRecord is called with every record of the result.
+	PollQuery(ctx context.Context, requestToken string, handleRecord RecordHandler) (*QueryStatus, error)
+}
+
+// QueryOptions configures how RunQuery waits for a query to complete
+type QueryOptions struct {
+	PollInterval   time.Duration // DefaultQueryPollInterval if not positive
+	Timeout        time.Duration // DefaultQueryTimeout if not positive
+	ProgressWriter io.Writer     // Receives the progress of the query while it is running when not nil
+}
+
+type httpClient struct {
+	dtURL         string
+	tokenProvider utils.AccessTokenProvider
+	client        *http.Client
+}
+
+// NewClient returns a Client for the Dynatrace environment with the given URL, authenticated with the tokens of tokenProvider.
+func NewClient(dtURL string, tokenProvider utils.AccessTokenProvider) Client {
+	return &httpClient{
+		dtURL:         dtURL,
+		tokenProvider: tokenProvider,
+		client:        &http.Client{Timeout: time.Second * 600},
+	}
+}
+
+func (c *httpClient) do(ctx context.Context, method string, path string, body []byte, successCode int) (*http.Response, error) {
+	accessToken, err := c.tokenProvider.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.dtURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != successCode {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, &utils.RequestError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("request failed: %s: %s", resp.Status, respBody)}
+	}
+
+	return resp, nil
+}
+
+func (c *httpClient) ExecuteQuery(ctx context.Context, query string) (string, error) {
+	payloadJSON, err := json.Marshal(DTQueryPayload{
+		Query:            query,
+		MaxResultRecords: maxResultRecords,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "platform/storage/query/v1/query:execute", payloadJSON, http.StatusAccepted)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var execState struct {
+		DTExecuteState
+		DTExecuteToken
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&execState); err != nil {
+		return "", err
+	}
+
+	if execState.State != QueryStateRunning && execState.State != QueryStateSucceeded {
+		return "", fmt.Errorf("query failed with state %s", execState.State)
+	}
+
+	return execState.RequestToken, nil
+}
+
+func (c *httpClient) PollQuery(ctx context.Context, requestToken string, handleRecord RecordHandler) (*QueryStatus, error) {
+	parameters := url.Values{
+		"request-token": {requestToken},
+	}.Encode()
+
+	resp, err := c.do(ctx, http.MethodGet, "platform/storage/query/v1/query:poll?"+parameters, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return decodePollResponse(resp.Body, handleRecord)
+}
+
+// decodePollResponse decodes the poll response as a stream, so that the records of large results are never all held in memory.
+func decodePollResponse(r io.Reader, handleRecord RecordHandler) (*QueryStatus, error) {
+	decoder := json.NewDecoder(r)
+	status := &QueryStatus{}
+
+	err := decodeObject(decoder, func(key string) error {
+		switch key {
+		case "state":
+			return decoder.Decode(&status.State)
+		case "progress":
+			return decoder.Decode(&status.Progress)
+		case "result":
+			// The result and its records are null while the query has no results yet
+			return decodeNullableObject(decoder, func(key string) error {
+				if key != "records" {
+					return skipValue(decoder)
+				}
+				return decodeNullableArray(decoder, func() error {
+					var record json.RawMessage
+					if err := decoder.Decode(&record); err != nil {
+						return err
+					}
+					return handleRecord(record)
+				})
+			})
+		default:
+			return skipValue(decoder)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode query results: %w", err)
+	}
+
+	return status, nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	_, err := expectDelimOrNull(decoder, delim, false)
+	return err
+}
+
+// expectDelimOrNull reads the next token, which must be the given delimiter or, if nullable, null.
+// It returns false if the token is null.
+func expectDelimOrNull(decoder *json.Decoder, delim json.Delim, nullable bool) (bool, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+	if token == nil && nullable {
+		return false, nil
+	}
+	if token != delim {
+		return false, fmt.Errorf("expected '%s', got '%v'", delim, token)
+	}
+
+	return true, nil
+}
+
+// decodeObject calls decodeValue for every key of the next JSON object, which must consume the value of the key
+func decodeObject(decoder *json.Decoder, decodeValue func(key string) error) error {
+	return decodeObjectOrNull(decoder, false, decodeValue)
+}
+
+// decodeNullableObject is decodeObject, a null value being decoded as an empty object
+func decodeNullableObject(decoder *json.Decoder, decodeValue func(key string) error) error {
+	return decodeObjectOrNull(decoder, true, decodeValue)
+}
+
+func decodeObjectOrNull(decoder *json.Decoder, nullable bool, decodeValue func(key string) error) error {
+	if isObject, err := expectDelimOrNull(decoder, '{', nullable); err != nil || !isObject {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("expected an object key, got '%v'", token)
+		}
+		if err := decodeValue(key); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, '}')
+}
+
+// decodeNullableArray calls decodeElement for every element of the next JSON array, which must consume the element.
+// A null value is decoded as an empty array.
+func decodeNullableArray(decoder *json.Decoder, decodeElement func() error) error {
+	if isArray, err := expectDelimOrNull(decoder, '[', true); err != nil || !isArray {
+		return err
+	}
+
+	for decoder.More() {
+		if err := decodeElement(); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(decoder, ']')
+}
+
+func skipValue(decoder *json.Decoder) error {
+	var value json.RawMessage
+	return decoder.Decode(&value)
+}
+
+// RunQuery executes the query and polls it until it completes, calling handleRecord with every record of the result.
+// It returns the number of records.
+func RunQuery(ctx context.Context, client Client, query string, opts QueryOptions, handleRecord RecordHandler) (int, error) {
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultQueryPollInterval
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	requestToken, err := client.ExecuteQuery(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	recordsCount := 0
+	countingHandler := func(record json.RawMessage) error {
+		recordsCount++
+		return handleRecord(record)
+	}
+
+	lastProgress := -1
+	for {
+		status, err := client.PollQuery(ctx, requestToken, countingHandler)
+		if err != nil {
+			return recordsCount, err
+		}
+
+		switch status.State {
+		case QueryStateSucceeded:
+			return recordsCount, nil
+		case QueryStateRunning:
+		default:
+			return recordsCount, fmt.Errorf("query failed with state %s", status.State)
+		}
+
+		if opts.ProgressWriter != nil && status.Progress != lastProgress {
+			fmt.Fprintf(opts.ProgressWriter, "Query running: %d%%\n", status.Progress)
+			lastProgress = status.Progress
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return recordsCount, fmt.Errorf("query did not complete within %s", timeout)
+			}
+			return recordsCount, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// writeLogRecord writes the content of a log record as a line
+func writeLogRecord(w io.Writer, record json.RawMessage) error {
+	var logContent LogContent
+	if err := json.Unmarshal(record, &logContent); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%s\n", logContent.Content)
+	return err
+}
+
+// writeEventRecord writes an event record as a JSON line
+func writeEventRecord(w io.Writer, record json.RawMessage) error {
+	_, err := fmt.Fprintf(w, "%s\n", record)
+	return err
+}
+
+// QueryLogs runs a logs query and writes the content of the logs to w, returning the number of logs.
+func QueryLogs(ctx context.Context, client Client, query string, opts QueryOptions, w io.Writer) (int, error) {
+	return RunQuery(ctx, client, query, opts, func(record json.RawMessage) error {
+		return writeLogRecord(w, record)
+	})
+}
+
+// QueryRecords runs a query and returns its records as generic field/value mappings.
+// Numbers are kept as json.Number so that they are printed exactly as returned by Dynatrace.
+func QueryRecords(ctx context.Context, client Client, query string, opts QueryOptions) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+
+	_, err := RunQuery(ctx, client, query, opts, func(record json.RawMessage) error {
+		var fields map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(record))
+		decoder.UseNumber()
+		if err := decoder.Decode(&fields); err != nil {
+			return err
+		}
+		records = append(records, fields)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
@@ -0,0 +1,222 @@
+package dynatrace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/osdctl/pkg/utils"
+)
+
+type staticTokenProvider struct{}
+
+func (staticTokenProvider) Token() (string, error) {
+	return "token", nil
+}
+
+func TestDecodePollResponse(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		expectedState   string
+		expectedRecords []string
+		expectError     bool
+	}{
+		{
+			name:          "running query has no result",
+			body:          `{"state":"RUNNING","progress":42,"ttlSeconds":500}`,
+			expectedState: QueryStateRunning,
+		},
+		{
+			name:            "records are streamed and other fields are skipped",
+			body:            `{"state":"SUCCEEDED","progress":100,"result":{"metadata":{"grail":{"scannedRecords":2}},"records":[{"content":"a"},{"content":"b"}],"types":[{"indexRange":[0,1]}]}}`,
+			expectedState:   QueryStateSucceeded,
+			expectedRecords: []string{`{"content":"a"}`, `{"content":"b"}`},
+		},
+		{
+			name:          "null result",
+			body:          `{"state":"SUCCEEDED","progress":100,"result":null}`,
+			expectedState: QueryStateSucceeded,
+		},
+		{
+			name:          "null records",
+			body:          `{"state":"SUCCEEDED","progress":100,"result":{"records":null,"types":[]}}`,
+			expectedState: QueryStateSucceeded,
+		},
+		{
+			name:        "result which is not an object",
+			body:        `{"state":"SUCCEEDED","result":[]}`,
+			expectError: true,
+		},
+		{
+			name:        "truncated response",
+			body:        `{"state":"SUCCEEDED","result":{"records":[{"content":"a"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []string
+			status, err := decodePollResponse(strings.NewReader(tt.body), func(record json.RawMessage) error {
+				records = append(records, string(record))
+				return nil
+			})
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status.State != tt.expectedState {
+				t.Errorf("state = %s, want %s", status.State, tt.expectedState)
+			}
+			if strings.Join(records, ",") != strings.Join(tt.expectedRecords, ",") {
+				t.Errorf("records = %v, want %v", records, tt.expectedRecords)
+			}
+		})
+	}
+}
+
+func TestRunQuery(t *testing.T) {
+	failure := errors.New("boom")
+
+	tests := []struct {
+		name             string
+		query            *FakeQuery
+		expectedRecords  int
+		expectedProgress string
+		expectError      bool
+	}{
+		{
+			name:             "running query is polled until it succeeds",
+			query:            &FakeQuery{RunningPolls: 2, Records: []json.RawMessage{[]byte(`{}`), []byte(`{}`)}},
+			expectedRecords:  2,
+			expectedProgress: "Query running: 0%\nQuery running: 50%\n",
+		},
+		{
+			name:             "failed query",
+			query:            &FakeQuery{RunningPolls: 1, State: "FAILED"},
+			expectedProgress: "Query running: 0%\n",
+			expectError:      true,
+		},
+		{
+			name:        "execution error",
+			query:       &FakeQuery{ExecuteErrs: []error{failure}},
+			expectError: true,
+		},
+		{
+			name:        "poll error",
+			query:       &FakeQuery{PollErr: failure},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewFakeClient(map[string]*FakeQuery{"fetch logs": tt.query})
+			progress := &bytes.Buffer{}
+
+			count, err := RunQuery(context.Background(), client, "fetch logs", QueryOptions{PollInterval: time.Millisecond, ProgressWriter: progress}, func(json.RawMessage) error {
+				return nil
+			})
+
+			if tt.expectError != (err != nil) {
+				t.Fatalf("expectError = %v, got %v", tt.expectError, err)
+			}
+			if count != tt.expectedRecords {
+				t.Errorf("records = %d, want %d", count, tt.expectedRecords)
+			}
+			if progress.String() != tt.expectedProgress {
+				t.Errorf("progress = %q, want %q", progress.String(), tt.expectedProgress)
+			}
+		})
+	}
+}
+
+func TestRunQuery_Timeout(t *testing.T) {
+	client := NewFakeClient(map[string]*FakeQuery{"fetch logs": {RunningPolls: 1000}})
+
+	_, err := RunQuery(context.Background(), client, "fetch logs", QueryOptions{PollInterval: 10 * time.Millisecond, Timeout: 50 * time.Millisecond}, func(json.RawMessage) error {
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "did not complete within") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestQueryRecords(t *testing.T) {
+	client := NewFakeClient(map[string]*FakeQuery{
+		"fetch logs | summarize count()": {Records: []json.RawMessage{[]byte(`{"count()":12345678901234567890}`)}},
+	})
+
+	records, err := QueryRecords(context.Background(), client, "fetch logs | summarize count()", QueryOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0]["count()"] != json.Number("12345678901234567890") {
+		t.Errorf("unexpected records: %v", records)
+	}
+}
+
+func TestHTTPClient(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "query:execute"):
+			var payload DTQueryPayload
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			if payload.Query == "invalid" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"message":"PARSE_ERROR"}}`))
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"state":"RUNNING","requestToken":"request-token"}`))
+		case strings.HasSuffix(r.URL.Path, "query:poll"):
+			if r.URL.Query().Get("request-token") != "request-token" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			polls++
+			if polls == 1 {
+				_, _ = w.Write([]byte(`{"state":"RUNNING","progress":10}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"state":"SUCCEEDED","progress":100,"result":{"records":[{"content":"line 1"},{"content":"line 2"}]}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", staticTokenProvider{})
+
+	logs := &bytes.Buffer{}
+	count, err := QueryLogs(context.Background(), client, "fetch logs", QueryOptions{PollInterval: time.Millisecond}, logs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || logs.String() != "line 1\nline 2\n" || polls != 2 {
+		t.Errorf("unexpected result: %d records after %d polls: %q", count, polls, logs.String())
+	}
+
+	_, err = QueryLogs(context.Background(), client, "invalid", QueryOptions{}, logs)
+	var requestErr *utils.RequestError
+	if !errors.As(err, &requestErr) || requestErr.StatusCode != http.StatusBadRequest || !strings.Contains(err.Error(), "PARSE_ERROR") {
+		t.Errorf("expected a bad request error, got %v", err)
+	}
+}
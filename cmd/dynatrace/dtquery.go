@@ -40,6 +40,61 @@ func (q *DTQuery) InitEvents(hours int) *DTQuery {
 	return q
 }
 
+// validDataObjects are the Grail data objects which can be fetched with InitFetch
+var validDataObjects = []string{
+	"logs",
+	"events",
+	"spans",
+	"bizevents",
+}
+
+func validateDataObject(dataObject string) error {
+	for _, validDataObject := range validDataObjects {
+		if validDataObject == dataObject {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no valid data object specified. valid data objects are %s. given %v", strings.Join(validDataObjects, ", "), dataObject)
+}
+
+// InitFetch starts a query on any supported data object. Logs queries are restricted to log events like with InitLogs.
+func (q *DTQuery) InitFetch(dataObject string, hours int) (query *DTQuery, error error) {
+	if err := validateDataObject(dataObject); err != nil {
+		return q, err
+	}
+
+	if dataObject == "logs" {
+		return q.InitLogs(hours), nil
+	}
+
+	q.fragments = []string{}
+
+	q.fragments = append(q.fragments, fmt.Sprintf("fetch %s, from:now()-%dh \n| filter ", dataObject, hours))
+
+	return q, nil
+}
+
+// InitFetchWithTimeRange is the same as InitFetch, with an absolute time range.
+func (q *DTQuery) InitFetchWithTimeRange(dataObject string, from time.Time, to time.Time) (query *DTQuery, error error) {
+	if err := validateDataObject(dataObject); err != nil {
+		return q, err
+	}
+
+	if dataObject == "logs" {
+		return q.InitLogsWithTimeRange(from, to), nil
+	}
+
+	q.fragments = []string{}
+
+	fromStr := from.Format(timeFormat)
+	toStr := to.Format(timeFormat)
+
+	q.fragments = append(q.fragments, fmt.Sprintf("fetch %s, from:\"%s\", to:\"%s\" \n| filter ", dataObject, fromStr, toStr))
+
+	return q, nil
+}
+
 func (q *DTQuery) Cluster(mgmtClusterName string) *DTQuery {
 	q.fragments = append(q.fragments, fmt.Sprintf("matchesPhrase(dt.kubernetes.cluster.name, \"%s\")", mgmtClusterName))
 
@@ -167,6 +222,95 @@ func (q *DTQuery) Deployments(workloads []string) *DTQuery {
 	return q
 }
 
+// Filter adds a raw DQL condition to the filter command.
+func (q *DTQuery) Filter(condition string) *DTQuery {
+	q.fragments = append(q.fragments, " and ("+condition+")")
+
+	return q
+}
+
+// Parse extracts new fields from the given field using a DPL pattern.
+func (q *DTQuery) Parse(field string, pattern string) *DTQuery {
+	q.fragments = append(q.fragments, fmt.Sprintf("\n| parse %s, %s", field, dqlString(pattern)))
+
+	return q
+}
+
+// dqlStringEscaper escapes the backslashes as well as the double quotes, so that a backslash of the value cannot escape a quote.
+var dqlStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// dqlString returns the value as a double-quoted DQL string literal.
+func dqlString(value string) string {
+	return `"` + dqlStringEscaper.Replace(value) + `"`
+}
+
+// Fields only keeps the given fields in the results.
+func (q *DTQuery) Fields(fields []string) *DTQuery {
+	q.fragments = append(q.fragments, "\n| fields "+strings.Join(fields, ", "))
+
+	return q
+}
+
+// Summarize aggregates the records, grouped by the given fields and, if bucket is not zero, by time buckets of the given size.
+// Time buckets are exposed in the results as the "time_bucket" field.
+func (q *DTQuery) Summarize(aggregations []string, byFields []string, bucket time.Duration) (query *DTQuery, error error) {
+	if len(aggregations) == 0 {
+		return q, fmt.Errorf("at least one aggregation is required to summarize")
+	}
+
+	groups := append([]string{}, byFields...)
+	if bucket != 0 {
+		bucketStr, err := toDQLDuration(bucket)
+		if err != nil {
+			return q, err
+		}
+		groups = append(groups, fmt.Sprintf("time_bucket = bin(timestamp, %s)", bucketStr))
+	}
+
+	summarizeQuery := "\n| summarize " + strings.Join(aggregations, ", ")
+	if len(groups) > 0 {
+		summarizeQuery += ", by:{" + strings.Join(groups, ", ") + "}"
+	}
+	q.fragments = append(q.fragments, summarizeQuery)
+
+	return q, nil
+}
+
+// SortBy sorts the results by any field, unlike Sort which only sorts by timestamp.
+func (q *DTQuery) SortBy(field string, order string) (query *DTQuery, error error) {
+	if order != "asc" && order != "desc" {
+		return q, fmt.Errorf("no valid sorting order specified. valid order are asc, desc. given %v", order)
+	}
+
+	q.fragments = append(q.fragments, fmt.Sprintf("\n| sort %s %s", field, order))
+
+	return q, nil
+}
+
+// toDQLDuration formats a duration as a DQL duration literal, using the largest unit dividing it.
+func toDQLDuration(d time.Duration) (string, error) {
+	if d <= 0 || d%time.Second != 0 {
+		return "", fmt.Errorf("invalid duration %v, it must be a positive number of seconds", d)
+	}
+
+	units := []struct {
+		duration time.Duration
+		suffix   string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+	for _, unit := range units {
+		if d%unit.duration == 0 {
+			return fmt.Sprintf("%d%s", d/unit.duration, unit.suffix), nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid duration %v", d)
+}
+
 func (q *DTQuery) Limit(limit int) *DTQuery {
 	q.fragments = append(q.fragments, "\n| limit "+fmt.Sprint(limit))
 
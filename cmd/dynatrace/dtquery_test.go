@@ -219,3 +219,132 @@ func TestDTQuery_InitLogsWithTimeRange(t *testing.T) {
 		})
 	}
 }
+
+func TestDTQuery_InitFetch(t *testing.T) {
+	tests := []struct {
+		name        string
+		dataObject  string
+		expectError bool
+		expected    string
+	}{
+		{"Logs are restricted to log events", "logs", false, "fetch logs, from:now()-3h \n| filter matchesValue(event.type, \"LOG\") and "},
+		{"Spans", "spans", false, "fetch spans, from:now()-3h \n| filter "},
+		{"Business events", "bizevents", false, "fetch bizevents, from:now()-3h \n| filter "},
+		{"Invalid data object", "metrics", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := new(DTQuery).InitFetch(tt.dataObject, 3)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error but got: %v", err)
+			}
+			if q.fragments[0] != tt.expected {
+				t.Errorf("expected: %s\ngot: %s", tt.expected, q.fragments[0])
+			}
+		})
+	}
+}
+
+func TestDTQuery_InitFetchWithTimeRange(t *testing.T) {
+	from := time.Date(2025, 6, 12, 5, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 17, 15, 0, 0, 0, time.UTC)
+
+	q, err := new(DTQuery).InitFetchWithTimeRange("events", from, to)
+	if err != nil {
+		t.Fatalf("did not expect error but got: %v", err)
+	}
+	expected := "fetch events, from:\"2025-06-12T05:00:00Z\", to:\"2025-06-17T15:00:00Z\" \n| filter "
+	if q.fragments[0] != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, q.fragments[0])
+	}
+}
+
+func TestDTQuery_Summarize(t *testing.T) {
+	tests := []struct {
+		name         string
+		aggregations []string
+		byFields     []string
+		bucket       time.Duration
+		expectError  bool
+		expected     string
+	}{
+		{"Count without grouping", []string{"count()"}, nil, 0, false, "\n| summarize count()"},
+		{"Count by pod", []string{"count()"}, []string{"k8s.pod.name"}, 0, false, "\n| summarize count(), by:{k8s.pod.name}"},
+		{"Count by pod and hour", []string{"count()"}, []string{"k8s.pod.name"}, time.Hour, false, "\n| summarize count(), by:{k8s.pod.name, time_bucket = bin(timestamp, 1h)}"},
+		{"Several aggregations by day", []string{"count()", "errors = countIf(status == \"ERROR\")"}, nil, 48 * time.Hour, false, "\n| summarize count(), errors = countIf(status == \"ERROR\"), by:{time_bucket = bin(timestamp, 2d)}"},
+		{"No aggregation", nil, []string{"k8s.pod.name"}, 0, true, ""},
+		{"Sub-second bucket", []string{"count()"}, nil, 500 * time.Millisecond, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := new(DTQuery).InitLogs(1).Summarize(tt.aggregations, tt.byFields, tt.bucket)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("did not expect error but got: %v", err)
+			}
+			if q.fragments[1] != tt.expected {
+				t.Errorf("expected: %s\ngot: %s", tt.expected, q.fragments[1])
+			}
+		})
+	}
+}
+
+func TestDTQuery_ParseEscaping(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{name: "double quotes", pattern: `LD "a"`, expected: `"LD \"a\""`},
+		{name: "trailing backslash", pattern: `LD "\`, expected: `"LD \"\\"`},
+		{name: "escaped double quote", pattern: `LD \"a`, expected: `"LD \\\"a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := new(DTQuery).Parse("content", tt.pattern)
+			if actual := q.Build(); actual != "\n| parse content, "+tt.expected {
+				t.Errorf("expected: %s\ngot: %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDTQuery_FieldsParseFilterSortBy(t *testing.T) {
+	q := new(DTQuery).
+		InitLogs(1).
+		Cluster("mc").
+		Filter("isNotNull(k8s.pod.name)").
+		Parse("content", `LD "reason=" WORD:reason`).
+		Fields([]string{"timestamp", "reason"})
+	q, err := q.SortBy("reason", "desc")
+	if err != nil {
+		t.Fatalf("did not expect error but got: %v", err)
+	}
+
+	expected := `fetch logs, from:now()-1h 
+| filter matchesValue(event.type, "LOG") and matchesPhrase(dt.kubernetes.cluster.name, "mc") and (isNotNull(k8s.pod.name))` +
+		"\n| parse content, \"LD \\\"reason=\\\" WORD:reason\"" +
+		"\n| fields timestamp, reason" +
+		"\n| sort reason desc"
+	if actual := q.Build(); actual != expected {
+		t.Errorf("expected: %s\ngot: %s", expected, actual)
+	}
+
+	if _, err := new(DTQuery).InitLogs(1).SortBy("reason", "up"); err == nil {
+		t.Errorf("expected error for invalid sort order but got none")
+	}
+}
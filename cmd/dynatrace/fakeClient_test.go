@@ -0,0 +1,105 @@
+package dynatrace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// FakeQuery is the scripted behavior of a query run against a FakeClient
+type FakeQuery struct {
+	ExecuteErrs  []error           // Returned by the successive executions of the query, the executions after the last error succeed
+	RunningPolls int               // Number of polls returning the RUNNING state before the query completes
+	State        string            // Final state of the query, QueryStateSucceeded if empty
+	PollErr      error             // Returned by the final poll when set
+	Records      []json.RawMessage // Records of the result
+}
+
+// FakeClient is a Client serving scripted queries, for tests
+type FakeClient struct {
+	// Queries maps a DQL query to its behavior. Queries which are not listed fail.
+	Queries map[string]*FakeQuery
+
+	mutex           sync.Mutex
+	executions      []string
+	queryExecutions map[string]int
+	requestToQuery  map[string]*FakeQuery
+	requestToPolls  map[string]int
+}
+
+func NewFakeClient(queries map[string]*FakeQuery) *FakeClient {
+	return &FakeClient{
+		Queries:         queries,
+		queryExecutions: map[string]int{},
+		requestToQuery:  map[string]*FakeQuery{},
+		requestToPolls:  map[string]int{},
+	}
+}
+
+// Executions returns the queries executed so far, in order
+func (c *FakeClient) Executions() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.executions...)
+}
+
+func (c *FakeClient) ExecuteQuery(ctx context.Context, query string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.executions = append(c.executions, query)
+	c.queryExecutions[query]++
+
+	fakeQuery, ok := c.Queries[query]
+	if !ok {
+		return "", fmt.Errorf("unexpected query: %s", query)
+	}
+	if executionIdx := c.queryExecutions[query] - 1; executionIdx < len(fakeQuery.ExecuteErrs) && fakeQuery.ExecuteErrs[executionIdx] != nil {
+		return "", fakeQuery.ExecuteErrs[executionIdx]
+	}
+
+	requestToken := fmt.Sprintf("request-%d", len(c.executions))
+	c.requestToQuery[requestToken] = fakeQuery
+
+	return requestToken, nil
+}
+
+func (c *FakeClient) PollQuery(ctx context.Context, requestToken string, handleRecord RecordHandler) (*QueryStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	fakeQuery, ok := c.requestToQuery[requestToken]
+	c.requestToPolls[requestToken]++
+	pollsCount := c.requestToPolls[requestToken]
+	c.mutex.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown request token: %s", requestToken)
+	}
+
+	if pollsCount <= fakeQuery.RunningPolls {
+		return &QueryStatus{State: QueryStateRunning, Progress: 100 * (pollsCount - 1) / fakeQuery.RunningPolls}, nil
+	}
+
+	if fakeQuery.PollErr != nil {
+		return nil, fakeQuery.PollErr
+	}
+
+	state := fakeQuery.State
+	if state == "" {
+		state = QueryStateSucceeded
+	}
+	if state == QueryStateSucceeded {
+		for _, record := range fakeQuery.Records {
+			if err := handleRecord(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &QueryStatus{State: state, Progress: 100}, nil
+}
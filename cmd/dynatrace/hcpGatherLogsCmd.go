@@ -1,16 +1,25 @@
 package dynatrace
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/openshift/osdctl/cmd/common"
 	"github.com/openshift/osdctl/pkg/utils"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -19,12 +28,34 @@ import (
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
+const (
+	DefaultGatherWorkers = 4
+	DefaultGatherRetries = 3
+)
+
 type GatherLogsOpts struct {
 	Since     int
 	Tail      int
 	SortOrder string
 	DestDir   string
 	ClusterID string
+	Workers   int  // Number of Dynatrace queries run concurrently, DefaultGatherWorkers if not positive
+	Retries   int  // Number of retries of a query failing with a transient Dynatrace error
+	Resume    bool // Skip the files already gathered according to the manifest of a previous run
+}
+
+// gatherTask is a Dynatrace query whose records are written to the file of its manifest entry
+type gatherTask struct {
+	entry       *GatherManifestEntry
+	writeRecord func(w io.Writer, record json.RawMessage) error
+}
+
+// newGatherBackOff returns the backoff used between the attempts of a failing query
+var newGatherBackOff = func() backoff.BackOff {
+	return backoff.NewExponentialBackOff(
+		backoff.WithInitialInterval(2*time.Second),
+		backoff.WithMaxInterval(30*time.Second),
+		backoff.WithMaxElapsedTime(0))
 }
 
 func NewCmdHCPMustGather() *cobra.Command {
@@ -38,14 +69,21 @@ func NewCmdHCPMustGather() *cobra.Command {
 
   This command fetches the logs from the HCP namespace, the hypershift namespace and cert-manager related namespaces.
   Logs will be dumped to a directory with prefix hcp-must-gather.
+
+  Queries are run concurrently and retried on Dynatrace throttling (429) and server (5xx) errors.
+  Every gathered file is described in the manifest.json file of the dump directory (query, time range, record count and error).
+  When some queries failed, run the command again with --resume to only gather the missing files.
 		`,
 		Example: `
   # Gather logs for a HCP cluster with cluster id hcp-cluster-id-123
-  osdctl dt gather-logs --cluster-id hcp-cluster-id-123`,
+  osdctl dt gather-logs --cluster-id hcp-cluster-id-123
+
+  # Gather the files which failed to be gathered by a previous run
+  osdctl dt gather-logs --cluster-id hcp-cluster-id-123 --resume`,
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
 
-			err := g.GatherLogs(g.ClusterID, "")
+			err := g.GatherLogs(cmd.Context(), g.ClusterID, "")
 			if err != nil {
 				cmdutil.CheckErr(err)
 			}
@@ -57,13 +95,20 @@ func NewCmdHCPMustGather() *cobra.Command {
 	hcpMgCmd.Flags().StringVar(&g.SortOrder, "sort", "asc", "Sort the results by timestamp in either ascending or descending order. Accepted values are 'asc' and 'desc'")
 	hcpMgCmd.Flags().StringVar(&g.DestDir, "dest-dir", "", "Destination directory for the logs dump, defaults to the local directory.")
 	hcpMgCmd.Flags().StringVarP(&g.ClusterID, "cluster-id", "C", "", "Internal ID of the HCP cluster to gather logs from (required)")
+	hcpMgCmd.Flags().IntVar(&g.Workers, "workers", DefaultGatherWorkers, "Number of Dynatrace queries to run concurrently")
+	hcpMgCmd.Flags().IntVar(&g.Retries, "retries", DefaultGatherRetries, "Number of times a query failing with a Dynatrace throttling or server error is retried")
+	hcpMgCmd.Flags().BoolVar(&g.Resume, "resume", false, "Only gather the files which are missing or failed according to the manifest of a previous run, over the same time range")
 
 	_ = hcpMgCmd.MarkFlagRequired("cluster-id")
 
 	return hcpMgCmd
 }
 
-func (g *GatherLogsOpts) GatherLogs(clusterID string, elevationReasons ...string) (error error) {
+func (g *GatherLogsOpts) GatherLogs(ctx context.Context, clusterID string, elevationReasons ...string) (error error) {
+	if g.Retries < 0 {
+		return fmt.Errorf("the number of retries cannot be negative")
+	}
+
 	tokenProvider, err := getStorageTokenProvider()
 	if err != nil {
 		return fmt.Errorf("failed to setup Dynatrace access token provider (is the vault CLI installed and configured?): %v", err)
@@ -93,199 +138,275 @@ func (g *GatherLogsOpts) GatherLogs(clusterID string, elevationReasons ...string
 		return err
 	}
 
+	manifest, err := g.loadOrCreateManifest(gatherDir, clusterID, hcpCluster.hcpNamespace)
+	if err != nil {
+		return err
+	}
+
+	var tasks []*gatherTask
 	for _, gatherNS := range gatherNamespaces {
 		fmt.Printf("Gathering for %s\n", gatherNS)
 
-		pods, err := getPodsForNamespace(clientset, gatherNS)
+		pods, err := getPodsForNamespace(ctx, clientset, gatherNS)
 		if err != nil {
 			return err
 		}
 
-		nsDir, err := addDir([]string{gatherDir, gatherNS}, []string{})
+		deployments, err := getDeploymentsForNamespace(ctx, clientset, gatherNS)
 		if err != nil {
 			return err
 		}
 
-		err = g.dumpPodLogs(pods, nsDir, gatherNS, hcpCluster.managementClusterName, hcpCluster.DynatraceURL, tokenProvider, g.Since, g.Tail, g.SortOrder)
+		nsDir, err := addDir([]string{gatherDir, gatherNS}, []string{})
 		if err != nil {
 			return err
 		}
 
-		deployments, err := getDeploymentsForNamespace(clientset, gatherNS)
+		nsTasks, err := g.planNamespaceTasks(pods, deployments, gatherDir, nsDir, gatherNS, hcpCluster.managementClusterName, manifest.From, manifest.To)
 		if err != nil {
 			return err
 		}
+		tasks = append(tasks, nsTasks...)
+	}
 
-		err = g.dumpEvents(deployments, nsDir, gatherNS, hcpCluster.managementClusterName, hcpCluster.DynatraceURL, tokenProvider, g.Since, g.Tail, g.SortOrder)
-		if err != nil {
-			return err
+	var pendingTasks []*gatherTask
+	for _, task := range tasks {
+		if g.Resume && manifest.isCompleted(gatherDir, task.entry.File) {
+			continue
 		}
+		pendingTasks = append(pendingTasks, task)
+	}
+	if g.Resume {
+		fmt.Printf("Resuming: %d of %d files already gathered\n", len(tasks)-len(pendingTasks), len(tasks))
+	}
 
-		err = g.dumpRestartedPodLogs(pods, nsDir, gatherNS, hcpCluster.managementClusterName, hcpCluster.DynatraceURL, tokenProvider)
-		if err != nil {
-			return err
-		}
+	client := NewClient(hcpCluster.DynatraceURL, tokenProvider)
+	failedCount := g.runGatherTasks(ctx, pendingTasks, manifest, gatherDir, client)
 
+	fmt.Printf("Gathered %d of %d files to %s\n", len(pendingTasks)-failedCount, len(pendingTasks), gatherDir)
+	if failedCount > 0 {
+		return fmt.Errorf("%d queries failed, see %s for details - run the command again with --resume to retry them", failedCount, manifest.path)
 	}
 
 	return nil
 }
 
-func (g *GatherLogsOpts) dumpEvents(deploys *appsv1.DeploymentList, parentDir string, targetNS string, managementClusterName string, DTURL string, tokenProvider utils.AccessTokenProvider, since int, tail int, sortOrder string) error {
-	totalDeployments := len(deploys.Items)
-	for k, d := range deploys.Items {
-		fmt.Printf("[%d/%d] Deployment events for %s\n", k+1, totalDeployments, d.Name)
+// loadOrCreateManifest returns the manifest of the previous run when resuming, so that the same time range is gathered, or a new manifest otherwise.
+func (g *GatherLogsOpts) loadOrCreateManifest(gatherDir string, clusterID string, hcpNamespace string) (*GatherManifest, error) {
+	manifestPath := filepath.Join(gatherDir, gatherManifestFileName)
 
-		eventQuery, err := getEventQuery(d.Name, targetNS, g.Since, g.Tail, g.SortOrder, managementClusterName)
+	if g.Resume {
+		manifest, err := loadGatherManifest(manifestPath)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		eventQuery.Build()
-
-		deploymentYamlFileName := "deployment.yaml"
-		eventsFileName := "events.log"
-		eventsDirPath, err := addDir([]string{parentDir, "events", d.Name}, []string{deploymentYamlFileName, eventsFileName})
-		if err != nil {
-			return err
+		if manifest != nil {
+			if manifest.ClusterID != clusterID {
+				return nil, fmt.Errorf("cannot resume: the manifest %s was written for cluster %s, not %s", manifestPath, manifest.ClusterID, clusterID)
+			}
+			fmt.Printf("Resuming from %s, gathering from %s to %s\n", manifestPath, manifest.From.Format(time.RFC3339), manifest.To.Format(time.RFC3339))
+			return manifest, nil
 		}
+		fmt.Printf("No manifest found in %s, gathering everything\n", gatherDir)
+	}
 
-		deploymentYamlPath := filepath.Join(eventsDirPath, deploymentYamlFileName)
-		deploymentYaml, err := yaml.Marshal(d)
-		if err != nil {
-			return fmt.Errorf("failed to marshal YAML: %v", err)
-		}
-		f, err := os.OpenFile(deploymentYamlPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if err != nil {
-			return err
-		}
-		_, writeErr := f.Write(deploymentYaml)
-		closeErr := f.Close()
-		if writeErr != nil {
-			return writeErr
-		}
-		if closeErr != nil {
-			return closeErr
-		}
+	to := time.Now().UTC().Truncate(time.Second)
+	from := to.Add(-time.Duration(g.Since) * time.Hour)
 
-		eventsFilePath := filepath.Join(eventsDirPath, eventsFileName)
+	return newGatherManifest(manifestPath, clusterID, hcpNamespace, from, to), nil
+}
 
-		accessToken, err := tokenProvider.Token()
-		if err != nil {
-			return fmt.Errorf("failed to get access token: %v", err)
-		}
+// planNamespaceTasks writes the pod & deployment YAMLs of the namespace and returns the queries gathering their logs and events.
+func (g *GatherLogsOpts) planNamespaceTasks(pods *corev1.PodList, deploys *appsv1.DeploymentList, gatherDir string, nsDir string, targetNS string, managementClusterName string, from time.Time, to time.Time) ([]*gatherTask, error) {
+	var tasks []*gatherTask
 
-		eventsRequestToken, err := getDTQueryExecution(DTURL, accessToken, eventQuery.finalQuery)
+	newTask := func(kind string, resource string, filePath string, query DTQuery, writeRecord func(io.Writer, json.RawMessage) error) error {
+		relativeFilePath, err := filepath.Rel(gatherDir, filePath)
 		if err != nil {
-			log.Printf("failed to get request token: %v", err)
-			continue
+			return err
 		}
+		tasks = append(tasks, &gatherTask{
+			entry: &GatherManifestEntry{
+				File:      relativeFilePath,
+				Kind:      kind,
+				Namespace: targetNS,
+				Resource:  resource,
+				Query:     query.Build(),
+				From:      from,
+				To:        to,
+			},
+			writeRecord: writeRecord,
+		})
+		return nil
+	}
 
-		err = fetchAndWriteEvents(DTURL, accessToken, eventsRequestToken, eventsFilePath)
+	for _, p := range pods.Items {
+		podLogsQuery, err := getPodQuery(p.Name, targetNS, from, to, g.Tail, g.SortOrder, managementClusterName)
 		if err != nil {
-			log.Printf("failed to get logs, continuing: %v. Query: %v", err, eventQuery.finalQuery)
-			continue
+			return nil, err
 		}
 
-	}
-	return nil
-}
-
-func (g *GatherLogsOpts) dumpPodLogs(pods *corev1.PodList, parentDir string, targetNS string, managementClusterName string, DTURL string, tokenProvider utils.AccessTokenProvider, since int, tail int, sortOrder string) error {
-	totalPods := len(pods.Items)
-	for k, p := range pods.Items {
-		fmt.Printf("[%d/%d] Pod logs for %s\n", k+1, totalPods, p.Name)
-
-		podLogsQuery, err := getPodQuery(p.Name, targetNS, g.Since, g.Tail, g.SortOrder, managementClusterName)
+		podDirPath, err := addDir([]string{nsDir, "pods", p.Name}, []string{})
 		if err != nil {
-			return err
+			return nil, err
 		}
-		podLogsQuery.Build()
 
-		podYamlFileName := "pod.yaml"
-		podLogFileName := "pod.log"
-		podDirPath, err := addDir([]string{parentDir, "pods", p.Name}, []string{podLogFileName, podYamlFileName})
+		err = writeYaml(filepath.Join(podDirPath, "pod.yaml"), p)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		podYamlFilePath := filepath.Join(podDirPath, podYamlFileName)
-		podYaml, err := yaml.Marshal(p)
+		err = newTask(gatherKindPodLogs, p.Name, filepath.Join(podDirPath, "pod.log"), podLogsQuery, writeLogRecord)
 		if err != nil {
-			return fmt.Errorf("failed to marshal YAML: %v", err)
+			return nil, err
 		}
-		f, err := os.OpenFile(podYamlFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	}
+
+	for _, d := range deploys.Items {
+		eventQuery, err := getEventQuery(d.Name, targetNS, from, to, g.Tail, g.SortOrder, managementClusterName)
 		if err != nil {
-			return err
-		}
-		_, writeErr := f.Write(podYaml)
-		closeErr := f.Close()
-		if writeErr != nil {
-			return writeErr
+			return nil, err
 		}
-		if closeErr != nil {
-			return closeErr
-		}
-
-		podLogsFilePath := filepath.Join(podDirPath, podLogFileName)
 
-		accessToken, err := tokenProvider.Token()
+		eventsDirPath, err := addDir([]string{nsDir, "events", d.Name}, []string{})
 		if err != nil {
-			return fmt.Errorf("failed to get access token: %v", err)
+			return nil, err
 		}
 
-		podLogsRequestToken, err := getDTQueryExecution(DTURL, accessToken, podLogsQuery.finalQuery)
+		err = writeYaml(filepath.Join(eventsDirPath, "deployment.yaml"), d)
 		if err != nil {
-			log.Printf("failed to get request token: %v", err)
-			continue
+			return nil, err
 		}
 
-		err = fetchAndWriteLogs(DTURL, accessToken, podLogsRequestToken, podLogsFilePath)
+		err = newTask(gatherKindEvents, d.Name, filepath.Join(eventsDirPath, "events.log"), eventQuery, writeEventRecord)
 		if err != nil {
-			log.Printf("failed to get logs, continuing: %v. Query: %v", err, podLogsQuery.finalQuery)
-			continue
+			return nil, err
 		}
 	}
 
-	return nil
-}
-
-func (g *GatherLogsOpts) dumpRestartedPodLogs(pods *corev1.PodList, parentDir string, targetNS string, managementClusterName string, DTURL string, tokenProvider utils.AccessTokenProvider) error {
 	var podList []string
 	for _, p := range pods.Items {
 		podList = append(podList, p.Name)
 	}
-	fmt.Printf("Collecting Restarted Pod logs for %s\n", targetNS)
 
-	restartedPodLogsQuery, err := getRestartedPodQuery(podList, targetNS, g.Since, g.Tail, g.SortOrder, managementClusterName)
+	restartedPodLogsQuery, err := getRestartedPodQuery(podList, targetNS, from, to, g.Tail, g.SortOrder, managementClusterName)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	restartedPodLogsQuery.Build()
 
-	restartedPodLogFileName := "pods.log"
-	podDirPath, err := addDir([]string{parentDir, "restarted-pods"}, []string{restartedPodLogFileName})
+	restartedPodsDirPath, err := addDir([]string{nsDir, "restarted-pods"}, []string{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	restartedPodLogsFilePath := filepath.Join(podDirPath, restartedPodLogFileName)
-
-	accessToken, err := tokenProvider.Token()
+	err = newTask(gatherKindRestartedPodLogs, "", filepath.Join(restartedPodsDirPath, "pods.log"), restartedPodLogsQuery, writeLogRecord)
 	if err != nil {
-		return fmt.Errorf("failed to get access token: %v", err)
+		return nil, err
 	}
 
-	podLogsRequestToken, err := getDTQueryExecution(DTURL, accessToken, restartedPodLogsQuery.finalQuery)
-	if err != nil {
-		log.Printf("failed to get request token: %v", err)
+	return tasks, nil
+}
+
+// runGatherTasks runs the given tasks with a bounded number of workers, recording the outcome of each of them in the manifest.
+// It returns the number of failed tasks.
+func (g *GatherLogsOpts) runGatherTasks(ctx context.Context, tasks []*gatherTask, manifest *GatherManifest, gatherDir string, client Client) int {
+	workers := g.Workers
+	if workers <= 0 {
+		workers = DefaultGatherWorkers
+	}
+
+	var mutex sync.Mutex
+	doneCount, failedCount := 0, 0
+
+	var group errgroup.Group
+	group.SetLimit(workers)
+	for _, task := range tasks {
+		group.Go(func() error {
+			err := g.runGatherTask(ctx, task, gatherDir, client)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			doneCount++
+			if err != nil {
+				failedCount++
+				task.entry.Status = GatherStatusFailed
+				task.entry.Error = err.Error()
+				log.Printf("[%d/%d] Failed to gather %s: %v. Query: %v", doneCount, len(tasks), task.entry.File, err, task.entry.Query)
+			} else {
+				task.entry.Status = GatherStatusCompleted
+				task.entry.Error = ""
+				fmt.Printf("[%d/%d] Gathered %d records to %s\n", doneCount, len(tasks), task.entry.Records, task.entry.File)
+			}
+
+			if err := manifest.record(task.entry); err != nil {
+				log.Printf("failed to update the manifest: %v", err)
+			}
+
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return failedCount
+}
+
+// runGatherTask runs the query of the task, retrying on transient Dynatrace errors. The output file is rewritten by every attempt.
+func (g *GatherLogsOpts) runGatherTask(ctx context.Context, task *gatherTask, gatherDir string, client Client) error {
+	filePath := filepath.Join(gatherDir, task.entry.File)
+
+	return backoff.Retry(func() error {
+		task.entry.Attempts++
+
+		f, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return backoff.Permanent(fmt.Errorf("failed to create file %v: %v", filePath, err))
+		}
+		w := bufio.NewWriter(f)
+
+		recordsCount, err := RunQuery(ctx, client, task.entry.Query, QueryOptions{}, func(record json.RawMessage) error {
+			return task.writeRecord(w, record)
+		})
+		if err == nil {
+			err = w.Flush()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return retryableOrPermanent(fmt.Errorf("failed to get records: %w", err))
+		}
+		task.entry.Records = recordsCount
+
 		return nil
+	}, backoff.WithContext(backoff.WithMaxRetries(newGatherBackOff(), uint64(g.Retries)), ctx))
+}
+
+// isRetryableDTError returns whether the error is a Dynatrace throttling or server error, which may succeed when retried
+func isRetryableDTError(err error) bool {
+	var requestErr *utils.RequestError
+	if !errors.As(err, &requestErr) {
+		return false
+	}
+
+	return requestErr.StatusCode == http.StatusTooManyRequests || requestErr.StatusCode >= http.StatusInternalServerError
+}
+
+func retryableOrPermanent(err error) error {
+	if isRetryableDTError(err) {
+		return err
 	}
-	err = fetchAndWriteLogs(DTURL, accessToken, podLogsRequestToken, restartedPodLogsFilePath)
+
+	return backoff.Permanent(err)
+}
+
+func writeYaml(filePath string, object interface{}) error {
+	objectYaml, err := yaml.Marshal(object)
 	if err != nil {
-		log.Printf("failed to get restarted pod logs: %v. Query: %v", err, restartedPodLogsQuery.finalQuery)
+		return fmt.Errorf("failed to marshal YAML: %v", err)
 	}
 
-	return nil
+	return os.WriteFile(filePath, objectYaml, 0600)
 }
 
 func setupGatherDir(destBaseDir string, dirName string) (logsDir string, error error) {
@@ -318,9 +439,9 @@ func addDir(dirs []string, filePaths []string) (path string, error error) {
 	return dirPath, nil
 }
 
-func getPodQuery(pod string, namespace string, since int, tail int, sortOrder string, srcCluster string) (query DTQuery, error error) {
+func getPodQuery(pod string, namespace string, from time.Time, to time.Time, tail int, sortOrder string, srcCluster string) (query DTQuery, error error) {
 	q := DTQuery{}
-	q.InitLogs(since).Cluster(srcCluster)
+	q.InitLogsWithTimeRange(from, to).Cluster(srcCluster)
 
 	if namespace != "" {
 		q.Namespaces([]string{namespace})
@@ -344,9 +465,9 @@ func getPodQuery(pod string, namespace string, since int, tail int, sortOrder st
 	return q, nil
 }
 
-func getRestartedPodQuery(pods []string, namespace string, since int, tail int, sortOrder string, srcCluster string) (query DTQuery, error error) {
+func getRestartedPodQuery(pods []string, namespace string, from time.Time, to time.Time, tail int, sortOrder string, srcCluster string) (query DTQuery, error error) {
 	q := DTQuery{}
-	q.InitLogs(since).Cluster(srcCluster)
+	q.InitLogsWithTimeRange(from, to).Cluster(srcCluster)
 
 	if namespace != "" {
 		q.Namespaces([]string{namespace})
@@ -376,9 +497,13 @@ func getRestartedPodQuery(pods []string, namespace string, since int, tail int,
 	return q, nil
 }
 
-func getEventQuery(deploy string, namespace string, since int, tail int, sortOrder string, srcCluster string) (query DTQuery, error error) {
+func getEventQuery(deploy string, namespace string, from time.Time, to time.Time, tail int, sortOrder string, srcCluster string) (query DTQuery, error error) {
 	q := DTQuery{}
-	q.InitEvents(since).Cluster(srcCluster)
+	_, err := q.InitFetchWithTimeRange("events", from, to)
+	if err != nil {
+		return q, err
+	}
+	q.Cluster(srcCluster)
 
 	if namespace != "" {
 		q.Namespaces([]string{namespace})
@@ -402,9 +527,9 @@ func getEventQuery(deploy string, namespace string, since int, tail int, sortOrd
 	return q, nil
 }
 
-func getPodsForNamespace(clientset *kubernetes.Clientset, namespace string) (pl *corev1.PodList, error error) {
+func getPodsForNamespace(ctx context.Context, clientset *kubernetes.Clientset, namespace string) (pl *corev1.PodList, error error) {
 	// Getting pod objects for non-running state pod
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), v1.ListOptions{})
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods in namespace '%s'", namespace)
 	}
@@ -412,9 +537,9 @@ func getPodsForNamespace(clientset *kubernetes.Clientset, namespace string) (pl
 	return pods, nil
 }
 
-func getDeploymentsForNamespace(clientset *kubernetes.Clientset, namespace string) (pl *appsv1.DeploymentList, error error) {
+func getDeploymentsForNamespace(ctx context.Context, clientset *kubernetes.Clientset, namespace string) (pl *appsv1.DeploymentList, error error) {
 	// Getting pod objects for non-running state pod
-	deploys, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), v1.ListOptions{})
+	deploys, err := clientset.AppsV1().Deployments(namespace).List(ctx, v1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods in namespace '%s'", namespace)
 	}
@@ -1,11 +1,19 @@
 package dynatrace
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/openshift/osdctl/pkg/utils"
 )
 
 func TestSetupGatherDir(t *testing.T) {
@@ -170,7 +178,7 @@ func TestGetPodQuery(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s-%s", tt.pod, tt.namespace), func(t *testing.T) {
-			query, err := getPodQuery(tt.pod, tt.namespace, tt.since, tt.tail, tt.sortOrder, tt.srcCluster)
+			query, err := getPodQuery(tt.pod, tt.namespace, time.Now().Add(-time.Duration(tt.since)*time.Hour), time.Now(), tt.tail, tt.sortOrder, tt.srcCluster)
 
 			if tt.expectError && err == nil {
 				t.Errorf("expected error but got none for test: %s-%s", tt.pod, tt.namespace)
@@ -249,7 +257,7 @@ func TestGetEventQuery(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("%s-%s", tt.event, tt.namespace), func(t *testing.T) {
-			query, err := getEventQuery(tt.event, tt.namespace, tt.since, tt.tail, tt.sortOrder, tt.srcCluster)
+			query, err := getEventQuery(tt.event, tt.namespace, time.Now().Add(-time.Duration(tt.since)*time.Hour), time.Now(), tt.tail, tt.sortOrder, tt.srcCluster)
 
 			if tt.expectError && err == nil {
 				t.Errorf("expected error but got none for test: %s-%s", tt.event, tt.namespace)
@@ -277,3 +285,160 @@ func TestGetEventQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestIsRetryableDTError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"throttled", &utils.RequestError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", fmt.Errorf("failed to get request token: %w", &utils.RequestError{StatusCode: http.StatusBadGateway}), true},
+		{"bad request", &utils.RequestError{StatusCode: http.StatusBadRequest}, false},
+		{"other error", errors.New("query failed"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableDTError(tt.err); got != tt.want {
+				t.Errorf("isRetryableDTError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatherManifest(t *testing.T) {
+	gatherDir := t.TempDir()
+	manifestPath := filepath.Join(gatherDir, gatherManifestFileName)
+
+	manifest, err := loadGatherManifest(manifestPath)
+	if err != nil || manifest != nil {
+		t.Fatalf("expected no manifest and no error, got %v and %v", manifest, err)
+	}
+
+	from := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	manifest = newGatherManifest(manifestPath, "cluster-id", "ocm-production-cluster-id", from, from.Add(10*time.Hour))
+
+	if err := os.WriteFile(filepath.Join(gatherDir, "pod.log"), []byte("log\n"), 0600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	for _, entry := range []*GatherManifestEntry{
+		{File: "pod.log", Status: GatherStatusCompleted, Records: 1},
+		{File: "events.log", Status: GatherStatusFailed, Error: "boom"},
+		{File: "deleted.log", Status: GatherStatusCompleted},
+	} {
+		if err := manifest.record(entry); err != nil {
+			t.Fatalf("failed to record entry: %v", err)
+		}
+	}
+	// Recording the same file again replaces its entry
+	if err := manifest.record(&GatherManifestEntry{File: "pod.log", Status: GatherStatusCompleted, Records: 2}); err != nil {
+		t.Fatalf("failed to record entry: %v", err)
+	}
+
+	loaded, err := loadGatherManifest(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to load manifest: %v", err)
+	}
+	if loaded.ClusterID != "cluster-id" || !loaded.From.Equal(from) || len(loaded.Entries) != 3 || loaded.Entries[0].Records != 2 {
+		t.Errorf("unexpected loaded manifest: %+v", loaded)
+	}
+
+	for file, want := range map[string]bool{
+		"pod.log":     true,
+		"events.log":  false, // failed
+		"deleted.log": false, // completed but removed since
+		"unknown.log": false,
+	} {
+		if got := loaded.isCompleted(gatherDir, file); got != want {
+			t.Errorf("isCompleted(%s) = %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestLoadOrCreateManifest(t *testing.T) {
+	gatherDir := t.TempDir()
+	manifestPath := filepath.Join(gatherDir, gatherManifestFileName)
+
+	from := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	previous := newGatherManifest(manifestPath, "cluster-id", "ocm-production-cluster-id", from, from.Add(10*time.Hour))
+	if err := previous.record(&GatherManifestEntry{File: "pod.log", Status: GatherStatusCompleted}); err != nil {
+		t.Fatalf("failed to record entry: %v", err)
+	}
+
+	g := &GatherLogsOpts{Since: 1, Resume: true}
+	manifest, err := g.loadOrCreateManifest(gatherDir, "cluster-id", "ocm-production-cluster-id")
+	if err != nil {
+		t.Fatalf("failed to resume the manifest: %v", err)
+	}
+	if !manifest.From.Equal(from) || len(manifest.Entries) != 1 {
+		t.Errorf("expected the previous manifest to be resumed, got %+v", manifest)
+	}
+
+	if _, err := g.loadOrCreateManifest(gatherDir, "other-cluster-id", "ocm-production-other-cluster-id"); err == nil || !strings.Contains(err.Error(), "other-cluster-id") {
+		t.Errorf("expected resuming the manifest of another cluster to fail, got %v", err)
+	}
+
+	g.Resume = false
+	manifest, err = g.loadOrCreateManifest(gatherDir, "other-cluster-id", "ocm-production-other-cluster-id")
+	if err != nil {
+		t.Fatalf("failed to create the manifest: %v", err)
+	}
+	if manifest.ClusterID != "other-cluster-id" || len(manifest.Entries) != 0 {
+		t.Errorf("expected a new manifest, got %+v", manifest)
+	}
+}
+
+func TestRunGatherTask(t *testing.T) {
+	originalBackOff := newGatherBackOff
+	newGatherBackOff = func() backoff.BackOff { return &backoff.ZeroBackOff{} }
+	defer func() { newGatherBackOff = originalBackOff }()
+
+	throttled := &utils.RequestError{StatusCode: http.StatusTooManyRequests}
+	unavailable := &utils.RequestError{StatusCode: http.StatusServiceUnavailable}
+	badRequest := &utils.RequestError{StatusCode: http.StatusBadRequest}
+	records := []json.RawMessage{[]byte(`{"content":"line 1"}`), []byte(`{"content":"line 2"}`)}
+
+	tests := []struct {
+		name             string
+		executeErrs      []error
+		retries          int
+		expectError      bool
+		expectedAttempts int
+	}{
+		{"throttled then succeeded", []error{throttled, unavailable}, 3, false, 3},
+		{"retries exhausted", []error{throttled, throttled}, 1, true, 2},
+		{"bad query is not retried", []error{badRequest}, 3, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewFakeClient(map[string]*FakeQuery{
+				"fetch logs": {ExecuteErrs: tt.executeErrs, Records: records},
+			})
+
+			gatherDir := t.TempDir()
+			// Leftovers of a previous attempt must not be kept
+			if err := os.WriteFile(filepath.Join(gatherDir, "pod.log"), []byte("stale\n"), 0600); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			task := &gatherTask{entry: &GatherManifestEntry{File: "pod.log", Query: "fetch logs"}, writeRecord: writeLogRecord}
+			g := &GatherLogsOpts{Retries: tt.retries}
+			err := g.runGatherTask(context.Background(), task, gatherDir, client)
+
+			if tt.expectError != (err != nil) {
+				t.Fatalf("expectError = %v, got %v", tt.expectError, err)
+			}
+			if task.entry.Attempts != tt.expectedAttempts || len(client.Executions()) != tt.expectedAttempts {
+				t.Errorf("attempts = %d with %d executions, want %d", task.entry.Attempts, len(client.Executions()), tt.expectedAttempts)
+			}
+			if !tt.expectError {
+				content, _ := os.ReadFile(filepath.Join(gatherDir, "pod.log"))
+				if string(content) != "line 1\nline 2\n" || task.entry.Records != 2 {
+					t.Errorf("unexpected file content %q with %d records", content, task.entry.Records)
+				}
+			}
+		})
+	}
+}
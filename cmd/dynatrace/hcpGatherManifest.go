@@ -0,0 +1,135 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/openshift/osdctl/pkg/utils"
+)
+
+const gatherManifestFileName = "manifest.json"
+
+const (
+	gatherKindPodLogs          = "pod-logs"
+	gatherKindEvents           = "events"
+	gatherKindRestartedPodLogs = "restarted-pod-logs"
+)
+
+type GatherStatus string
+
+const (
+	GatherStatusCompleted GatherStatus = "completed"
+	GatherStatusFailed    GatherStatus = "failed"
+)
+
+// GatherManifestEntry describes a file of a logs dump and the Dynatrace query it was gathered with
+type GatherManifestEntry struct {
+	File      string       `json:"file"` // Relative to the dump directory
+	Kind      string       `json:"kind"`
+	Namespace string       `json:"namespace"`
+	Resource  string       `json:"resource,omitempty"`
+	Query     string       `json:"query"`
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"`
+	Records   int          `json:"records"`
+	Attempts  int          `json:"attempts"`
+	Status    GatherStatus `json:"status"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// GatherManifest describes all the files of a logs dump. It is rewritten after every gathered file so that an interrupted run can be resumed.
+type GatherManifest struct {
+	ClusterID    string                 `json:"clusterId"`
+	HCPNamespace string                 `json:"hcpNamespace"`
+	From         time.Time              `json:"from"`
+	To           time.Time              `json:"to"`
+	Entries      []*GatherManifestEntry `json:"entries"`
+
+	path  string
+	mutex sync.Mutex
+}
+
+func newGatherManifest(path string, clusterID string, hcpNamespace string, from time.Time, to time.Time) *GatherManifest {
+	return &GatherManifest{
+		ClusterID:    clusterID,
+		HCPNamespace: hcpNamespace,
+		From:         from,
+		To:           to,
+		Entries:      []*GatherManifestEntry{},
+		path:         path,
+	}
+}
+
+// loadGatherManifest returns the manifest stored at the given path, or nil if there is none.
+func loadGatherManifest(path string) (*GatherManifest, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %v", err)
+	}
+
+	manifest := &GatherManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest '%s': %v", path, err)
+	}
+	manifest.path = path
+
+	return manifest, nil
+}
+
+// isCompleted returns whether the given file was successfully gathered and is still present in the dump directory.
+func (m *GatherManifest) isCompleted(gatherDir string, file string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, entry := range m.Entries {
+		if entry.File == file {
+			if entry.Status != GatherStatusCompleted {
+				return false
+			}
+			_, err := os.Stat(filepath.Join(gatherDir, file))
+			return err == nil
+		}
+	}
+
+	return false
+}
+
+// record adds or replaces the entry of the same file, then saves the manifest.
+func (m *GatherManifest) record(entry *GatherManifestEntry) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	isReplaced := false
+	for idx, existingEntry := range m.Entries {
+		if existingEntry.File == entry.File {
+			m.Entries[idx] = entry
+			isReplaced = true
+			break
+		}
+	}
+	if !isReplaced {
+		m.Entries = append(m.Entries, entry)
+	}
+
+	return m.save()
+}
+
+func (m *GatherManifest) save() error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+
+	if err := utils.WriteFileAtomic(m.path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package dynatrace
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
 	"time"
 
 	k8s "github.com/openshift/osdctl/pkg/k8s"
@@ -84,7 +86,7 @@ func NewCmdLogs() *cobra.Command {
 				pod = args[0]
 			}
 
-			err = main(clusterID)
+			err = main(cmd.Context(), clusterID)
 			if err != nil {
 				cmdutil.CheckErr(err)
 			}
@@ -138,7 +140,7 @@ func GetLinkToWebConsole(dtURL string, from string, to string, finalQuery string
 	return fmt.Sprintf("%sui/apps/dynatrace.logs/#%s", dtURL, url.PathEscape(string(mStr))), nil
 }
 
-func main(clusterID string) error {
+func main(ctx context.Context, clusterID string) error {
 	var hcpCluster HCPCluster
 	if since <= 0 {
 		return fmt.Errorf("invalid time duration")
@@ -186,16 +188,13 @@ func main(clusterID string) error {
 		return nil
 	}
 
-	accessToken, err := getStorageAccessToken()
+	tokenProvider, err := getStorageTokenProvider()
 	if err != nil {
-		return fmt.Errorf("failed to acquire access token %v", err)
+		return fmt.Errorf("failed to setup Dynatrace access token provider %v", err)
 	}
 
-	requestToken, err := getDTQueryExecution(hcpCluster.DynatraceURL, accessToken, query.finalQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get  vault token %v", err)
-	}
-	err = fetchAndWriteLogs(hcpCluster.DynatraceURL, accessToken, requestToken, "")
+	client := NewClient(hcpCluster.DynatraceURL, tokenProvider)
+	_, err = QueryLogs(ctx, client, query.finalQuery, QueryOptions{ProgressWriter: os.Stderr}, os.Stdout)
 	if err != nil {
 		return fmt.Errorf("failed to get logs %v", err)
 	}
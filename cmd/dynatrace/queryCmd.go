@@ -0,0 +1,415 @@
+package dynatrace
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	k8s "github.com/openshift/osdctl/pkg/k8s"
+	"github.com/openshift/osdctl/pkg/printer"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	queryCmdDescription = `
+  Run a DQL query against the Dynatrace tenant of a MC or HCP cluster and print the resulting records as a table.
+
+  The query can either be given as raw DQL (--dql) or built from flags: the data object to fetch (logs, events, spans or bizevents),
+  filters, a parse command, a fields projection, a summarize command with optional time buckets, sorting and a limit.
+  Records are always restricted to the management cluster of the given cluster, and to its HCP namespace if no namespace is given.
+
+`
+
+	queryCmdExample = `
+  # Count errors per pod over the last 6 hours
+  $ osdctl dt query --cluster-id <cluster-id> --since 6 --status ERROR --summarize "count()" --by k8s.pod.name --sort-by "count()" --sort desc
+
+  # Count logs containing a phrase per hour and per container
+  $ osdctl dt query --cluster-id <cluster-id> --since 24 --contains "context deadline exceeded" --summarize "count()" --by k8s.container.name --bucket 1h
+
+  # Only print some fields of the events of a namespace
+  $ osdctl dt query --cluster-id <cluster-id> --source events -n hypershift --fields timestamp,event.name,event.type
+
+  # Extract a field from log lines and count its values
+  $ osdctl dt query --cluster-id <cluster-id> --parse "LD 'reason=' WORD:reason" --summarize "count()" --by reason
+
+  # Run raw DQL and print the results as JSON
+  $ osdctl dt query --cluster-id <cluster-id> --dql 'fetch logs | summarize count(), by:{status}' -o json
+`
+)
+
+const (
+	queryOutputTable = "table"
+	queryOutputJSON  = "json"
+	queryOutputCSV   = "csv"
+)
+
+var dqlDataObjectRegex = regexp.MustCompile(`^\s*fetch\s+(\w+)`)
+
+type queryOpts struct {
+	clusterID    string
+	dql          string
+	dataObject   string
+	since        int
+	from         time.Time
+	to           time.Time
+	namespaces   []string
+	pods         []string
+	containers   []string
+	statuses     []string
+	contains     string
+	filters      []string
+	parseField   string
+	parsePattern string
+	fields       []string
+	aggregations []string
+	byFields     []string
+	bucket       time.Duration
+	sortBy       string
+	sortOrder    string
+	limit        int
+	output       string
+	dryRun       bool
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+func newCmdQuery() *cobra.Command {
+	o := &queryOpts{}
+
+	queryCmd := &cobra.Command{
+		Use:               "query --cluster-id <cluster-identifier>",
+		Short:             "Run a DQL query and print the results as a table",
+		Long:              queryCmdDescription,
+		Example:           queryCmdExample,
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.run(cmd.Context()))
+		},
+	}
+
+	queryCmd.Flags().StringVarP(&o.clusterID, "cluster-id", "C", "", "Name or Internal ID of the cluster (defaults to current cluster context)")
+	queryCmd.Flags().StringVar(&o.dql, "dql", "", "Raw DQL query to run - exclusive with all the query building flags")
+	queryCmd.Flags().StringVar(&o.dataObject, "source", "logs", fmt.Sprintf("Data object to fetch. Accepted values are %s", strings.Join(validDataObjects, ", ")))
+	queryCmd.Flags().IntVar(&o.since, "since", 1, "Number of hours (integer) since which to search")
+	queryCmd.Flags().TimeVar(&o.from, "from", time.Time{}, []string{time.RFC3339, "2006-01-02 15:04"}, "Datetime from which to filter records, in the format \"YYYY-MM-DD HH:MM\"")
+	queryCmd.Flags().TimeVar(&o.to, "to", time.Time{}, []string{time.RFC3339, "2006-01-02 15:04"}, "Datetime until which to filter records, in the format \"YYYY-MM-DD HH:MM\"")
+	queryCmd.Flags().StringSliceVarP(&o.namespaces, "namespace", "n", []string{}, "Namespace(s) (comma-separated) - defaults to the HCP namespace for HCP clusters")
+	queryCmd.Flags().StringSliceVar(&o.pods, "pod", []string{}, "Pod name(s) (comma-separated)")
+	queryCmd.Flags().StringSliceVar(&o.containers, "container", []string{}, "Container name(s) (comma-separated)")
+	queryCmd.Flags().StringSliceVar(&o.statuses, "status", []string{}, "Status(Info/Warn/Error) (comma-separated)")
+	queryCmd.Flags().StringVar(&o.contains, "contains", "", "Include records whose content contains a phrase")
+	queryCmd.Flags().StringArrayVar(&o.filters, "filter", []string{}, "Raw DQL condition the records must match, e.g. 'isNotNull(k8s.pod.name)' - flag can be repeated")
+	queryCmd.Flags().StringVar(&o.parsePattern, "parse", "", "DPL pattern used to extract new fields, e.g. \"LD 'reason=' WORD:reason\"")
+	queryCmd.Flags().StringVar(&o.parseField, "parse-field", "content", "Field to apply the --parse pattern to")
+	queryCmd.Flags().StringSliceVar(&o.fields, "fields", []string{}, "Fields to keep in the results (comma-separated)")
+	queryCmd.Flags().StringArrayVar(&o.aggregations, "summarize", []string{}, "Aggregation to compute, e.g. 'count()' or 'errors = countIf(status == \"ERROR\")' - flag can be repeated")
+	queryCmd.Flags().StringSliceVar(&o.byFields, "by", []string{}, "Fields to group the aggregations by (comma-separated) - only applicable with --summarize")
+	queryCmd.Flags().DurationVar(&o.bucket, "bucket", 0, "Size of the time buckets to group the aggregations by, e.g. 1h or 15m - only applicable with --summarize")
+	queryCmd.Flags().StringVar(&o.sortBy, "sort-by", "", "Field to sort the results by (defaults to timestamp when not summarizing)")
+	queryCmd.Flags().StringVar(&o.sortOrder, "sort", "asc", "Sort the results in either ascending or descending order. Accepted values are 'asc' and 'desc'.")
+	queryCmd.Flags().IntVar(&o.limit, "limit", 1000, "Maximum number of records to return, 0 for no limit")
+	queryCmd.Flags().StringVarP(&o.output, "output", "o", queryOutputTable, "Output format. Accepted values are 'table', 'json' and 'csv'")
+	queryCmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "Only builds the query without running it")
+	queryCmd.Flags().DurationVar(&o.pollInterval, "poll-interval", DefaultQueryPollInterval, "Interval between two checks of the completion of the query")
+	queryCmd.Flags().DurationVar(&o.timeout, "timeout", DefaultQueryTimeout, "Maximum time to wait for the query to complete")
+
+	queryCmd.MarkFlagsRequiredTogether("from", "to")
+	queryCmd.MarkFlagsMutuallyExclusive("since", "from")
+	queryCmd.MarkFlagsMutuallyExclusive("since", "to")
+	for _, builderFlag := range []string{"source", "since", "from", "to", "namespace", "pod", "container", "status", "contains", "filter", "parse", "parse-field", "fields", "summarize", "by", "bucket", "sort-by", "sort", "limit"} {
+		queryCmd.MarkFlagsMutuallyExclusive("dql", builderFlag)
+	}
+
+	return queryCmd
+}
+
+func (o *queryOpts) validate() error {
+	if o.output != queryOutputTable && o.output != queryOutputJSON && o.output != queryOutputCSV {
+		return fmt.Errorf("invalid output format '%s', expecting 'table', 'json' or 'csv'", o.output)
+	}
+
+	if o.dql != "" {
+		return nil
+	}
+
+	if err := validateDataObject(o.dataObject); err != nil {
+		return err
+	}
+	if o.since <= 0 {
+		return fmt.Errorf("invalid time duration")
+	}
+	if !o.from.IsZero() && !o.to.IsZero() && o.to.Before(o.from) {
+		return fmt.Errorf("--to cannot be set to a datetime before --from")
+	}
+	if o.sortOrder != "asc" && o.sortOrder != "desc" {
+		return fmt.Errorf("invalid sort order, expecting 'asc' or 'desc'")
+	}
+	if len(o.aggregations) == 0 && (len(o.byFields) > 0 || o.bucket != 0) {
+		return fmt.Errorf("--by and --bucket can only be used with --summarize")
+	}
+	if o.limit < 0 {
+		return fmt.Errorf("invalid limit %d, it must be positive", o.limit)
+	}
+
+	return nil
+}
+
+// buildQuery returns the DQL to run, either the raw one or the one built from the flags.
+func (o *queryOpts) buildQuery(hcpCluster HCPCluster) (string, error) {
+	if o.dql != "" {
+		return o.dql, nil
+	}
+
+	q := &DTQuery{}
+	var err error
+
+	if !o.from.IsZero() && !o.to.IsZero() {
+		q, err = q.InitFetchWithTimeRange(o.dataObject, o.from, o.to)
+	} else {
+		q, err = q.InitFetch(o.dataObject, o.since)
+	}
+	if err != nil {
+		return "", err
+	}
+	q.Cluster(hcpCluster.managementClusterName)
+
+	namespaces := o.namespaces
+	if len(namespaces) == 0 && hcpCluster.hcpNamespace != "" {
+		namespaces = []string{hcpCluster.hcpNamespace}
+	}
+	if len(namespaces) > 0 {
+		q.Namespaces(namespaces)
+	}
+	if len(o.pods) > 0 {
+		q.Pods(o.pods)
+	}
+	if len(o.containers) > 0 {
+		q.Containers(o.containers)
+	}
+	if len(o.statuses) > 0 {
+		q.Status(o.statuses)
+	}
+	if o.contains != "" {
+		q.ContainsPhrase(o.contains)
+	}
+	for _, filter := range o.filters {
+		q.Filter(filter)
+	}
+
+	if o.parsePattern != "" {
+		q.Parse(o.parseField, o.parsePattern)
+	}
+
+	if len(o.aggregations) > 0 {
+		q, err = q.Summarize(o.aggregations, o.byFields, o.bucket)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if o.sortBy != "" {
+		q, err = q.SortBy(o.sortBy, o.sortOrder)
+	} else if len(o.aggregations) == 0 {
+		q, err = q.Sort(o.sortOrder)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Projecting the fields after sorting lets the records be sorted on fields which are not printed
+	if len(o.fields) > 0 {
+		q.Fields(o.fields)
+	}
+
+	if o.limit > 0 {
+		q.Limit(o.limit)
+	}
+
+	return q.Build(), nil
+}
+
+// queriedDataObject returns the data object fetched by the query, used to request the right token scopes.
+func (o *queryOpts) queriedDataObject(query string) string {
+	if o.dql == "" {
+		return o.dataObject
+	}
+
+	matches := dqlDataObjectRegex.FindStringSubmatch(query)
+	if len(matches) < 2 {
+		return ""
+	}
+
+	return matches[1]
+}
+
+// preferredColumns returns the columns explicitly requested through the flags, in the order they were requested.
+func (o *queryOpts) preferredColumns() []string {
+	var columns []string
+	columns = append(columns, o.fields...)
+	columns = append(columns, o.byFields...)
+	if o.bucket != 0 {
+		columns = append(columns, "time_bucket")
+	}
+	for _, aggregation := range o.aggregations {
+		// Aliased aggregations like "errors = count()" are returned under their alias
+		if alias, _, found := strings.Cut(aggregation, "="); found && !strings.ContainsAny(alias, "(\"") {
+			columns = append(columns, strings.TrimSpace(alias))
+		} else {
+			columns = append(columns, strings.TrimSpace(aggregation))
+		}
+	}
+
+	return columns
+}
+
+func (o *queryOpts) run(ctx context.Context) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	var err error
+	if o.clusterID == "" {
+		o.clusterID, err = k8s.GetCurrentCluster()
+		if err != nil {
+			return err
+		}
+	}
+
+	hcpCluster, err := FetchClusterDetails(o.clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to acquire cluster details %v", err)
+	}
+
+	query, err := o.buildQuery(hcpCluster)
+	if err != nil {
+		return fmt.Errorf("failed to build query for Dynatrace %v", err)
+	}
+
+	fmt.Fprintln(os.Stderr, query)
+	fmt.Fprintln(os.Stderr)
+
+	if o.dryRun {
+		return nil
+	}
+
+	tokenProvider, err := getQueryTokenProvider(o.queriedDataObject(query))
+	if err != nil {
+		return fmt.Errorf("failed to setup Dynatrace access token provider %v", err)
+	}
+
+	client := NewClient(hcpCluster.DynatraceURL, tokenProvider)
+	records, err := QueryRecords(ctx, client, query, QueryOptions{PollInterval: o.pollInterval, Timeout: o.timeout, ProgressWriter: os.Stderr})
+	if err != nil {
+		return fmt.Errorf("failed to get query results %v", err)
+	}
+
+	return printRecords(os.Stdout, records, o.preferredColumns(), o.output)
+}
+
+// recordColumns returns the columns of the records: the preferred ones first, then timestamp, then all the other ones sorted.
+func recordColumns(records []map[string]interface{}, preferredColumns []string) []string {
+	allColumns := map[string]bool{}
+	for _, record := range records {
+		for column := range record {
+			allColumns[column] = true
+		}
+	}
+
+	var columns []string
+	for _, column := range preferredColumns {
+		if allColumns[column] {
+			columns = append(columns, column)
+			delete(allColumns, column)
+		}
+	}
+	if allColumns["timestamp"] {
+		columns = append(columns, "timestamp")
+		delete(allColumns, "timestamp")
+	}
+
+	var otherColumns []string
+	for column := range allColumns {
+		otherColumns = append(otherColumns, column)
+	}
+	sort.Strings(otherColumns)
+
+	return append(columns, otherColumns...)
+}
+
+func formatRecordValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	default:
+		valueJSON, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(valueJSON)
+	}
+}
+
+func printRecords(w io.Writer, records []map[string]interface{}, preferredColumns []string, output string) error {
+	if output == queryOutputJSON {
+		recordsJSON, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal records: %v", err)
+		}
+		_, err = fmt.Fprintln(w, string(recordsJSON))
+		return err
+	}
+
+	columns := recordColumns(records, preferredColumns)
+	rows := make([][]string, 0, len(records))
+	for _, record := range records {
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			row = append(row, formatRecordValue(record[column]))
+		}
+		rows = append(rows, row)
+	}
+
+	if output == queryOutputCSV {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "No records found")
+		return err
+	}
+
+	table := printer.NewTablePrinter(w, 20, 1, 3, ' ')
+	header := make([]string, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, strings.ToUpper(column))
+	}
+	table.AddRow(header)
+	for _, row := range rows {
+		table.AddRow(row)
+	}
+
+	return table.Flush()
+}
@@ -0,0 +1,144 @@
+package dynatrace
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestQueryOpts_BuildQuery(t *testing.T) {
+	hcpCluster := HCPCluster{managementClusterName: "mc-1", hcpNamespace: "ocm-production-abc-hcp"}
+
+	tests := []struct {
+		name     string
+		opts     queryOpts
+		expected string
+	}{
+		{
+			name:     "Raw DQL is used as is",
+			opts:     queryOpts{dql: "fetch logs | limit 1"},
+			expected: "fetch logs | limit 1",
+		},
+		{
+			name: "Error count per pod defaults to the HCP namespace",
+			opts: queryOpts{dataObject: "logs", since: 6, statuses: []string{"ERROR"}, aggregations: []string{"count()"}, byFields: []string{"k8s.pod.name"}, sortBy: "count()", sortOrder: "desc"},
+			expected: `fetch logs, from:now()-6h 
+| filter matchesValue(event.type, "LOG") and matchesPhrase(dt.kubernetes.cluster.name, "mc-1") and (matchesValue(k8s.namespace.name, "ocm-production-abc-hcp")) and (matchesValue(status, "ERROR"))` +
+				"\n| summarize count(), by:{k8s.pod.name}" +
+				"\n| sort count() desc",
+		},
+		{
+			name: "Events with fields projection, explicit namespace and limit",
+			opts: queryOpts{dataObject: "events", since: 1, namespaces: []string{"hypershift"}, fields: []string{"timestamp", "event.name"}, sortOrder: "asc", limit: 10},
+			expected: `fetch events, from:now()-1h 
+| filter matchesPhrase(dt.kubernetes.cluster.name, "mc-1") and (matchesValue(k8s.namespace.name, "hypershift"))` +
+				"\n| sort timestamp asc" +
+				"\n| fields timestamp, event.name" +
+				"\n| limit 10",
+		},
+		{
+			name: "Fields projection without timestamp is applied after the default sort",
+			opts: queryOpts{dataObject: "events", since: 1, namespaces: []string{"hypershift"}, fields: []string{"event.name", "event.type"}, sortOrder: "desc"},
+			expected: `fetch events, from:now()-1h 
+| filter matchesPhrase(dt.kubernetes.cluster.name, "mc-1") and (matchesValue(k8s.namespace.name, "hypershift"))` +
+				"\n| sort timestamp desc" +
+				"\n| fields event.name, event.type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.opts.buildQuery(hcpCluster)
+			if err != nil {
+				t.Fatalf("did not expect error but got: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected: %s\ngot: %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQueryOpts_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        queryOpts
+		expectError bool
+	}{
+		{"Valid builder options", queryOpts{dataObject: "spans", since: 1, sortOrder: "asc", output: "table"}, false},
+		{"Raw DQL skips builder checks", queryOpts{dql: "fetch logs", output: "csv"}, false},
+		{"Invalid output", queryOpts{dataObject: "logs", since: 1, sortOrder: "asc", output: "yaml"}, true},
+		{"Invalid data object", queryOpts{dataObject: "metrics", since: 1, sortOrder: "asc", output: "table"}, true},
+		{"Grouping without aggregation", queryOpts{dataObject: "logs", since: 1, sortOrder: "asc", output: "table", byFields: []string{"status"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.validate()
+			if tt.expectError && err == nil {
+				t.Errorf("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("did not expect error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestQueryOpts_QueriedDataObject(t *testing.T) {
+	if got := (&queryOpts{dataObject: "spans"}).queriedDataObject(""); got != "spans" {
+		t.Errorf("expected spans, got %s", got)
+	}
+	if got := (&queryOpts{dql: " fetch bizevents | limit 1"}).queriedDataObject(" fetch bizevents | limit 1"); got != "bizevents" {
+		t.Errorf("expected bizevents, got %s", got)
+	}
+}
+
+func TestPrintRecords(t *testing.T) {
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(`[
+		{"count()": 12, "k8s.pod.name": "pod-a", "extra": {"a": 1}},
+		{"count()": 3, "k8s.pod.name": "pod-b", "extra": null}
+	]`))
+	decoder.UseNumber()
+	if err := decoder.Decode(&records); err != nil {
+		t.Fatalf("failed to decode records: %v", err)
+	}
+	opts := queryOpts{aggregations: []string{"count()"}, byFields: []string{"k8s.pod.name"}}
+
+	var csvOut bytes.Buffer
+	if err := printRecords(&csvOut, records, opts.preferredColumns(), queryOutputCSV); err != nil {
+		t.Fatalf("did not expect error but got: %v", err)
+	}
+	expectedCSV := "k8s.pod.name,count(),extra\npod-a,12,\"{\"\"a\"\":1}\"\npod-b,3,\n"
+	if csvOut.String() != expectedCSV {
+		t.Errorf("expected: %q\ngot: %q", expectedCSV, csvOut.String())
+	}
+
+	var tableOut bytes.Buffer
+	if err := printRecords(&tableOut, records, opts.preferredColumns(), queryOutputTable); err != nil {
+		t.Fatalf("did not expect error but got: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(tableOut.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "K8S.POD.NAME") || !strings.Contains(lines[1], "pod-a") {
+		t.Errorf("unexpected table output:\n%s", tableOut.String())
+	}
+
+	var emptyOut bytes.Buffer
+	if err := printRecords(&emptyOut, nil, nil, queryOutputTable); err != nil {
+		t.Fatalf("did not expect error but got: %v", err)
+	}
+	if emptyOut.String() != "No records found\n" {
+		t.Errorf("unexpected empty output: %q", emptyOut.String())
+	}
+}
+
+func TestPreferredColumns_Aliases(t *testing.T) {
+	opts := queryOpts{aggregations: []string{"errors = countIf(status == \"ERROR\")", "countIf(status == \"WARN\")"}, bucket: 1}
+	got := opts.preferredColumns()
+	expected := []string{"time_bucket", "errors", "countIf(status == \"WARN\")"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -23,6 +23,7 @@ func NewCmdDynatrace() *cobra.Command {
 	dtCmd.AddCommand(newCmdURL())
 	dtCmd.AddCommand(newCmdDashboard())
 	dtCmd.AddCommand(NewCmdHCPMustGather())
+	dtCmd.AddCommand(newCmdQuery())
 
 	return dtCmd
 }
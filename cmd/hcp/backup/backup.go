@@ -23,25 +23,14 @@ func NewCmdBackup() *cobra.Command {
 		Long:  longDescription,
 		Example: "  osdctl hcp backup --cluster-id ${CLUSTER_ID} --reason ${REASON}\n" +
 			"  osdctl hcp backup --cluster-id ${CLUSTER_ID} --reason ${REASON} --label env=prod --label incident=${REASON}\n" +
-			"  osdctl hcp backup --cluster-id ${CLUSTER_ID} --reason ${REASON} --annotation owner=sre-team",
+			"  osdctl hcp backup --cluster-id ${CLUSTER_ID} --reason ${REASON} --annotation owner=sre-team\n" +
+			"  osdctl hcp backup --cluster-id ${CLUSTER_ID} --reason ${REASON} --wait --wait-timeout 1h",
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			logger := logrus.New()
-			logger.SetOutput(cmd.ErrOrStderr())
-
-			ocmConn, err := utils.CreateConnection()
-			if err != nil {
-				return fmt.Errorf("creating OCM connection: %w", err)
-			}
-			defer ocmConn.Close()
-
-			runner := NewDefaultBackupRunner(
-				ocmConn,
-				WithLogger{Logger: logger},
-				WithPrinter{Printer: &defaultPrinter{w: cmd.OutOrStdout()}},
-			)
-			return runner.Run(cmd.Context(), flags)
+			return withDefaultBackupRunner(cmd, func(runner *defaultBackupRunner) error {
+				return runner.Run(cmd.Context(), flags)
+			})
 		},
 	}
 
@@ -49,9 +38,32 @@ func NewCmdBackup() *cobra.Command {
 	_ = cmd.MarkFlagRequired("cluster-id")
 	_ = cmd.MarkFlagRequired("reason")
 
+	cmd.AddCommand(newCmdList())
+	cmd.AddCommand(newCmdDescribe())
+
 	return cmd
 }
 
+// withDefaultBackupRunner opens an OCM connection and calls fn with a runner
+// logging to the command's stderr and printing to its stdout.
+func withDefaultBackupRunner(cmd *cobra.Command, fn func(runner *defaultBackupRunner) error) error {
+	logger := logrus.New()
+	logger.SetOutput(cmd.ErrOrStderr())
+
+	ocmConn, err := utils.CreateConnection()
+	if err != nil {
+		return fmt.Errorf("creating OCM connection: %w", err)
+	}
+	defer ocmConn.Close()
+
+	runner := NewDefaultBackupRunner(
+		ocmConn,
+		WithLogger{Logger: logger},
+		WithPrinter{Printer: &defaultPrinter{w: cmd.OutOrStdout()}},
+	)
+	return fn(runner)
+}
+
 // newKubeClientForCluster logs into the given cluster via backplane, reusing the
 // caller's OCM connection to avoid opening a second connection. elevationReasons,
 // if provided, elevates the session to backplane-cluster-admin (required for pod
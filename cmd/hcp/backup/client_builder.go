@@ -16,6 +16,9 @@ import (
 type ClusterInfo struct {
 	HCPClusterID  string
 	MgmtClusterID string
+	// HCPNamespace is the namespace of the hosted control plane on the
+	// management cluster.
+	HCPNamespace string
 }
 
 // ClusterResolver resolves an HCP cluster identifier to its canonical OCM IDs,
@@ -60,6 +63,7 @@ func (r *ocmClusterResolver) Resolve(ctx context.Context, clusterIdentifier stri
 	return ClusterInfo{
 		HCPClusterID:  clusterID,
 		MgmtClusterID: mc.ID(),
+		HCPNamespace:  hypershiftResp.Body().HCPNamespace(),
 	}, nil
 }
 
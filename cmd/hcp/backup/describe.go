@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// describeFlags holds the parsed command-line flag values for the backup describe command.
+type describeFlags struct {
+	clusterID  string
+	backupName string
+}
+
+func newCmdDescribe() *cobra.Command {
+	flags := &describeFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "describe <backup-id> --cluster-id <cluster-id>",
+		Short: "Show the details of a Velero backup of an HCP cluster",
+		Long: "Show the phase, progress, warnings, errors and content of a Velero backup of an HCP cluster.\n\n" +
+			"The backup must have been created from the schedule of the cluster. The Backup CR is read\n" +
+			"from the management cluster with an unprivileged session.",
+		Example:           "  osdctl hcp backup describe ${CLUSTER_ID}-daily-20260319184212 --cluster-id ${CLUSTER_ID}",
+		Args:              cobra.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags.backupName = args[0]
+			return withDefaultBackupRunner(cmd, func(runner *defaultBackupRunner) error {
+				return runner.Describe(cmd.Context(), flags.clusterID, flags.backupName)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.clusterID, "cluster-id", "C", "", "Internal ID, name, or external ID of the HCP cluster")
+	_ = cmd.MarkFlagRequired("cluster-id")
+
+	return cmd
+}
+
+// Describe prints the details of a backup of the HCP cluster. It fails if the
+// backup was not created from the schedule of the cluster, so that the backup of
+// another cluster hosted on the same management cluster is never reported.
+func (r *defaultBackupRunner) Describe(ctx context.Context, clusterIdentifier string, backupName string) error {
+	scheduleName, readClient, err := r.readClientForCluster(ctx, clusterIdentifier)
+	if err != nil {
+		return err
+	}
+
+	backup, err := r.getBackup(ctx, readClient, backupName)
+	if err != nil {
+		return err
+	}
+	if backup.Schedule != scheduleName {
+		return fmt.Errorf("backup %q was not created from schedule %q of the cluster (schedule: %s)",
+			backupName, scheduleName, valueOrNone(backup.Schedule))
+	}
+
+	r.printer.Printf("Name:                %s\n", backup.Name)
+	r.printer.Printf("Namespace:           %s\n", r.cfg.ADPNamespace)
+	r.printer.Printf("Schedule:            %s\n", backup.Schedule)
+	r.printer.Printf("Phase:               %s\n", backup.Phase)
+	r.printer.Printf("Items backed up:     %s\n", backup.progress())
+	r.printer.Printf("Warnings:            %d\n", backup.Warnings)
+	r.printer.Printf("Errors:              %d\n", backup.Errors)
+	if backup.FailureReason != "" {
+		r.printer.Printf("Failure reason:      %s\n", backup.FailureReason)
+	}
+	for _, validationError := range backup.ValidationErrors {
+		r.printer.Printf("Validation error:    %s\n", validationError)
+	}
+	r.printer.Printf("Started:             %s\n", valueOrNone(backup.StartTimestamp))
+	r.printer.Printf("Completed:           %s\n", valueOrNone(backup.CompletionTimestamp))
+	r.printer.Printf("Expiration:          %s\n", valueOrNone(backup.Expiration))
+	r.printer.Printf("TTL:                 %s\n", valueOrNone(backup.TTL))
+	r.printer.Printf("Storage location:    %s\n", valueOrNone(backup.StorageLocation))
+	r.printer.Printf("Included namespaces: %s\n", valueOrNone(strings.Join(backup.IncludedNamespaces, ", ")))
+	r.printer.Printf("Labels:              %s\n", valueOrNone(joinSortedMap(backup.Labels)))
+
+	return nil
+}
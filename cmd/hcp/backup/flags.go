@@ -1,6 +1,10 @@
 package backup
 
-import "github.com/spf13/pflag"
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
 
 // backupFlags holds the parsed command-line flag values for the backup command.
 type backupFlags struct {
@@ -12,6 +16,9 @@ type backupFlags struct {
 	// annotations holds optional key=value pairs that are forwarded to the
 	// Velero backup CR via --annotations. Populated by repeated --annotation flags.
 	annotations map[string]string
+	// wait follows the Backup CR until it reaches a terminal phase.
+	wait        bool
+	waitTimeout time.Duration
 }
 
 // AddFlags binds the command-line flags for this command to the given FlagSet.
@@ -20,4 +27,6 @@ func (f *backupFlags) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&f.reason, "reason", "", "Reason for privilege elevation (e.g., OHSS-1234 or PD incident ID)")
 	flags.StringToStringVar(&f.labels, "label", nil, "Label to add to the Velero Backup CR (key=value); may be repeated")
 	flags.StringToStringVar(&f.annotations, "annotation", nil, "Annotation to add to the Velero Backup CR (key=value); may be repeated")
+	flags.BoolVar(&f.wait, "wait", false, "Wait for the backup to complete, reporting its progress; fails unless the backup phase is Completed")
+	flags.DurationVar(&f.waitTimeout, "wait-timeout", 30*time.Minute, "Maximum time to wait for the backup to complete with --wait")
 }
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listFlags holds the parsed command-line flag values for the backup list command.
+type listFlags struct {
+	clusterID string
+}
+
+func newCmdList() *cobra.Command {
+	flags := &listFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "list --cluster-id <cluster-id>",
+		Short: "List the Velero backups of an HCP cluster",
+		Long: "List the Velero backups created from the schedule of an HCP cluster, newest first.\n\n" +
+			"The Backup CRs are read from the management cluster with an unprivileged session.",
+		Example:           "  osdctl hcp backup list --cluster-id ${CLUSTER_ID}",
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDefaultBackupRunner(cmd, func(runner *defaultBackupRunner) error {
+				return runner.List(cmd.Context(), flags.clusterID)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&flags.clusterID, "cluster-id", "C", "", "Internal ID, name, or external ID of the HCP cluster")
+	_ = cmd.MarkFlagRequired("cluster-id")
+
+	return cmd
+}
+
+// readClientForCluster resolves the HCP cluster and returns an unprivileged
+// KubeClient for its management cluster, along with the schedule name of its
+// backups.
+func (r *defaultBackupRunner) readClientForCluster(ctx context.Context, clusterIdentifier string) (string, KubeClient, error) {
+	clusterInfo, err := r.resolver.Resolve(ctx, clusterIdentifier)
+	if err != nil {
+		return "", nil, err
+	}
+
+	readClient, err := r.builder.Build(ctx, WithClusterID{ClusterID: clusterInfo.MgmtClusterID})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return clusterInfo.HCPClusterID + r.cfg.ScheduleNameSuffix, readClient, nil
+}
+
+// List prints the Velero backups created from the schedule of the HCP cluster,
+// newest first.
+func (r *defaultBackupRunner) List(ctx context.Context, clusterIdentifier string) error {
+	scheduleName, readClient, err := r.readClientForCluster(ctx, clusterIdentifier)
+	if err != nil {
+		return err
+	}
+
+	backups, err := r.listBackups(ctx, readClient, scheduleName)
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		r.printer.Printf("No backup found for schedule %q in namespace %q.\n", scheduleName, r.cfg.ADPNamespace)
+		return nil
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "NAME\tPHASE\tITEMS\tWARNINGS\tERRORS\tSTARTED\tCOMPLETED\tEXPIRES\n")
+	for _, backup := range backups {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n", backup.Name, backup.Phase, backup.progress(),
+			backup.Warnings, backup.Errors, valueOrNone(backup.StartTimestamp), valueOrNone(backup.CompletionTimestamp),
+			valueOrNone(backup.Expiration))
+	}
+	_ = w.Flush()
+	r.printer.Print(sb.String())
+
+	return nil
+}
+
+// listBackups returns the backups created from the given schedule, newest first.
+func (r *defaultBackupRunner) listBackups(ctx context.Context, readClient KubeClient, scheduleName string) ([]backupStatus, error) {
+	list := newBackupListObject()
+	if err := readClient.List(ctx, list,
+		client.InNamespace(r.cfg.ADPNamespace),
+		client.MatchingLabels{scheduleNameLabel: scheduleName},
+	); err != nil {
+		return nil, fmt.Errorf("listing Velero backups of schedule %q in namespace %q: %w", scheduleName, r.cfg.ADPNamespace, err)
+	}
+
+	backups := make([]backupStatus, 0, len(list.Items))
+	for i := range list.Items {
+		backups = append(backups, parseBackupStatus(&list.Items[i]))
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].CreationTimestamp.Equal(backups[j].CreationTimestamp) {
+			return backups[i].CreationTimestamp.After(backups[j].CreationTimestamp)
+		}
+		// Backup names end with their creation time, which breaks ties.
+		return backups[i].Name > backups[j].Name
+	})
+
+	return backups, nil
+}
@@ -1,6 +1,10 @@
 package backup
 
-import logrus "github.com/sirupsen/logrus"
+import (
+	"time"
+
+	logrus "github.com/sirupsen/logrus"
+)
 
 // The With* types below are concrete implementations of DefaultBackupRunnerOption,
 // used to override defaultBackupRunnerConfig defaults at construction time.
@@ -40,6 +44,13 @@ func (v WithScheduleNameSuffix) ConfigureDefaultBackupRunner(c *defaultBackupRun
 	c.ScheduleNameSuffix = string(v)
 }
 
+// WithPollInterval overrides the delay between two reads of a Backup CR when waiting for it to complete.
+type WithPollInterval time.Duration
+
+func (v WithPollInterval) ConfigureDefaultBackupRunner(c *defaultBackupRunnerConfig) {
+	c.PollInterval = time.Duration(v)
+}
+
 // WithLogger overrides the logrus.Logger used for diagnostic output.
 // By default a new logger writing to os.Stderr is created; callers may redirect
 // it (e.g. to cmd.ErrOrStderr()) before passing it here.
@@ -57,6 +68,14 @@ func (v WithPrinter) ConfigureDefaultBackupRunner(c *defaultBackupRunnerConfig)
 	c.Printer = v.Printer
 }
 
+// WithConfirm overrides the confirmation prompt shown before a restore.
+// Primarily useful in tests to avoid reading stdin.
+type WithConfirm func() bool
+
+func (v WithConfirm) ConfigureDefaultBackupRunner(c *defaultBackupRunnerConfig) {
+	c.Confirm = v
+}
+
 // WithResolver overrides the ClusterResolver used to translate a raw cluster
 // identifier (from --cluster-id) into canonical OCM IDs. Primarily useful in
 // tests to avoid real OCM calls.
@@ -0,0 +1,149 @@
+package backup
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//go:embed restore_description.txt
+var restoreLongDescription string
+
+// restoreReasonAnnotation records the elevation reason on the Restore CR.
+const restoreReasonAnnotation = "osdctl.openshift.io/restore-reason"
+
+// restoreFlags holds the parsed command-line flag values for the restore command.
+type restoreFlags struct {
+	clusterID   string
+	backupName  string
+	reason      string
+	waitTimeout time.Duration
+}
+
+// AddFlags binds the command-line flags for the restore command to the given FlagSet.
+func (f *restoreFlags) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&f.clusterID, "cluster-id", "C", "", "Internal ID, name, or external ID of the HCP cluster")
+	flags.StringVar(&f.backupName, "backup", "", "Name of the Velero backup to restore, see 'osdctl hcp backup list'")
+	flags.StringVar(&f.reason, "reason", "", "Reason for privilege elevation (e.g., OHSS-1234 or PD incident ID)")
+	flags.DurationVar(&f.waitTimeout, "wait-timeout", time.Hour, "Maximum time to wait for the restore to complete")
+}
+
+// NewCmdRestore returns the command restoring an HCP cluster from a Velero backup.
+func NewCmdRestore() *cobra.Command {
+	flags := &restoreFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "restore --cluster-id <cluster-id> --backup <backup-id> --reason <reason>",
+		Short: "Restore an HCP cluster from a Velero backup",
+		Long:  restoreLongDescription,
+		Example: "  osdctl hcp backup list --cluster-id ${CLUSTER_ID}\n" +
+			"  osdctl hcp restore --cluster-id ${CLUSTER_ID} --backup ${CLUSTER_ID}-daily-20260319184212 --reason ${REASON}",
+		Args:              cobra.NoArgs,
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withDefaultBackupRunner(cmd, func(runner *defaultBackupRunner) error {
+				return runner.Restore(cmd.Context(), flags)
+			})
+		},
+	}
+
+	flags.AddFlags(cmd.Flags())
+	_ = cmd.MarkFlagRequired("cluster-id")
+	_ = cmd.MarkFlagRequired("backup")
+	_ = cmd.MarkFlagRequired("reason")
+
+	return cmd
+}
+
+// Restore executes the restore workflow: resolve cluster, validate the backup,
+// confirm, create the Restore CR with an elevated session and follow it to
+// completion.
+func (r *defaultBackupRunner) Restore(ctx context.Context, flags *restoreFlags) error {
+	clusterInfo, err := r.resolver.Resolve(ctx, flags.clusterID)
+	if err != nil {
+		return err
+	}
+
+	// As for backups, the elevated login is deferred until the backup is
+	// known to be restorable.
+	readClient, err := r.builder.Build(ctx, WithClusterID{ClusterID: clusterInfo.MgmtClusterID})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Infof("Validating Velero backup %q in namespace %q...", flags.backupName, r.cfg.ADPNamespace)
+	backup, err := r.getBackup(ctx, readClient, flags.backupName)
+	if err != nil {
+		return err
+	}
+	if err := validateRestorableBackup(backup, clusterInfo); err != nil {
+		return err
+	}
+
+	restoreName := fmt.Sprintf("%s-restore-%s", backup.Name, time.Now().UTC().Format("20060102150405"))
+
+	r.printer.Printf("The following backup will be restored on management cluster %s:\n", clusterInfo.MgmtClusterID)
+	r.printer.Printf("  Backup:       %s\n", backup.Name)
+	r.printer.Printf("  Phase:        %s\n", backup.Phase)
+	r.printer.Printf("  Started:      %s\n", valueOrNone(backup.StartTimestamp))
+	r.printer.Printf("  Completed:    %s\n", valueOrNone(backup.CompletionTimestamp))
+	r.printer.Printf("  Items:        %s\n", backup.progress())
+	r.printer.Printf("  Namespaces:   %s\n", strings.Join(backup.IncludedNamespaces, ", "))
+	r.printer.Printf("  Restore name: %s\n", restoreName)
+	r.printer.Printf("Existing resources are left untouched by Velero, only missing ones are restored.\n")
+
+	if !r.cfg.Confirm() {
+		r.printer.Printf("Restore cancelled.\n")
+		return nil
+	}
+
+	execClient, err := r.builder.Build(ctx, WithClusterID{ClusterID: clusterInfo.MgmtClusterID}, WithElevation{Reason: flags.reason})
+	if err != nil {
+		return err
+	}
+
+	restore := newRestoreObject(restoreName, r.cfg.ADPNamespace, backup.Name, backup.IncludedNamespaces,
+		map[string]string{restoreReasonAnnotation: flags.reason})
+	r.logger.Infof("Creating Velero restore %q from backup %q...", restoreName, backup.Name)
+	if err := execClient.Create(ctx, restore); err != nil {
+		return fmt.Errorf("creating Velero restore %q from backup %q: %w", restoreName, backup.Name, err)
+	}
+	r.printer.Printf("Restore %q created.\n", restoreName)
+
+	return r.waitForVelero(ctx, execClient, restoreResource, restoreName, flags.waitTimeout)
+}
+
+// validateRestorableBackup checks that the backup Completed and belongs to the
+// HCP cluster: it must include the HCP namespace of the cluster, and only
+// namespaces of the cluster, so that restoring it can never touch the control
+// plane of another cluster hosted on the same management cluster.
+func validateRestorableBackup(backup backupStatus, clusterInfo ClusterInfo) error {
+	if backup.Phase != veleroPhaseCompleted {
+		return fmt.Errorf("backup %q cannot be restored: phase is %s, expected %s", backup.Name, backup.Phase, veleroPhaseCompleted)
+	}
+
+	if clusterInfo.HCPNamespace == "" {
+		return fmt.Errorf("no HCP namespace found for cluster %s", clusterInfo.HCPClusterID)
+	}
+
+	includesHCPNamespace := false
+	for _, ns := range backup.IncludedNamespaces {
+		if ns == clusterInfo.HCPNamespace {
+			includesHCPNamespace = true
+		}
+		if !strings.Contains(ns, clusterInfo.HCPClusterID) {
+			return fmt.Errorf("backup %q includes namespace %q which does not belong to cluster %s", backup.Name, ns, clusterInfo.HCPClusterID)
+		}
+	}
+	if !includesHCPNamespace {
+		return fmt.Errorf("backup %q does not include the HCP namespace %q of cluster %s (included namespaces: %s)",
+			backup.Name, clusterInfo.HCPNamespace, clusterInfo.HCPClusterID, valueOrNone(strings.Join(backup.IncludedNamespaces, ", ")))
+	}
+
+	return nil
+}
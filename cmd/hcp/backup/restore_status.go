@@ -0,0 +1,29 @@
+package backup
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var restoreGVK = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Restore"}
+
+// newRestoreObject returns an unstructured Velero Restore of the given backup,
+// limited to the given namespaces.
+func newRestoreObject(name, namespace, backupName string, includedNamespaces []string, annotations map[string]string) *unstructured.Unstructured {
+	restore := &unstructured.Unstructured{}
+	restore.SetGroupVersionKind(restoreGVK)
+	restore.SetName(name)
+	restore.SetNamespace(namespace)
+	restore.SetAnnotations(annotations)
+
+	namespaces := make([]interface{}, 0, len(includedNamespaces))
+	for _, ns := range includedNamespaces {
+		namespaces = append(namespaces, ns)
+	}
+	restore.Object["spec"] = map[string]interface{}{
+		"backupName":         backupName,
+		"includedNamespaces": namespaces,
+	}
+
+	return restore
+}
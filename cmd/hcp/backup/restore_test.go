@@ -0,0 +1,259 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// completingKubeClient is a privileged test client that records the Restore
+// CRs it creates and reports them in the given phase when read back, as the
+// fake client has no Velero controller to progress them.
+type completingKubeClient struct {
+	testKubeClient
+	phase     string
+	createErr error
+	created   []*unstructured.Unstructured
+}
+
+func (c *completingKubeClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if c.createErr != nil {
+		return c.createErr
+	}
+	c.created = append(c.created, obj.(*unstructured.Unstructured))
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *completingKubeClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+	u := obj.(*unstructured.Unstructured)
+	u.Object["status"] = map[string]interface{}{
+		"phase":    c.phase,
+		"progress": map[string]interface{}{"itemsRestored": int64(42), "totalItems": int64(42)},
+	}
+	return nil
+}
+
+func TestValidateRestorableBackup(t *testing.T) {
+	t.Parallel()
+
+	clusterInfo := ClusterInfo{HCPClusterID: "abc123", MgmtClusterID: "mc1", HCPNamespace: "ocm-production-abc123-my-cluster"}
+
+	tests := []struct {
+		name        string
+		backup      backupStatus
+		clusterInfo ClusterInfo
+		errContains string
+	}{
+		{
+			name: "completed backup of the cluster",
+			backup: backupStatus{veleroStatus: veleroStatus{Name: "b1", Phase: veleroPhaseCompleted},
+				IncludedNamespaces: []string{"ocm-production-abc123", "ocm-production-abc123-my-cluster"}},
+			clusterInfo: clusterInfo,
+		},
+		{
+			name: "backup not completed",
+			backup: backupStatus{veleroStatus: veleroStatus{Name: "b1", Phase: veleroPhasePartiallyFailed},
+				IncludedNamespaces: []string{"ocm-production-abc123-my-cluster"}},
+			clusterInfo: clusterInfo,
+			errContains: "phase is PartiallyFailed",
+		},
+		{
+			name: "backup without the HCP namespace",
+			backup: backupStatus{veleroStatus: veleroStatus{Name: "b1", Phase: veleroPhaseCompleted},
+				IncludedNamespaces: []string{"ocm-production-abc123"}},
+			clusterInfo: clusterInfo,
+			errContains: "does not include the HCP namespace",
+		},
+		{
+			name:        "backup of all namespaces",
+			backup:      backupStatus{veleroStatus: veleroStatus{Name: "b1", Phase: veleroPhaseCompleted}},
+			clusterInfo: clusterInfo,
+			errContains: "does not include the HCP namespace",
+		},
+		{
+			name: "backup with a namespace of another cluster",
+			backup: backupStatus{veleroStatus: veleroStatus{Name: "b1", Phase: veleroPhaseCompleted},
+				IncludedNamespaces: []string{"ocm-production-abc123-my-cluster", "ocm-production-xyz789-other"}},
+			clusterInfo: clusterInfo,
+			errContains: `"ocm-production-xyz789-other" which does not belong`,
+		},
+		{
+			name: "unknown HCP namespace",
+			backup: backupStatus{veleroStatus: veleroStatus{Name: "b1", Phase: veleroPhaseCompleted},
+				IncludedNamespaces: []string{"ocm-production-abc123-my-cluster"}},
+			clusterInfo: ClusterInfo{HCPClusterID: "abc123"},
+			errContains: "no HCP namespace found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateRestorableBackup(tt.backup, tt.clusterInfo)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+
+	const (
+		clusterID  = "abc123"
+		backupName = clusterID + "-daily-20260319184212"
+		reason     = "OHSS-9999"
+	)
+	newCompletedBackup := func(namespaces ...interface{}) *unstructured.Unstructured {
+		backup := newBackup(backupName, "openshift-adp", clusterID+"-daily", time.Now(), map[string]interface{}{
+			"phase":    veleroPhaseCompleted,
+			"progress": map[string]interface{}{"itemsBackedUp": int64(42), "totalItems": int64(42)},
+		})
+		if len(namespaces) > 0 {
+			backup.Object["spec"].(map[string]interface{})["includedNamespaces"] = namespaces
+		}
+		return backup
+	}
+
+	tests := []struct {
+		name           string
+		backup         func() *unstructured.Unstructured
+		confirm        bool
+		restorePhase   string
+		createErr      error
+		privilegedErr  error
+		wantErr        string
+		wantOutput     []string
+		wantCreated    bool
+		wantBuildCalls int
+	}{
+		{
+			name:           "restore completes",
+			backup:         func() *unstructured.Unstructured { return newCompletedBackup() },
+			confirm:        true,
+			restorePhase:   veleroPhaseCompleted,
+			wantOutput:     []string{"ocm-production-abc123-my-cluster", "created", "finished with phase Completed", "42/42"},
+			wantCreated:    true,
+			wantBuildCalls: 2,
+		},
+		{
+			name:           "restore partially fails",
+			backup:         func() *unstructured.Unstructured { return newCompletedBackup() },
+			confirm:        true,
+			restorePhase:   veleroPhasePartiallyFailed,
+			wantErr:        "finished with phase PartiallyFailed",
+			wantOutput:     []string{"oc get restore"},
+			wantCreated:    true,
+			wantBuildCalls: 2,
+		},
+		{
+			name:           "operator cancels — no elevated login",
+			backup:         func() *unstructured.Unstructured { return newCompletedBackup() },
+			confirm:        false,
+			wantOutput:     []string{"Restore cancelled"},
+			wantBuildCalls: 1,
+		},
+		{
+			name:           "backup of another cluster — rejected before the prompt",
+			backup:         func() *unstructured.Unstructured { return newCompletedBackup("ocm-production-xyz789-other") },
+			confirm:        true,
+			wantErr:        "does not belong to cluster abc123",
+			wantBuildCalls: 1,
+		},
+		{
+			name:           "elevation fails",
+			backup:         func() *unstructured.Unstructured { return newCompletedBackup() },
+			confirm:        true,
+			privilegedErr:  errors.New("elevation denied"),
+			wantErr:        "elevation denied",
+			wantBuildCalls: 2,
+		},
+		{
+			name:           "restore creation fails",
+			backup:         func() *unstructured.Unstructured { return newCompletedBackup() },
+			confirm:        true,
+			createErr:      errors.New("forbidden"),
+			wantErr:        "creating Velero restore",
+			wantBuildCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			readClient := newTestClient(fake.NewClientBuilder().WithObjects(tt.backup()).Build())
+			execClient := &completingKubeClient{
+				testKubeClient: testKubeClient{Client: fake.NewClientBuilder().Build()},
+				phase:          tt.restorePhase,
+				createErr:      tt.createErr,
+			}
+			builder := &staticKubeClientBuilder{
+				unprivilegedClient: readClient,
+				privilegedClient:   execClient,
+				privilegedErr:      tt.privilegedErr,
+			}
+
+			var out strings.Builder
+			runner := NewDefaultBackupRunner(nil,
+				WithPrinter{Printer: &defaultPrinter{w: &out}},
+				WithPollInterval(time.Millisecond),
+				WithConfirm(func() bool { return tt.confirm }),
+				WithResolver{Resolver: &staticClusterResolver{clusterInfo: ClusterInfo{
+					HCPClusterID:  clusterID,
+					MgmtClusterID: "mgmt-cluster-id",
+					HCPNamespace:  "ocm-production-abc123-my-cluster",
+				}}},
+				WithBuilder{Builder: builder},
+			)
+
+			err := runner.Restore(context.Background(), &restoreFlags{
+				clusterID:   clusterID,
+				backupName:  backupName,
+				reason:      reason,
+				waitTimeout: time.Minute,
+			})
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, substr := range tt.wantOutput {
+				assert.Contains(t, out.String(), substr)
+			}
+			assert.Len(t, builder.calls, tt.wantBuildCalls)
+			if tt.wantBuildCalls == 2 {
+				assert.Equal(t, buildConfig{clusterID: "mgmt-cluster-id", elevated: true, elevationReason: reason}, builder.calls[1])
+			}
+
+			if !tt.wantCreated {
+				assert.Empty(t, execClient.created)
+				return
+			}
+			assert.Len(t, execClient.created, 1)
+			restore := execClient.created[0]
+			assert.True(t, strings.HasPrefix(restore.GetName(), backupName+"-restore-"))
+			assert.Equal(t, "openshift-adp", restore.GetNamespace())
+			assert.Equal(t, reason, restore.GetAnnotations()[restoreReasonAnnotation])
+			specBackup, _, _ := unstructured.NestedString(restore.Object, "spec", "backupName")
+			assert.Equal(t, backupName, specBackup)
+			namespaces, _, _ := unstructured.NestedStringSlice(restore.Object, "spec", "includedNamespaces")
+			assert.Equal(t, []string{"ocm-production-abc123", "ocm-production-abc123-my-cluster"}, namespaces)
+		})
+	}
+}
@@ -9,9 +9,11 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	ocmsdk "github.com/openshift-online/ocm-sdk-go"
 	"github.com/openshift/osdctl/cmd/cluster"
+	"github.com/openshift/osdctl/pkg/utils"
 	logrus "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -46,8 +48,8 @@ func joinSortedMap(m map[string]string) string {
 }
 
 // KubeClient is a generic interface for interacting with Kubernetes resources.
-// It mirrors controller-runtime's Reader for Get and List, and adds Exec for
-// in-pod command execution. Both privileged and unprivileged clients satisfy
+// It mirrors controller-runtime's Reader for Get and List and its Writer for
+// Create, and adds Exec for in-pod command execution. Both privileged and unprivileged clients satisfy
 // this interface, making them interchangeable in the runner and in tests.
 type KubeClient interface {
 	// Get retrieves the resource identified by key and populates obj with the result.
@@ -58,6 +60,9 @@ type KubeClient interface {
 	// Namespace and label filters are passed as ListOptions (e.g. client.InNamespace, client.MatchingLabels).
 	List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error
 
+	// Create saves obj in the cluster. It requires a privileged client.
+	Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error
+
 	// Exec runs cmd inside the named container of the given pod and returns its stdout.
 	// It is only supported by clients constructed with a REST config and Clientset.
 	Exec(ctx context.Context, namespace, pod, container string, cmd []string) (string, error)
@@ -90,6 +95,10 @@ func (k *kubeClient) List(ctx context.Context, list client.ObjectList, opts ...c
 	return k.runtimeCli.List(ctx, list, opts...)
 }
 
+func (k *kubeClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return k.runtimeCli.Create(ctx, obj, opts...)
+}
+
 // Exec runs cmd inside the named container of the given pod using a SPDY executor.
 // It returns an error if the client was constructed without a REST config or Clientset.
 func (k *kubeClient) Exec(ctx context.Context, namespace, pod, container string, cmd []string) (string, error) {
@@ -165,8 +174,11 @@ type defaultBackupRunnerConfig struct {
 	VeleroLabelKey     string
 	VeleroLabelValue   string
 	ScheduleNameSuffix string
-	Logger             *logrus.Logger
-	Printer            Printer
+	// PollInterval is the delay between two reads of a Backup CR when waiting
+	// for it to complete.
+	PollInterval time.Duration
+	Logger       *logrus.Logger
+	Printer      Printer
 	// Resolver resolves a raw cluster identifier to canonical OCM IDs.
 	// Defaults to an ocmClusterResolver constructed from the OCM connection
 	// passed to NewDefaultBackupRunner. Override via WithResolver in tests.
@@ -175,6 +187,9 @@ type defaultBackupRunnerConfig struct {
 	// constructed from the OCM connection passed to NewDefaultBackupRunner.
 	// Override via WithBuilder in tests.
 	Builder KubeClientBuilder
+	// Confirm asks the operator to confirm a restore. Defaults to an
+	// interactive prompt on stdin. Override via WithConfirm in tests.
+	Confirm func() bool
 }
 
 // newDefaultBackupRunnerConfig returns a defaultBackupRunnerConfig populated with
@@ -188,8 +203,10 @@ func newDefaultBackupRunnerConfig(opts ...DefaultBackupRunnerOption) defaultBack
 		VeleroLabelKey:     "app.kubernetes.io/name",
 		VeleroLabelValue:   "velero",
 		ScheduleNameSuffix: "-daily",
+		PollInterval:       10 * time.Second,
 		Logger:             logrus.New(),
 		Printer:            &defaultPrinter{w: os.Stdout},
+		Confirm:            utils.ConfirmPrompt,
 	}
 	for _, o := range opts {
 		o.ConfigureDefaultBackupRunner(&cfg)
@@ -323,13 +340,24 @@ func (r *defaultBackupRunner) Run(ctx context.Context, flags *backupFlags) error
 		// Unexpected output format — print raw output so the operator can inspect it.
 		r.printer.Print(output)
 		r.printer.Printf("Backup triggered successfully, but could not parse backup ID from velero output.\n")
+		if flags.wait {
+			return errors.New("cannot wait for the backup to complete without its ID")
+		}
 		return nil
 	}
 	backupID := matches[1]
 
 	r.printer.Printf("Backup %q triggered successfully.\n", backupID)
+
+	if flags.wait {
+		// Reading the Backup CR only needs the unprivileged session.
+		return r.waitForVelero(ctx, readClient, backupResource, backupID, flags.waitTimeout)
+	}
+
 	r.printer.Printf("To check status, run:\n")
 	r.printer.Printf("oc get backup %s -n %s\n", backupID, r.cfg.ADPNamespace)
+	r.printer.Printf("or:\n")
+	r.printer.Printf("osdctl hcp backup describe %s --cluster-id %s\n", backupID, flags.clusterID)
 
 	return nil
 }
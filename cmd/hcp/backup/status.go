@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scheduleNameLabel is set by Velero on the backups created from a schedule.
+const scheduleNameLabel = "velero.io/schedule-name"
+
+var backupGVK = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Backup"}
+
+// backupStatus is the subset of a Velero Backup CR shown to the operator. It is
+// read from an unstructured object to avoid importing the Velero SDK.
+type backupStatus struct {
+	veleroStatus
+	Schedule            string
+	IncludedNamespaces  []string
+	StorageLocation     string
+	TTL                 string
+	Labels              map[string]string
+	CreationTimestamp   time.Time
+	StartTimestamp      string
+	CompletionTimestamp string
+	Expiration          string
+}
+
+// newBackupObject returns an empty unstructured Velero Backup for Get calls.
+func newBackupObject() *unstructured.Unstructured {
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(backupGVK)
+	return backup
+}
+
+// newBackupListObject returns an empty unstructured Velero BackupList for List calls.
+func newBackupListObject() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(backupGVK.GroupVersion().WithKind("BackupList"))
+	return list
+}
+
+// parseBackupStatus extracts the backupStatus of an unstructured Velero Backup.
+// Missing fields are left empty, Velero only fills the status as the backup progresses.
+func parseBackupStatus(backup *unstructured.Unstructured) backupStatus {
+	obj := backup.Object
+	status := backupStatus{
+		veleroStatus:      parseVeleroStatus(backupResource, backup),
+		Schedule:          backup.GetLabels()[scheduleNameLabel],
+		Labels:            backup.GetLabels(),
+		CreationTimestamp: backup.GetCreationTimestamp().Time,
+	}
+
+	status.StartTimestamp, _, _ = unstructured.NestedString(obj, "status", "startTimestamp")
+	status.CompletionTimestamp, _, _ = unstructured.NestedString(obj, "status", "completionTimestamp")
+	status.Expiration, _, _ = unstructured.NestedString(obj, "status", "expiration")
+	status.IncludedNamespaces, _, _ = unstructured.NestedStringSlice(obj, "spec", "includedNamespaces")
+	status.StorageLocation, _, _ = unstructured.NestedString(obj, "spec", "storageLocation")
+	status.TTL, _, _ = unstructured.NestedString(obj, "spec", "ttl")
+
+	return status
+}
+
+// getBackup fetches the Velero Backup with the given name from the ADP namespace.
+func (r *defaultBackupRunner) getBackup(ctx context.Context, readClient KubeClient, backupName string) (backupStatus, error) {
+	backup := newBackupObject()
+	key := client.ObjectKey{Namespace: r.cfg.ADPNamespace, Name: backupName}
+	if err := readClient.Get(ctx, key, backup); err != nil {
+		return backupStatus{}, fmt.Errorf("getting Velero backup %q in namespace %q: %w", backupName, r.cfg.ADPNamespace, err)
+	}
+	return parseBackupStatus(backup), nil
+}
+
+// valueOrNone returns "<none>" for empty values, as oc does.
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,347 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newBackup builds an unstructured Velero Backup created from the given schedule.
+// status may be nil for a backup Velero has not picked up yet.
+func newBackup(name, namespace, schedule string, created time.Time, status map[string]interface{}) *unstructured.Unstructured {
+	obj := newBackupObject()
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetCreationTimestamp(metav1.NewTime(created))
+	if schedule != "" {
+		obj.SetLabels(map[string]string{scheduleNameLabel: schedule})
+	}
+	obj.Object["spec"] = map[string]interface{}{
+		"includedNamespaces": []interface{}{"ocm-production-abc123", "ocm-production-abc123-my-cluster"},
+		"storageLocation":    "default",
+		"ttl":                "720h0m0s",
+	}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	return obj
+}
+
+// sequenceKubeClient is a KubeClient whose Get returns the given Backup
+// statuses in turn, repeating the last one, to simulate a backup progressing.
+type sequenceKubeClient struct {
+	testKubeClient
+	statuses []map[string]interface{}
+	calls    int
+}
+
+func (c *sequenceKubeClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	idx := min(c.calls, len(c.statuses)-1)
+	c.calls++
+	if c.statuses[idx] == nil {
+		return errors.New("not found")
+	}
+	backup := newBackup(key.Name, key.Namespace, "abc123-daily", time.Now(), c.statuses[idx])
+	obj.(*unstructured.Unstructured).Object = backup.Object
+	return nil
+}
+
+func TestParseBackupStatus(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 3, 19, 18, 42, 12, 0, time.UTC)
+	backup := newBackup("abc123-daily-20260319184212", "openshift-adp", "abc123-daily", created, map[string]interface{}{
+		"phase":               "PartiallyFailed",
+		"progress":            map[string]interface{}{"itemsBackedUp": int64(120), "totalItems": int64(125)},
+		"warnings":            int64(3),
+		"errors":              int64(2),
+		"failureReason":       "volume snapshot failed",
+		"startTimestamp":      "2026-03-19T18:42:12Z",
+		"completionTimestamp": "2026-03-19T18:45:00Z",
+		"expiration":          "2026-04-18T18:42:12Z",
+	})
+
+	status := parseBackupStatus(backup)
+
+	assert.Equal(t, "abc123-daily-20260319184212", status.Name)
+	assert.Equal(t, "abc123-daily", status.Schedule)
+	assert.Equal(t, veleroPhasePartiallyFailed, status.Phase)
+	assert.Equal(t, int64(120), status.Items)
+	assert.Equal(t, int64(125), status.TotalItems)
+	assert.Equal(t, int64(3), status.Warnings)
+	assert.Equal(t, int64(2), status.Errors)
+	assert.Equal(t, "volume snapshot failed", status.FailureReason)
+	assert.Equal(t, "2026-03-19T18:45:00Z", status.CompletionTimestamp)
+	assert.Equal(t, []string{"ocm-production-abc123", "ocm-production-abc123-my-cluster"}, status.IncludedNamespaces)
+	assert.Equal(t, "default", status.StorageLocation)
+	assert.True(t, status.CreationTimestamp.Equal(created))
+	assert.True(t, status.isTerminal())
+	assert.Equal(t, "120/125", status.progress())
+}
+
+func TestParseBackupStatus_NoStatus(t *testing.T) {
+	t.Parallel()
+
+	status := parseBackupStatus(newBackup("b1", "openshift-adp", "abc123-daily", time.Now(), nil))
+
+	assert.Equal(t, "New", status.Phase)
+	assert.False(t, status.isTerminal())
+	assert.Equal(t, "0", status.progress())
+}
+
+func TestWaitForBackup(t *testing.T) {
+	t.Parallel()
+
+	inProgress := map[string]interface{}{
+		"phase":    "InProgress",
+		"progress": map[string]interface{}{"itemsBackedUp": int64(10), "totalItems": int64(100)},
+	}
+
+	tests := []struct {
+		name        string
+		statuses    []map[string]interface{}
+		timeout     time.Duration
+		wantErr     string
+		wantOutput  []string
+		wantLogs    []string
+		wantMinGets int
+	}{
+		{
+			name: "backup progresses to Completed",
+			statuses: []map[string]interface{}{
+				nil, // not visible yet right after submission
+				inProgress,
+				{"phase": "Completed", "progress": map[string]interface{}{"itemsBackedUp": int64(100), "totalItems": int64(100)}},
+			},
+			timeout:     time.Minute,
+			wantOutput:  []string{"finished with phase Completed", "Items backed up: 100/100, warnings: 0, errors: 0"},
+			wantLogs:    []string{"retrying", "phase InProgress, 10/100 items backed up"},
+			wantMinGets: 3,
+		},
+		{
+			name: "PartiallyFailed backup returns an error",
+			statuses: []map[string]interface{}{
+				{"phase": "PartiallyFailed", "errors": int64(2), "warnings": int64(1)},
+			},
+			timeout:    time.Minute,
+			wantErr:    "finished with phase PartiallyFailed",
+			wantOutput: []string{"warnings: 1, errors: 2", "osdctl hcp backup describe"},
+		},
+		{
+			name: "Failed backup reports the failure reason",
+			statuses: []map[string]interface{}{
+				{"phase": "Failed", "failureReason": "backup storage location unavailable"},
+			},
+			timeout:    time.Minute,
+			wantErr:    "finished with phase Failed",
+			wantOutput: []string{"Failure reason: backup storage location unavailable"},
+		},
+		{
+			name: "FailedValidation backup reports the validation errors",
+			statuses: []map[string]interface{}{
+				{"phase": "FailedValidation", "validationErrors": []interface{}{"invalid included namespace"}},
+			},
+			timeout:    time.Minute,
+			wantErr:    "finished with phase FailedValidation",
+			wantOutput: []string{"Validation error: invalid included namespace"},
+		},
+		{
+			name:     "timeout while the backup is in progress",
+			statuses: []map[string]interface{}{inProgress},
+			timeout:  50 * time.Millisecond,
+			wantErr:  "timed out waiting for backup",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out, logs strings.Builder
+			runner := NewDefaultBackupRunner(nil,
+				WithPrinter{Printer: &defaultPrinter{w: &out}},
+				WithPollInterval(time.Millisecond),
+			)
+			runner.logger.SetOutput(&logs)
+			readClient := &sequenceKubeClient{statuses: tt.statuses}
+
+			err := runner.waitForVelero(context.Background(), readClient, backupResource, "abc123-daily-20260319184212", tt.timeout)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, substr := range tt.wantOutput {
+				assert.Contains(t, out.String(), substr)
+			}
+			for _, substr := range tt.wantLogs {
+				assert.Contains(t, logs.String(), substr)
+			}
+			assert.GreaterOrEqual(t, readClient.calls, tt.wantMinGets)
+		})
+	}
+}
+
+func TestRun_Wait(t *testing.T) {
+	t.Parallel()
+
+	const backupName = "abc123-daily-20260319184212"
+	execClient := &testKubeClient{
+		Client: fake.NewClientBuilder().WithObjects(newReadyVeleroPod("velero-pod-1", "openshift-adp")).Build(),
+		execFn: func(_ context.Context, _, _, _ string, _ []string) (string, error) {
+			return `Backup request "` + backupName + `" submitted successfully.` + "\n", nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		phase      string
+		wantErr    bool
+		wantOutput []string
+	}{
+		{name: "completed", phase: veleroPhaseCompleted, wantOutput: []string{"triggered successfully", "finished with phase Completed"}},
+		{name: "failed", phase: veleroPhaseFailed, wantErr: true, wantOutput: []string{"finished with phase Failed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			readClient := newTestClient(fake.NewClientBuilder().WithObjects(
+				newSchedule("abc123-daily", "openshift-adp"),
+				newBackup(backupName, "openshift-adp", "abc123-daily", time.Now(), map[string]interface{}{"phase": tt.phase}),
+			).Build())
+
+			var out strings.Builder
+			runner := NewDefaultBackupRunner(nil,
+				WithPrinter{Printer: &defaultPrinter{w: &out}},
+				WithPollInterval(time.Millisecond),
+				WithResolver{Resolver: &staticClusterResolver{clusterInfo: ClusterInfo{HCPClusterID: "abc123", MgmtClusterID: "mgmt-cluster-id"}}},
+				WithBuilder{Builder: &staticKubeClientBuilder{unprivilegedClient: readClient, privilegedClient: execClient}},
+			)
+
+			err := runner.Run(context.Background(), &backupFlags{clusterID: "abc123", reason: "OHSS-1", wait: true, waitTimeout: time.Minute})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			for _, substr := range tt.wantOutput {
+				assert.Contains(t, out.String(), substr)
+			}
+			assert.NotContains(t, out.String(), "To check status")
+		})
+	}
+}
+
+func newInspectRunner(out *strings.Builder, objs ...client.Object) *defaultBackupRunner {
+	readClient := newTestClient(fake.NewClientBuilder().WithObjects(objs...).Build())
+	builder := &staticKubeClientBuilder{unprivilegedClient: readClient}
+	return NewDefaultBackupRunner(nil,
+		WithPrinter{Printer: &defaultPrinter{w: out}},
+		WithResolver{Resolver: &staticClusterResolver{clusterInfo: ClusterInfo{HCPClusterID: "abc123", MgmtClusterID: "mgmt-cluster-id"}}},
+		WithBuilder{Builder: builder},
+	)
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Truncate(time.Second)
+	var out strings.Builder
+	runner := newInspectRunner(&out,
+		newBackup("abc123-daily-1", "openshift-adp", "abc123-daily", now.Add(-48*time.Hour), map[string]interface{}{"phase": "Completed"}),
+		newBackup("abc123-daily-2", "openshift-adp", "abc123-daily", now.Add(-time.Hour), map[string]interface{}{"phase": "PartiallyFailed", "errors": int64(4)}),
+		newBackup("xyz789-daily-1", "openshift-adp", "xyz789-daily", now, map[string]interface{}{"phase": "Completed"}),
+		newBackup("abc123-daily-other-ns", "other-ns", "abc123-daily", now, nil),
+	)
+
+	err := runner.List(context.Background(), "abc123")
+
+	assert.NoError(t, err)
+	got := out.String()
+	assert.Contains(t, got, "NAME")
+	assert.NotContains(t, got, "xyz789", "backups of other clusters must not be listed")
+	assert.NotContains(t, got, "other-ns")
+	newest, oldest := strings.Index(got, "abc123-daily-2"), strings.Index(got, "abc123-daily-1")
+	assert.True(t, newest != -1 && oldest != -1 && newest < oldest, "backups must be listed newest first:\n%s", got)
+	assert.Contains(t, got, "PartiallyFailed")
+}
+
+func TestList_NoBackup(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	runner := newInspectRunner(&out)
+
+	assert.NoError(t, runner.List(context.Background(), "abc123"))
+	assert.Contains(t, out.String(), `No backup found for schedule "abc123-daily"`)
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	backups := func() []client.Object {
+		return []client.Object{
+			newBackup("abc123-daily-1", "openshift-adp", "abc123-daily", time.Now(), map[string]interface{}{
+				"phase":         "Failed",
+				"failureReason": "timeout",
+			}),
+			newBackup("xyz789-daily-1", "openshift-adp", "xyz789-daily", time.Now(), map[string]interface{}{"phase": "Completed"}),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		backupName string
+		wantErr    string
+		wantOutput []string
+	}{
+		{
+			name:       "backup of the cluster",
+			backupName: "abc123-daily-1",
+			wantOutput: []string{"abc123-daily-1", "Phase:               Failed", "Failure reason:      timeout", "ocm-production-abc123"},
+		},
+		{
+			name:       "backup of another cluster",
+			backupName: "xyz789-daily-1",
+			wantErr:    "was not created from schedule",
+		},
+		{
+			name:       "unknown backup",
+			backupName: "missing",
+			wantErr:    `getting Velero backup "missing"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out strings.Builder
+			runner := newInspectRunner(&out, backups()...)
+
+			err := runner.Describe(context.Background(), "abc123", tt.backupName)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Empty(t, out.String())
+				return
+			}
+			assert.NoError(t, err)
+			for _, substr := range tt.wantOutput {
+				assert.Contains(t, out.String(), substr)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Velero Backup and Restore phases reported in status.phase. Only the terminal
+// ones are listed, the others (New, InProgress, WaitingForPluginOperations,
+// Finalizing...) mean Velero is still working on the resource.
+const (
+	veleroPhaseCompleted        = "Completed"
+	veleroPhasePartiallyFailed  = "PartiallyFailed"
+	veleroPhaseFailed           = "Failed"
+	veleroPhaseFailedValidation = "FailedValidation"
+)
+
+// veleroResource describes a kind of Velero resource followed to completion:
+// its GVK, where its progress is reported and how to review its errors.
+type veleroResource struct {
+	kind string
+	gvk  schema.GroupVersionKind
+	// itemsField is the field of status.progress counting the processed items, itemsVerb how they are processed
+	itemsField string
+	itemsVerb  string
+	// reviewHint and reviewCommand tell how to review the errors, reviewCommand
+	// being formatted with the name and the namespace of the resource
+	reviewHint    string
+	reviewCommand string
+}
+
+var (
+	backupResource = veleroResource{
+		kind:          "Backup",
+		gvk:           backupGVK,
+		itemsField:    "itemsBackedUp",
+		itemsVerb:     "backed up",
+		reviewHint:    "To review the errors, run:",
+		reviewCommand: "osdctl hcp backup describe %[1]s --cluster-id <cluster-id>",
+	}
+	restoreResource = veleroResource{
+		kind:          "Restore",
+		gvk:           restoreGVK,
+		itemsField:    "itemsRestored",
+		itemsVerb:     "restored",
+		reviewHint:    "To review the errors, log into the management cluster and run:",
+		reviewCommand: "oc get restore %[1]s -n %[2]s -o yaml",
+	}
+)
+
+// veleroStatus is the status shared by the Velero Backup and Restore CRs.
+type veleroStatus struct {
+	Name             string
+	Phase            string
+	Items            int64
+	TotalItems       int64
+	Warnings         int64
+	Errors           int64
+	FailureReason    string
+	ValidationErrors []string
+}
+
+// parseVeleroStatus extracts the veleroStatus of an unstructured Velero resource.
+// Missing fields are left empty, Velero only fills the status as it progresses.
+func parseVeleroStatus(resource veleroResource, obj *unstructured.Unstructured) veleroStatus {
+	status := veleroStatus{Name: obj.GetName()}
+
+	status.Phase, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+	status.Items, _, _ = unstructured.NestedInt64(obj.Object, "status", "progress", resource.itemsField)
+	status.TotalItems, _, _ = unstructured.NestedInt64(obj.Object, "status", "progress", "totalItems")
+	status.Warnings, _, _ = unstructured.NestedInt64(obj.Object, "status", "warnings")
+	status.Errors, _, _ = unstructured.NestedInt64(obj.Object, "status", "errors")
+	status.FailureReason, _, _ = unstructured.NestedString(obj.Object, "status", "failureReason")
+	status.ValidationErrors, _, _ = unstructured.NestedStringSlice(obj.Object, "status", "validationErrors")
+
+	if status.Phase == "" {
+		status.Phase = "New"
+	}
+
+	return status
+}
+
+// isTerminal reports whether Velero is done with the resource.
+func (s veleroStatus) isTerminal() bool {
+	switch s.Phase {
+	case veleroPhaseCompleted, veleroPhasePartiallyFailed, veleroPhaseFailed, veleroPhaseFailedValidation:
+		return true
+	}
+	return false
+}
+
+// progress renders the processed items as "<processed>/<total>", or just the
+// processed items while Velero has not computed the total yet.
+func (s veleroStatus) progress() string {
+	if s.TotalItems == 0 {
+		return fmt.Sprintf("%d", s.Items)
+	}
+	return fmt.Sprintf("%d/%d", s.Items, s.TotalItems)
+}
+
+// waitForVelero polls the Velero resource until it reaches a terminal phase or
+// the timeout expires, logging its progress whenever it changes. It returns an
+// error unless the resource Completed, so that callers can rely on the exit code.
+func (r *defaultBackupRunner) waitForVelero(ctx context.Context, kubeClient KubeClient, resource veleroResource, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	kind := strings.ToLower(resource.kind)
+	r.logger.Infof("Waiting for %s %q to complete (timeout %s)...", kind, name, timeout)
+
+	var last veleroStatus
+	for {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(resource.gvk)
+		key := client.ObjectKey{Namespace: r.cfg.ADPNamespace, Name: name}
+		if err := kubeClient.Get(ctx, key, obj); err != nil {
+			// The CR may not be visible yet right after it was submitted.
+			r.logger.Warnf("getting Velero %s %q in namespace %q: %v, retrying", kind, name, r.cfg.ADPNamespace, err)
+		} else {
+			status := parseVeleroStatus(resource, obj)
+			if status.Phase != last.Phase || status.Items != last.Items ||
+				status.Warnings != last.Warnings || status.Errors != last.Errors {
+				r.logger.Infof("%s %q: phase %s, %s items %s, %d warnings, %d errors",
+					resource.kind, name, status.Phase, status.progress(), resource.itemsVerb, status.Warnings, status.Errors)
+			}
+			last = status
+
+			if status.isTerminal() {
+				r.printVeleroResult(resource, status)
+				if status.Phase != veleroPhaseCompleted {
+					return fmt.Errorf("%s %q finished with phase %s", kind, name, status.Phase)
+				}
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s %q to complete (last phase: %s)", kind, name, last.Phase)
+		case <-time.After(r.cfg.PollInterval):
+		}
+	}
+}
+
+// printVeleroResult prints the outcome of a finished Velero resource.
+func (r *defaultBackupRunner) printVeleroResult(resource veleroResource, status veleroStatus) {
+	r.printer.Printf("%s %q finished with phase %s.\n", resource.kind, status.Name, status.Phase)
+	r.printer.Printf("Items %s: %s, warnings: %d, errors: %d\n", resource.itemsVerb, status.progress(), status.Warnings, status.Errors)
+	if status.FailureReason != "" {
+		r.printer.Printf("Failure reason: %s\n", status.FailureReason)
+	}
+	for _, validationError := range status.ValidationErrors {
+		r.printer.Printf("Validation error: %s\n", validationError)
+	}
+	if status.Phase != veleroPhaseCompleted {
+		r.printer.Printf("%s\n", resource.reviewHint)
+		r.printer.Printf(resource.reviewCommand+"\n", status.Name, r.cfg.ADPNamespace)
+	}
+}
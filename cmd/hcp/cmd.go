@@ -4,6 +4,7 @@ import (
 	"github.com/openshift/osdctl/cmd/hcp/backup"
 	"github.com/openshift/osdctl/cmd/hcp/forceupgrade"
 	getcpautoscalingstatus "github.com/openshift/osdctl/cmd/hcp/get-cp-autoscaling-status"
+	"github.com/openshift/osdctl/cmd/hcp/journal"
 	"github.com/openshift/osdctl/cmd/hcp/mustgather"
 	"github.com/openshift/osdctl/cmd/hcp/status"
 	"github.com/openshift/osdctl/cmd/hcp/transitiontoeus"
@@ -19,7 +20,9 @@ func NewCmdHCP() *cobra.Command {
 	hcp.AddCommand(backup.NewCmdBackup())
 	hcp.AddCommand(getcpautoscalingstatus.NewCmdGetCPAutoscalingStatus())
 	hcp.AddCommand(mustgather.NewCmdMustGather())
+	hcp.AddCommand(backup.NewCmdRestore())
 	hcp.AddCommand(forceupgrade.NewCmdForceUpgrade())
+	hcp.AddCommand(journal.NewCmdJournal())
 	hcp.AddCommand(status.NewCmdStatus())
 	hcp.AddCommand(transitiontoeus.NewCmdTransitionToEUS())
 
@@ -1,6 +1,7 @@
 package forceupgrade
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,6 +14,8 @@ import (
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/osdctl/internal/io"
+	"github.com/openshift/osdctl/internal/journal"
+	"github.com/openshift/osdctl/internal/rollout"
 	"github.com/openshift/osdctl/internal/servicelog"
 	"github.com/openshift/osdctl/internal/utils"
 	ocmutils "github.com/openshift/osdctl/pkg/utils"
@@ -27,9 +30,17 @@ type forceUpgradeOptions struct {
 	nextRunMinutes     int
 	dryRun             bool
 	serviceLogTemplate string
+	preflight          bool
+	excludeHighRisk    bool
+	hiveOcmURL         string
+	journalFile        string
+	rollout            rollout.Options
 
 	// Parsed cluster IDs (populated during validation)
 	clusterIDs []string
+
+	// Records the mutating OCM calls, nil in dry-run mode
+	journal *journal.Journal
 }
 
 // Service log template mappings
@@ -64,7 +75,25 @@ This serves two purposes:
 1. Force upgrades to latest z-stream of the SAME y-stream for critical bug fixes
 2. Force upgrades to latest z-stream of a SUBSEQUENT y-stream when current y-stream goes out of support
 
-Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.g., 4.15.32).`,
+Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.g., 4.15.32).
+
+PRE-FLIGHT CHECKS:
+As the validation checks are skipped, --preflight gathers for each cluster the firing critical alerts (from RHOBS),
+the HostedCluster and NodePool conditions (from the OCM live resources), the existing upgrade policies and the
+missing version gate agreements, and prints a risk table before asking for confirmation. High risk clusters,
+e.g. already unavailable or with critical alerts firing, can be left out with --exclude-high-risk.
+
+STAGED ROLLOUT:
+With --wave-by, the clusters are split into waves by percentage, management cluster or region. Before starting
+a wave, the command waits for the control planes of the previous wave to run the target version, and aborts when
+more than --failure-threshold percent of the processed clusters failed, so that a bad target version cannot
+hit all the clusters at once. --rollout-state persists the progress so that an interrupted rollout can be resumed
+with --resume.
+
+JOURNAL:
+Every mutating OCM call (deleted automatic upgrade policies, scheduled upgrade policies, service logs sent) is
+recorded as it happens to a JSON lines journal, written to --journal or to a timestamped file in the current
+directory. The deleted automatic upgrade policies can be recreated from it with 'osdctl hcp journal restore'.`,
 		Example: `  # Force upgrade without service log
   osdctl hcp force-upgrade -C cluster123 --target-y 4.15
 
@@ -74,14 +103,26 @@ Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.
   # Multiple clusters from file with end-of-support service log
   osdctl hcp force-upgrade --clusters-file clusters.json --target-y 4.16 --send-service-log end-of-support
 
+  # Review the risk of each cluster and leave out the high risk ones before scheduling
+  osdctl hcp force-upgrade --clusters-file clusters.json --target-y 4.16 --preflight --exclude-high-risk
+
+  # Roll the upgrade out by waves of 10% of the clusters, persisting the progress
+  osdctl hcp force-upgrade --clusters-file clusters.json --target-y 4.16 --wave-by percentage --wave-size 10 --rollout-state rollout.json
+
+  # Resume the interrupted rollout
+  osdctl hcp force-upgrade --clusters-file clusters.json --target-y 4.16 --wave-by percentage --wave-size 10 --rollout-state rollout.json --resume
+
   # Force upgrade with custom service log template file
   osdctl hcp force-upgrade -C cluster123 --target-y 4.15 --send-service-log /path/to/custom-template.json
 
+  # Record the changes to a given journal
+  osdctl hcp force-upgrade --clusters-file clusters.json --target-y 4.16 --journal force-upgrade-4.16.jsonl
+
 `,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return opts.Run()
+			return opts.Run(cmd.Context())
 		},
 	}
 
@@ -93,10 +134,19 @@ Example: --target-y 4.15 will upgrade to the latest available 4.15.z version (e.
 	cmd.Flags().StringVar(&opts.targetYStream, "target-y", "", "Target Y-stream version (e.g., 4.15) - will upgrade to the LATEST Z-stream of this Y-stream")
 	cmd.Flags().IntVar(&opts.nextRunMinutes, "next-run-minutes", 10, "Offset in minutes for scheduling upgrade (minimum 6 for the scheduling to take place)")
 	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Simulate the upgrade without making any changes")
+	cmd.Flags().StringVar(&opts.journalFile, "journal", "", "File to record the mutating OCM calls to (default: force-upgrade-journal-<timestamp>.jsonl in the current directory)")
 
 	// Service log flags
 	cmd.Flags().StringVar(&opts.serviceLogTemplate, "send-service-log", "", "Send service log notification after scheduling upgrade. Specify template name (e.g., 'end-of-support') or file path (e.g., '/path/to/template.json')")
 
+	// Pre-flight flags
+	cmd.Flags().BoolVar(&opts.preflight, "preflight", false, "Check firing critical alerts, cluster conditions, upgrade policies and version gate agreements of the clusters and print a risk report before scheduling")
+	cmd.Flags().BoolVar(&opts.excludeHighRisk, "exclude-high-risk", false, "Do not upgrade the clusters assessed as high risk by the pre-flight checks (requires --preflight)")
+	cmd.Flags().StringVar(&opts.hiveOcmURL, "hive-ocm-url", "production", `OCM environment URL for hive operations, used to find the RHOBS cell of the clusters - aliases: "production", "staging", "integration"`)
+
+	// Rollout flags
+	rollout.AddFlags(cmd.Flags(), &opts.rollout)
+
 	// Mark required flags
 	_ = cmd.MarkFlagRequired("target-y")
 
@@ -113,6 +163,14 @@ func (o *forceUpgradeOptions) validate() error {
 		return fmt.Errorf("cannot specify both --cluster-id and --clusters-file, choose one")
 	}
 
+	if err := o.rollout.Validate(); err != nil {
+		return err
+	}
+
+	if o.excludeHighRisk && !o.preflight {
+		return fmt.Errorf("--exclude-high-risk requires --preflight")
+	}
+
 	if o.nextRunMinutes < 6 {
 		return fmt.Errorf("next-run-minutes must be at least 6 minutes")
 	}
@@ -157,7 +215,7 @@ func (o *forceUpgradeOptions) validate() error {
 	return nil
 }
 
-func (o *forceUpgradeOptions) Run() error {
+func (o *forceUpgradeOptions) Run(ctx context.Context) error {
 	if err := o.validate(); err != nil {
 		return err
 	}
@@ -178,6 +236,19 @@ func (o *forceUpgradeOptions) Run() error {
 		return nil
 	}
 
+	if o.preflight {
+		risks := o.runPreflight(ctx, ocmClient, clusters)
+		printRiskReport(risks)
+
+		if o.excludeHighRisk {
+			clusters = excludeHighRiskClusters(risks)
+			if len(clusters) == 0 {
+				fmt.Println("\nAll clusters are high risk, no force upgrade to schedule.")
+				return nil
+			}
+		}
+	}
+
 	// Display cluster list and service log preview before processing
 	if err := o.printPreProcessingSummary(clusters); err != nil {
 		return fmt.Errorf("failed to display pre-processing summary: %w", err)
@@ -190,43 +261,89 @@ func (o *forceUpgradeOptions) Run() error {
 			return nil
 		}
 		fmt.Println()
+
+		if o.journalFile == "" {
+			o.journalFile = journal.DefaultPath("force-upgrade")
+		}
+		o.journal, err = journal.Open(o.journalFile, "force-upgrade")
+		if err != nil {
+			return err
+		}
+		defer o.journal.Close()
+		fmt.Printf("📒 Recording the changes to the journal: %s\n", o.journal.Path())
 	}
 
-	var successful, failed []string
 	var serviceLogSuccessful, serviceLogFailed []string
+	targetVersions := map[string]string{}
 
-	for i, cluster := range clusters {
-		fmt.Printf("\n[%d/%d] Processing cluster: %s (%s)\n", i+1, len(clusters), cluster.ID(), cluster.Name())
-
+	apply := func(cluster *v1.Cluster) error {
 		targetVersion, err := o.processCluster(ocmClient, cluster)
 		if err != nil {
-			failed = append(failed, fmt.Sprintf("%s: %s", cluster.ExternalID(), err.Error()))
 			fmt.Printf("  ⚠️  Failed to create upgrade policy: %v\n", err)
-		} else {
-			successful = append(successful, cluster.ExternalID())
+			return err
+		}
+		targetVersions[cluster.ID()] = targetVersion
 
-			if o.serviceLogTemplate != "" {
-				if o.dryRun {
-					fmt.Printf("  📧 DRY-RUN: Would send service log notification\n")
-					serviceLogSuccessful = append(serviceLogSuccessful, cluster.ExternalID())
-					continue
-				}
+		if o.serviceLogTemplate != "" {
+			if o.dryRun {
+				fmt.Printf("  📧 DRY-RUN: Would send service log notification\n")
+				serviceLogSuccessful = append(serviceLogSuccessful, cluster.ExternalID())
+				return nil
+			}
 
-				if err := sendUpgradeServiceLog(ocmClient, cluster, o.serviceLogTemplate, targetVersion); err != nil {
-					serviceLogFailed = append(serviceLogFailed, fmt.Sprintf("%s: %s", cluster.ExternalID(), err.Error()))
-					fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
-				} else {
-					serviceLogSuccessful = append(serviceLogSuccessful, cluster.ExternalID())
-					fmt.Printf("  📧 Service log notification sent successfully\n")
-				}
+			err := sendUpgradeServiceLog(ocmClient, cluster, o.serviceLogTemplate, targetVersion)
+			o.journal.Record(cluster, journal.ActionSendServiceLog, err, journal.Entry{
+				Details: map[string]string{"template": o.serviceLogTemplate, "version": targetVersion},
+			})
+			if err != nil {
+				serviceLogFailed = append(serviceLogFailed, fmt.Sprintf("%s: %s", cluster.ExternalID(), err.Error()))
+				fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
+			} else {
+				serviceLogSuccessful = append(serviceLogSuccessful, cluster.ExternalID())
+				fmt.Printf("  📧 Service log notification sent successfully\n")
 			}
 		}
+		return nil
+	}
+
+	reached := func(cluster *v1.Cluster) (bool, error) {
+		return o.upgradeReached(cluster, targetVersions[cluster.ID()])
+	}
+
+	result, err := rollout.NewExecutor(ocmClient, "force-upgrade", o.rollout, o.dryRun, apply, reached).Run(ctx, clusters)
+	if err != nil {
+		return err
 	}
 
-	o.printSummary(successful, failed, serviceLogSuccessful, serviceLogFailed)
+	o.printSummary(result.Succeeded, result.Failed, serviceLogSuccessful, serviceLogFailed)
+	result.PrintSummary()
+	if o.journal != nil {
+		fmt.Printf("\n📒 Journal of the changes: %s\n", o.journal.Path())
+	}
 	return nil
 }
 
+// upgradeReached tells whether the control plane of the cluster runs the target version of the force upgrade.
+// The target version is unknown when the upgrade was scheduled by a previous run, the cluster being on the target
+// Y-stream is then considered enough.
+func (o *forceUpgradeOptions) upgradeReached(cluster *v1.Cluster, targetVersion string) (bool, error) {
+	currentVersion, err := semver.NewVersion(cluster.Version().RawID())
+	if err != nil {
+		return false, fmt.Errorf("failed to parse cluster version '%s': %w", cluster.Version().RawID(), err)
+	}
+
+	if targetVersion == "" {
+		return fmt.Sprintf("%d.%d", currentVersion.Major(), currentVersion.Minor()) == o.targetYStream, nil
+	}
+
+	version, err := semver.NewVersion(targetVersion)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse target version '%s': %w", targetVersion, err)
+	}
+
+	return !currentVersion.LessThan(version), nil
+}
+
 func (o *forceUpgradeOptions) getClusters(ocmClient *sdk.Connection) ([]*v1.Cluster, error) {
 	clusterIDs := o.clusterIDs
 
@@ -280,10 +397,10 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 		return "", fmt.Errorf("failed to list existing upgrade policies: %w", err)
 	}
 
-	var automaticPolicyIDs []string
+	var automaticPolicies []*v1.ControlPlaneUpgradePolicy
 	for _, policy := range policiesResponse.Items().Slice() {
 		if policy.ScheduleType() == v1.ScheduleTypeAutomatic {
-			automaticPolicyIDs = append(automaticPolicyIDs, policy.ID())
+			automaticPolicies = append(automaticPolicies, policy)
 		} else {
 			return "", fmt.Errorf("existing manual upgrade policy found: target version %s scheduled at %s",
 				policy.Version(), policy.NextRun().Format(time.RFC3339))
@@ -309,10 +426,12 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 	}
 
 	// Delete automatic Z-stream upgrade policies
-	for _, id := range automaticPolicyIDs {
+	for _, automaticPolicy := range automaticPolicies {
+		id := automaticPolicy.ID()
 		fmt.Printf("  🗑️  Deleting automatic Z-stream upgrade policy (ID: %s) ahead of scheduling manual upgrade\n", id)
 		_, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
 			ControlPlane().UpgradePolicies().ControlPlaneUpgradePolicy(id).Delete().Send()
+		o.journal.RecordPolicy(cluster, journal.ActionDeleteUpgradePolicy, automaticPolicy, "", err)
 		if err != nil {
 			return "", fmt.Errorf("failed to delete automatic upgrade policy (ID: %s): %w", id, err)
 		}
@@ -328,11 +447,13 @@ func (o *forceUpgradeOptions) processCluster(ocmClient *sdk.Connection, cluster
 		return "", fmt.Errorf("failed to build upgrade policy: %w", err)
 	}
 
-	_, err = ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+	response, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
 		ControlPlane().UpgradePolicies().Add().Body(policy).Send()
 	if err != nil {
+		o.journal.RecordPolicy(cluster, journal.ActionCreateUpgradePolicy, policy, "", err)
 		return "", fmt.Errorf("failed to create upgrade policy: %w", err)
 	}
+	o.journal.RecordPolicy(cluster, journal.ActionCreateUpgradePolicy, response.Body(), "", nil)
 
 	fmt.Printf("  ✅ Scheduled force upgrade to version %s at %s\n",
 		targetVersion, scheduleTime.Format(time.RFC3339))
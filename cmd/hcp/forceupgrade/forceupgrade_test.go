@@ -48,6 +48,16 @@ func TestForceUpgradeOptionsValidation(t *testing.T) {
 			wantErr: true,
 			errMsg:  "cannot specify both --cluster-id and --clusters-file, choose one",
 		},
+		{
+			name: "invalid - exclude high risk without preflight",
+			opts: &forceUpgradeOptions{
+				clusterID:       "test-cluster",
+				nextRunMinutes:  10,
+				excludeHighRisk: true,
+			},
+			wantErr: true,
+			errMsg:  "--exclude-high-risk requires --preflight",
+		},
 		{
 			name: "invalid - next run minutes too low",
 			opts: &forceUpgradeOptions{
@@ -0,0 +1,304 @@
+package forceupgrade
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/osdctl/cmd/hcp/status"
+	"github.com/openshift/osdctl/cmd/rhobs"
+	"golang.org/x/sync/errgroup"
+)
+
+// Number of clusters whose pre-flight checks run concurrently
+const preflightConcurrency = 5
+
+type riskLevel string
+
+const (
+	riskLow    riskLevel = "Low"
+	riskMedium riskLevel = "Medium"
+	riskHigh   riskLevel = "High"
+)
+
+func (l riskLevel) rank() int {
+	switch l {
+	case riskHigh:
+		return 2
+	case riskMedium:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// riskFinding is the result of a pre-flight check which may make a forced upgrade risky
+type riskFinding struct {
+	level   riskLevel
+	check   string
+	message string
+}
+
+// clusterRisk holds the pre-flight findings of a cluster
+type clusterRisk struct {
+	cluster  *v1.Cluster
+	findings []riskFinding
+}
+
+// level returns the highest risk level of the findings, Low when there is none.
+func (r *clusterRisk) level() riskLevel {
+	level := riskLow
+	for _, finding := range r.findings {
+		if finding.level.rank() > level.rank() {
+			level = finding.level
+		}
+	}
+	return level
+}
+
+// preflightData holds what was gathered about a cluster for the pre-flight checks, or why it could not be gathered
+type preflightData struct {
+	targetVersion string
+
+	criticalAlerts []rhobs.FiringAlert
+	alertsErr      error
+
+	health    *status.HealthVerdict
+	healthErr error
+
+	policies    []*v1.ControlPlaneUpgradePolicy
+	policiesErr error
+
+	missingGates []*v1.VersionGate
+	gatesErr     error
+}
+
+// runPreflight gathers the pre-flight data of every cluster concurrently and assesses their risk.
+// The risks are returned in the order of the clusters.
+func (o *forceUpgradeOptions) runPreflight(ctx context.Context, ocmClient *sdk.Connection, clusters []*v1.Cluster) []*clusterRisk {
+	fmt.Printf("\nRunning pre-flight checks on %d clusters...\n", len(clusters))
+
+	// Version gates are global, only the agreements are specific to each cluster
+	gates, gatesErr := listVersionGates(ocmClient, o.targetYStream)
+
+	risks := make([]*clusterRisk, len(clusters))
+
+	var group errgroup.Group
+	group.SetLimit(preflightConcurrency)
+	for idx, cluster := range clusters {
+		group.Go(func() error {
+			risks[idx] = &clusterRisk{
+				cluster:  cluster,
+				findings: o.checkCluster(ctx, ocmClient, cluster, gates, gatesErr),
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return risks
+}
+
+// checkCluster runs the pre-flight checks of a cluster and returns their findings.
+func (o *forceUpgradeOptions) checkCluster(ctx context.Context, ocmClient *sdk.Connection, cluster *v1.Cluster, gates []*v1.VersionGate, gatesErr error) []riskFinding {
+	// The upgrade would be rejected anyway, the other checks are irrelevant
+	if !cluster.Hypershift().Enabled() {
+		return []riskFinding{{riskHigh, "Cluster", "not an HCP cluster, force upgrading is only allowed on ROSA HCP clusters"}}
+	}
+	if cluster.State() != v1.ClusterStateReady {
+		return []riskFinding{{riskHigh, "Cluster", fmt.Sprintf("cluster is not ready (current state: %s)", cluster.State())}}
+	}
+
+	data := preflightData{}
+
+	var err error
+	data.targetVersion, err = o.determineTargetVersion(cluster.Version().AvailableUpgrades())
+	if err != nil {
+		return []riskFinding{{riskHigh, "Version", fmt.Sprintf("failed to determine target version: %v", err)}}
+	}
+
+	fetcher, err := rhobs.CreateRhobsFetcher(ctx, cluster.ID(), rhobs.RhobsFetchForMetrics, o.hiveOcmURL)
+	if err != nil {
+		data.alertsErr = err
+	} else {
+		data.criticalAlerts, data.alertsErr = fetcher.QueryFiringAlerts(ctx, "critical")
+	}
+
+	hcpStatus, err := status.FetchClusterStatus(ocmClient, cluster)
+	if err != nil {
+		data.healthErr = err
+	} else {
+		data.health = hcpStatus.Health
+	}
+
+	policiesResponse, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+		ControlPlane().UpgradePolicies().List().SendContext(ctx)
+	if err != nil {
+		data.policiesErr = err
+	} else {
+		data.policies = policiesResponse.Items().Slice()
+	}
+
+	if gatesErr != nil {
+		data.gatesErr = gatesErr
+	} else if requiresGateAgreements(cluster.Version().RawID(), o.targetYStream) {
+		agreementsResponse, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+			GateAgreements().List().SendContext(ctx)
+		if err != nil {
+			data.gatesErr = err
+		} else {
+			data.missingGates = missingGateAgreements(gates, agreementsResponse.Items().Slice())
+		}
+	}
+
+	return assessRisk(data)
+}
+
+// assessRisk turns the pre-flight data of a cluster into findings. Checks which could not run are Medium risks,
+// as nothing tells the cluster is safe to upgrade.
+func assessRisk(data preflightData) []riskFinding {
+	findings := []riskFinding{}
+
+	if data.targetVersion == "" {
+		findings = append(findings, riskFinding{riskHigh, "Version", "no available upgrade to the target Y-stream"})
+	}
+
+	if data.alertsErr != nil {
+		findings = append(findings, riskFinding{riskMedium, "Alerts", fmt.Sprintf("failed to check firing alerts: %v", data.alertsErr)})
+	}
+	for _, alert := range data.criticalAlerts {
+		message := fmt.Sprintf("%s firing since %s", alert.Name, alert.StartsAt.UTC().Format(time.RFC3339))
+		if alert.Summary != "" {
+			message += ": " + alert.Summary
+		}
+		findings = append(findings, riskFinding{riskHigh, "Alerts", message})
+	}
+
+	if data.healthErr != nil {
+		findings = append(findings, riskFinding{riskMedium, "Conditions", fmt.Sprintf("failed to check cluster conditions: %v", data.healthErr)})
+	}
+	if data.health != nil {
+		for _, reason := range data.health.Reasons {
+			level := riskMedium
+			if reason.Severity == status.VerdictCritical {
+				level = riskHigh
+			}
+			findings = append(findings, riskFinding{level, "Conditions", fmt.Sprintf("%s: %s", reason.Component, reason.Message)})
+		}
+	}
+
+	if data.policiesErr != nil {
+		findings = append(findings, riskFinding{riskMedium, "UpgradePolicies", fmt.Sprintf("failed to list upgrade policies: %v", data.policiesErr)})
+	}
+	for _, policy := range data.policies {
+		if policy.ScheduleType() == v1.ScheduleTypeAutomatic {
+			findings = append(findings, riskFinding{riskLow, "UpgradePolicies", "automatic upgrade policy will be deleted"})
+			continue
+		}
+		findings = append(findings, riskFinding{riskHigh, "UpgradePolicies", fmt.Sprintf("manual upgrade policy to %s already scheduled at %s",
+			policy.Version(), policy.NextRun().Format(time.RFC3339))})
+	}
+
+	if data.gatesErr != nil {
+		findings = append(findings, riskFinding{riskMedium, "VersionGates", fmt.Sprintf("failed to check version gate agreements: %v", data.gatesErr)})
+	}
+	for _, gate := range data.missingGates {
+		findings = append(findings, riskFinding{riskMedium, "VersionGates", fmt.Sprintf("version gate '%s' not acknowledged: %s", gate.Label(), gate.Description())})
+	}
+
+	return findings
+}
+
+func listVersionGates(ocmClient *sdk.Connection, targetYStream string) ([]*v1.VersionGate, error) {
+	response, err := ocmClient.ClustersMgmt().V1().VersionGates().List().
+		Search(fmt.Sprintf("version_raw_id_prefix = '%s'", targetYStream)).
+		Send()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list version gates: %w", err)
+	}
+
+	return response.Items().Slice(), nil
+}
+
+// requiresGateAgreements tells whether upgrading from the current version to the target Y-stream crosses a Y-stream,
+// the version gates only applying to Y-stream upgrades.
+func requiresGateAgreements(currentVersion string, targetYStream string) bool {
+	version, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return true
+	}
+
+	return fmt.Sprintf("%d.%d", version.Major(), version.Minor()) != targetYStream
+}
+
+// missingGateAgreements returns the version gates which have not been agreed to.
+func missingGateAgreements(gates []*v1.VersionGate, agreements []*v1.VersionGateAgreement) []*v1.VersionGate {
+	agreedGateIDs := map[string]bool{}
+	for _, agreement := range agreements {
+		agreedGateIDs[agreement.VersionGate().ID()] = true
+	}
+
+	var missingGates []*v1.VersionGate
+	for _, gate := range gates {
+		if !agreedGateIDs[gate.ID()] {
+			missingGates = append(missingGates, gate)
+		}
+	}
+
+	return missingGates
+}
+
+// printRiskReport displays a row per finding of every cluster, and a summary of the risk levels
+func printRiskReport(risks []*clusterRisk) {
+	fmt.Print("\n" + strings.Repeat("=", 60) + "\n")
+	fmt.Print("PRE-FLIGHT RISK REPORT\n")
+	fmt.Print(strings.Repeat("=", 60) + "\n\n")
+
+	counts := map[riskLevel]int{}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "CLUSTER\tNAME\tRISK\tCHECK\tFINDING\n")
+	for _, risk := range risks {
+		counts[risk.level()]++
+
+		if len(risk.findings) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", risk.cluster.ExternalID(), risk.cluster.Name(), riskLow, "-", "no issue found")
+			continue
+		}
+		for _, finding := range risk.findings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", risk.cluster.ExternalID(), risk.cluster.Name(), finding.level, finding.check, finding.message)
+		}
+	}
+	w.Flush()
+
+	fmt.Printf("\nRisk summary: %d high, %d medium, %d low\n", counts[riskHigh], counts[riskMedium], counts[riskLow])
+	fmt.Print(strings.Repeat("=", 60) + "\n")
+}
+
+// excludeHighRiskClusters returns the clusters whose risk is not High, printing the excluded ones.
+func excludeHighRiskClusters(risks []*clusterRisk) []*v1.Cluster {
+	var clusters []*v1.Cluster
+	var excluded []string
+	for _, risk := range risks {
+		if risk.level() == riskHigh {
+			excluded = append(excluded, fmt.Sprintf("%s (%s)", risk.cluster.ExternalID(), risk.cluster.Name()))
+			continue
+		}
+		clusters = append(clusters, risk.cluster)
+	}
+
+	if len(excluded) > 0 {
+		fmt.Printf("\nExcluding %d high risk clusters from the force upgrade:\n", len(excluded))
+		for _, entry := range excluded {
+			fmt.Printf("  - %s\n", entry)
+		}
+	}
+
+	return clusters
+}
@@ -0,0 +1,142 @@
+package forceupgrade
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/osdctl/cmd/hcp/status"
+	"github.com/openshift/osdctl/cmd/rhobs"
+)
+
+func TestAssessRisk(t *testing.T) {
+	manualPolicy, _ := v1.NewControlPlaneUpgradePolicy().ScheduleType(v1.ScheduleTypeManual).Version("4.16.3").
+		NextRun(time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)).Build()
+	automaticPolicy, _ := v1.NewControlPlaneUpgradePolicy().ScheduleType(v1.ScheduleTypeAutomatic).Build()
+	gate, _ := v1.NewVersionGate().ID("gate-1").Label("api.openshift.com/gate-sts").Description("IAM policies changed").Build()
+
+	tests := []struct {
+		name             string
+		data             preflightData
+		expectedLevel    riskLevel
+		expectedFindings []string
+	}{
+		{
+			name:          "Nothing found",
+			data:          preflightData{targetVersion: "4.16.3", health: &status.HealthVerdict{Verdict: status.VerdictHealthy}},
+			expectedLevel: riskLow,
+		},
+		{
+			name:             "Automatic policy only",
+			data:             preflightData{targetVersion: "4.16.3", policies: []*v1.ControlPlaneUpgradePolicy{automaticPolicy}},
+			expectedLevel:    riskLow,
+			expectedFindings: []string{"Low UpgradePolicies automatic upgrade policy will be deleted"},
+		},
+		{
+			name: "Degraded cluster with missing gate agreement",
+			data: preflightData{
+				targetVersion: "4.16.3",
+				health: &status.HealthVerdict{Verdict: status.VerdictDegraded, Reasons: []status.HealthReason{
+					{Severity: status.VerdictDegraded, Component: "NodePool/workers", Message: "Ready is False: scaling"},
+				}},
+				missingGates: []*v1.VersionGate{gate},
+			},
+			expectedLevel: riskMedium,
+			expectedFindings: []string{
+				"Medium Conditions NodePool/workers: Ready is False: scaling",
+				"Medium VersionGates version gate 'api.openshift.com/gate-sts' not acknowledged: IAM policies changed",
+			},
+		},
+		{
+			name: "Critical alert, unavailable cluster and manual policy",
+			data: preflightData{
+				targetVersion: "4.16.3",
+				criticalAlerts: []rhobs.FiringAlert{
+					{Name: "KubeAPIDown", Severity: "critical", Summary: "API is down", StartsAt: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)},
+				},
+				health: &status.HealthVerdict{Verdict: status.VerdictCritical, Reasons: []status.HealthReason{
+					{Severity: status.VerdictCritical, Component: "HostedCluster", Message: "Available is False: etcd quorum lost"},
+				}},
+				policies: []*v1.ControlPlaneUpgradePolicy{manualPolicy},
+			},
+			expectedLevel: riskHigh,
+			expectedFindings: []string{
+				"High Alerts KubeAPIDown firing since 2024-05-02T10:00:00Z: API is down",
+				"High Conditions HostedCluster: Available is False: etcd quorum lost",
+				"High UpgradePolicies manual upgrade policy to 4.16.3 already scheduled at 2024-05-02T12:00:00Z",
+			},
+		},
+		{
+			name: "Checks which could not run",
+			data: preflightData{
+				targetVersion: "4.16.3",
+				alertsErr:     errors.New("no RHOBS cell"),
+				healthErr:     errors.New("no live resources"),
+				policiesErr:   errors.New("timeout"),
+				gatesErr:      errors.New("forbidden"),
+			},
+			expectedLevel: riskMedium,
+			expectedFindings: []string{
+				"Medium Alerts failed to check firing alerts: no RHOBS cell",
+				"Medium Conditions failed to check cluster conditions: no live resources",
+				"Medium UpgradePolicies failed to list upgrade policies: timeout",
+				"Medium VersionGates failed to check version gate agreements: forbidden",
+			},
+		},
+		{
+			name:             "No target version",
+			data:             preflightData{},
+			expectedLevel:    riskHigh,
+			expectedFindings: []string{"High Version no available upgrade to the target Y-stream"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk := &clusterRisk{findings: assessRisk(tt.data)}
+
+			var got []string
+			for _, finding := range risk.findings {
+				got = append(got, strings.Join([]string{string(finding.level), finding.check, finding.message}, " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expectedFindings, "\n") {
+				t.Errorf("expected findings:\n%s\ngot:\n%s", strings.Join(tt.expectedFindings, "\n"), strings.Join(got, "\n"))
+			}
+			if level := risk.level(); level != tt.expectedLevel {
+				t.Errorf("expected risk level %s, got %s", tt.expectedLevel, level)
+			}
+		})
+	}
+}
+
+func TestMissingGateAgreements(t *testing.T) {
+	gate1, _ := v1.NewVersionGate().ID("gate-1").Build()
+	gate2, _ := v1.NewVersionGate().ID("gate-2").Build()
+	agreement, _ := v1.NewVersionGateAgreement().VersionGate(v1.NewVersionGate().ID("gate-1")).Build()
+
+	missing := missingGateAgreements([]*v1.VersionGate{gate1, gate2}, []*v1.VersionGateAgreement{agreement})
+
+	if len(missing) != 1 || missing[0].ID() != "gate-2" {
+		t.Errorf("expected only gate-2 to be missing, got %v", missing)
+	}
+}
+
+func TestRequiresGateAgreements(t *testing.T) {
+	tests := []struct {
+		currentVersion string
+		targetYStream  string
+		expected       bool
+	}{
+		{"4.15.10", "4.15", false},
+		{"4.15.10", "4.16", true},
+		{"invalid", "4.16", true},
+	}
+
+	for _, tt := range tests {
+		if got := requiresGateAgreements(tt.currentVersion, tt.targetYStream); got != tt.expected {
+			t.Errorf("requiresGateAgreements(%s, %s) = %v, expected %v", tt.currentVersion, tt.targetYStream, got, tt.expected)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	schedulingv1alpha1 "github.com/openshift/hypershift/api/scheduling/v1alpha1"
 	"github.com/openshift/osdctl/pkg/k8s"
 	"github.com/openshift/osdctl/pkg/printer"
 	"github.com/openshift/osdctl/pkg/utils"
@@ -36,6 +37,9 @@ type options struct {
 	output        string
 	showOnly      string
 	noHeaders     bool
+	recommend     bool
+	window        time.Duration
+	hiveOcmURL    string
 }
 
 type clusterInfo struct {
@@ -64,7 +68,14 @@ func newCmdAutoscalingAudit() *cobra.Command {
 		Long: `Query a single HCP management cluster to retrieve autoscaling status for all hosted clusters.
 
 This command is useful for checking the autoscaling configuration status of hosted clusters
-on a specific management cluster during day-to-day operations.`,
+on a specific management cluster during day-to-day operations.
+
+With --recommend, the 95th percentile of the API server and etcd resource usage of each hosted
+cluster over --window is pulled from RHOBS. The API server memory usage is compared against the
+kasGoMemLimit of the current request-serving size class of the ClusterSizingConfiguration:
+clusters using more than 80% of it are undersized, clusters using less than 40% of it while fitting
+in a smaller size class are oversized. Only the over- and under-sized clusters are listed, with
+the usage they were assessed on.`,
 		Example: `
   # Get autoscaling status for all hosted clusters on a management cluster
   osdctl hcp get-cp-autoscaling-status --mgmt-cluster-id ${MGMT_CLUSTER_ID}
@@ -79,7 +90,10 @@ on a specific management cluster during day-to-day operations.`,
   osdctl hcp get-cp-autoscaling-status --mgmt-cluster-id ${MGMT_CLUSTER_ID} --show-only needs-removal
 
   # Show only clusters safe to remove override
-  osdctl hcp get-cp-autoscaling-status --mgmt-cluster-id ${MGMT_CLUSTER_ID} --show-only safe-to-remove-override`,
+  osdctl hcp get-cp-autoscaling-status --mgmt-cluster-id ${MGMT_CLUSTER_ID} --show-only safe-to-remove-override
+
+  # Recommend size changes from the usage of the last 3 days, as CSV
+  osdctl hcp get-cp-autoscaling-status --mgmt-cluster-id ${MGMT_CLUSTER_ID} --recommend --window 72h --output csv`,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -95,6 +109,12 @@ on a specific management cluster during day-to-day operations.`,
 		"Filter output: needs-removal, ready-for-migration, safe-to-remove-override")
 	cmd.Flags().BoolVar(&opts.noHeaders, "no-headers", false,
 		"Skip table headers in output")
+	cmd.Flags().BoolVar(&opts.recommend, "recommend", false,
+		"Recommend size changes from the API server and etcd usage reported by RHOBS")
+	cmd.Flags().DurationVar(&opts.window, "window", 7*24*time.Hour,
+		"Period of the usage the recommendations are based on")
+	cmd.Flags().StringVar(&opts.hiveOcmURL, "hive-ocm-url", "production",
+		`OCM environment URL for hive operations, used to find the RHOBS cell of the management cluster - aliases: "production", "staging", "integration"`)
 
 	if err := cmd.MarkFlagRequired("mgmt-cluster-id"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
@@ -116,6 +136,10 @@ func (o *options) run(ctx context.Context) error {
 		}
 	}
 
+	if o.recommend && o.window < usageQueryStep {
+		return fmt.Errorf("invalid window '%s'. It must be at least %s", o.window, usageQueryStep)
+	}
+
 	connection, err := utils.CreateConnection()
 	if err != nil {
 		return fmt.Errorf("failed to create OCM connection: %v", err)
@@ -153,6 +177,9 @@ func (o *options) auditManagementCluster(ctx context.Context, conn *sdk.Connecti
 	if err := corev1.AddToScheme(scheme); err != nil {
 		return fmt.Errorf("failed to add core v1 scheme: %v", err)
 	}
+	if err := schedulingv1alpha1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("failed to add scheduling scheme: %v", err)
+	}
 
 	mgmtClient, err := k8s.NewWithConn(resolvedMgmtClusterID, client.Options{Scheme: scheme}, conn)
 	if err != nil {
@@ -198,6 +225,10 @@ func (o *options) auditManagementCluster(ctx context.Context, conn *sdk.Connecti
 		results = o.applyFilter(results)
 	}
 
+	if o.recommend {
+		return o.recommendSizes(ctx, mgmtClient, resolvedMgmtClusterID, results)
+	}
+
 	return o.outputResults(results)
 }
 
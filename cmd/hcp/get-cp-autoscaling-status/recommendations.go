@@ -0,0 +1,419 @@
+package getcpautoscalingstatus
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	schedulingv1alpha1 "github.com/openshift/hypershift/api/scheduling/v1alpha1"
+	"github.com/openshift/osdctl/cmd/rhobs"
+	"github.com/openshift/osdctl/pkg/printer"
+	"gopkg.in/yaml.v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// A cluster whose API server memory usage exceeds this ratio of the GOMEMLIMIT of its size class is undersized
+	undersizedUtilization = 0.8
+	// A cluster whose API server memory usage is below this ratio of the GOMEMLIMIT of its size class is oversized,
+	// provided that a smaller size class fits its usage
+	oversizedUtilization = 0.4
+
+	// Resolution of the RHOBS subqueries computing the usage percentiles
+	usageQueryStep = 5 * time.Minute
+
+	containerKubeAPIServer = "kube-apiserver"
+	containerEtcd          = "etcd"
+)
+
+type sizingVerdict string
+
+const (
+	verdictOversized  sizingVerdict = "oversized"
+	verdictUndersized sizingVerdict = "undersized"
+	verdictRightSized sizingVerdict = "right-sized"
+	verdictUnknown    sizingVerdict = "unknown"
+)
+
+// sizeClass is a request-serving size class of the ClusterSizingConfiguration, in increasing order of size
+type sizeClass struct {
+	name string
+	// GOMEMLIMIT of the API server of the clusters of this class, 0 if not set
+	kasMemoryLimitBytes float64
+}
+
+// metricsQuerier evaluates instant PromQL queries, see rhobs.RhobsFetcher
+type metricsQuerier interface {
+	QueryInstantMetricSamples(ctx context.Context, promExpr string, evalTime time.Time) ([]rhobs.MetricSample, error)
+}
+
+// usageEvidence is the control plane resource usage a recommendation is based on, as 95th percentiles over the window
+type usageEvidence struct {
+	KASMemoryP95Bytes    float64 `json:"kas_memory_p95_bytes" yaml:"kas_memory_p95_bytes"`
+	KASMemoryLimitBytes  float64 `json:"kas_memory_limit_bytes" yaml:"kas_memory_limit_bytes"`
+	KASMemoryUtilization float64 `json:"kas_memory_utilization" yaml:"kas_memory_utilization"`
+	KASCPUP95Cores       float64 `json:"kas_cpu_p95_cores" yaml:"kas_cpu_p95_cores"`
+	EtcdMemoryP95Bytes   float64 `json:"etcd_memory_p95_bytes" yaml:"etcd_memory_p95_bytes"`
+	EtcdCPUP95Cores      float64 `json:"etcd_cpu_p95_cores" yaml:"etcd_cpu_p95_cores"`
+
+	hasKASMemory bool
+}
+
+type sizingRecommendation struct {
+	ClusterID             string        `json:"cluster_id" yaml:"cluster_id"`
+	ClusterName           string        `json:"cluster_name" yaml:"cluster_name"`
+	Namespace             string        `json:"namespace" yaml:"namespace"`
+	AutoscalingEnabled    bool          `json:"autoscaling_enabled" yaml:"autoscaling_enabled"`
+	CurrentSize           string        `json:"current_size" yaml:"current_size"`
+	HypershiftRecommended string        `json:"hypershift_recommended_size" yaml:"hypershift_recommended_size"`
+	RecommendedSize       string        `json:"recommended_size" yaml:"recommended_size"`
+	Verdict               sizingVerdict `json:"verdict" yaml:"verdict"`
+	Reason                string        `json:"reason" yaml:"reason"`
+	Evidence              usageEvidence `json:"evidence" yaml:"evidence"`
+}
+
+type recommendationResults struct {
+	Timestamp          time.Time              `json:"timestamp" yaml:"timestamp"`
+	ManagementCluster  string                 `json:"management_cluster" yaml:"management_cluster"`
+	Window             string                 `json:"window" yaml:"window"`
+	TotalClusters      int                    `json:"total_clusters" yaml:"total_clusters"`
+	RightSizedClusters int                    `json:"right_sized_clusters" yaml:"right_sized_clusters"`
+	UnknownClusters    int                    `json:"unknown_clusters" yaml:"unknown_clusters"`
+	Recommendations    []sizingRecommendation `json:"recommendations" yaml:"recommendations"`
+}
+
+// getSizeClasses returns the size classes of the ClusterSizingConfiguration of the management cluster, ordered by
+// the node count they start at
+func getSizeClasses(ctx context.Context, kubeClient client.Client) ([]sizeClass, error) {
+	cscList := &schedulingv1alpha1.ClusterSizingConfigurationList{}
+	if err := kubeClient.List(ctx, cscList); err != nil {
+		return nil, fmt.Errorf("failed to list ClusterSizingConfigurations: %v", err)
+	}
+	if len(cscList.Items) == 0 {
+		return nil, fmt.Errorf("no ClusterSizingConfiguration found")
+	}
+
+	sizes := append([]schedulingv1alpha1.SizeConfiguration{}, cscList.Items[0].Spec.Sizes...)
+	sort.SliceStable(sizes, func(i, j int) bool {
+		return sizes[i].Criteria.From < sizes[j].Criteria.From
+	})
+
+	classes := make([]sizeClass, 0, len(sizes))
+	for _, size := range sizes {
+		class := sizeClass{name: size.Name}
+		if size.Effects != nil && size.Effects.KASGoMemLimit != nil {
+			class.kasMemoryLimitBytes = float64(size.Effects.KASGoMemLimit.Value())
+		}
+		classes = append(classes, class)
+	}
+
+	return classes, nil
+}
+
+// usageQuery returns the PromQL query of the 95th percentile over the window of a per namespace usage expression
+func usageQuery(usageExpr string, window time.Duration) string {
+	return fmt.Sprintf("quantile_over_time(0.95, (%s)[%ds:%ds])", usageExpr, int64(window.Seconds()), int64(usageQueryStep.Seconds()))
+}
+
+// The usage of the busiest replica is compared to the limits, which apply to each replica. The series are scoped to the
+// management cluster, whose _mc_id label is kept for the results to be matched against it.
+func memoryUsageExpr(mgmtClusterID string, container string) string {
+	return fmt.Sprintf(`max by (_mc_id, namespace) (container_memory_working_set_bytes{_mc_id="%s", namespace=~"ocm-(production|staging)-.+", container="%s"})`, mgmtClusterID, container)
+}
+
+func cpuUsageExpr(mgmtClusterID string, container string) string {
+	return fmt.Sprintf(`max by (_mc_id, namespace) (rate(container_cpu_usage_seconds_total{_mc_id="%s", namespace=~"ocm-(production|staging)-.+", container="%s"}[5m]))`, mgmtClusterID, container)
+}
+
+// queryControlPlaneUsage returns the usage evidence of every HCP namespace of the management cluster
+func queryControlPlaneUsage(ctx context.Context, querier metricsQuerier, mgmtClusterID string, window time.Duration) (map[string]*usageEvidence, error) {
+	usages := map[string]*usageEvidence{}
+	usageOf := func(namespace string) *usageEvidence {
+		if usages[namespace] == nil {
+			usages[namespace] = &usageEvidence{}
+		}
+		return usages[namespace]
+	}
+
+	queries := []struct {
+		expr string
+		set  func(usage *usageEvidence, value float64)
+	}{
+		{memoryUsageExpr(mgmtClusterID, containerKubeAPIServer), func(usage *usageEvidence, value float64) {
+			usage.KASMemoryP95Bytes = value
+			usage.hasKASMemory = true
+		}},
+		{cpuUsageExpr(mgmtClusterID, containerKubeAPIServer), func(usage *usageEvidence, value float64) { usage.KASCPUP95Cores = value }},
+		{memoryUsageExpr(mgmtClusterID, containerEtcd), func(usage *usageEvidence, value float64) { usage.EtcdMemoryP95Bytes = value }},
+		{cpuUsageExpr(mgmtClusterID, containerEtcd), func(usage *usageEvidence, value float64) { usage.EtcdCPUP95Cores = value }},
+	}
+
+	for _, query := range queries {
+		samples, err := querier.QueryInstantMetricSamples(ctx, usageQuery(query.expr, window), time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query control plane usage from RHOBS: %v", err)
+		}
+		for _, sample := range samples {
+			namespace := sample.Labels["namespace"]
+			if namespace == "" {
+				continue
+			}
+			query.set(usageOf(namespace), sample.Value)
+		}
+	}
+
+	return usages, nil
+}
+
+// recommendSize compares the API server memory usage of a cluster against the GOMEMLIMIT of its current size class.
+// The recommended size is the smallest class whose limit fits the usage below the undersized threshold, moving down
+// only when the usage is below the oversized threshold of the current class.
+func recommendSize(classes []sizeClass, currentSize string, usage usageEvidence) (sizingVerdict, string, string) {
+	if !usage.hasKASMemory {
+		return verdictUnknown, "", "no API server memory usage in RHOBS"
+	}
+
+	current := -1
+	for i, class := range classes {
+		if class.name == currentSize {
+			current = i
+		}
+	}
+	if current < 0 {
+		return verdictUnknown, "", fmt.Sprintf("size class %q is not in the ClusterSizingConfiguration", currentSize)
+	}
+
+	currentLimit := classes[current].kasMemoryLimitBytes
+	if currentLimit == 0 {
+		return verdictUnknown, "", fmt.Sprintf("size class %q has no kasGoMemLimit", currentSize)
+	}
+
+	target := -1
+	for i, class := range classes {
+		if class.kasMemoryLimitBytes > 0 && usage.KASMemoryP95Bytes <= class.kasMemoryLimitBytes*undersizedUtilization {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		target = len(classes) - 1
+	}
+
+	utilization := usage.KASMemoryP95Bytes / currentLimit
+	evidence := fmt.Sprintf("p95 API server memory %s is %.0f%% of the %s GOMEMLIMIT of size %s",
+		formatGiB(usage.KASMemoryP95Bytes), utilization*100, formatGiB(currentLimit), currentSize)
+
+	switch {
+	case target > current:
+		return verdictUndersized, classes[target].name, evidence
+	case target < current && utilization < oversizedUtilization:
+		return verdictOversized, classes[target].name, evidence
+	default:
+		return verdictRightSized, currentSize, evidence
+	}
+}
+
+// buildRecommendations returns the over- and under-sized clusters, counting the others
+func buildRecommendations(audit *auditResults, classes []sizeClass, usages map[string]*usageEvidence, window time.Duration) *recommendationResults {
+	results := &recommendationResults{
+		Timestamp:         audit.Timestamp,
+		ManagementCluster: audit.ManagementCluster,
+		Window:            window.String(),
+		TotalClusters:     len(audit.Clusters),
+		Recommendations:   []sizingRecommendation{},
+	}
+
+	classLimits := map[string]float64{}
+	for _, class := range classes {
+		classLimits[class.name] = class.kasMemoryLimitBytes
+	}
+
+	for _, cluster := range audit.Clusters {
+		// The control plane runs in the namespace of the HostedCluster suffixed with its name
+		usage := usageEvidence{}
+		if hcpUsage, ok := usages[cluster.Namespace+"-"+cluster.ClusterName]; ok {
+			usage = *hcpUsage
+		}
+		usage.KASMemoryLimitBytes = classLimits[cluster.CurrentSize]
+		if usage.KASMemoryLimitBytes > 0 {
+			usage.KASMemoryUtilization = usage.KASMemoryP95Bytes / usage.KASMemoryLimitBytes
+		}
+
+		verdict, recommendedSize, reason := recommendSize(classes, cluster.CurrentSize, usage)
+		switch verdict {
+		case verdictRightSized:
+			results.RightSizedClusters++
+			continue
+		case verdictUnknown:
+			results.UnknownClusters++
+			continue
+		}
+
+		results.Recommendations = append(results.Recommendations, sizingRecommendation{
+			ClusterID:             cluster.ClusterID,
+			ClusterName:           cluster.ClusterName,
+			Namespace:             cluster.Namespace,
+			AutoscalingEnabled:    cluster.AutoscalingEnabled,
+			CurrentSize:           cluster.CurrentSize,
+			HypershiftRecommended: cluster.RecommendedSize,
+			RecommendedSize:       recommendedSize,
+			Verdict:               verdict,
+			Reason:                reason,
+			Evidence:              usage,
+		})
+	}
+
+	sort.Slice(results.Recommendations, func(i, j int) bool {
+		return results.Recommendations[i].ClusterName < results.Recommendations[j].ClusterName
+	})
+
+	return results
+}
+
+func (o *options) recommendSizes(ctx context.Context, mgmtClient client.Client, mgmtClusterID string, audit *auditResults) error {
+	classes, err := getSizeClasses(ctx, mgmtClient)
+	if err != nil {
+		return err
+	}
+
+	fetcher, err := rhobs.CreateRhobsFetcher(ctx, mgmtClusterID, rhobs.RhobsFetchForMetrics, o.hiveOcmURL)
+	if err != nil {
+		return fmt.Errorf("failed to create RHOBS fetcher: %v", err)
+	}
+
+	usages, err := queryControlPlaneUsage(ctx, fetcher, mgmtClusterID, o.window)
+	if err != nil {
+		return err
+	}
+
+	return o.outputRecommendations(buildRecommendations(audit, classes, usages, o.window))
+}
+
+func (o *options) outputRecommendations(results *recommendationResults) error {
+	switch o.output {
+	case "text":
+		return o.printRecommendationsTable(results)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case "yaml":
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to YAML: %v", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	case "csv":
+		return o.printRecommendationsCSV(results)
+	default:
+		return fmt.Errorf("unsupported output format: %s", o.output)
+	}
+}
+
+func (o *options) printRecommendationsTable(results *recommendationResults) error {
+	fmt.Printf("\n=== Management Cluster: %s ===\n", results.ManagementCluster)
+	fmt.Printf("Timestamp: %s\n", results.Timestamp.Format(time.RFC3339))
+	fmt.Printf("Usage window: %s (95th percentile)\n", results.Window)
+	fmt.Printf("Total Hosted Clusters: %d (right-sized: %d, unknown: %d)\n\n", results.TotalClusters, results.RightSizedClusters, results.UnknownClusters)
+
+	if len(results.Recommendations) == 0 {
+		fmt.Println("No over- or under-sized hosted clusters found")
+		return nil
+	}
+
+	p := printer.NewTablePrinter(os.Stdout, 20, 1, 3, ' ')
+
+	if !o.noHeaders {
+		p.AddRow([]string{
+			"CLUSTER ID",
+			"CLUSTER NAME",
+			"VERDICT",
+			"CURRENT SIZE",
+			"RECOMMENDED SIZE",
+			"KAS MEMORY P95",
+			"KAS MEMORY LIMIT",
+			"KAS CPU P95",
+			"ETCD MEMORY P95",
+			"ETCD CPU P95",
+		})
+	}
+
+	for _, r := range results.Recommendations {
+		p.AddRow([]string{
+			r.ClusterID,
+			r.ClusterName,
+			string(r.Verdict),
+			r.CurrentSize,
+			r.RecommendedSize,
+			fmt.Sprintf("%s (%.0f%%)", formatGiB(r.Evidence.KASMemoryP95Bytes), r.Evidence.KASMemoryUtilization*100),
+			formatGiB(r.Evidence.KASMemoryLimitBytes),
+			fmt.Sprintf("%.2f", r.Evidence.KASCPUP95Cores),
+			formatGiB(r.Evidence.EtcdMemoryP95Bytes),
+			fmt.Sprintf("%.2f", r.Evidence.EtcdCPUP95Cores),
+		})
+	}
+
+	p.Flush()
+	fmt.Println()
+
+	return nil
+}
+
+func (o *options) printRecommendationsCSV(results *recommendationResults) error {
+	w := csv.NewWriter(os.Stdout)
+	defer w.Flush()
+
+	if !o.noHeaders {
+		if err := w.Write([]string{
+			"cluster_id",
+			"cluster_name",
+			"namespace",
+			"verdict",
+			"current_size",
+			"recommended_size",
+			"hypershift_recommended_size",
+			"kas_memory_p95_bytes",
+			"kas_memory_limit_bytes",
+			"kas_memory_utilization",
+			"kas_cpu_p95_cores",
+			"etcd_memory_p95_bytes",
+			"etcd_cpu_p95_cores",
+			"reason",
+		}); err != nil {
+			return fmt.Errorf("failed to write CSV header: %v", err)
+		}
+	}
+
+	for _, r := range results.Recommendations {
+		if err := w.Write([]string{
+			r.ClusterID,
+			r.ClusterName,
+			r.Namespace,
+			string(r.Verdict),
+			r.CurrentSize,
+			r.RecommendedSize,
+			r.HypershiftRecommended,
+			strconv.FormatFloat(r.Evidence.KASMemoryP95Bytes, 'f', 0, 64),
+			strconv.FormatFloat(r.Evidence.KASMemoryLimitBytes, 'f', 0, 64),
+			strconv.FormatFloat(r.Evidence.KASMemoryUtilization, 'f', 3, 64),
+			strconv.FormatFloat(r.Evidence.KASCPUP95Cores, 'f', 3, 64),
+			strconv.FormatFloat(r.Evidence.EtcdMemoryP95Bytes, 'f', 0, 64),
+			strconv.FormatFloat(r.Evidence.EtcdCPUP95Cores, 'f', 3, 64),
+			r.Reason,
+		}); err != nil {
+			return fmt.Errorf("failed to write CSV row: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func formatGiB(bytes float64) string {
+	return fmt.Sprintf("%.2fGiB", bytes/(1024*1024*1024))
+}
@@ -0,0 +1,278 @@
+package getcpautoscalingstatus
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	schedulingv1alpha1 "github.com/openshift/hypershift/api/scheduling/v1alpha1"
+	"github.com/openshift/osdctl/cmd/rhobs"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const gib = 1024 * 1024 * 1024
+
+var testSizeClasses = []sizeClass{
+	{name: "small", kasMemoryLimitBytes: 4 * gib},
+	{name: "medium", kasMemoryLimitBytes: 8 * gib},
+	{name: "large", kasMemoryLimitBytes: 16 * gib},
+}
+
+func TestRecommendSize(t *testing.T) {
+	tests := []struct {
+		name            string
+		classes         []sizeClass
+		currentSize     string
+		usage           usageEvidence
+		expectedVerdict sizingVerdict
+		expectedSize    string
+	}{
+		{
+			name:            "no metrics",
+			classes:         testSizeClasses,
+			currentSize:     "medium",
+			usage:           usageEvidence{},
+			expectedVerdict: verdictUnknown,
+		},
+		{
+			name:            "unknown size class",
+			classes:         testSizeClasses,
+			currentSize:     "N/A",
+			usage:           usageEvidence{KASMemoryP95Bytes: 1 * gib, hasKASMemory: true},
+			expectedVerdict: verdictUnknown,
+		},
+		{
+			name:            "size class without limit",
+			classes:         []sizeClass{{name: "small"}, {name: "large"}},
+			currentSize:     "small",
+			usage:           usageEvidence{KASMemoryP95Bytes: 1 * gib, hasKASMemory: true},
+			expectedVerdict: verdictUnknown,
+		},
+		{
+			name:            "right-sized",
+			classes:         testSizeClasses,
+			currentSize:     "medium",
+			usage:           usageEvidence{KASMemoryP95Bytes: 5 * gib, hasKASMemory: true},
+			expectedVerdict: verdictRightSized,
+			expectedSize:    "medium",
+		},
+		{
+			name:            "undersized",
+			classes:         testSizeClasses,
+			currentSize:     "small",
+			usage:           usageEvidence{KASMemoryP95Bytes: 7 * gib, hasKASMemory: true},
+			expectedVerdict: verdictUndersized,
+			expectedSize:    "large",
+		},
+		{
+			name:            "undersized in the largest class",
+			classes:         testSizeClasses,
+			currentSize:     "large",
+			usage:           usageEvidence{KASMemoryP95Bytes: 15 * gib, hasKASMemory: true},
+			expectedVerdict: verdictRightSized,
+			expectedSize:    "large",
+		},
+		{
+			name:            "oversized",
+			classes:         testSizeClasses,
+			currentSize:     "large",
+			usage:           usageEvidence{KASMemoryP95Bytes: 2 * gib, hasKASMemory: true},
+			expectedVerdict: verdictOversized,
+			expectedSize:    "small",
+		},
+		{
+			name:            "low usage without a fitting smaller class",
+			classes:         testSizeClasses,
+			currentSize:     "medium",
+			usage:           usageEvidence{KASMemoryP95Bytes: 3.5 * gib, hasKASMemory: true},
+			expectedVerdict: verdictRightSized,
+			expectedSize:    "medium",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, size, reason := recommendSize(tt.classes, tt.currentSize, tt.usage)
+			if verdict != tt.expectedVerdict {
+				t.Errorf("expected verdict %s, got %s (%s)", tt.expectedVerdict, verdict, reason)
+			}
+			if size != tt.expectedSize {
+				t.Errorf("expected size %q, got %q", tt.expectedSize, size)
+			}
+			if reason == "" {
+				t.Errorf("expected a reason")
+			}
+		})
+	}
+}
+
+type fakeMetricsQuerier struct {
+	samples map[string][]rhobs.MetricSample // By container
+	queries []string
+}
+
+func (q *fakeMetricsQuerier) QueryInstantMetricSamples(_ context.Context, promExpr string, _ time.Time) ([]rhobs.MetricSample, error) {
+	q.queries = append(q.queries, promExpr)
+	for container, samples := range q.samples {
+		if strings.Contains(promExpr, `container="`+container+`"`) && strings.Contains(promExpr, "memory") {
+			return samples, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestQueryControlPlaneUsage(t *testing.T) {
+	querier := &fakeMetricsQuerier{samples: map[string][]rhobs.MetricSample{
+		containerKubeAPIServer: {
+			{Labels: map[string]string{"namespace": "ocm-production-001-cluster-a"}, Value: 2 * gib},
+			{Labels: map[string]string{}, Value: 1},
+		},
+		containerEtcd: {
+			{Labels: map[string]string{"namespace": "ocm-production-001-cluster-a"}, Value: 1 * gib},
+		},
+	}}
+
+	usages, err := queryControlPlaneUsage(context.Background(), querier, "mc-id", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(querier.queries) != 4 {
+		t.Fatalf("expected 4 queries, got %d", len(querier.queries))
+	}
+	if !strings.HasPrefix(querier.queries[0], "quantile_over_time(0.95, (") || !strings.HasSuffix(querier.queries[0], ")[86400s:300s])") {
+		t.Errorf("unexpected query: %s", querier.queries[0])
+	}
+
+	for _, query := range querier.queries {
+		if !strings.Contains(query, `_mc_id="mc-id"`) || !strings.Contains(query, "by (_mc_id, namespace)") {
+			t.Errorf("query is not scoped to the management cluster: %s", query)
+		}
+	}
+
+	if len(usages) != 1 {
+		t.Fatalf("expected usage of 1 namespace, got %d", len(usages))
+	}
+	usage := usages["ocm-production-001-cluster-a"]
+	if usage == nil || !usage.hasKASMemory || usage.KASMemoryP95Bytes != 2*gib || usage.EtcdMemoryP95Bytes != 1*gib {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestUsageExpr(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{
+			name:     "memory",
+			expr:     memoryUsageExpr("mc-id", containerKubeAPIServer),
+			expected: `max by (_mc_id, namespace) (container_memory_working_set_bytes{_mc_id="mc-id", namespace=~"ocm-(production|staging)-.+", container="kube-apiserver"})`,
+		},
+		{
+			name:     "cpu",
+			expr:     cpuUsageExpr("mc-id", containerEtcd),
+			expected: `max by (_mc_id, namespace) (rate(container_cpu_usage_seconds_total{_mc_id="mc-id", namespace=~"ocm-(production|staging)-.+", container="etcd"}[5m]))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expr != tt.expected {
+				t.Errorf("expr = %s, want %s", tt.expr, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildRecommendations(t *testing.T) {
+	audit := &auditResults{
+		Timestamp:         time.Now(),
+		ManagementCluster: "test-mc",
+		Clusters: []clusterInfo{
+			{ClusterID: "001", ClusterName: "cluster-a", Namespace: "ocm-production-001", CurrentSize: "small"},
+			{ClusterID: "002", ClusterName: "cluster-b", Namespace: "ocm-production-002", CurrentSize: "large"},
+			{ClusterID: "003", ClusterName: "cluster-c", Namespace: "ocm-production-003", CurrentSize: "medium"},
+			{ClusterID: "004", ClusterName: "cluster-d", Namespace: "ocm-production-004", CurrentSize: "medium"},
+		},
+	}
+	usages := map[string]*usageEvidence{
+		"ocm-production-001-cluster-a": {KASMemoryP95Bytes: 3.8 * gib, hasKASMemory: true},
+		"ocm-production-002-cluster-b": {KASMemoryP95Bytes: 1 * gib, hasKASMemory: true},
+		"ocm-production-003-cluster-c": {KASMemoryP95Bytes: 5 * gib, hasKASMemory: true},
+	}
+
+	results := buildRecommendations(audit, testSizeClasses, usages, 24*time.Hour)
+
+	if results.TotalClusters != 4 || results.RightSizedClusters != 1 || results.UnknownClusters != 1 {
+		t.Errorf("unexpected counts: total %d, right-sized %d, unknown %d", results.TotalClusters, results.RightSizedClusters, results.UnknownClusters)
+	}
+	if len(results.Recommendations) != 2 {
+		t.Fatalf("expected 2 recommendations, got %d", len(results.Recommendations))
+	}
+
+	undersized := results.Recommendations[0]
+	if undersized.ClusterID != "001" || undersized.Verdict != verdictUndersized || undersized.RecommendedSize != "medium" {
+		t.Errorf("unexpected recommendation: %+v", undersized)
+	}
+	if undersized.Evidence.KASMemoryLimitBytes != 4*gib || undersized.Evidence.KASMemoryUtilization != 0.95 {
+		t.Errorf("unexpected evidence: %+v", undersized.Evidence)
+	}
+
+	oversized := results.Recommendations[1]
+	if oversized.ClusterID != "002" || oversized.Verdict != verdictOversized || oversized.RecommendedSize != "small" {
+		t.Errorf("unexpected recommendation: %+v", oversized)
+	}
+}
+
+func TestGetSizeClasses(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := schedulingv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheduling scheme: %v", err)
+	}
+
+	to := uint32(10)
+	kasGoMemLimit := resource.MustParse("8Gi")
+	csc := &schedulingv1alpha1.ClusterSizingConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: schedulingv1alpha1.ClusterSizingConfigurationSpec{
+			Sizes: []schedulingv1alpha1.SizeConfiguration{
+				{
+					Name:     "large",
+					Criteria: schedulingv1alpha1.NodeCountCriteria{From: 11},
+					Effects:  &schedulingv1alpha1.Effects{KASGoMemLimit: &kasGoMemLimit},
+				},
+				{
+					Name:     "small",
+					Criteria: schedulingv1alpha1.NodeCountCriteria{From: 0, To: &to},
+				},
+			},
+		},
+	}
+
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(csc).Build()
+	classes, err := getSizeClasses(context.Background(), kubeClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []sizeClass{{name: "small"}, {name: "large", kasMemoryLimitBytes: 8 * gib}}
+	if len(classes) != len(expected) {
+		t.Fatalf("expected %d classes, got %d", len(expected), len(classes))
+	}
+	for i := range expected {
+		if classes[i] != expected[i] {
+			t.Errorf("expected class %+v, got %+v", expected[i], classes[i])
+		}
+	}
+
+	_, err = getSizeClasses(context.Background(), fake.NewClientBuilder().WithScheme(scheme).Build())
+	if err == nil {
+		t.Errorf("expected an error without ClusterSizingConfiguration")
+	}
+}
@@ -0,0 +1,18 @@
+package journal
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCmdJournal creates and returns the journal command
+func NewCmdJournal() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Act on the journals of the changes made by force-upgrade and transition-to-eus",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newCmdRestore())
+
+	return cmd
+}
@@ -0,0 +1,193 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/osdctl/internal/journal"
+	ocmutils "github.com/openshift/osdctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// restoreOptions contains all options for the journal restore command
+type restoreOptions struct {
+	journalFile string
+	clusterID   string
+	dryRun      bool
+}
+
+func newCmdRestore() *cobra.Command {
+	opts := &restoreOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "restore <journal-file>",
+		Short: "Recreate the upgrade policies deleted and not restored according to a journal",
+		Long: `Recreate the control plane upgrade policies which were deleted by force-upgrade or transition-to-eus,
+and not restored afterwards, according to the journal of the command.
+
+The policies are recreated with their original schedule, schedule type, upgrade type and minor version upgrade
+setting. The restored policies are recorded to the same journal, so that running the command again only restores
+the policies which are still missing. A recurring policy is not restored on a cluster which already has one.`,
+		Example: `  # Show the policies which would be restored
+  osdctl hcp journal restore transition-to-eus-journal-20250101_120000.jsonl --dry-run
+
+  # Restore the policies deleted from a single cluster
+  osdctl hcp journal restore force-upgrade-journal-20250101_120000.jsonl --cluster-id cluster123`,
+		Args:              cobra.ExactArgs(1),
+		DisableAutoGenTag: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.journalFile = args[0]
+			return opts.run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.clusterID, "cluster-id", "C", "", "Only restore the policies of the cluster with this internal or external ID")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show the policies to restore without making any changes")
+
+	return cmd
+}
+
+func (o *restoreOptions) run() error {
+	entries, err := journal.Read(o.journalFile)
+	if err != nil {
+		return err
+	}
+
+	deletedPolicies, err := journal.UnrestoredPolicies(entries)
+	if err != nil {
+		return err
+	}
+	deletedPolicies = filterByCluster(deletedPolicies, o.clusterID)
+
+	if len(deletedPolicies) == 0 {
+		fmt.Println("No deleted upgrade policy left to restore.")
+		return nil
+	}
+
+	printDeletedPolicies(deletedPolicies)
+
+	if o.dryRun {
+		fmt.Printf("\n🔍 DRY-RUN: Would restore %d upgrade policies\n", len(deletedPolicies))
+		return nil
+	}
+
+	if !ocmutils.ConfirmPrompt() {
+		fmt.Println("Restore operation cancelled.")
+		return nil
+	}
+
+	ocmClient, err := ocmutils.CreateConnection()
+	if err != nil {
+		return fmt.Errorf("failed to create OCM connection: %w", err)
+	}
+	defer ocmClient.Close()
+
+	restoreJournal, err := journal.Open(o.journalFile, "journal-restore")
+	if err != nil {
+		return err
+	}
+	defer restoreJournal.Close()
+
+	var failed []string
+	for idx, deletedPolicy := range deletedPolicies {
+		fmt.Printf("\n[%d/%d] Restoring upgrade policy %s of cluster %s\n", idx+1, len(deletedPolicies), deletedPolicy.Policy.ID(), deletedPolicy.ClusterID)
+		if err := restorePolicy(ocmClient, restoreJournal, deletedPolicy); err != nil {
+			fmt.Printf("  ⚠️  Failed to restore upgrade policy: %v\n", err)
+			failed = append(failed, fmt.Sprintf("%s (%s): %v", deletedPolicy.Policy.ID(), deletedPolicy.ClusterID, err))
+		}
+	}
+
+	fmt.Printf("\nRestored %d of %d upgrade policies\n", len(deletedPolicies)-len(failed), len(deletedPolicies))
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to restore %d upgrade policies:\n  - %s", len(failed), strings.Join(failed, "\n  - "))
+	}
+
+	return nil
+}
+
+func restorePolicy(ocmClient *sdk.Connection, restoreJournal *journal.Journal, deletedPolicy journal.DeletedPolicy) error {
+	clusterResponse, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(deletedPolicy.ClusterID).Get().Send()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster: %w", err)
+	}
+	cluster := clusterResponse.Body()
+
+	policiesResponse, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+		ControlPlane().UpgradePolicies().List().Send()
+	if err != nil {
+		return fmt.Errorf("failed to list upgrade policies: %w", err)
+	}
+	if deletedPolicy.Policy.ScheduleType() == v1.ScheduleTypeAutomatic && hasAutomaticPolicy(policiesResponse.Items().Slice()) {
+		return fmt.Errorf("cluster already has a recurring upgrade policy")
+	}
+
+	newPolicy, err := journal.NewRestoredPolicy(deletedPolicy.Policy)
+	if err != nil {
+		return fmt.Errorf("failed to build upgrade policy: %w", err)
+	}
+
+	response, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
+		ControlPlane().UpgradePolicies().Add().Body(newPolicy).Send()
+	if err != nil {
+		restoreJournal.RecordPolicy(cluster, journal.ActionRestoreUpgradePolicy, newPolicy, deletedPolicy.Policy.ID(), err)
+		return fmt.Errorf("failed to create upgrade policy: %w", err)
+	}
+	restoreJournal.RecordPolicy(cluster, journal.ActionRestoreUpgradePolicy, response.Body(), deletedPolicy.Policy.ID(), nil)
+
+	fmt.Printf("  ✅ Restored upgrade policy (new ID: %s)\n", response.Body().ID())
+	return nil
+}
+
+// filterByCluster returns the deleted policies of the cluster with the given internal or external ID, all of them if
+// the ID is empty.
+func filterByCluster(deletedPolicies []journal.DeletedPolicy, clusterID string) []journal.DeletedPolicy {
+	if clusterID == "" {
+		return deletedPolicies
+	}
+
+	var filtered []journal.DeletedPolicy
+	for _, deletedPolicy := range deletedPolicies {
+		if deletedPolicy.ClusterID == clusterID || deletedPolicy.ExternalID == clusterID {
+			filtered = append(filtered, deletedPolicy)
+		}
+	}
+
+	return filtered
+}
+
+// hasAutomaticPolicy tells whether one of the policies is recurring, OCM allowing a single one per cluster
+func hasAutomaticPolicy(policies []*v1.ControlPlaneUpgradePolicy) bool {
+	for _, policy := range policies {
+		if policy.ScheduleType() == v1.ScheduleTypeAutomatic {
+			return true
+		}
+	}
+
+	return false
+}
+
+func printDeletedPolicies(deletedPolicies []journal.DeletedPolicy) {
+	fmt.Printf("Found %d deleted upgrade policies to restore:\n\n", len(deletedPolicies))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "CLUSTER\tPOLICY\tSCHEDULE TYPE\tSCHEDULE\tVERSION\tDELETED AT\tDELETED BY\n")
+	for _, deletedPolicy := range deletedPolicies {
+		policy := deletedPolicy.Policy
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", deletedPolicy.ClusterID, policy.ID(), policy.ScheduleType(),
+			valueOrDash(policy.Schedule()), valueOrDash(policy.Version()), deletedPolicy.DeletedAt.Format(time.RFC3339), deletedPolicy.Operation)
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
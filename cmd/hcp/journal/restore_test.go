@@ -0,0 +1,50 @@
+package journal
+
+import (
+	"testing"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/openshift/osdctl/internal/journal"
+)
+
+func TestFilterByCluster(t *testing.T) {
+	policy, err := v1.NewControlPlaneUpgradePolicy().ID("policy-1").Build()
+	if err != nil {
+		t.Fatalf("failed to build policy: %v", err)
+	}
+	deletedPolicies := []journal.DeletedPolicy{
+		{ClusterID: "cluster-1", ExternalID: "ext-1", Policy: policy},
+		{ClusterID: "cluster-2", ExternalID: "ext-2", Policy: policy},
+	}
+
+	tests := []struct {
+		name      string
+		clusterID string
+		expected  int
+	}{
+		{name: "no filter", clusterID: "", expected: 2},
+		{name: "internal ID", clusterID: "cluster-1", expected: 1},
+		{name: "external ID", clusterID: "ext-2", expected: 1},
+		{name: "unknown cluster", clusterID: "cluster-3", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if filtered := filterByCluster(deletedPolicies, tt.clusterID); len(filtered) != tt.expected {
+				t.Errorf("expected %d policies, got %d", tt.expected, len(filtered))
+			}
+		})
+	}
+}
+
+func TestHasAutomaticPolicy(t *testing.T) {
+	automatic, _ := v1.NewControlPlaneUpgradePolicy().ScheduleType(v1.ScheduleTypeAutomatic).Build()
+	manual, _ := v1.NewControlPlaneUpgradePolicy().ScheduleType(v1.ScheduleTypeManual).Build()
+
+	if hasAutomaticPolicy([]*v1.ControlPlaneUpgradePolicy{manual}) {
+		t.Error("expected no automatic policy")
+	}
+	if !hasAutomaticPolicy([]*v1.ControlPlaneUpgradePolicy{manual, automatic}) {
+		t.Error("expected an automatic policy")
+	}
+}
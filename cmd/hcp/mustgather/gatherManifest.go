@@ -0,0 +1,260 @@
+package mustgather
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const gatherManifestFileName = "must-gather-manifest.json"
+
+type gatherStatus string
+
+const (
+	gatherStatusCompleted gatherStatus = "completed"
+	gatherStatusFailed    gatherStatus = "failed"
+)
+
+// gatherManifestTask describes what a gather task collected, or why it failed
+type gatherManifestTask struct {
+	Target    string       `json:"target"`
+	Name      string       `json:"name"`
+	Command   string       `json:"command"`
+	Dir       string       `json:"dir"` // Relative to the output directory
+	Status    gatherStatus `json:"status"`
+	Error     string       `json:"error,omitempty"`
+	Duration  string       `json:"duration"`
+	Files     int          `json:"files"`
+	SizeBytes int64        `json:"sizeBytes"`
+}
+
+// droppedFile is a log file removed to fit the size budget
+type droppedFile struct {
+	File      string `json:"file"` // Relative to the output directory
+	SizeBytes int64  `json:"sizeBytes"`
+}
+
+// gatherManifest describes the content of a must-gather, written next to the gathered data before it is archived
+type gatherManifest struct {
+	ClusterID      string                `json:"clusterId"`
+	StartedAt      time.Time             `json:"startedAt"`
+	CompletedAt    time.Time             `json:"completedAt"`
+	Targets        []string              `json:"targets"`
+	Namespaces     []string              `json:"namespaces,omitempty"`
+	Resources      []string              `json:"resources,omitempty"`
+	Since          string                `json:"since,omitempty"`
+	MaxSizeBytes   int64                 `json:"maxSizeBytes,omitempty"`
+	TotalSizeBytes int64                 `json:"totalSizeBytes"`
+	Tasks          []*gatherManifestTask `json:"tasks"`
+	DroppedFiles   []droppedFile         `json:"droppedFiles,omitempty"`
+}
+
+// failedTasks returns the tasks which failed
+func (m *gatherManifest) failedTasks() []*gatherManifestTask {
+	var failed []*gatherManifestTask
+	for _, task := range m.Tasks {
+		if task.Status == gatherStatusFailed {
+			failed = append(failed, task)
+		}
+	}
+	return failed
+}
+
+// write stores the manifest in outputDir
+func (m *gatherManifest) write(outputDir string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, gatherManifestFileName), content, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+
+	return nil
+}
+
+// newGatherManifestTask returns the manifest entry of a finished task, with the files it collected in outputDir
+func newGatherManifestTask(outputDir string, task *gatherTask, duration time.Duration, taskErr error) *gatherManifestTask {
+	manifestTask := &gatherManifestTask{
+		Target:   task.target,
+		Name:     task.name,
+		Command:  task.command(),
+		Dir:      relativePath(outputDir, task.destDir),
+		Status:   gatherStatusCompleted,
+		Duration: duration.Round(time.Second).String(),
+	}
+	if taskErr != nil {
+		manifestTask.Status = gatherStatusFailed
+		manifestTask.Error = taskErr.Error()
+	}
+
+	// The hcp tasks share their directory, which is then reported by both
+	files, err := listFiles(task.destDir)
+	if err == nil {
+		manifestTask.Files = len(files)
+		for _, file := range files {
+			manifestTask.SizeBytes += file.size
+		}
+	}
+
+	return manifestTask
+}
+
+// gatheredFile is a file of the output directory
+type gatheredFile struct {
+	path string
+	size int64
+}
+
+// listFiles returns the regular files under dir, none if dir does not exist
+func listFiles(dir string) ([]gatheredFile, error) {
+	var files []gatheredFile
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, gatheredFile{path: path, size: info.Size()})
+		return nil
+	})
+
+	return files, err
+}
+
+// isLogFile tells whether a gathered file holds logs, which may be dropped to fit the size budget
+func isLogFile(path string) bool {
+	return strings.HasSuffix(path, ".log") || strings.HasSuffix(path, ".log.gz")
+}
+
+// previousLogFileNames are the names of the files in which oc adm must-gather and inspect save the logs of the
+// previous container of a pod
+var previousLogFileNames = map[string]bool{
+	"previous.log":          true,
+	"previous.insecure.log": true,
+}
+
+// isPreviousLogFile tells whether a log file holds the logs of a previous container, dropped before the current logs
+func isPreviousLogFile(path string) bool {
+	return previousLogFileNames[strings.TrimSuffix(filepath.Base(path), ".gz")]
+}
+
+// lastLogTimestampReadSize is how much of the end of a log file is read to find its last log line
+const lastLogTimestampReadSize = 64 * 1024
+
+// lastLogTimestamp returns the timestamp of the last line of a log file, oc adm must-gather and inspect prefixing
+// every log line with its RFC 3339 timestamp. It returns false when the file is compressed or its last line has no
+// timestamp, e.g. for the logs gathered from Dynatrace.
+func lastLogTimestamp(path string) (time.Time, bool) {
+	if strings.HasSuffix(path, ".gz") {
+		return time.Time{}, false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return time.Time{}, false
+	}
+	offset := max(info.Size()-lastLogTimestampReadSize, 0)
+	buf := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(buf, offset); err != nil {
+		return time.Time{}, false
+	}
+
+	lines := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+	timestamp, _, _ := strings.Cut(lines[len(lines)-1], " ")
+	logTime, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return logTime, true
+}
+
+// enforceSizeBudget deletes log files from dir until its total size fits maxSizeBytes: logs of previous containers
+// first, then the logs whose last line is the oldest, then the logs without timestamps. The other files are always
+// kept, as they are small and describe the cluster state. It returns the size of dir and the dropped files.
+func enforceSizeBudget(dir string, maxSizeBytes int64) (int64, []droppedFile, error) {
+	files, err := listFiles(dir)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list gathered files: %v", err)
+	}
+
+	var totalSize int64
+	var logFiles []gatheredFile
+	for _, file := range files {
+		totalSize += file.size
+		if isLogFile(file.path) {
+			logFiles = append(logFiles, file)
+		}
+	}
+	if maxSizeBytes <= 0 || totalSize <= maxSizeBytes {
+		return totalSize, nil, nil
+	}
+
+	type logRecency struct {
+		previous    bool
+		lastLogTime time.Time
+		hasLogTime  bool
+	}
+	recencies := map[string]logRecency{}
+	for _, file := range logFiles {
+		lastLogTime, hasLogTime := lastLogTimestamp(file.path)
+		recencies[file.path] = logRecency{previous: isPreviousLogFile(file.path), lastLogTime: lastLogTime, hasLogTime: hasLogTime}
+	}
+
+	sort.Slice(logFiles, func(i, j int) bool {
+		recencyI, recencyJ := recencies[logFiles[i].path], recencies[logFiles[j].path]
+		if recencyI.previous != recencyJ.previous {
+			return recencyI.previous
+		}
+		if recencyI.hasLogTime != recencyJ.hasLogTime {
+			return recencyI.hasLogTime
+		}
+		if !recencyI.lastLogTime.Equal(recencyJ.lastLogTime) {
+			return recencyI.lastLogTime.Before(recencyJ.lastLogTime)
+		}
+		return logFiles[i].path < logFiles[j].path
+	})
+
+	var dropped []droppedFile
+	for _, file := range logFiles {
+		if totalSize <= maxSizeBytes {
+			break
+		}
+		if err := os.Remove(file.path); err != nil {
+			return totalSize, dropped, fmt.Errorf("failed to drop log file: %v", err)
+		}
+		totalSize -= file.size
+		dropped = append(dropped, droppedFile{File: relativePath(dir, file.path), SizeBytes: file.size})
+	}
+
+	return totalSize, dropped, nil
+}
+
+func relativePath(baseDir string, path string) string {
+	relPath, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return path
+	}
+	return relPath
+}
@@ -0,0 +1,148 @@
+package mustgather
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeGatheredFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+// logLines returns size bytes of logs, the last line being logged at lastLogTime
+func logLines(size int, lastLogTime time.Time) string {
+	lastLine := lastLogTime.Format(time.RFC3339Nano) + " last\n"
+	return strings.Repeat("x", size-len(lastLine)-1) + "\n" + lastLine
+}
+
+func TestEnforceSizeBudget(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeGatheredFile(t, dir, "mc_infra/pods.yaml", strings.Repeat("x", 100))
+	writeGatheredFile(t, dir, "mc_infra/pods/etcd-0/etcd/etcd/logs/current.log", logLines(100, now.Add(-2*time.Hour)))
+	writeGatheredFile(t, dir, "mc_infra/pods/kas-0/kas/kas/logs/current.log", logLines(100, now.Add(-1*time.Hour)))
+	writeGatheredFile(t, dir, "mc_infra/pods/kas-0/kas/kas/logs/previous.log", logLines(100, now.Add(-3*time.Hour)))
+	writeGatheredFile(t, dir, "hcp/pods/kas-0/pod.log", strings.Repeat("x", 100))
+
+	totalSize, dropped, err := enforceSizeBudget(dir, 250)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), totalSize)
+	assert.Equal(t, []droppedFile{
+		{File: "mc_infra/pods/kas-0/kas/kas/logs/previous.log", SizeBytes: 100},
+		{File: "mc_infra/pods/etcd-0/etcd/etcd/logs/current.log", SizeBytes: 100},
+		{File: "mc_infra/pods/kas-0/kas/kas/logs/current.log", SizeBytes: 100},
+	}, dropped)
+
+	assert.FileExists(t, filepath.Join(dir, "mc_infra/pods.yaml"))
+	assert.FileExists(t, filepath.Join(dir, "hcp/pods/kas-0/pod.log"))
+}
+
+func TestIsPreviousLogFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{path: "pods/kas-0/kas/kas/logs/previous.log", expected: true},
+		{path: "pods/kas-0/kas/kas/logs/previous.insecure.log", expected: true},
+		{path: "pods/kas-0/kas/kas/logs/current.log", expected: false},
+		{path: "pods/previous-version-checker/checker/checker/logs/current.log", expected: false},
+		{path: "hcp/pods/previous-pod/pod.log", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isPreviousLogFile(tt.path))
+		})
+	}
+}
+
+func TestLastLogTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	lastLogTime := time.Date(2026, 3, 20, 12, 0, 0, 123456789, time.UTC)
+	writeGatheredFile(t, dir, "current.log", logLines(200, lastLogTime))
+	writeGatheredFile(t, dir, "pod.log", "no timestamp\n")
+
+	logTime, ok := lastLogTimestamp(filepath.Join(dir, "current.log"))
+	assert.True(t, ok)
+	assert.True(t, lastLogTime.Equal(logTime))
+
+	_, ok = lastLogTimestamp(filepath.Join(dir, "pod.log"))
+	assert.False(t, ok)
+	_, ok = lastLogTimestamp(filepath.Join(dir, "missing.log"))
+	assert.False(t, ok)
+}
+
+func TestEnforceSizeBudget_KeepsNonLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeGatheredFile(t, dir, "pods.yaml", strings.Repeat("x", 100))
+	writeGatheredFile(t, dir, "pod.log", strings.Repeat("x", 100))
+
+	totalSize, dropped, err := enforceSizeBudget(dir, 50)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), totalSize)
+	assert.Len(t, dropped, 1)
+	assert.FileExists(t, filepath.Join(dir, "pods.yaml"))
+}
+
+func TestEnforceSizeBudget_NoBudget(t *testing.T) {
+	dir := t.TempDir()
+	writeGatheredFile(t, dir, "pod.log", strings.Repeat("x", 100))
+
+	totalSize, dropped, err := enforceSizeBudget(dir, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), totalSize)
+	assert.Empty(t, dropped)
+}
+
+func TestRunGatherTasks(t *testing.T) {
+	dir := t.TempDir()
+	tasks := []*gatherTask{
+		{target: "mc", name: "mc", destDir: filepath.Join(dir, "mc_infra"), cluster: clusterMC, ocArgs: []string{"adm", "must-gather"}},
+		{target: "sc", name: "sc", destDir: filepath.Join(dir, "sc_infra"), cluster: clusterSC, ocArgs: []string{"adm", "must-gather"}},
+		{target: "hcp", name: "hcp_dump", destDir: filepath.Join(dir, "hcp"), cluster: clusterMC, planErr: errors.New("no HCP namespace")},
+	}
+
+	manifestTasks := runGatherTasks(dir, tasks, 2, func(task *gatherTask) error {
+		if task.name == "sc" {
+			return errors.New("boom")
+		}
+		writeGatheredFile(t, task.destDir, "pods.yaml", strings.Repeat("x", 10))
+		return nil
+	})
+	require.Len(t, manifestTasks, 3)
+
+	assert.Equal(t, gatherStatusCompleted, manifestTasks[0].Status)
+	assert.Equal(t, "mc_infra", manifestTasks[0].Dir)
+	assert.Equal(t, 1, manifestTasks[0].Files)
+	assert.Equal(t, int64(10), manifestTasks[0].SizeBytes)
+	assert.Equal(t, "oc adm must-gather", manifestTasks[0].Command)
+
+	assert.Equal(t, gatherStatusFailed, manifestTasks[1].Status)
+	assert.Equal(t, "boom", manifestTasks[1].Error)
+	assert.Zero(t, manifestTasks[1].Files)
+
+	assert.Equal(t, gatherStatusFailed, manifestTasks[2].Status)
+	assert.Equal(t, "no HCP namespace", manifestTasks[2].Error)
+
+	manifest := &gatherManifest{ClusterID: "abc", Targets: []string{"mc", "sc", "hcp"}, Tasks: manifestTasks}
+	assert.Len(t, manifest.failedTasks(), 2)
+
+	require.NoError(t, manifest.write(dir))
+	content, err := os.ReadFile(filepath.Join(dir, gatherManifestFileName))
+	require.NoError(t, err)
+	written := &gatherManifest{}
+	require.NoError(t, json.Unmarshal(content, written))
+	assert.Equal(t, "abc", written.ClusterID)
+	assert.Len(t, written.Tasks, 3)
+}
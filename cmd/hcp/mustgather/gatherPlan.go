@@ -0,0 +1,205 @@
+package mustgather
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Gather targets accepted by --gather
+const (
+	targetSC    = "sc"
+	targetSCACM = "sc_acm"
+	targetMC    = "mc"
+	targetHCP   = "hcp"
+)
+
+// Clusters the oc commands of the gather tasks run against
+const (
+	clusterMC = "mc"
+	clusterSC = "sc"
+)
+
+// defaultHCPLogsSince is the period of the HCP Dynatrace logs gathered when --since is not set
+const defaultHCPLogsSince = 72 * time.Hour
+
+// gatherSelectors narrows down what is gathered from the targets
+type gatherSelectors struct {
+	namespaces []string      // Namespaces inspected on the mc and sc targets instead of a full must-gather
+	resources  []string      // Resource kinds inspected on the mc and sc targets instead of a full must-gather
+	since      time.Duration // Only gather logs newer than this, all logs if zero
+}
+
+// isSelective tells whether the mc and sc targets are inspected rather than fully gathered
+func (s gatherSelectors) isSelective() bool {
+	return len(s.namespaces) > 0 || len(s.resources) > 0
+}
+
+// hostedClusterRef identifies the HostedCluster gathered by the ACM must-gather of the hcp target
+type hostedClusterRef struct {
+	namespace string
+	name      string
+}
+
+// gatherTask is a single gathering, run concurrently with the others
+type gatherTask struct {
+	target  string   // Gather target the task belongs to
+	name    string   // Unique name of the task, reported in the progress and the manifest
+	destDir string   // Directory the task writes to
+	cluster string   // Cluster the oc command runs against, empty for the HCP Dynatrace logs
+	ocArgs  []string // Arguments of the oc command, the kubeconfig being added when run
+
+	// Error preventing the task from running, reported as its failure
+	planErr error
+}
+
+// command returns the command run by the task, as shown in the manifest
+func (t *gatherTask) command() string {
+	if t.cluster == "" {
+		return "dynatrace logs"
+	}
+	return "oc " + strings.Join(t.ocArgs, " ")
+}
+
+// parseGatherTargets splits the comma-separated --gather value, rejecting unknown and duplicate targets
+func parseGatherTargets(gatherTargets string) ([]string, error) {
+	var targets []string
+	seen := map[string]bool{}
+	for _, target := range strings.Split(gatherTargets, ",") {
+		target = strings.TrimSpace(target)
+		switch target {
+		case targetSC, targetSCACM, targetMC, targetHCP:
+		default:
+			return nil, fmt.Errorf("unknown gather target '%s' (available: %s, %s, %s, %s)", target, targetSC, targetSCACM, targetMC, targetHCP)
+		}
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// planGatherTasks returns the tasks gathering the targets into outputDir. The ACM must-gather of the hcp target
+// requires the HostedCluster, it is planned as failed with hostedClusterErr when the HostedCluster is unknown.
+func planGatherTasks(targets []string, selectors gatherSelectors, outputDir string, acmImage string, hostedCluster *hostedClusterRef, hostedClusterErr error) []*gatherTask {
+	var tasks []*gatherTask
+	for _, target := range targets {
+		switch target {
+		case targetSC:
+			tasks = append(tasks, planInfraTasks(target, clusterSC, filepath.Join(outputDir, "sc_infra"), selectors)...)
+		case targetMC:
+			tasks = append(tasks, planInfraTasks(target, clusterMC, filepath.Join(outputDir, "mc_infra"), selectors)...)
+		case targetSCACM:
+			destDir := filepath.Join(outputDir, "sc_acm")
+			tasks = append(tasks, &gatherTask{
+				target:  target,
+				name:    target,
+				destDir: destDir,
+				cluster: clusterSC,
+				ocArgs:  mustGatherArgs(destDir, selectors.since, "--image="+acmImage),
+			})
+		case targetHCP:
+			destDir := filepath.Join(outputDir, "hcp")
+			tasks = append(tasks, &gatherTask{
+				target:  target,
+				name:    "hcp_logs",
+				destDir: destDir,
+			})
+
+			// ACM must-gather which includes running the hypershift binary for a dump
+			acmTask := &gatherTask{
+				target:  target,
+				name:    "hcp_dump",
+				destDir: destDir,
+				cluster: clusterMC,
+				planErr: hostedClusterErr,
+			}
+			if hostedCluster != nil {
+				gatherScript := fmt.Sprintf("/usr/bin/gather hosted-cluster-namespace=%s hosted-cluster-name=%s", hostedCluster.namespace, hostedCluster.name)
+				acmTask.ocArgs = mustGatherArgs(destDir, selectors.since, "--image="+acmImage, gatherScript)
+			} else if acmTask.planErr == nil {
+				acmTask.planErr = fmt.Errorf("unknown HostedCluster")
+			}
+			tasks = append(tasks, acmTask)
+		}
+	}
+
+	return tasks
+}
+
+// planInfraTasks returns the tasks gathering the infrastructure of a service or management cluster: a full
+// must-gather, or an inspection of the selected namespaces and resources, one task per namespace.
+func planInfraTasks(target string, cluster string, destDir string, selectors gatherSelectors) []*gatherTask {
+	if !selectors.isSelective() {
+		return []*gatherTask{{
+			target:  target,
+			name:    target,
+			destDir: destDir,
+			cluster: cluster,
+			ocArgs:  mustGatherArgs(destDir, selectors.since),
+		}}
+	}
+
+	resources := strings.Join(selectors.resources, ",")
+	if len(selectors.namespaces) == 0 {
+		return []*gatherTask{{
+			target:  target,
+			name:    target,
+			destDir: destDir,
+			cluster: cluster,
+			ocArgs:  inspectArgs(destDir, selectors.since, resources, "--all-namespaces"),
+		}}
+	}
+
+	tasks := make([]*gatherTask, 0, len(selectors.namespaces))
+	for _, namespace := range selectors.namespaces {
+		nsDestDir := filepath.Join(destDir, namespace)
+
+		var args []string
+		if resources == "" {
+			args = inspectArgs(nsDestDir, selectors.since, "ns/"+namespace)
+		} else {
+			args = inspectArgs(nsDestDir, selectors.since, resources, "--namespace="+namespace)
+		}
+
+		tasks = append(tasks, &gatherTask{
+			target:  target,
+			name:    target + "/" + namespace,
+			destDir: nsDestDir,
+			cluster: cluster,
+			ocArgs:  args,
+		})
+	}
+
+	return tasks
+}
+
+func mustGatherArgs(destDir string, since time.Duration, extraArgs ...string) []string {
+	args := []string{"adm", "must-gather", "--dest-dir=" + destDir}
+	if since > 0 {
+		args = append(args, "--since="+since.String())
+	}
+	// The gather script, if any, must come last
+	return append(args, extraArgs...)
+}
+
+func inspectArgs(destDir string, since time.Duration, resources string, extraArgs ...string) []string {
+	args := []string{"adm", "inspect", resources, "--dest-dir=" + destDir}
+	if since > 0 {
+		args = append(args, "--since="+since.String())
+	}
+	return append(args, extraArgs...)
+}
+
+// hcpLogsSinceHours returns the number of hours of HCP Dynatrace logs to gather, rounded up
+func hcpLogsSinceHours(since time.Duration) int {
+	if since <= 0 {
+		since = defaultHCPLogsSince
+	}
+	return int(math.Ceil(since.Hours()))
+}
@@ -0,0 +1,127 @@
+package mustgather
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGatherTargets(t *testing.T) {
+	targets, err := parseGatherTargets("mc, hcp,mc")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mc", "hcp"}, targets)
+
+	_, err = parseGatherTargets("mc,foo")
+	assert.ErrorContains(t, err, "unknown gather target 'foo'")
+}
+
+func TestPlanGatherTasks_Full(t *testing.T) {
+	hostedCluster := &hostedClusterRef{namespace: "ocm-production-abc", name: "my-cluster"}
+	tasks := planGatherTasks([]string{"sc", "sc_acm", "mc", "hcp"}, gatherSelectors{}, "/out", "acm:1", hostedCluster, nil)
+	require.Len(t, tasks, 5)
+
+	assert.Equal(t, "sc", tasks[0].name)
+	assert.Equal(t, clusterSC, tasks[0].cluster)
+	assert.Equal(t, []string{"adm", "must-gather", "--dest-dir=/out/sc_infra"}, tasks[0].ocArgs)
+
+	assert.Equal(t, []string{"adm", "must-gather", "--dest-dir=/out/sc_acm", "--image=acm:1"}, tasks[1].ocArgs)
+
+	assert.Equal(t, clusterMC, tasks[2].cluster)
+	assert.Equal(t, []string{"adm", "must-gather", "--dest-dir=/out/mc_infra"}, tasks[2].ocArgs)
+
+	assert.Equal(t, "hcp_logs", tasks[3].name)
+	assert.Empty(t, tasks[3].cluster)
+	assert.Equal(t, "dynatrace logs", tasks[3].command())
+
+	assert.Equal(t, "hcp_dump", tasks[4].name)
+	assert.Equal(t, []string{"adm", "must-gather", "--dest-dir=/out/hcp", "--image=acm:1",
+		"/usr/bin/gather hosted-cluster-namespace=ocm-production-abc hosted-cluster-name=my-cluster"}, tasks[4].ocArgs)
+	assert.NoError(t, tasks[4].planErr)
+}
+
+func TestPlanGatherTasks_Selective(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectors gatherSelectors
+		wantNames []string
+		wantArgs  [][]string
+	}{
+		{
+			name:      "namespaces",
+			selectors: gatherSelectors{namespaces: []string{"hypershift", "open-cluster-management"}},
+			wantNames: []string{"mc/hypershift", "mc/open-cluster-management"},
+			wantArgs: [][]string{
+				{"adm", "inspect", "ns/hypershift", "--dest-dir=/out/mc_infra/hypershift"},
+				{"adm", "inspect", "ns/open-cluster-management", "--dest-dir=/out/mc_infra/open-cluster-management"},
+			},
+		},
+		{
+			name:      "namespaces and resources since",
+			selectors: gatherSelectors{namespaces: []string{"hypershift"}, resources: []string{"pods", "events"}, since: 2 * time.Hour},
+			wantNames: []string{"mc/hypershift"},
+			wantArgs: [][]string{
+				{"adm", "inspect", "pods,events", "--dest-dir=/out/mc_infra/hypershift", "--since=2h0m0s", "--namespace=hypershift"},
+			},
+		},
+		{
+			name:      "resources",
+			selectors: gatherSelectors{resources: []string{"nodes"}},
+			wantNames: []string{"mc"},
+			wantArgs: [][]string{
+				{"adm", "inspect", "nodes", "--dest-dir=/out/mc_infra", "--all-namespaces"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := planGatherTasks([]string{"mc"}, tt.selectors, "/out", "acm:1", nil, nil)
+			require.Len(t, tasks, len(tt.wantNames))
+			for i, task := range tasks {
+				assert.Equal(t, tt.wantNames[i], task.name)
+				assert.Equal(t, tt.wantArgs[i], task.ocArgs)
+			}
+		})
+	}
+}
+
+func TestPlanGatherTasks_UnknownHostedCluster(t *testing.T) {
+	tasks := planGatherTasks([]string{"hcp"}, gatherSelectors{}, "/out", "acm:1", nil, errors.New("no HCP namespace"))
+	require.Len(t, tasks, 2)
+	assert.NoError(t, tasks[0].planErr)
+	assert.EqualError(t, tasks[1].planErr, "no HCP namespace")
+}
+
+func TestHCPLogsSinceHours(t *testing.T) {
+	assert.Equal(t, 72, hcpLogsSinceHours(0))
+	assert.Equal(t, 2, hcpLogsSinceHours(90*time.Minute))
+	assert.Equal(t, 6, hcpLogsSinceHours(6*time.Hour))
+}
+
+func TestOcCommandArgs(t *testing.T) {
+	args := ocCommandArgs([]string{"adm", "must-gather", "--dest-dir=/out", "/usr/bin/gather a=b"}, "/tmp/kc")
+	assert.Equal(t, []string{"adm", "must-gather", "--kubeconfig=/tmp/kc", "--dest-dir=/out", "/usr/bin/gather a=b"}, args)
+
+	args = ocCommandArgs([]string{"adm", "inspect", "ns/foo", "--dest-dir=/out"}, "/tmp/kc")
+	assert.Equal(t, []string{"adm", "inspect", "--kubeconfig=/tmp/kc", "ns/foo", "--dest-dir=/out"}, args)
+}
+
+func TestMustGatherValidate(t *testing.T) {
+	mg := &mustGather{gatherTargets: "mc", maxSize: "2Gi", concurrency: 4}
+	targets, maxSizeBytes, err := mg.validate()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mc"}, targets)
+	assert.Equal(t, int64(2*1024*1024*1024), maxSizeBytes)
+
+	mg.maxSize = "lots"
+	_, _, err = mg.validate()
+	assert.ErrorContains(t, err, "invalid --max-size")
+
+	mg.maxSize = ""
+	mg.concurrency = 0
+	_, _, err = mg.validate()
+	assert.ErrorContains(t, err, "invalid --concurrency")
+}
@@ -13,17 +13,21 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/osdctl/cmd/common"
 	"github.com/openshift/osdctl/cmd/dynatrace"
 	"github.com/openshift/osdctl/pkg/osdctlConfig"
 	"github.com/openshift/osdctl/pkg/utils"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -34,19 +38,45 @@ type mustGather struct {
 	reason             string
 	gatherTargets      string
 	acmMustGatherImage string
+	namespaces         []string
+	resources          []string
+	since              time.Duration
+	maxSize            string
+	concurrency        int
 }
 
+const mustGatherLongDescription = `Create a must-gather for an HCP cluster with optional gather targets:
+  sc      must-gather of the service cluster
+  sc_acm  ACM must-gather of the service cluster
+  mc      must-gather of the management cluster
+  hcp     Dynatrace logs of the hosted control plane and ACM must-gather of the HostedCluster
+
+Full must-gathers of busy management clusters are large. The gathered data can be narrowed down:
+  --namespaces and --resources inspect only the given namespaces and resource kinds of the sc and mc
+  targets with 'oc adm inspect' instead of running a full must-gather.
+  --since only gathers the logs newer than the given duration.
+  --max-size drops log files until the gathered data fits in the given size: logs of previous
+  containers first, then the logs whose last line is the oldest, then the logs without timestamps,
+  like the ones gathered from Dynatrace.
+
+The targets are gathered in parallel. A failing target does not stop the others: the outcome of every
+gathering, the files it collected and the log files dropped to fit --max-size are listed in the
+` + gatherManifestFileName + ` file of the archive.`
+
 func NewCmdMustGather() *cobra.Command {
 	mg := &mustGather{}
 
 	mustGatherCommand := &cobra.Command{
-		Use:     "must-gather --cluster-id <cluster-identifier>",
-		Short:   "Create a must-gather for HCP cluster",
-		Long:    "Create a must-gather for an HCP cluster with optional gather targets",
-		Example: "  osdctl hcp must-gather --cluster-id ${CLUSTER_ID} --gather sc,mc,sc_acm --reason ${REASON}",
+		Use:   "must-gather --cluster-id <cluster-identifier>",
+		Short: "Create a must-gather for HCP cluster",
+		Long:  mustGatherLongDescription,
+		Example: `  osdctl hcp must-gather --cluster-id ${CLUSTER_ID} --gather sc,mc,sc_acm --reason ${REASON}
+
+  # Gather the pods and events of the HyperShift operator on the MC, and the last 6h of HCP logs, within 500Mi
+  osdctl hcp must-gather --cluster-id ${CLUSTER_ID} --gather mc,hcp --namespaces hypershift --resources pods,events --since 6h --max-size 500Mi --reason ${REASON}`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
-			return mg.Run()
+			return mg.Run(cmd.Context())
 		},
 	}
 
@@ -56,6 +86,11 @@ func NewCmdMustGather() *cobra.Command {
 	mustGatherCommand.Flags().StringVar(&mg.reason, "reason", "", "The reason for this command, which requires elevation (e.g., OHSS ticket or PD incident).")
 	mustGatherCommand.Flags().StringVar(&mg.gatherTargets, "gather", "hcp", "Comma-separated list of gather targets (available: sc, sc_acm, mc, hcp).")
 	mustGatherCommand.Flags().StringVar(&mg.acmMustGatherImage, "acm_image", defaultAcmImage, "Overrides the acm must-gather image being used for acm mc, sc as well as hcp must-gathers.")
+	mustGatherCommand.Flags().StringSliceVar(&mg.namespaces, "namespaces", nil, "Only inspect these namespaces of the sc and mc targets instead of running a full must-gather.")
+	mustGatherCommand.Flags().StringSliceVar(&mg.resources, "resources", nil, "Only inspect these resource kinds (e.g. pods,events) of the sc and mc targets, in --namespaces or else in all namespaces.")
+	mustGatherCommand.Flags().DurationVar(&mg.since, "since", 0, "Only gather logs newer than this duration (e.g. 6h). Defaults to all logs for the sc and mc targets and 72h for the HCP Dynatrace logs.")
+	mustGatherCommand.Flags().StringVar(&mg.maxSize, "max-size", "", "Size budget of the gathered data (e.g. 2Gi). Log files are dropped, oldest first, until the data fits.")
+	mustGatherCommand.Flags().IntVar(&mg.concurrency, "concurrency", 4, "Number of gatherings run in parallel.")
 
 	mustGatherCommand.MarkFlagRequired("cluster-id")
 	mustGatherCommand.MarkFlagRequired("reason")
@@ -63,152 +98,165 @@ func NewCmdMustGather() *cobra.Command {
 	return mustGatherCommand
 }
 
-func (mg *mustGather) Run() error {
-	ocmClient, err := utils.CreateConnection()
-	if err != nil {
-		return err
+// parseMaxSize returns the --max-size budget in bytes, 0 if there is none
+func parseMaxSize(maxSize string) (int64, error) {
+	if maxSize == "" {
+		return 0, nil
 	}
-	defer ocmClient.Close()
 
-	cluster, err := utils.GetClusterAnyStatus(ocmClient, mg.clusterId)
+	quantity, err := resource.ParseQuantity(maxSize)
 	if err != nil {
-		return fmt.Errorf("failed to get OCM cluster info for %s: %s", mg.clusterId, err)
+		return 0, fmt.Errorf("invalid --max-size '%s': %v", maxSize, err)
+	}
+	if quantity.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid --max-size '%s': must be positive", maxSize)
 	}
 
-	mc, err := utils.GetManagementCluster(cluster.ID())
+	return quantity.Value(), nil
+}
+
+func (mg *mustGather) validate() ([]string, int64, error) {
+	targets, err := parseGatherTargets(mg.gatherTargets)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
 
-	sc, err := utils.GetServiceCluster(cluster.ID())
+	maxSizeBytes, err := parseMaxSize(mg.maxSize)
 	if err != nil {
-		return err
+		return nil, 0, err
+	}
+
+	if mg.since < 0 {
+		return nil, 0, fmt.Errorf("invalid --since '%s': must be positive", mg.since)
 	}
 
-	_, mcRestCfg, mcK8sCli, err := common.GetKubeConfigAndClient(mc.ID(), mg.reason)
+	if mg.concurrency < 1 {
+		return nil, 0, fmt.Errorf("invalid --concurrency %d: must be at least 1", mg.concurrency)
+	}
+
+	return targets, maxSizeBytes, nil
+}
+
+func (mg *mustGather) Run(ctx context.Context) error {
+	targets, maxSizeBytes, err := mg.validate()
 	if err != nil {
 		return err
 	}
 
-	_, scRestCfg, scK8sCli, err := common.GetKubeConfigAndClient(sc.ID(), mg.reason)
+	ocmClient, err := utils.CreateConnection()
 	if err != nil {
 		return err
 	}
+	defer ocmClient.Close()
 
-	// hack(typeid): work around backplane overwriting our config
-	err = osdctlConfig.EnsureConfigFile()
+	cluster, err := utils.GetClusterAnyStatus(ocmClient, mg.clusterId)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get OCM cluster info for %s: %s", mg.clusterId, err)
+	}
+
+	// The HostedCluster is only needed by the ACM must-gather of the hcp target
+	var hostedCluster *hostedClusterRef
+	var hostedClusterErr error
+	if slices.Contains(targets, targetHCP) {
+		hostedCluster, hostedClusterErr = getHostedClusterRef(ocmClient, cluster)
 	}
 
 	// Prepare for gathering data
-	timestamp := time.Now().Format("20060102150405")
+	startedAt := time.Now()
+	timestamp := startedAt.Format("20060102150405")
 	baseDir := "/tmp"
 	outputDir := fmt.Sprintf("%s/cluster_dump_%s_%s", baseDir, mg.clusterId, timestamp)
 	tarballName := fmt.Sprintf("cluster_dump_%s_%s.tar.gz", mg.clusterId, timestamp)
 	outputTarballTmp := fmt.Sprintf("%s/%s", baseDir, tarballName)
 	outputTarballPath := fmt.Sprintf("%s/%s", outputDir, tarballName)
-	err = os.MkdirAll(outputDir, 0750)
-	if err != nil {
-		return err
-	}
-
-	// Prints with color :)
-	fmt.Printf("\033[1;34mCreating must-gather with targets '%s'. Output directory: '%s'\033[0m\n", mg.gatherTargets, outputDir)
-	gatherTargets := strings.Split(mg.gatherTargets, ",")
 
-	// Progress tracking
-	var completed sync.Map
-	var totalGatherTargets = len(gatherTargets)
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
+	selectors := gatherSelectors{namespaces: mg.namespaces, resources: mg.resources, since: mg.since}
+	tasks := planGatherTasks(targets, selectors, outputDir, mg.acmMustGatherImage, hostedCluster, hostedClusterErr)
 
-		for range ticker.C {
-			var completedCount int
-			var remaining []string
+	// Only log into the clusters the tasks run against
+	restConfigs := map[string]*rest.Config{}
+	for _, task := range tasks {
+		if task.cluster == "" || task.planErr != nil || restConfigs[task.cluster] != nil {
+			continue
+		}
 
-			for _, gatherType := range gatherTargets {
-				if _, ok := completed.Load(gatherType); ok {
-					completedCount++
-				} else {
-					remaining = append(remaining, gatherType)
-				}
+		var clusterID string
+		switch task.cluster {
+		case clusterMC:
+			mc, err := utils.GetManagementCluster(cluster.ID())
+			if err != nil {
+				return err
 			}
-
-			// Prints with color :)
-			fmt.Printf("\033[1;34mProgress: %d/%d completed. Remaining: %v\033[0m\n", completedCount, totalGatherTargets, remaining)
-
-			if completedCount == totalGatherTargets {
-				return
+			clusterID = mc.ID()
+		case clusterSC:
+			sc, err := utils.GetServiceCluster(cluster.ID())
+			if err != nil {
+				return err
 			}
+			clusterID = sc.ID()
 		}
-	}()
 
-	// Start must-gathers in parallel
-	var wg sync.WaitGroup
-	for _, gatherTarget := range gatherTargets {
-		wg.Add(1)
-		go func(gatherTarget string) {
-			defer wg.Done()
-			// Mark this gatherTarget as completed for progress tracking
-			defer completed.Store(gatherTarget, true)
-
-			switch gatherTarget {
-			case "sc":
-				destDir := outputDir + "/sc_infra"
-				if err := createMustGather(scRestCfg, scK8sCli, []string{"--dest-dir=" + destDir}); err != nil {
-					fmt.Printf("failed to gather %s: %v\n", gatherTarget, err)
-				}
-			case "sc_acm":
-				destDir := outputDir + "/sc_acm"
-				if err := createMustGather(scRestCfg, scK8sCli, []string{"--dest-dir=" + destDir, "--image=" + mg.acmMustGatherImage}); err != nil {
-					fmt.Printf("failed to gather %s: %v\n", gatherTarget, err)
-				}
-			case "mc":
-				destDir := outputDir + "/mc_infra"
-				if err := createMustGather(mcRestCfg, mcK8sCli, []string{"--dest-dir=" + destDir}); err != nil {
-					fmt.Printf("failed to gather %s: %v\n", gatherTarget, err)
-				}
-			case "hcp":
-				destDir := outputDir + "/hcp"
+		_, restCfg, _, err := common.GetKubeConfigAndClient(clusterID, mg.reason)
+		if err != nil {
+			return err
+		}
+		restConfigs[task.cluster] = restCfg
+	}
 
-				// 1. Gather logs from DT
-				gatherOptions := &dynatrace.GatherLogsOpts{Since: 72, SortOrder: "asc", DestDir: destDir}
-				if err := gatherOptions.GatherLogs(mg.clusterId); err != nil {
-					fmt.Printf("failed to gather HCP dynatrace logs: %v\n", err)
-				}
+	// hack(typeid): work around backplane overwriting our config
+	err = osdctlConfig.EnsureConfigFile()
+	if err != nil {
+		return err
+	}
 
-				// 2. ACM must-gather which includes running the hypershift binary for a dump
-				clusterHyperShift, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(mg.clusterId).Hypershift().Get().Send()
-				if err != nil {
-					fmt.Printf("collected HCP dynatrace logs but failed to get OCM cluster hypershift info for %s: %v\n", mg.clusterId, err)
-					return
-				}
+	err = os.MkdirAll(outputDir, 0750)
+	if err != nil {
+		return err
+	}
 
-				hcpNamespace, ok := clusterHyperShift.Body().GetHCPNamespace()
-				if !ok {
-					fmt.Println("collected HCP dynatrace logs but failed to get HCP namespace")
-					return
-				}
+	// Prints with color :)
+	fmt.Printf("\033[1;34mCreating must-gather with targets '%s'. Output directory: '%s'\033[0m\n", strings.Join(targets, ","), outputDir)
 
-				hcName := cluster.DomainPrefix()
-				hcNamespace := strings.TrimSuffix(hcpNamespace, "-"+hcName)
+	manifestTasks := runGatherTasks(outputDir, tasks, mg.concurrency, func(task *gatherTask) error {
+		if task.cluster == "" {
+			gatherOptions := &dynatrace.GatherLogsOpts{Since: hcpLogsSinceHours(mg.since), SortOrder: "asc", DestDir: task.destDir, Workers: dynatrace.DefaultGatherWorkers, Retries: dynatrace.DefaultGatherRetries}
+			return gatherOptions.GatherLogs(ctx, mg.clusterId)
+		}
+		return runOcCommand(restConfigs[task.cluster], task.ocArgs)
+	})
 
-				gatherScript := fmt.Sprintf("/usr/bin/gather hosted-cluster-namespace=%s hosted-cluster-name=%s", hcNamespace, hcName)
-				if err := createMustGather(mcRestCfg, mcK8sCli, []string{"--dest-dir=" + destDir, "--image=" + mg.acmMustGatherImage, gatherScript}); err != nil {
-					fmt.Printf("collected HCP dynatrace logs but failed to gather %s: %v\n", gatherTarget, err.Error())
-				}
+	fmt.Println()
+	fmt.Println("All must-gather tasks completed. Creating tarball.")
 
-			default:
-				fmt.Printf("unknown gather type: %s\n", gatherTarget)
-			}
-		}(gatherTarget)
+	totalSizeBytes, droppedFiles, err := enforceSizeBudget(outputDir, maxSizeBytes)
+	if err != nil {
+		return err
+	}
+	if len(droppedFiles) > 0 {
+		fmt.Printf("Dropped %d log files to fit the size budget of %s.\n", len(droppedFiles), mg.maxSize)
+	}
+	if maxSizeBytes > 0 && totalSizeBytes > maxSizeBytes {
+		fmt.Printf("Warning: the gathered data is %d bytes, over the size budget of %s even without logs.\n", totalSizeBytes, mg.maxSize)
 	}
 
-	wg.Wait()
-	fmt.Println()
-	fmt.Println("All must-gather tasks completed. Creating tarball.")
+	manifest := &gatherManifest{
+		ClusterID:      mg.clusterId,
+		StartedAt:      startedAt.UTC(),
+		CompletedAt:    time.Now().UTC(),
+		Targets:        targets,
+		Namespaces:     mg.namespaces,
+		Resources:      mg.resources,
+		MaxSizeBytes:   maxSizeBytes,
+		TotalSizeBytes: totalSizeBytes,
+		Tasks:          manifestTasks,
+		DroppedFiles:   droppedFiles,
+	}
+	if mg.since > 0 {
+		manifest.Since = mg.since.String()
+	}
+	if err := manifest.write(outputDir); err != nil {
+		return err
+	}
 
 	// Create a tarball with all collected data
 	if err := createTarball(outputDir, outputTarballTmp); err != nil {
@@ -221,13 +269,93 @@ func (mg *mustGather) Run() error {
 		return fmt.Errorf("failed to move tarball to output directory: %w", err)
 	}
 
-	fmt.Println("Data collection completed successfully in:", outputDir)
+	fmt.Println("Data collection completed in:", outputDir)
 	fmt.Println("Compressed archive has been created at:", outputTarballPath)
 
+	if failed := manifest.failedTasks(); len(failed) > 0 {
+		for _, task := range failed {
+			fmt.Printf("failed to gather %s: %s\n", task.Name, task.Error)
+		}
+		return fmt.Errorf("%d of %d gather tasks failed, see %s", len(failed), len(manifest.Tasks), gatherManifestFileName)
+	}
+
 	return nil
 }
 
-func createMustGather(restCfg *rest.Config, k8sCli *kubernetes.Clientset, additionalFlags []string) error {
+// getHostedClusterRef returns the HostedCluster of the cluster on its management cluster
+func getHostedClusterRef(ocmClient *sdk.Connection, cluster *cmv1.Cluster) (*hostedClusterRef, error) {
+	clusterHyperShift, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Hypershift().Get().Send()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OCM cluster hypershift info for %s: %v", cluster.ID(), err)
+	}
+
+	hcpNamespace, ok := clusterHyperShift.Body().GetHCPNamespace()
+	if !ok {
+		return nil, fmt.Errorf("failed to get HCP namespace")
+	}
+
+	hcName := cluster.DomainPrefix()
+	return &hostedClusterRef{namespace: strings.TrimSuffix(hcpNamespace, "-"+hcName), name: hcName}, nil
+}
+
+// runGatherTasks runs the tasks, at most concurrency at a time, reporting the progress every 30 seconds. A failing
+// task does not stop the others, the outcome of every task is returned in the order of the tasks.
+func runGatherTasks(outputDir string, tasks []*gatherTask, concurrency int, run func(task *gatherTask) error) []*gatherManifestTask {
+	manifestTasks := make([]*gatherManifestTask, len(tasks))
+
+	// Progress tracking
+	var completed sync.Map
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			var remaining []string
+			for _, task := range tasks {
+				if _, ok := completed.Load(task.name); !ok {
+					remaining = append(remaining, task.name)
+				}
+			}
+
+			// Prints with color :)
+			fmt.Printf("\033[1;34mProgress: %d/%d completed. Remaining: %v\033[0m\n", len(tasks)-len(remaining), len(tasks), remaining)
+		}
+	}()
+
+	var group errgroup.Group
+	group.SetLimit(concurrency)
+	for i, task := range tasks {
+		group.Go(func() error {
+			// Mark this task as completed for progress tracking
+			defer completed.Store(task.name, true)
+
+			start := time.Now()
+			err := task.planErr
+			if err == nil {
+				err = run(task)
+			}
+			if err != nil {
+				fmt.Printf("failed to gather %s: %v\n", task.name, err)
+			}
+			manifestTasks[i] = newGatherManifestTask(outputDir, task, time.Since(start), err)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return manifestTasks
+}
+
+// runOcCommand runs an oc command, e.g. 'adm must-gather', against the cluster of the rest config
+func runOcCommand(restCfg *rest.Config, ocArgs []string) error {
 	// We used to run this programatically by directly using the must-gather package  (see https://github.com/openshift/osdctl/pull/660)
 	// from the oc cli, but decided to opt for oc.Exec instead.
 	// Reasoning:
@@ -243,15 +371,17 @@ func createMustGather(restCfg *rest.Config, k8sCli *kubernetes.Clientset, additi
 
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signalChan)
 	go func() {
-		<-signalChan
-		fmt.Println("Received interrupt signal, canceling operation...")
-		cancel()
+		select {
+		case <-signalChan:
+			fmt.Println("Received interrupt signal, canceling operation...")
+			cancel()
+		case <-ctx.Done():
+		}
 	}()
 
-	cmdArgs := []string{"adm", "must-gather", "--kubeconfig=" + kubeConfigFile}
-	cmdArgs = append(cmdArgs, additionalFlags...)
-
+	cmdArgs := ocCommandArgs(ocArgs, kubeConfigFile)
 	cmd := exec.CommandContext(ctx, "oc", cmdArgs...)
 
 	var stdout, stderr bytes.Buffer
@@ -263,12 +393,30 @@ func createMustGather(restCfg *rest.Config, k8sCli *kubernetes.Clientset, additi
 		if ctx.Err() == context.Canceled {
 			return fmt.Errorf("command was canceled by user (e.g., Ctrl+C): %v\nstderr: %s", err, stderr.String())
 		}
-		return fmt.Errorf("failed to run 'oc adm must-gather': %v\nstderr: %s", err, stderr.String())
+		return fmt.Errorf("failed to run 'oc %s': %v\nstderr: %s", strings.Join(ocSubcommand(ocArgs), " "), err, stderr.String())
 	}
 
 	return nil
 }
 
+// ocCommandArgs inserts the kubeconfig flag right after the oc subcommand, as a must-gather script must come last
+func ocCommandArgs(ocArgs []string, kubeConfigFile string) []string {
+	subcommand := ocSubcommand(ocArgs)
+	cmdArgs := append([]string{}, subcommand...)
+	cmdArgs = append(cmdArgs, "--kubeconfig="+kubeConfigFile)
+	return append(cmdArgs, ocArgs[len(subcommand):]...)
+}
+
+// ocSubcommand returns the leading arguments of the oc command which are not flags, e.g. 'adm must-gather'
+func ocSubcommand(ocArgs []string) []string {
+	for i, arg := range ocArgs {
+		if i == 2 || strings.HasPrefix(arg, "-") {
+			return ocArgs[:i]
+		}
+	}
+	return ocArgs
+}
+
 // The sole purpose of this function is to work around the hack described in `createMustGather`
 func createKubeconfigFileForRestConfig(restConfig *rest.Config) string {
 	var proxyUrl *url.URL
@@ -0,0 +1,306 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	"github.com/openshift/osdctl/cmd/org"
+	"github.com/openshift/osdctl/internal/io"
+	"github.com/openshift/osdctl/pkg/k8s"
+	"github.com/openshift/osdctl/pkg/utils"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	labelClusterID = "api.openshift.com/id"
+
+	// Number of clusters looked up per OCM search query
+	clusterSearchBatchSize = 50
+)
+
+// runFleet prints the unhealthy clusters among the targeted ones and returns the worst verdict.
+func (o *statusOptions) runFleet(ctx context.Context) (Verdict, error) {
+	if err := o.validate(); err != nil {
+		return "", err
+	}
+
+	conn, err := utils.CreateConnection()
+	if err != nil {
+		return "", fmt.Errorf("failed to create OCM connection: %w", err)
+	}
+	defer conn.Close()
+
+	clusters, err := o.resolveFleetClusters(ctx, conn)
+	if err != nil {
+		return "", err
+	}
+	if len(clusters) == 0 {
+		return "", fmt.Errorf("no HCP cluster found to check")
+	}
+
+	statuses, errs := o.fetchFleetStatuses(ctx, conn, clusters)
+	report := buildFleetReport(clusters, statuses, errs)
+
+	switch o.output {
+	case outputJSON:
+		err = printAsJSON(report)
+	case outputYAML:
+		err = printAsYAML(report)
+	default:
+		printFleetReport(report)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return report.verdict()
+}
+
+// resolveFleetClusters returns the HCP clusters targeted by --mc, --clusters-file or --org.
+func (o *statusOptions) resolveFleetClusters(ctx context.Context, conn *sdk.Connection) ([]*cmv1.Cluster, error) {
+	var clusterKeys []string
+	var err error
+
+	switch {
+	case o.mgmtCluster != "":
+		clusterKeys, err = listHostedClusterIDs(ctx, conn, o.mgmtCluster)
+	case o.clustersFile != "":
+		clusterKeys, err = io.ParseAndValidateClustersFile(o.clustersFile)
+		if err == nil && len(clusterKeys) == 0 {
+			err = fmt.Errorf("clusters file contains no cluster IDs - the 'clusters' array is empty")
+		}
+	default:
+		clusterKeys, err = listOrgClusterIDs(o.orgID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := searchClusters(conn, clusterKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	var hcpClusters []*cmv1.Cluster
+	for _, cluster := range clusters {
+		if !cluster.Hypershift().Enabled() {
+			continue
+		}
+		hcpClusters = append(hcpClusters, cluster)
+	}
+
+	if skippedCount := len(clusters) - len(hcpClusters); skippedCount > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %d clusters which are not HCP clusters\n", skippedCount)
+	}
+	if o.clustersFile != "" && len(clusters) != len(clusterKeys) {
+		fmt.Fprintf(os.Stderr, "Warning: found %d clusters but expected %d. This can happen when clusters are no longer available in OCM, e.g. due to a deletion.\n", len(clusters), len(clusterKeys))
+	}
+
+	return hcpClusters, nil
+}
+
+// listHostedClusterIDs returns the internal IDs of the HostedClusters of the given management cluster.
+func listHostedClusterIDs(ctx context.Context, conn *sdk.Connection, mgmtClusterKey string) ([]string, error) {
+	mgmtCluster, err := utils.GetCluster(conn, mgmtClusterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find management cluster: %w", err)
+	}
+
+	isMC, err := utils.IsManagementCluster(mgmtCluster.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify management cluster: %w", err)
+	}
+	if !isMC {
+		return nil, fmt.Errorf("cluster %s is not a management cluster", mgmtCluster.ID())
+	}
+
+	scheme := runtime.NewScheme()
+	if err := hypershiftv1beta1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to add hypershift scheme: %w", err)
+	}
+
+	mgmtClient, err := k8s.NewWithConn(mgmtCluster.ID(), client.Options{Scheme: scheme}, conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create management cluster client: %w", err)
+	}
+
+	hcList := &hypershiftv1beta1.HostedClusterList{}
+	if err := mgmtClient.List(ctx, hcList); err != nil {
+		return nil, fmt.Errorf("failed to list HostedClusters of management cluster %s: %w", mgmtCluster.Name(), err)
+	}
+
+	clusterIDs := hostedClusterIDs(hcList.Items)
+	if len(clusterIDs) == 0 {
+		return nil, fmt.Errorf("no hosted cluster found on management cluster %s", mgmtCluster.Name())
+	}
+
+	return clusterIDs, nil
+}
+
+// hostedClusterIDs returns the sorted OCM internal IDs of the given HostedClusters.
+func hostedClusterIDs(hostedClusters []hypershiftv1beta1.HostedCluster) []string {
+	var clusterIDs []string
+	for _, hc := range hostedClusters {
+		if clusterID := hc.Labels[labelClusterID]; clusterID != "" {
+			clusterIDs = append(clusterIDs, clusterID)
+		}
+	}
+	sort.Strings(clusterIDs)
+
+	return clusterIDs
+}
+
+func listOrgClusterIDs(orgID string) ([]string, error) {
+	subscriptions, err := org.SearchAllSubscriptionsByOrg(orgID, org.StatusActive, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cluster subscriptions for org '%s': %w", orgID, err)
+	}
+
+	var clusterIDs []string
+	for _, subscription := range subscriptions {
+		if subscription.ClusterID() != "" {
+			clusterIDs = append(clusterIDs, subscription.ClusterID())
+		}
+	}
+	if len(clusterIDs) == 0 {
+		return nil, fmt.Errorf("no active managed cluster found for org '%s'", orgID)
+	}
+
+	return clusterIDs, nil
+}
+
+// searchClusters looks the given cluster names or IDs up in OCM, in batches to keep the search queries short.
+func searchClusters(conn *sdk.Connection, clusterKeys []string) ([]*cmv1.Cluster, error) {
+	var clusters []*cmv1.Cluster
+
+	for start := 0; start < len(clusterKeys); start += clusterSearchBatchSize {
+		end := min(start+clusterSearchBatchSize, len(clusterKeys))
+
+		var queries []string
+		for _, clusterKey := range clusterKeys[start:end] {
+			queries = append(queries, utils.GenerateQuery(clusterKey))
+		}
+
+		batch, err := utils.ApplyFilters(conn, []string{strings.Join(queries, " or ")})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find clusters: %w", err)
+		}
+		clusters = append(clusters, batch...)
+	}
+
+	return clusters, nil
+}
+
+// fetchFleetStatuses fetches the status of the clusters with a bounded concurrency, rate limiting the requests to OCM.
+// The status or the error of every cluster is returned at the cluster index.
+func (o *statusOptions) fetchFleetStatuses(ctx context.Context, conn *sdk.Connection, clusters []*cmv1.Cluster) ([]*HCPStatus, []error) {
+	statuses := make([]*HCPStatus, len(clusters))
+	errs := make([]error, len(clusters))
+
+	throttle := time.NewTicker(time.Second / time.Duration(o.qps))
+	defer throttle.Stop()
+
+	var group errgroup.Group
+	group.SetLimit(o.concurrency)
+	for idx, cluster := range clusters {
+		group.Go(func() error {
+			select {
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return nil
+			case <-throttle.C:
+			}
+
+			fmt.Fprintf(os.Stderr, "[%d/%d] Fetching status of %s (%s)\n", idx+1, len(clusters), cluster.Name(), cluster.ID())
+			statuses[idx], errs[idx] = FetchClusterStatus(conn, cluster)
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	return statuses, errs
+}
+
+// buildFleetReport counts the clusters per verdict and lists the unhealthy ones, the most severe first.
+func buildFleetReport(clusters []*cmv1.Cluster, statuses []*HCPStatus, errs []error) *FleetReport {
+	report := &FleetReport{
+		TotalClusters:     len(clusters),
+		UnhealthyClusters: []FleetClusterStatus{},
+	}
+
+	for idx, cluster := range clusters {
+		clusterStatus := FleetClusterStatus{
+			ClusterID:   cluster.ID(),
+			ExternalID:  cluster.ExternalID(),
+			ClusterName: cluster.Name(),
+		}
+
+		if errs[idx] != nil {
+			report.Failed++
+			clusterStatus.Error = errs[idx].Error()
+			report.UnhealthyClusters = append(report.UnhealthyClusters, clusterStatus)
+			continue
+		}
+
+		health := statuses[idx].Health
+		switch health.Verdict {
+		case VerdictHealthy:
+			report.Healthy++
+			continue
+		case VerdictDegraded:
+			report.Degraded++
+		case VerdictCritical:
+			report.Critical++
+		}
+
+		clusterStatus.Verdict = health.Verdict
+		clusterStatus.Reasons = health.Reasons
+		report.UnhealthyClusters = append(report.UnhealthyClusters, clusterStatus)
+	}
+
+	// Critical first, then Degraded, then the clusters whose status could not be fetched
+	rank := func(s FleetClusterStatus) int {
+		if s.Error != "" {
+			return -1
+		}
+		return severityRank(s.Verdict)
+	}
+	sort.SliceStable(report.UnhealthyClusters, func(i, j int) bool {
+		left, right := report.UnhealthyClusters[i], report.UnhealthyClusters[j]
+		if rank(left) != rank(right) {
+			return rank(left) > rank(right)
+		}
+		return left.ClusterName < right.ClusterName
+	})
+
+	return report
+}
+
+// verdict returns the worst verdict of the fleet, or an error when the status of some clusters could not be fetched,
+// as their verdict is unknown.
+func (r *FleetReport) verdict() (Verdict, error) {
+	if r.Failed > 0 {
+		return "", fmt.Errorf("failed to get the status of %d clusters (worst verdict of the others: %s)", r.Failed, r.worstVerdict())
+	}
+	return r.worstVerdict(), nil
+}
+
+func (r *FleetReport) worstVerdict() Verdict {
+	switch {
+	case r.Critical > 0:
+		return VerdictCritical
+	case r.Degraded > 0:
+		return VerdictDegraded
+	default:
+		return VerdictHealthy
+	}
+}
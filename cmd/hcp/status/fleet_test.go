@@ -0,0 +1,116 @@
+package status
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildFleetReport(t *testing.T) {
+	newCluster := func(id, name string) *cmv1.Cluster {
+		cluster, err := cmv1.NewCluster().ID(id).Name(name).ExternalID("ext-" + id).Build()
+		if err != nil {
+			t.Fatalf("failed to build cluster: %v", err)
+		}
+		return cluster
+	}
+	withVerdict := func(verdict Verdict, reasons ...HealthReason) *HCPStatus {
+		return &HCPStatus{Health: &HealthVerdict{Verdict: verdict, Reasons: reasons}}
+	}
+
+	clusters := []*cmv1.Cluster{
+		newCluster("1", "healthy"),
+		newCluster("2", "degraded"),
+		newCluster("3", "failed"),
+		newCluster("4", "critical-b"),
+		newCluster("5", "critical-a"),
+	}
+	statuses := []*HCPStatus{
+		withVerdict(VerdictHealthy),
+		withVerdict(VerdictDegraded, HealthReason{Severity: VerdictDegraded, Component: "NodePool/workers", Message: "Ready is False"}),
+		nil,
+		withVerdict(VerdictCritical, HealthReason{Severity: VerdictCritical, Component: "HostedCluster", Message: "Available is False"}),
+		withVerdict(VerdictCritical, HealthReason{Severity: VerdictCritical, Component: "IngressCertificate", Message: "certificate expires in 3d"}),
+	}
+	errs := []error{nil, nil, errors.New("live resources unavailable"), nil, nil}
+
+	report := buildFleetReport(clusters, statuses, errs)
+
+	if report.TotalClusters != 5 || report.Healthy != 1 || report.Degraded != 1 || report.Critical != 2 || report.Failed != 1 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+
+	var names []string
+	for _, c := range report.UnhealthyClusters {
+		names = append(names, c.ClusterName)
+	}
+	if expected := []string{"critical-a", "critical-b", "degraded", "failed"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected unhealthy clusters %v, got %v", expected, names)
+	}
+
+	failed := report.UnhealthyClusters[3]
+	if failed.Error != "live resources unavailable" || failed.ExternalID != "ext-3" {
+		t.Errorf("unexpected failed cluster: %+v", failed)
+	}
+
+	if verdict := report.worstVerdict(); verdict != VerdictCritical {
+		t.Errorf("expected worst verdict %s, got %s", VerdictCritical, verdict)
+	}
+}
+
+func TestBuildFleetReportAllHealthy(t *testing.T) {
+	cluster, _ := cmv1.NewCluster().ID("1").Build()
+
+	report := buildFleetReport([]*cmv1.Cluster{cluster}, []*HCPStatus{{Health: &HealthVerdict{Verdict: VerdictHealthy}}}, []error{nil})
+
+	if report.Healthy != 1 || len(report.UnhealthyClusters) != 0 || report.worstVerdict() != VerdictHealthy {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestFleetReportVerdict(t *testing.T) {
+	tests := []struct {
+		name            string
+		report          FleetReport
+		expectedVerdict Verdict
+		expectedErr     bool
+	}{
+		{name: "healthy", report: FleetReport{Healthy: 2}, expectedVerdict: VerdictHealthy},
+		{name: "critical", report: FleetReport{Healthy: 1, Critical: 1}, expectedVerdict: VerdictCritical},
+		{name: "failed only", report: FleetReport{Healthy: 1, Failed: 1}, expectedErr: true},
+		{name: "critical and failed", report: FleetReport{Critical: 1, Failed: 1}, expectedErr: true},
+		{name: "degraded and failed", report: FleetReport{Degraded: 1, Failed: 2}, expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := tt.report.verdict()
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if verdict != tt.expectedVerdict {
+				t.Errorf("expected verdict %q, got %q", tt.expectedVerdict, verdict)
+			}
+		})
+	}
+}
+
+func TestHostedClusterIDs(t *testing.T) {
+	hostedCluster := func(labels map[string]string) hypershiftv1beta1.HostedCluster {
+		return hypershiftv1beta1.HostedCluster{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+	}
+
+	ids := hostedClusterIDs([]hypershiftv1beta1.HostedCluster{
+		hostedCluster(map[string]string{labelClusterID: "b-id"}),
+		hostedCluster(nil),
+		hostedCluster(map[string]string{labelClusterID: "a-id", "other": "label"}),
+	})
+
+	if expected := []string{"a-id", "b-id"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
@@ -0,0 +1,195 @@
+package status
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// Failing ManifestWorks younger than this are considered as still converging
+	manifestWorkStuckThreshold = 15 * time.Minute
+
+	certificateCriticalWindow = 7 * 24 * time.Hour
+	certificateDegradedWindow = 30 * 24 * time.Hour
+)
+
+// Exit codes of the status command. 1 is left to errors preventing from getting the status.
+const (
+	exitCodeHealthy  = 0
+	exitCodeDegraded = 2
+	exitCodeCritical = 3
+)
+
+// expectedHostedClusterConditions maps the HostedCluster conditions relevant for the health to their healthy status,
+// and the severity of not having it.
+var expectedHostedClusterConditions = map[string]struct {
+	status   string
+	severity Verdict
+}{
+	"Available":                {"True", VerdictCritical},
+	"Degraded":                 {"False", VerdictDegraded},
+	"ClusterVersionSucceeding": {"True", VerdictDegraded},
+	"ClusterVersionFailing":    {"False", VerdictDegraded},
+	"ReconciliationSucceeded":  {"True", VerdictDegraded},
+	"ValidConfiguration":       {"True", VerdictDegraded},
+	"InfrastructureReady":      {"True", VerdictDegraded},
+	"EtcdAvailable":            {"True", VerdictCritical},
+	"KubeAPIServerAvailable":   {"True", VerdictCritical},
+}
+
+// expectedNodePoolConditions maps the NodePool conditions relevant for the health to their healthy status.
+var expectedNodePoolConditions = map[string]string{
+	"Ready":            "True",
+	"AllMachinesReady": "True",
+	"AllNodesHealthy":  "True",
+}
+
+func severityRank(verdict Verdict) int {
+	switch verdict {
+	case VerdictCritical:
+		return 2
+	case VerdictDegraded:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ExitCode returns the exit code of the status command for the verdict.
+func (v Verdict) ExitCode() int {
+	switch v {
+	case VerdictCritical:
+		return exitCodeCritical
+	case VerdictDegraded:
+		return exitCodeDegraded
+	default:
+		return exitCodeHealthy
+	}
+}
+
+type healthEvaluator struct {
+	now     time.Time
+	verdict *HealthVerdict
+}
+
+func (e *healthEvaluator) addReason(severity Verdict, component string, format string, args ...interface{}) {
+	e.verdict.Reasons = append(e.verdict.Reasons, HealthReason{
+		Severity:  severity,
+		Component: component,
+		Message:   fmt.Sprintf(format, args...),
+	})
+	if severityRank(severity) > severityRank(e.verdict.Verdict) {
+		e.verdict.Verdict = severity
+	}
+}
+
+// evaluateHealth computes the health verdict of the cluster from ManifestWork sync, HostedCluster & NodePool conditions
+// and certificate expiry.
+func evaluateHealth(s *HCPStatus, now time.Time) *HealthVerdict {
+	e := &healthEvaluator{
+		now:     now,
+		verdict: &HealthVerdict{Verdict: VerdictHealthy, Reasons: []HealthReason{}},
+	}
+
+	e.evaluateManifestWorks(s.ManifestWorks)
+	e.evaluateHostedCluster(s.HostedClusterConditions)
+	e.evaluateNodePools(s.NodePools)
+	if s.APIServerCertificate != nil {
+		e.evaluateCertificate("APIServerCertificate", s.APIServerCertificate)
+	}
+	if s.IngressCertificate != nil {
+		e.evaluateCertificate("IngressCertificate", s.IngressCertificate)
+	}
+
+	return e.verdict
+}
+
+func (e *healthEvaluator) evaluateManifestWorks(manifestWorks []ManifestWorkSync) {
+	if len(manifestWorks) == 0 {
+		e.addReason(VerdictDegraded, "ManifestWork", "no ManifestWork found")
+		return
+	}
+
+	for _, mw := range manifestWorks {
+		if mw.Applied && mw.Available {
+			continue
+		}
+
+		state := "not applied"
+		if mw.Applied {
+			state = "not available"
+		}
+
+		if mw.LastSyncTime.IsZero() {
+			e.addReason(VerdictCritical, "ManifestWork/"+mw.Name, "%s", state)
+			continue
+		}
+
+		age := e.now.Sub(mw.LastSyncTime)
+		severity := VerdictDegraded
+		if age > manifestWorkStuckThreshold {
+			severity = VerdictCritical
+		}
+		e.addReason(severity, "ManifestWork/"+mw.Name, "%s since %s", state, age.Round(time.Second))
+	}
+}
+
+func (e *healthEvaluator) evaluateHostedCluster(conditions []Condition) {
+	if len(conditions) == 0 {
+		e.addReason(VerdictDegraded, "HostedCluster", "no HostedCluster conditions available")
+		return
+	}
+
+	for _, c := range conditions {
+		expected, ok := expectedHostedClusterConditions[c.Type]
+		if !ok || c.Status == expected.status {
+			continue
+		}
+		e.addReason(expected.severity, "HostedCluster", "%s is %s: %s", c.Type, c.Status, conditionDetails(c))
+	}
+}
+
+func (e *healthEvaluator) evaluateNodePools(nodePools []NodePoolStatus) {
+	for _, np := range nodePools {
+		for _, c := range np.Conditions {
+			expectedStatus, ok := expectedNodePoolConditions[c.Type]
+			if !ok || c.Status == expectedStatus {
+				continue
+			}
+			e.addReason(VerdictDegraded, "NodePool/"+np.Name, "%s is %s: %s", c.Type, c.Status, conditionDetails(c))
+		}
+	}
+}
+
+func (e *healthEvaluator) evaluateCertificate(component string, c *CertificateStatus) {
+	if c.Ready != nil && !*c.Ready {
+		e.addReason(VerdictCritical, component, "certificate is not ready")
+	}
+
+	if c.NotAfter.IsZero() {
+		return
+	}
+
+	remaining := c.NotAfter.Sub(e.now)
+	daysRemaining := int(math.Ceil(remaining.Hours() / 24))
+	switch {
+	case remaining <= 0:
+		e.addReason(VerdictCritical, component, "certificate expired on %s", c.NotAfter.Format("2006-01-02"))
+	case remaining < certificateCriticalWindow:
+		e.addReason(VerdictCritical, component, "certificate expires in %dd", daysRemaining)
+	case remaining < certificateDegradedWindow:
+		e.addReason(VerdictDegraded, component, "certificate expires in %dd", daysRemaining)
+	}
+}
+
+// conditionDetails returns the first line of the message of the condition, or its reason.
+func conditionDetails(c Condition) string {
+	details := c.Message
+	if details == "" {
+		details = c.Reason
+	}
+
+	return strings.SplitN(details, "\n", 2)[0]
+}
@@ -0,0 +1,200 @@
+package status
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluateHealth(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	ready := true
+	notReady := false
+
+	healthyStatus := func() *HCPStatus {
+		return &HCPStatus{
+			ManifestWorks: []ManifestWorkSync{
+				{Name: "mw-1", Applied: true, Available: true, LastSyncTime: now.Add(-48 * time.Hour)},
+			},
+			HostedClusterConditions: []Condition{
+				{Type: "Available", Status: "True"},
+				{Type: "Degraded", Status: "False"},
+				{Type: "Progressing", Status: "True"},
+			},
+			NodePools: []NodePoolStatus{
+				{Name: "workers", Conditions: []Condition{{Type: "Ready", Status: "True"}, {Type: "UpdatingVersion", Status: "True"}}},
+			},
+			APIServerCertificate: &CertificateStatus{Ready: &ready, NotAfter: now.Add(90 * 24 * time.Hour)},
+			IngressCertificate:   &CertificateStatus{Ready: &ready, NotAfter: now.Add(60 * 24 * time.Hour)},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		mutate             func(s *HCPStatus)
+		expectedVerdict    Verdict
+		expectedComponents []string
+	}{
+		{
+			name:            "healthy cluster",
+			mutate:          func(s *HCPStatus) {},
+			expectedVerdict: VerdictHealthy,
+		},
+		{
+			name: "recently failing ManifestWork is degraded",
+			mutate: func(s *HCPStatus) {
+				s.ManifestWorks[0].Available = false
+				s.ManifestWorks[0].LastSyncTime = now.Add(-5 * time.Minute)
+			},
+			expectedVerdict:    VerdictDegraded,
+			expectedComponents: []string{"ManifestWork/mw-1"},
+		},
+		{
+			name: "stuck ManifestWork is critical",
+			mutate: func(s *HCPStatus) {
+				s.ManifestWorks[0].Applied = false
+				s.ManifestWorks[0].LastSyncTime = now.Add(-time.Hour)
+			},
+			expectedVerdict:    VerdictCritical,
+			expectedComponents: []string{"ManifestWork/mw-1"},
+		},
+		{
+			name: "unavailable HostedCluster is critical",
+			mutate: func(s *HCPStatus) {
+				s.HostedClusterConditions[0] = Condition{Type: "Available", Status: "False", Message: "KAS unreachable\nretrying"}
+				s.HostedClusterConditions[1] = Condition{Type: "Degraded", Status: "True", Reason: "EtcdDegraded"}
+			},
+			expectedVerdict:    VerdictCritical,
+			expectedComponents: []string{"HostedCluster", "HostedCluster"},
+		},
+		{
+			name: "NodePool not ready is degraded",
+			mutate: func(s *HCPStatus) {
+				s.NodePools[0].Conditions[0].Status = "False"
+			},
+			expectedVerdict:    VerdictDegraded,
+			expectedComponents: []string{"NodePool/workers"},
+		},
+		{
+			name: "certificate expiring within 30 days is degraded",
+			mutate: func(s *HCPStatus) {
+				s.IngressCertificate.NotAfter = now.Add(20 * 24 * time.Hour)
+			},
+			expectedVerdict:    VerdictDegraded,
+			expectedComponents: []string{"IngressCertificate"},
+		},
+		{
+			name: "certificate not ready and expired is critical",
+			mutate: func(s *HCPStatus) {
+				s.IngressCertificate.Ready = &notReady
+				s.IngressCertificate.NotAfter = now.Add(-time.Hour)
+			},
+			expectedVerdict:    VerdictCritical,
+			expectedComponents: []string{"IngressCertificate", "IngressCertificate"},
+		},
+		{
+			name: "API server certificate expiring within 7 days is critical",
+			mutate: func(s *HCPStatus) {
+				s.APIServerCertificate.NotAfter = now.Add(3 * 24 * time.Hour)
+			},
+			expectedVerdict:    VerdictCritical,
+			expectedComponents: []string{"APIServerCertificate"},
+		},
+		{
+			name: "API server certificate expiring within 30 days is degraded",
+			mutate: func(s *HCPStatus) {
+				s.APIServerCertificate.NotAfter = now.Add(20 * 24 * time.Hour)
+			},
+			expectedVerdict:    VerdictDegraded,
+			expectedComponents: []string{"APIServerCertificate"},
+		},
+		{
+			name: "expired API server certificate is critical",
+			mutate: func(s *HCPStatus) {
+				s.APIServerCertificate.NotAfter = now.Add(-time.Hour)
+			},
+			expectedVerdict:    VerdictCritical,
+			expectedComponents: []string{"APIServerCertificate"},
+		},
+		{
+			name: "API server certificate without feedback is not evaluated",
+			mutate: func(s *HCPStatus) {
+				s.APIServerCertificate = &CertificateStatus{}
+			},
+			expectedVerdict: VerdictHealthy,
+		},
+		{
+			name: "missing resources are degraded",
+			mutate: func(s *HCPStatus) {
+				s.ManifestWorks = nil
+				s.HostedClusterConditions = nil
+			},
+			expectedVerdict:    VerdictDegraded,
+			expectedComponents: []string{"ManifestWork", "HostedCluster"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := healthyStatus()
+			tt.mutate(status)
+
+			health := evaluateHealth(status, now)
+
+			if health.Verdict != tt.expectedVerdict {
+				t.Errorf("verdict = %s, want %s (reasons: %+v)", health.Verdict, tt.expectedVerdict, health.Reasons)
+			}
+			var components []string
+			for _, reason := range health.Reasons {
+				components = append(components, reason.Component)
+				if strings.Contains(reason.Message, "\n") {
+					t.Errorf("reason message should be a single line: %q", reason.Message)
+				}
+			}
+			if strings.Join(components, ",") != strings.Join(tt.expectedComponents, ",") {
+				t.Errorf("components = %v, want %v", components, tt.expectedComponents)
+			}
+		})
+	}
+}
+
+func TestVerdictExitCode(t *testing.T) {
+	for verdict, expected := range map[Verdict]int{
+		VerdictHealthy:  0,
+		VerdictDegraded: 2,
+		VerdictCritical: 3,
+	} {
+		if got := verdict.ExitCode(); got != expected {
+			t.Errorf("%s.ExitCode() = %d, want %d", verdict, got, expected)
+		}
+	}
+}
+
+func TestHCPStatusJSONSchema(t *testing.T) {
+	status := &HCPStatus{
+		ClusterID: "ext-id",
+		Health:    &HealthVerdict{Verdict: VerdictDegraded, Reasons: []HealthReason{{Severity: VerdictDegraded, Component: "NodePool/workers", Message: "Ready is False: scaling"}}},
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("failed to marshal status: %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("failed to unmarshal status: %v", err)
+	}
+
+	for _, key := range []string{"cluster_id", "cluster_name", "cluster_state", "management_cluster", "version", "manifest_works", "hosted_cluster_conditions", "node_pools", "health"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("expected key %q in JSON output: %s", key, data)
+		}
+	}
+
+	health := fields["health"].(map[string]interface{})
+	if health["verdict"] != "Degraded" || len(health["reasons"].([]interface{})) != 1 {
+		t.Errorf("unexpected health: %v", health)
+	}
+}
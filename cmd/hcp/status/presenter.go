@@ -1,12 +1,15 @@
 package status
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"os"
 	"strings"
 	"text/tabwriter"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
 // printStatus renders the full HCP cluster status to stdout.
@@ -65,6 +68,70 @@ func printStatus(s *HCPStatus) {
 			printNodePoolStatus(np)
 		}
 	}
+
+	if s.Health != nil {
+		printHealth(s.Health)
+	}
+}
+
+// printHealth renders the health verdict and the reasons behind it.
+func printHealth(h *HealthVerdict) {
+	fmt.Printf("HEALTH: %s\n", h.Verdict)
+	if len(h.Reasons) == 0 {
+		fmt.Println()
+		return
+	}
+
+	w := newTabWriter()
+	fmt.Fprintf(w, "  SEVERITY\tCOMPONENT\tREASON\n")
+	for _, r := range h.Reasons {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", r.Severity, r.Component, r.Message)
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+// printAsJSON renders the full HCP cluster status or fleet report as indented JSON.
+func printAsJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printAsYAML renders the full HCP cluster status or fleet report as YAML.
+func printAsYAML(v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status to YAML: %w", err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// printFleetReport renders the fleet summary and a row per failing check of the unhealthy clusters.
+func printFleetReport(r *FleetReport) {
+	fmt.Println()
+	fmt.Printf("FLEET HEALTH: %d clusters (%d healthy, %d degraded, %d critical, %d failed)\n",
+		r.TotalClusters, r.Healthy, r.Degraded, r.Critical, r.Failed)
+	fmt.Println()
+
+	if len(r.UnhealthyClusters) == 0 {
+		fmt.Printf("All %d clusters are healthy\n", r.TotalClusters)
+		return
+	}
+
+	w := newTabWriter()
+	fmt.Fprintf(w, "CLUSTER ID\tNAME\tVERDICT\tCOMPONENT\tREASON\n")
+	for _, c := range r.UnhealthyClusters {
+		if c.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.ClusterID, c.ClusterName, "Error", "-", c.Error)
+			continue
+		}
+		for _, reason := range c.Reasons {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.ClusterID, c.ClusterName, reason.Severity, reason.Component, reason.Message)
+		}
+	}
+	w.Flush()
 }
 
 // printHostedClusterStatus renders the HostedCluster section with version and conditions.
@@ -2,13 +2,32 @@ package status
 
 import (
 	"fmt"
+	"os"
+	"time"
 
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/osdctl/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
 type statusOptions struct {
-	clusterID string
+	clusterID    string
+	output       string
+	mgmtCluster  string
+	clustersFile string
+	orgID        string
+	concurrency  int
+	qps          int
+	watch        bool
+	interval     time.Duration
+	eventsFile   string
 }
 
 // NewCmdStatus creates and returns the status command.
@@ -20,58 +39,180 @@ func NewCmdStatus() *cobra.Command {
 		Short: "Show HCP cluster health status from OCM live resources",
 		Long: `Display a comprehensive health overview of a ROSA HCP cluster using
 data from the OCM live resources endpoint. Shows ManifestWork sync status,
-HostedCluster conditions, certificate status, and NodePool health.`,
+HostedCluster conditions, certificate status, and NodePool health.
+
+A health verdict is computed from the status: Critical when the cluster is unavailable,
+a ManifestWork has been failing for more than 15 minutes or a certificate expires within
+7 days, Degraded for any other failing condition or a certificate expiring within 30 days,
+Healthy otherwise.
+
+With --mc, --clusters-file or --org, the status of many HCP clusters is fetched
+concurrently and only the unhealthy clusters are printed, with their failing conditions.
+
+With --watch, the status of the cluster is polled every --interval and only the transitions are
+printed with their timestamp: condition changes, ManifestWork re-syncs, NodePool scaling, version
+progress and health verdict changes. With -o json they are printed as JSON lines, and --events-file
+appends them as JSON lines to a file, e.g. to keep a timeline of an upgrade.
+
+Outside of watch mode, the exit code reflects the verdict (the worst one in fleet mode): 0 when Healthy,
+2 when Degraded, 3 when Critical. 1 is returned when a status cannot be retrieved, in fleet mode as soon as
+the status of one of the clusters cannot be retrieved.`,
 		Example: `  # Show HCP cluster status
-  osdctl hcp status --cluster-id ${CLUSTER_ID}`,
+  osdctl hcp status --cluster-id ${CLUSTER_ID}
+
+  # Get the status as JSON, e.g. for scripting
+  osdctl hcp status --cluster-id ${CLUSTER_ID} -o json | jq .health
+
+  # Show the unhealthy hosted clusters of a management cluster, e.g. before upgrading it
+  osdctl hcp status --mc ${MGMT_CLUSTER_ID}
+
+  # Show the unhealthy HCP clusters of a list or of an organization
+  osdctl hcp status --clusters-file clusters.json
+  osdctl hcp status --org ${ORG_ID}
+
+  # Follow a control plane upgrade, keeping a timeline of the transitions
+  osdctl hcp status --cluster-id ${CLUSTER_ID} --watch --interval 30s --events-file upgrade-events.jsonl`,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return opts.run()
+			var verdict Verdict
+			var err error
+			if opts.watch {
+				return opts.runWatch(cmd.Context())
+			}
+			if opts.isFleetMode() {
+				verdict, err = opts.runFleet(cmd.Context())
+			} else {
+				verdict, err = opts.run()
+			}
+			if err != nil {
+				return err
+			}
+			if exitCode := verdict.ExitCode(); exitCode != exitCodeHealthy {
+				os.Exit(exitCode)
+			}
+			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&opts.clusterID, "cluster-id", "C", "", "Cluster name, ID, or external ID")
-	_ = cmd.MarkFlagRequired("cluster-id")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", outputText, "Output format: text, json, yaml")
+	cmd.Flags().StringVar(&opts.mgmtCluster, "mc", "", "Management cluster name or ID, to check all of its hosted clusters")
+	cmd.Flags().StringVarP(&opts.clustersFile, "clusters-file", "c", "", "JSON file containing cluster IDs to check (format: {\"clusters\":[\"$CLUSTERID1\", \"$CLUSTERID2\"]})")
+	cmd.Flags().StringVar(&opts.orgID, "org", "", "Organization ID, to check all of its HCP clusters")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 10, "Maximum number of clusters whose status is fetched concurrently in fleet mode")
+	cmd.Flags().IntVar(&opts.qps, "qps", 5, "Maximum number of OCM live resources requests per second in fleet mode")
+
+	cmd.Flags().BoolVarP(&opts.watch, "watch", "w", false, "Poll the status of the cluster and print its transitions until interrupted")
+	cmd.Flags().DurationVar(&opts.interval, "interval", defaultWatchInterval, "Interval between two polls in watch mode")
+	cmd.Flags().StringVar(&opts.eventsFile, "events-file", "", "File to append the transitions to as JSON lines in watch mode")
+
+	cmd.MarkFlagsOneRequired("cluster-id", "mc", "clusters-file", "org")
+	cmd.MarkFlagsMutuallyExclusive("cluster-id", "mc", "clusters-file", "org")
+	cmd.MarkFlagsMutuallyExclusive("watch", "mc")
+	cmd.MarkFlagsMutuallyExclusive("watch", "clusters-file")
+	cmd.MarkFlagsMutuallyExclusive("watch", "org")
 
 	return cmd
 }
 
-func (o *statusOptions) run() error {
+func (o *statusOptions) validate() error {
+	switch o.output {
+	case outputText, outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("invalid output format '%s'. Valid options: text, json, yaml", o.output)
+	}
+
+	if o.concurrency < 1 || o.qps < 1 {
+		return fmt.Errorf("--concurrency and --qps must be at least 1")
+	}
+
+	if o.watch {
+		if o.output == outputYAML {
+			return fmt.Errorf("output format 'yaml' is not supported in watch mode. Valid options: text, json")
+		}
+		if o.interval < minWatchInterval {
+			return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+		}
+	} else if o.eventsFile != "" {
+		return fmt.Errorf("--events-file can only be used with --watch")
+	}
+
+	return nil
+}
+
+func (o *statusOptions) isFleetMode() bool {
+	return o.mgmtCluster != "" || o.clustersFile != "" || o.orgID != ""
+}
+
+// run prints the status of the cluster and returns its health verdict.
+func (o *statusOptions) run() (Verdict, error) {
+	if err := o.validate(); err != nil {
+		return "", err
+	}
+
+	status, err := fetchStatus(o.clusterID)
+	if err != nil {
+		return "", err
+	}
+
+	switch o.output {
+	case outputJSON:
+		err = printAsJSON(status)
+	case outputYAML:
+		err = printAsYAML(status)
+	default:
+		printStatus(status)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return status.Health.Verdict, nil
+}
+
+// fetchStatus returns the status of the given HCP cluster from its OCM live resources, with its health verdict.
+func fetchStatus(clusterID string) (*HCPStatus, error) {
 	conn, err := utils.CreateConnection()
 	if err != nil {
-		return fmt.Errorf("failed to create OCM connection: %w", err)
+		return nil, fmt.Errorf("failed to create OCM connection: %w", err)
 	}
 	defer conn.Close()
 
-	cluster, err := utils.GetCluster(conn, o.clusterID)
+	cluster, err := utils.GetCluster(conn, clusterID)
 	if err != nil {
-		return fmt.Errorf("failed to find cluster: %w", err)
+		return nil, fmt.Errorf("failed to find cluster: %w", err)
 	}
 
 	if !cluster.Hypershift().Enabled() {
-		return fmt.Errorf("cluster %q is not an HCP cluster", o.clusterID)
+		return nil, fmt.Errorf("cluster %q is not an HCP cluster", clusterID)
 	}
 
+	return FetchClusterStatus(conn, cluster)
+}
+
+// FetchClusterStatus returns the status of the given HCP cluster from its OCM live resources, with its health verdict.
+func FetchClusterStatus(conn *sdk.Connection, cluster *cmv1.Cluster) (*HCPStatus, error) {
 	liveResponse, err := conn.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Resources().Live().Get().Send()
 	if err != nil {
-		return fmt.Errorf("failed to get live resources: %w", err)
+		return nil, fmt.Errorf("failed to get live resources: %w", err)
 	}
 
 	resources := liveResponse.Body().Resources()
 	if len(resources) == 0 {
-		return fmt.Errorf("no live resources found for cluster %s", cluster.ID())
+		return nil, fmt.Errorf("no live resources found for cluster %s", cluster.ID())
 	}
 
 	status, err := parseLiveResources(resources, cluster.ID())
 	if err != nil {
-		return fmt.Errorf("failed to parse live resources: %w", err)
+		return nil, fmt.Errorf("failed to parse live resources: %w", err)
 	}
 
 	status.ClusterID = cluster.ExternalID()
 	status.ClusterName = cluster.Name()
 	status.ClusterState = string(cluster.State())
 
-	printStatus(status)
+	status.Health = evaluateHealth(status, time.Now())
 
-	return nil
+	return status, nil
 }
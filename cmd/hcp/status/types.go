@@ -3,59 +3,128 @@ package status
 import "time"
 
 // HCPStatus holds the parsed status of an HCP cluster from the live endpoint.
+// Its JSON/YAML representation is the stable output schema of the status command.
 type HCPStatus struct {
-	ClusterID               string
-	ClusterName             string
-	ClusterState            string
-	ManagementCluster       string
-	Version                 VersionInfo
-	APIServerCertificate    *CertificateStatus
-	IngressCertificate      *CertificateStatus
-	ManifestWorks           []ManifestWorkSync
-	HostedClusterConditions []Condition
-	NodePools               []NodePoolStatus
+	ClusterID               string             `json:"cluster_id" yaml:"cluster_id"`
+	ClusterName             string             `json:"cluster_name" yaml:"cluster_name"`
+	ClusterState            string             `json:"cluster_state" yaml:"cluster_state"`
+	ManagementCluster       string             `json:"management_cluster" yaml:"management_cluster"`
+	Version                 VersionInfo        `json:"version" yaml:"version"`
+	APIServerCertificate    *CertificateStatus `json:"api_server_certificate,omitempty" yaml:"api_server_certificate,omitempty"`
+	IngressCertificate      *CertificateStatus `json:"ingress_certificate,omitempty" yaml:"ingress_certificate,omitempty"`
+	ManifestWorks           []ManifestWorkSync `json:"manifest_works" yaml:"manifest_works"`
+	HostedClusterConditions []Condition        `json:"hosted_cluster_conditions" yaml:"hosted_cluster_conditions"`
+	NodePools               []NodePoolStatus   `json:"node_pools" yaml:"node_pools"`
+	Health                  *HealthVerdict     `json:"health,omitempty" yaml:"health,omitempty"`
 }
 
 // ManifestWorkSync represents the sync status of a single ManifestWork.
 type ManifestWorkSync struct {
-	Name         string
-	Applied      bool
-	Available    bool
-	LastSyncTime time.Time
+	Name         string    `json:"name" yaml:"name"`
+	Applied      bool      `json:"applied" yaml:"applied"`
+	Available    bool      `json:"available" yaml:"available"`
+	LastSyncTime time.Time `json:"last_sync_time" yaml:"last_sync_time"`
 }
 
 // VersionInfo holds cluster version details.
 type VersionInfo struct {
-	Current          string
-	Desired          string
-	Status           string
-	Image            string
-	AvailableUpdates []string
+	Current          string   `json:"current" yaml:"current"`
+	Desired          string   `json:"desired" yaml:"desired"`
+	Status           string   `json:"status" yaml:"status"`
+	Image            string   `json:"image" yaml:"image"`
+	AvailableUpdates []string `json:"available_updates" yaml:"available_updates"`
 }
 
 // CertificateStatus holds the certificate details.
 type CertificateStatus struct {
-	Ready       *bool // nil = unknown, true/false = known status
-	NotAfter    time.Time
-	RenewalTime time.Time
-	DNSNames    []string
+	Ready       *bool     `json:"ready" yaml:"ready"` // nil = unknown, true/false = known status
+	NotAfter    time.Time `json:"not_after" yaml:"not_after"`
+	RenewalTime time.Time `json:"renewal_time" yaml:"renewal_time"`
+	DNSNames    []string  `json:"dns_names" yaml:"dns_names"`
 }
 
 // Condition represents a single condition from a HostedCluster or NodePool.
 type Condition struct {
-	Type               string
-	Status             string
-	Reason             string
-	Message            string
-	LastTransitionTime string
+	Type               string `json:"type" yaml:"type"`
+	Status             string `json:"status" yaml:"status"`
+	Reason             string `json:"reason" yaml:"reason"`
+	Message            string `json:"message" yaml:"message"`
+	LastTransitionTime string `json:"last_transition_time" yaml:"last_transition_time"`
 }
 
 // NodePoolStatus holds the status of a single NodePool.
 type NodePoolStatus struct {
-	Name       string
-	Replicas   int
-	Version    string
-	Conditions []Condition
+	Name       string      `json:"name" yaml:"name"`
+	Replicas   int         `json:"replicas" yaml:"replicas"`
+	Version    string      `json:"version" yaml:"version"`
+	Conditions []Condition `json:"conditions" yaml:"conditions"`
+}
+
+// Verdict is the overall health of an HCP cluster, ordered by severity.
+type Verdict string
+
+const (
+	VerdictHealthy  Verdict = "Healthy"
+	VerdictDegraded Verdict = "Degraded"
+	VerdictCritical Verdict = "Critical"
+)
+
+// HealthVerdict is the health computed from the status of an HCP cluster.
+type HealthVerdict struct {
+	Verdict Verdict        `json:"verdict" yaml:"verdict"`
+	Reasons []HealthReason `json:"reasons" yaml:"reasons"`
+}
+
+// HealthReason explains why a cluster is not healthy.
+type HealthReason struct {
+	Severity  Verdict `json:"severity" yaml:"severity"`
+	Component string  `json:"component" yaml:"component"`
+	Message   string  `json:"message" yaml:"message"`
+}
+
+// FleetClusterStatus is the health of a single cluster checked in fleet mode.
+type FleetClusterStatus struct {
+	ClusterID   string         `json:"cluster_id" yaml:"cluster_id"`
+	ExternalID  string         `json:"external_id" yaml:"external_id"`
+	ClusterName string         `json:"cluster_name" yaml:"cluster_name"`
+	Verdict     Verdict        `json:"verdict,omitempty" yaml:"verdict,omitempty"`
+	Reasons     []HealthReason `json:"reasons,omitempty" yaml:"reasons,omitempty"`
+	Error       string         `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// FleetReport summarizes the health of many HCP clusters, detailing only the unhealthy ones.
+type FleetReport struct {
+	TotalClusters     int                  `json:"total_clusters" yaml:"total_clusters"`
+	Healthy           int                  `json:"healthy" yaml:"healthy"`
+	Degraded          int                  `json:"degraded" yaml:"degraded"`
+	Critical          int                  `json:"critical" yaml:"critical"`
+	Failed            int                  `json:"failed" yaml:"failed"`
+	UnhealthyClusters []FleetClusterStatus `json:"unhealthy_clusters" yaml:"unhealthy_clusters"`
+}
+
+// EventKind is the kind of change between two successive statuses of a watched cluster.
+type EventKind string
+
+const (
+	EventConditionChanged   EventKind = "ConditionChanged"
+	EventManifestWorkSynced EventKind = "ManifestWorkSynced"
+	EventNodePoolScaled     EventKind = "NodePoolScaled"
+	EventVersionProgressed  EventKind = "VersionProgressed"
+	EventHealthChanged      EventKind = "HealthChanged"
+	EventComponentAdded     EventKind = "ComponentAdded"
+	EventComponentRemoved   EventKind = "ComponentRemoved"
+)
+
+// StatusEvent is a transition observed while watching an HCP cluster.
+// Its JSON representation is the format of the lines of the watch events file.
+type StatusEvent struct {
+	Time      time.Time `json:"time" yaml:"time"`
+	ClusterID string    `json:"cluster_id" yaml:"cluster_id"`
+	Kind      EventKind `json:"kind" yaml:"kind"`
+	Component string    `json:"component" yaml:"component"`
+	Previous  string    `json:"previous,omitempty" yaml:"previous,omitempty"`
+	Current   string    `json:"current,omitempty" yaml:"current,omitempty"`
+	Message   string    `json:"message" yaml:"message"`
 }
 
 // mainMWResult holds the parsed output from the main ManifestWork.
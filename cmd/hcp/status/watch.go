@@ -0,0 +1,241 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/openshift/osdctl/pkg/utils"
+)
+
+const (
+	defaultWatchInterval = 30 * time.Second
+	minWatchInterval     = 5 * time.Second
+)
+
+// runWatch polls the status of the cluster until interrupted, printing the transitions between successive statuses.
+func (o *statusOptions) runWatch(ctx context.Context) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var eventsFile *os.File
+	if o.eventsFile != "" {
+		var err error
+		eventsFile, err = os.OpenFile(o.eventsFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open events file: %w", err)
+		}
+		defer eventsFile.Close()
+	}
+
+	conn, err := utils.CreateConnection()
+	if err != nil {
+		return fmt.Errorf("failed to create OCM connection: %w", err)
+	}
+	defer conn.Close()
+
+	cluster, err := utils.GetCluster(conn, o.clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to find cluster: %w", err)
+	}
+	if !cluster.Hypershift().Enabled() {
+		return fmt.Errorf("cluster %q is not an HCP cluster", o.clusterID)
+	}
+
+	previous, err := FetchClusterStatus(conn, cluster)
+	if err != nil {
+		return err
+	}
+	if o.output == outputText {
+		printStatus(previous)
+		fmt.Printf("Watching %s every %s, press Ctrl+C to stop\n\n", cluster.Name(), o.interval)
+	}
+
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := FetchClusterStatus(conn, cluster)
+		if err != nil {
+			// Live resources are briefly unavailable during some upgrades, keep watching
+			fmt.Fprintf(os.Stderr, "%s Warning: %v\n", time.Now().UTC().Format(time.RFC3339), err)
+			continue
+		}
+
+		for _, event := range diffStatus(previous, current, time.Now().UTC()) {
+			if err := writeEvent(os.Stdout, o.output, event); err != nil {
+				return err
+			}
+			if eventsFile != nil {
+				if err := writeEvent(eventsFile, outputJSON, event); err != nil {
+					return fmt.Errorf("failed to write to events file: %w", err)
+				}
+			}
+		}
+		previous = current
+	}
+}
+
+// writeEvent writes the event as a line, JSON encoded unless the output is text.
+func writeEvent(w io.Writer, output string, event StatusEvent) error {
+	if output != outputText {
+		return json.NewEncoder(w).Encode(event)
+	}
+
+	_, err := fmt.Fprintf(w, "%s  %-20s %-32s %s\n", event.Time.Format(time.RFC3339), event.Kind, event.Component, event.Message)
+	return err
+}
+
+// statusDiffer collects the transitions between two statuses of a cluster.
+type statusDiffer struct {
+	now       time.Time
+	clusterID string
+	events    []StatusEvent
+}
+
+func (d *statusDiffer) add(kind EventKind, component string, previous string, current string, format string, args ...interface{}) {
+	d.events = append(d.events, StatusEvent{
+		Time:      d.now,
+		ClusterID: d.clusterID,
+		Kind:      kind,
+		Component: component,
+		Previous:  previous,
+		Current:   current,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// diffStatus returns the transitions from the previous to the current status of a cluster: condition changes,
+// ManifestWork re-syncs, NodePool scaling, version progress and health verdict changes.
+func diffStatus(previous *HCPStatus, current *HCPStatus, now time.Time) []StatusEvent {
+	d := &statusDiffer{now: now, clusterID: current.ClusterID}
+
+	d.diffVersion("HostedCluster", previous.Version, current.Version)
+	d.diffConditions("HostedCluster", previous.HostedClusterConditions, current.HostedClusterConditions)
+	d.diffManifestWorks(previous.ManifestWorks, current.ManifestWorks)
+	d.diffNodePools(previous.NodePools, current.NodePools)
+
+	if previous.Health != nil && current.Health != nil && previous.Health.Verdict != current.Health.Verdict {
+		d.add(EventHealthChanged, "Cluster", string(previous.Health.Verdict), string(current.Health.Verdict),
+			"health %s -> %s", previous.Health.Verdict, current.Health.Verdict)
+	}
+
+	return d.events
+}
+
+func (d *statusDiffer) diffVersion(component string, previous VersionInfo, current VersionInfo) {
+	if previous.Current != current.Current {
+		d.add(EventVersionProgressed, component, previous.Current, current.Current, "version %s -> %s", previous.Current, current.Current)
+	}
+	if previous.Desired != current.Desired {
+		d.add(EventVersionProgressed, component, previous.Desired, current.Desired, "desired version %s -> %s", previous.Desired, current.Desired)
+	}
+	if previous.Status != current.Status {
+		d.add(EventVersionProgressed, component, previous.Status, current.Status, "version status %s -> %s", previous.Status, current.Status)
+	}
+}
+
+func (d *statusDiffer) diffConditions(component string, previous []Condition, current []Condition) {
+	previousByType := map[string]Condition{}
+	for _, c := range previous {
+		previousByType[c.Type] = c
+	}
+
+	currentTypes := map[string]bool{}
+	for _, c := range current {
+		currentTypes[c.Type] = true
+
+		p, ok := previousByType[c.Type]
+		switch {
+		case !ok:
+			d.add(EventConditionChanged, component, "", c.Status, "%s is %s: %s", c.Type, c.Status, conditionDetails(c))
+		case p.Status != c.Status || p.Reason != c.Reason:
+			d.add(EventConditionChanged, component, p.Status, c.Status, "%s %s -> %s: %s", c.Type, p.Status, c.Status, conditionDetails(c))
+		}
+	}
+
+	for _, p := range previous {
+		if !currentTypes[p.Type] {
+			d.add(EventConditionChanged, component, p.Status, "", "%s condition removed", p.Type)
+		}
+	}
+}
+
+func (d *statusDiffer) diffManifestWorks(previous []ManifestWorkSync, current []ManifestWorkSync) {
+	previousByName := map[string]ManifestWorkSync{}
+	for _, mw := range previous {
+		previousByName[mw.Name] = mw
+	}
+
+	currentNames := map[string]bool{}
+	for _, mw := range current {
+		currentNames[mw.Name] = true
+		component := "ManifestWork/" + mw.Name
+
+		p, ok := previousByName[mw.Name]
+		if !ok {
+			d.add(EventComponentAdded, component, "", "", "ManifestWork created")
+			continue
+		}
+		if p.Applied != mw.Applied || p.Available != mw.Available || !p.LastSyncTime.Equal(mw.LastSyncTime) {
+			d.add(EventManifestWorkSynced, component, manifestWorkState(p), manifestWorkState(mw), "re-synced: %s", manifestWorkState(mw))
+		}
+	}
+
+	for _, p := range previous {
+		if !currentNames[p.Name] {
+			d.add(EventComponentRemoved, "ManifestWork/"+p.Name, "", "", "ManifestWork removed")
+		}
+	}
+}
+
+func manifestWorkState(mw ManifestWorkSync) string {
+	return fmt.Sprintf("applied=%s available=%s", boolStatus(mw.Applied), boolStatus(mw.Available))
+}
+
+func (d *statusDiffer) diffNodePools(previous []NodePoolStatus, current []NodePoolStatus) {
+	previousByName := map[string]NodePoolStatus{}
+	for _, np := range previous {
+		previousByName[np.Name] = np
+	}
+
+	currentNames := map[string]bool{}
+	for _, np := range current {
+		currentNames[np.Name] = true
+		component := "NodePool/" + np.Name
+
+		p, ok := previousByName[np.Name]
+		if !ok {
+			d.add(EventComponentAdded, component, "", "", "NodePool created with %d replicas", np.Replicas)
+			continue
+		}
+		if p.Replicas != np.Replicas {
+			d.add(EventNodePoolScaled, component, strconv.Itoa(p.Replicas), strconv.Itoa(np.Replicas), "replicas %d -> %d", p.Replicas, np.Replicas)
+		}
+		if p.Version != np.Version {
+			d.add(EventVersionProgressed, component, p.Version, np.Version, "version %s -> %s", p.Version, np.Version)
+		}
+		d.diffConditions(component, p.Conditions, np.Conditions)
+	}
+
+	for _, p := range previous {
+		if !currentNames[p.Name] {
+			d.add(EventComponentRemoved, "NodePool/"+p.Name, "", "", "NodePool removed")
+		}
+	}
+}
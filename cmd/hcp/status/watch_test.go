@@ -0,0 +1,136 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffStatus(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	baseStatus := func() *HCPStatus {
+		return &HCPStatus{
+			ClusterID: "ext-id",
+			Version:   VersionInfo{Current: "4.15.1", Desired: "4.15.1", Status: "Completed"},
+			ManifestWorks: []ManifestWorkSync{
+				{Name: "mw-1", Applied: true, Available: true, LastSyncTime: now.Add(-time.Hour)},
+			},
+			HostedClusterConditions: []Condition{
+				{Type: "Available", Status: "True", Reason: "AsExpected"},
+				{Type: "Progressing", Status: "False", Reason: "AsExpected"},
+			},
+			NodePools: []NodePoolStatus{
+				{Name: "workers", Replicas: 2, Version: "4.15.1", Conditions: []Condition{{Type: "Ready", Status: "True"}}},
+			},
+			Health: &HealthVerdict{Verdict: VerdictHealthy},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		update         func(s *HCPStatus)
+		expectedEvents []string
+	}{
+		{
+			name:   "No change",
+			update: func(s *HCPStatus) {},
+		},
+		{
+			name: "Upgrade started",
+			update: func(s *HCPStatus) {
+				s.Version.Desired = "4.15.2"
+				s.Version.Status = "Partial"
+				s.HostedClusterConditions[1] = Condition{Type: "Progressing", Status: "True", Message: "Working towards 4.15.2"}
+			},
+			expectedEvents: []string{
+				"VersionProgressed HostedCluster desired version 4.15.1 -> 4.15.2",
+				"VersionProgressed HostedCluster version status Completed -> Partial",
+				"ConditionChanged HostedCluster Progressing False -> True: Working towards 4.15.2",
+			},
+		},
+		{
+			name: "ManifestWork re-synced and NodePool scaled",
+			update: func(s *HCPStatus) {
+				s.ManifestWorks[0].LastSyncTime = now
+				s.NodePools[0].Replicas = 3
+				s.NodePools[0].Conditions[0] = Condition{Type: "Ready", Status: "False", Reason: "ScalingUp"}
+				s.Health = &HealthVerdict{Verdict: VerdictDegraded}
+			},
+			expectedEvents: []string{
+				"ManifestWorkSynced ManifestWork/mw-1 re-synced: applied=True available=True",
+				"NodePoolScaled NodePool/workers replicas 2 -> 3",
+				"ConditionChanged NodePool/workers Ready True -> False: ScalingUp",
+				"HealthChanged Cluster health Healthy -> Degraded",
+			},
+		},
+		{
+			name: "Components added and removed",
+			update: func(s *HCPStatus) {
+				s.ManifestWorks = append(s.ManifestWorks, ManifestWorkSync{Name: "mw-2"})
+				s.NodePools = []NodePoolStatus{{Name: "infra", Replicas: 1}}
+				s.HostedClusterConditions = s.HostedClusterConditions[:1]
+			},
+			expectedEvents: []string{
+				"ConditionChanged HostedCluster Progressing condition removed",
+				"ComponentAdded ManifestWork/mw-2 ManifestWork created",
+				"ComponentAdded NodePool/infra NodePool created with 1 replicas",
+				"ComponentRemoved NodePool/workers NodePool removed",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := baseStatus()
+			tt.update(current)
+
+			events := diffStatus(baseStatus(), current, now)
+
+			var got []string
+			for _, e := range events {
+				if !e.Time.Equal(now) || e.ClusterID != "ext-id" {
+					t.Errorf("unexpected event metadata: %+v", e)
+				}
+				got = append(got, strings.Join([]string{string(e.Kind), e.Component, e.Message}, " "))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expectedEvents, "\n") {
+				t.Errorf("expected events:\n%s\ngot:\n%s", strings.Join(tt.expectedEvents, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	event := StatusEvent{
+		Time:      time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC),
+		ClusterID: "ext-id",
+		Kind:      EventNodePoolScaled,
+		Component: "NodePool/workers",
+		Previous:  "2",
+		Current:   "3",
+		Message:   "replicas 2 -> 3",
+	}
+
+	var text bytes.Buffer
+	if err := writeEvent(&text, outputText, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(text.String(), "2024-05-02T12:00:00Z  NodePoolScaled") || !strings.HasSuffix(text.String(), "replicas 2 -> 3\n") {
+		t.Errorf("unexpected text event: %q", text.String())
+	}
+
+	var jsonLine bytes.Buffer
+	if err := writeEvent(&jsonLine, outputJSON, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jsonLine.Bytes(), &fields); err != nil {
+		t.Fatalf("failed to unmarshal event: %v", err)
+	}
+	if fields["kind"] != "NodePoolScaled" || fields["previous"] != "2" || fields["current"] != "3" || fields["cluster_id"] != "ext-id" {
+		t.Errorf("unexpected JSON event: %s", jsonLine.String())
+	}
+}
@@ -1,6 +1,7 @@
 package transitiontoeus
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,6 +14,8 @@ import (
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/osdctl/internal/io"
+	"github.com/openshift/osdctl/internal/journal"
+	"github.com/openshift/osdctl/internal/rollout"
 	"github.com/openshift/osdctl/internal/servicelog"
 	"github.com/openshift/osdctl/internal/utils"
 	ocmutils "github.com/openshift/osdctl/pkg/utils"
@@ -24,9 +27,14 @@ type transitionOptions struct {
 	clusterID    string
 	clustersFile string
 	dryRun       bool
+	journalFile  string
+	rollout      rollout.Options
 
 	// Parsed cluster IDs (populated during validation)
 	clusterIDs []string
+
+	// Records the mutating OCM calls, nil in dry-run mode
+	journal *journal.Journal
 }
 
 // Service log template mappings
@@ -76,6 +84,17 @@ SERVICE LOG BEHAVIOR:
 - After each successful transition, you will be prompted to optionally send a service log notification
 - On failures where recurring policy was modified and restored: You will be prompted to send an 'attempted' notification to the customer
 
+STAGED ROLLOUT:
+With --wave-by, the clusters are split into waves by percentage, management cluster or region. Before starting
+a wave, the command waits for the clusters of the previous wave to be on the 'eus' channel, and aborts when more
+than --failure-threshold percent of the processed clusters failed. --rollout-state persists the progress so that
+an interrupted rollout can be resumed with --resume.
+
+JOURNAL:
+Every mutating OCM call (deleted and restored upgrade policies, channel updates, service logs sent) is recorded
+as it happens to a JSON lines journal, written to --journal or to a timestamped file in the current directory.
+The upgrade policies deleted but not restored can be recreated from it with 'osdctl hcp journal restore'.
+
 This approach extends the support lifecycle for clusters on even y-streams without forcing upgrades.`,
 		Example: `  # Transition single cluster (will prompt to send service log after success)
   osdctl hcp transition-to-eus -C cluster123
@@ -83,13 +102,19 @@ This approach extends the support lifecycle for clusters on even y-streams witho
   # Multiple clusters from file
   osdctl hcp transition-to-eus --clusters-file clusters.json
 
+  # Transition the clusters one management cluster at a time, persisting the progress
+  osdctl hcp transition-to-eus --clusters-file clusters.json --wave-by mc --rollout-state eus-rollout.json
+
   # Dry-run to preview changes
   osdctl hcp transition-to-eus --clusters-file clusters.json --dry-run
+
+  # Recreate the upgrade policies which could not be restored, from the journal of the transition
+  osdctl hcp journal restore transition-to-eus-journal-20250101_120000.jsonl
 `,
 		Args:              cobra.NoArgs,
 		DisableAutoGenTag: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return opts.Run()
+			return opts.Run(cmd.Context())
 		},
 	}
 
@@ -99,6 +124,10 @@ This approach extends the support lifecycle for clusters on even y-streams witho
 
 	// Configuration flags
 	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Simulate the transition without making any changes")
+	cmd.Flags().StringVar(&opts.journalFile, "journal", "", "File to record the mutating OCM calls to (default: transition-to-eus-journal-<timestamp>.jsonl in the current directory)")
+
+	// Rollout flags
+	rollout.AddFlags(cmd.Flags(), &opts.rollout)
 
 	return cmd
 }
@@ -113,6 +142,10 @@ func (o *transitionOptions) validate() error {
 		return fmt.Errorf("cannot specify both --cluster-id and --clusters-file, choose one")
 	}
 
+	if err := o.rollout.Validate(); err != nil {
+		return err
+	}
+
 	// Validate cluster ID format when using single cluster ID
 	if o.clusterID != "" {
 		if !validClusterIDRegex.MatchString(o.clusterID) {
@@ -138,7 +171,7 @@ func (o *transitionOptions) validate() error {
 	return nil
 }
 
-func (o *transitionOptions) Run() error {
+func (o *transitionOptions) Run(ctx context.Context) error {
 	if err := o.validate(); err != nil {
 		return err
 	}
@@ -189,20 +222,25 @@ func (o *transitionOptions) Run() error {
 			return nil
 		}
 		fmt.Println()
+
+		if o.journalFile == "" {
+			o.journalFile = journal.DefaultPath("transition-to-eus")
+		}
+		o.journal, err = journal.Open(o.journalFile, "transition-to-eus")
+		if err != nil {
+			return err
+		}
+		defer o.journal.Close()
+		fmt.Printf("📒 Recording the changes to the journal: %s\n", o.journal.Path())
 	}
 
 	// Process only eligible clusters
 	clusters = eligibleClusters
 
-	var successful, failed []string
-
-	for i, cluster := range clusters {
-		fmt.Printf("\n[%d/%d] Processing cluster: %s (%s)\n", i+1, len(clusters), cluster.ID(), cluster.Name())
-
+	apply := func(cluster *v1.Cluster) error {
 		result := o.processCluster(ocmClient, cluster)
 
 		if result.err != nil {
-			failed = append(failed, fmt.Sprintf("%s: %s", cluster.ExternalID(), result.err.Error()))
 			fmt.Printf("  ⚠️  Failed to transition: %v\n", result.err)
 
 			// Handle service log notification based on policy modification state
@@ -214,7 +252,7 @@ func (o *transitionOptions) Run() error {
 						fmt.Printf("  ℹ️  Note: The recurring upgrade policy was modified during this attempt but has been restored.\n")
 
 						// Show service log preview and prompt
-						if err := promptAndSendServiceLog(ocmClient, cluster, "attempted"); err != nil {
+						if err := promptAndSendServiceLog(ocmClient, o.journal, cluster, "attempted"); err != nil {
 							fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
 						}
 					} else {
@@ -228,7 +266,7 @@ func (o *transitionOptions) Run() error {
 						fmt.Printf("  ⚠️  Action required: Use the policy details printed above to manually restore.\n")
 
 						// Show service log preview and prompt for critical failure
-						if err := promptAndSendServiceLog(ocmClient, cluster, "attempted"); err != nil {
+						if err := promptAndSendServiceLog(ocmClient, o.journal, cluster, "attempted"); err != nil {
 							fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
 						}
 					} else {
@@ -236,25 +274,38 @@ func (o *transitionOptions) Run() error {
 					}
 				}
 			}
-		} else {
-			successful = append(successful, cluster.ExternalID())
+			return result.err
+		}
 
-			// Prompt user to send service log after successful transition
-			if !o.dryRun {
-				fmt.Println()
-				fmt.Printf("  ✅ Transition completed successfully!\n")
+		// Prompt user to send service log after successful transition
+		if !o.dryRun {
+			fmt.Println()
+			fmt.Printf("  ✅ Transition completed successfully!\n")
 
-				// Show service log preview and prompt
-				if err := promptAndSendServiceLog(ocmClient, cluster, "success"); err != nil {
-					fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
-				}
-			} else {
-				fmt.Printf("  📧 DRY-RUN: Would prompt to send service log notification\n")
+			// Show service log preview and prompt
+			if err := promptAndSendServiceLog(ocmClient, o.journal, cluster, "success"); err != nil {
+				fmt.Printf("  ⚠️  Failed to send service log: %v\n", err)
 			}
+		} else {
+			fmt.Printf("  📧 DRY-RUN: Would prompt to send service log notification\n")
 		}
+		return nil
+	}
+
+	reached := func(cluster *v1.Cluster) (bool, error) {
+		return cluster.Version().ChannelGroup() == "eus", nil
+	}
+
+	result, err := rollout.NewExecutor(ocmClient, "transition-to-eus", o.rollout, o.dryRun, apply, reached).Run(ctx, clusters)
+	if err != nil {
+		return err
 	}
 
-	o.printSummary(successful, failed)
+	o.printSummary(result.Succeeded, result.Failed)
+	result.PrintSummary()
+	if o.journal != nil {
+		fmt.Printf("\n📒 Journal of the changes: %s\n", o.journal.Path())
+	}
 	return nil
 }
 
@@ -375,6 +426,12 @@ type clusterProcessResult struct {
 	unrestoredPolicies []*v1.ControlPlaneUpgradePolicy // policies that were deleted but could not be restored
 }
 
+// restoredPolicy is an upgrade policy recreated by restoreUpgradePolicies, deleted again on rollback
+type restoredPolicy struct {
+	id       string
+	sourceID string // ID of the deleted policy it recreates
+}
+
 func (o *transitionOptions) processCluster(ocmClient *sdk.Connection, cluster *v1.Cluster) *clusterProcessResult {
 	result := &clusterProcessResult{}
 
@@ -526,6 +583,7 @@ func (o *transitionOptions) deleteUpgradePolicies(ocmClient *sdk.Connection, clu
 	for _, policy := range policies {
 		_, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
 			ControlPlane().UpgradePolicies().ControlPlaneUpgradePolicy(policy.ID()).Delete().Send()
+		o.journal.RecordPolicy(cluster, journal.ActionDeleteUpgradePolicy, policy, "", err)
 		if err != nil {
 			return deleted, fmt.Errorf("failed to delete policy %s: %w", policy.ID(), err)
 		}
@@ -543,33 +601,24 @@ func (o *transitionOptions) transitionChannelToEUS(ocmClient *sdk.Connection, cl
 	}
 
 	_, err = ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Update().Body(clusterUpdate).Send()
+	o.journal.Record(cluster, journal.ActionUpdateChannel, err, journal.Entry{
+		Details: map[string]string{"previousChannel": cluster.Version().ChannelGroup(), "channel": "eus"},
+	})
 	return err
 }
 
 func (o *transitionOptions) restoreUpgradePolicies(ocmClient *sdk.Connection, cluster *v1.Cluster, policies []*v1.ControlPlaneUpgradePolicy) error {
-	// Track newly created policy IDs for rollback if needed
-	var restoredPolicyIDs []string
+	// Track newly created policies for rollback if needed
+	var restoredPolicies []restoredPolicy
 
 	for i, originalPolicy := range policies {
 		// Build new policy from the original, preserving all fields except ID
 		// (ID will be generated by the API when we create it)
-		policyBuilder := v1.NewControlPlaneUpgradePolicy().
-			Schedule(originalPolicy.Schedule()).
-			ScheduleType(originalPolicy.ScheduleType()).
-			UpgradeType(originalPolicy.UpgradeType()).
-			EnableMinorVersionUpgrades(originalPolicy.EnableMinorVersionUpgrades())
-
-		// Add version only for manual policies - automatic policies must not have version set
-		// Manual policies target a specific version, automatic policies use the latest available
-		if originalPolicy.Version() != "" && originalPolicy.ScheduleType() == v1.ScheduleTypeManual {
-			policyBuilder = policyBuilder.Version(originalPolicy.Version())
-		}
-
-		newPolicy, err := policyBuilder.Build()
+		newPolicy, err := journal.NewRestoredPolicy(originalPolicy)
 		if err != nil {
 			// Rollback: delete any policies we've already restored
-			if len(restoredPolicyIDs) > 0 {
-				o.rollbackRestoredPolicies(ocmClient, cluster, restoredPolicyIDs)
+			if len(restoredPolicies) > 0 {
+				o.rollbackRestoredPolicies(ocmClient, cluster, restoredPolicies)
 			}
 			return fmt.Errorf("failed to build upgrade policy %d/%d: %w", i+1, len(policies), err)
 		}
@@ -577,15 +626,17 @@ func (o *transitionOptions) restoreUpgradePolicies(ocmClient *sdk.Connection, cl
 		response, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
 			ControlPlane().UpgradePolicies().Add().Body(newPolicy).Send()
 		if err != nil {
+			o.journal.RecordPolicy(cluster, journal.ActionRestoreUpgradePolicy, newPolicy, originalPolicy.ID(), err)
 			// Rollback: delete any policies we've already restored
-			if len(restoredPolicyIDs) > 0 {
-				o.rollbackRestoredPolicies(ocmClient, cluster, restoredPolicyIDs)
+			if len(restoredPolicies) > 0 {
+				o.rollbackRestoredPolicies(ocmClient, cluster, restoredPolicies)
 			}
 			return fmt.Errorf("failed to restore policy %d/%d: %w", i+1, len(policies), err)
 		}
+		o.journal.RecordPolicy(cluster, journal.ActionRestoreUpgradePolicy, response.Body(), originalPolicy.ID(), nil)
 
-		// Track the newly created policy ID for potential rollback
-		restoredPolicyIDs = append(restoredPolicyIDs, response.Body().ID())
+		// Track the newly created policy for potential rollback
+		restoredPolicies = append(restoredPolicies, restoredPolicy{id: response.Body().ID(), sourceID: originalPolicy.ID()})
 	}
 	return nil
 }
@@ -666,6 +717,9 @@ func (o *transitionOptions) attemptRestoreWithFallback(ocmClient *sdk.Connection
 		// CRITICAL: Failed to restore policies - print details for manual restoration
 		fmt.Printf("  ⚠️  CRITICAL: Failed to restore policies after %s\n", context)
 		printPoliciesForManualRestore(policies, cluster.ID())
+		if o.journal != nil {
+			fmt.Printf("  📒 The deleted policies can also be restored with: osdctl hcp journal restore %s --cluster-id %s\n", o.journal.Path(), cluster.ID())
+		}
 		result.unrestoredPolicies = policies
 		return fmt.Errorf("%w (CRITICAL: also failed to restore %d policies: %v)", contextErr, len(policies), restoreErr)
 	}
@@ -675,13 +729,17 @@ func (o *transitionOptions) attemptRestoreWithFallback(ocmClient *sdk.Connection
 }
 
 // rollbackRestoredPolicies deletes policies that were just created during a failed restore operation
-func (o *transitionOptions) rollbackRestoredPolicies(ocmClient *sdk.Connection, cluster *v1.Cluster, policyIDs []string) {
-	fmt.Printf("  🔁 Rolling back %d partially restored policies\n", len(policyIDs))
-	for _, policyID := range policyIDs {
+func (o *transitionOptions) rollbackRestoredPolicies(ocmClient *sdk.Connection, cluster *v1.Cluster, policies []restoredPolicy) {
+	fmt.Printf("  🔁 Rolling back %d partially restored policies\n", len(policies))
+	for _, policy := range policies {
 		_, err := ocmClient.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).
-			ControlPlane().UpgradePolicies().ControlPlaneUpgradePolicy(policyID).Delete().Send()
+			ControlPlane().UpgradePolicies().ControlPlaneUpgradePolicy(policy.id).Delete().Send()
+		o.journal.Record(cluster, journal.ActionRollbackUpgradePolicy, err, journal.Entry{
+			SourcePolicyID: policy.sourceID,
+			Details:        map[string]string{"restoredPolicyId": policy.id},
+		})
 		if err != nil {
-			fmt.Printf("  ⚠️  Warning: failed to rollback policy %s during restore failure: %v\n", policyID, err)
+			fmt.Printf("  ⚠️  Warning: failed to rollback policy %s during restore failure: %v\n", policy.id, err)
 		}
 	}
 }
@@ -748,7 +806,7 @@ func loadServiceLogTemplate(templateOrFile string) ([]byte, bool, error) {
 }
 
 // promptAndSendServiceLog shows the service log preview and prompts user to send it
-func promptAndSendServiceLog(ocmClient *sdk.Connection, cluster *v1.Cluster, templateName string) error {
+func promptAndSendServiceLog(ocmClient *sdk.Connection, serviceLogJournal *journal.Journal, cluster *v1.Cluster, templateName string) error {
 	// Load and prepare the message
 	templateBytes, usingDefaultTemplate, err := loadServiceLogTemplate(templateName)
 	if err != nil {
@@ -795,7 +853,11 @@ func promptAndSendServiceLog(ocmClient *sdk.Connection, cluster *v1.Cluster, tem
 
 	if ocmutils.ConfirmPrompt() {
 		fmt.Printf("  📄 Sending '%s' service log template\n", templateName)
-		if err := sendServiceLog(ocmClient, &message); err != nil {
+		err := sendServiceLog(ocmClient, &message)
+		serviceLogJournal.Record(cluster, journal.ActionSendServiceLog, err, journal.Entry{
+			Details: map[string]string{"template": templateName, "summary": message.Summary},
+		})
+		if err != nil {
 			return err
 		}
 		fmt.Printf("  📧 Service log notification sent successfully\n")
@@ -1,12 +1,16 @@
 package iampermissions
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
+	"slices"
+	"strings"
 
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	"github.com/openshift/osdctl/pkg/policies"
+	"github.com/openshift/osdctl/pkg/printer"
 	"github.com/spf13/cobra"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
@@ -15,28 +19,74 @@ type diffOptions struct {
 	BaseVersion   string
 	TargetVersion string
 	Cloud         policies.CloudSpec
+	Output        string
 	downloadFunc  func(string, policies.CloudSpec) (string, error)
-	execFunc      func(string, ...string) *exec.Cmd
+	parseFunc     func(string) ([]*cco.CredentialsRequest, error)
 	outputWriter  io.Writer
 }
 
 const (
 	baseVersionFlagName   = "base-version"
 	targetVersionFlagName = "target-version"
+
+	diffOutputTable    = "table"
+	diffOutputJSON     = "json"
+	diffOutputMarkdown = "markdown"
+)
+
+// operatorChange is how the CredentialsRequest of an operator changed between the two versions
+type operatorChange string
+
+const (
+	operatorAdded   operatorChange = "added"
+	operatorRemoved operatorChange = "removed"
+	operatorChanged operatorChange = "changed"
 )
 
+// actionChange is a change of the resources or the conditions an action is granted on
+type actionChange struct {
+	Action string   `json:"action"`
+	Base   []string `json:"base"`
+	Target []string `json:"target"`
+}
+
+// operatorDiff holds the permission changes of the CredentialsRequest of an operator
+type operatorDiff struct {
+	CredentialsRequest string         `json:"credentialsRequest"`
+	Change             operatorChange `json:"change"`
+	AddedActions       []string       `json:"addedActions,omitempty"`
+	RemovedActions     []string       `json:"removedActions,omitempty"`
+	ResourceChanges    []actionChange `json:"resourceChanges,omitempty"`
+	ConditionChanges   []actionChange `json:"conditionChanges,omitempty"`
+}
+
+type permissionsDiff struct {
+	Cloud         string         `json:"cloud"`
+	BaseVersion   string         `json:"baseVersion"`
+	TargetVersion string         `json:"targetVersion"`
+	Operators     []operatorDiff `json:"operators"`
+}
+
 func newCmdDiff() *cobra.Command {
 	ops := &diffOptions{
 		downloadFunc: policies.DownloadCredentialRequests,
-		execFunc:     exec.Command,
+		parseFunc:    policies.ParseCredentialsRequestsInDir,
 		outputWriter: os.Stdout,
 	}
 
 	policyCmd := &cobra.Command{
 		Use:   "diff",
 		Short: "Diff IAM permissions for cluster operators between two versions",
+		Long: `Diff IAM permissions for cluster operators between two versions.
+
+For each CredentialsRequest, the actions (or GCP permissions and predefined roles) added and removed
+in the target version are listed, along with the actions whose resources or conditions changed.
+CredentialsRequests which only exist in one of the versions are reported as added or removed.`,
 		Example: `  # Diff IAM permissions between two OCP versions
-  osdctl iampermissions diff --base-version 4.14.0 --target-version 4.15.0`,
+  osdctl iampermissions diff --base-version 4.14.0 --target-version 4.15.0
+
+  # Diff WIF permissions as Markdown, e.g. for a policy review
+  osdctl iampermissions diff --cloud wif --base-version 4.14.0 --target-version 4.15.0 --output markdown`,
 		Args:              cobra.ExactArgs(0),
 		DisableAutoGenTag: true,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -47,6 +97,7 @@ func newCmdDiff() *cobra.Command {
 
 	policyCmd.Flags().StringVarP(&ops.BaseVersion, baseVersionFlagName, "b", "", "")
 	policyCmd.Flags().StringVarP(&ops.TargetVersion, targetVersionFlagName, "t", "", "")
+	policyCmd.Flags().StringVarP(&ops.Output, "output", "o", diffOutputTable, "Output format: table, json, markdown")
 	_ = policyCmd.MarkFlagRequired(baseVersionFlagName)
 	_ = policyCmd.MarkFlagRequired(targetVersionFlagName)
 
@@ -54,21 +105,220 @@ func newCmdDiff() *cobra.Command {
 }
 
 func (o *diffOptions) run() error {
-	fmt.Fprintf(os.Stderr, "Downloading Credential Requests for %s\n", o.BaseVersion)
-	baseDir, err := o.downloadFunc(o.BaseVersion, o.Cloud)
+	switch o.Output {
+	case diffOutputTable, diffOutputJSON, diffOutputMarkdown:
+	default:
+		return fmt.Errorf("invalid output format '%s'. Valid options: table, json, markdown", o.Output)
+	}
+
+	basePermissions, err := o.versionPermissions(o.BaseVersion)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(os.Stderr, "Downloading Credential Requests for %s\n", o.TargetVersion)
-	targetDir, err := o.downloadFunc(o.TargetVersion, o.Cloud)
+	targetPermissions, err := o.versionPermissions(o.TargetVersion)
 	if err != nil {
 		return err
 	}
 
-	cmd := o.execFunc("diff", baseDir, targetDir)
-	output, _ := cmd.CombinedOutput() // #nosec G204
-	fmt.Fprintln(o.outputWriter, string(output))
+	diff := &permissionsDiff{
+		Cloud:         o.Cloud.String(),
+		BaseVersion:   o.BaseVersion,
+		TargetVersion: o.TargetVersion,
+		Operators:     diffPermissions(basePermissions, targetPermissions),
+	}
+
+	switch o.Output {
+	case diffOutputJSON:
+		encoder := json.NewEncoder(o.outputWriter)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	case diffOutputMarkdown:
+		printDiffMarkdown(o.outputWriter, diff)
+	default:
+		return printDiffTable(o.outputWriter, diff)
+	}
 
 	return nil
 }
+
+// versionPermissions returns the permissions of the CredentialsRequests of a version, by CredentialsRequest name
+func (o *diffOptions) versionPermissions(version string) (map[string]policies.OperatorPermissions, error) {
+	fmt.Fprintf(os.Stderr, "Downloading Credential Requests for %s\n", version)
+	dir, err := o.downloadFunc(version, o.Cloud)
+	if err != nil {
+		return nil, err
+	}
+
+	crs, err := o.parseFunc(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string]policies.OperatorPermissions, len(crs))
+	for _, cr := range crs {
+		crPermissions, err := policies.CredentialsRequestPermissions(cr, o.Cloud)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing CredentialsRequest '%s' of %s: %w", cr.Name, version, err)
+		}
+		permissions[cr.Name] = crPermissions
+	}
+
+	return permissions, nil
+}
+
+// diffPermissions returns the changed CredentialsRequests, sorted by name
+func diffPermissions(base map[string]policies.OperatorPermissions, target map[string]policies.OperatorPermissions) []operatorDiff {
+	var names []string
+	for name := range base {
+		names = append(names, name)
+	}
+	for name := range target {
+		if _, ok := base[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	diffs := []operatorDiff{}
+	for _, name := range names {
+		basePermissions, inBase := base[name]
+		targetPermissions, inTarget := target[name]
+
+		diff := operatorDiff{CredentialsRequest: name, Change: operatorChanged}
+		switch {
+		case !inBase:
+			diff.Change = operatorAdded
+			diff.AddedActions = targetPermissions.Actions()
+		case !inTarget:
+			diff.Change = operatorRemoved
+			diff.RemovedActions = basePermissions.Actions()
+		default:
+			for _, action := range targetPermissions.Actions() {
+				baseGrant, ok := basePermissions[action]
+				if !ok {
+					diff.AddedActions = append(diff.AddedActions, action)
+					continue
+				}
+				targetGrant := targetPermissions[action]
+				if !slices.Equal(baseGrant.Resources, targetGrant.Resources) {
+					diff.ResourceChanges = append(diff.ResourceChanges, actionChange{Action: action, Base: baseGrant.Resources, Target: targetGrant.Resources})
+				}
+				if !slices.Equal(baseGrant.Conditions, targetGrant.Conditions) {
+					diff.ConditionChanges = append(diff.ConditionChanges, actionChange{Action: action, Base: baseGrant.Conditions, Target: targetGrant.Conditions})
+				}
+			}
+			for _, action := range basePermissions.Actions() {
+				if _, ok := targetPermissions[action]; !ok {
+					diff.RemovedActions = append(diff.RemovedActions, action)
+				}
+			}
+
+			if len(diff.AddedActions) == 0 && len(diff.RemovedActions) == 0 &&
+				len(diff.ResourceChanges) == 0 && len(diff.ConditionChanges) == 0 {
+				continue
+			}
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs
+}
+
+// diffLines returns the changes of an operator as "<kind>: <detail>" pairs, in the order they are printed
+func diffLines(diff operatorDiff) [][2]string {
+	var lines [][2]string
+	for _, action := range diff.AddedActions {
+		lines = append(lines, [2]string{"+ action", action})
+	}
+	for _, action := range diff.RemovedActions {
+		lines = append(lines, [2]string{"- action", action})
+	}
+	for _, change := range diff.ResourceChanges {
+		lines = append(lines, [2]string{"~ resources", fmt.Sprintf("%s: %s -> %s", change.Action, joinOrNone(change.Base), joinOrNone(change.Target))})
+	}
+	for _, change := range diff.ConditionChanges {
+		lines = append(lines, [2]string{"~ conditions", fmt.Sprintf("%s: %s -> %s", change.Action, joinOrNone(change.Base), joinOrNone(change.Target))})
+	}
+	return lines
+}
+
+func printDiffTable(w io.Writer, diff *permissionsDiff) error {
+	if len(diff.Operators) == 0 {
+		fmt.Fprintf(w, "No IAM permission changes between %s and %s\n", diff.BaseVersion, diff.TargetVersion)
+		return nil
+	}
+
+	p := printer.NewTablePrinter(w, 20, 1, 3, ' ')
+	p.AddRow([]string{"CREDENTIALSREQUEST", "CHANGE", "KIND", "DETAIL"})
+	for _, operator := range diff.Operators {
+		for i, line := range diffLines(operator) {
+			name, change := operator.CredentialsRequest, string(operator.Change)
+			if i > 0 {
+				name, change = "", ""
+			}
+			p.AddRow([]string{name, change, line[0], line[1]})
+		}
+	}
+
+	return p.Flush()
+}
+
+func printDiffMarkdown(w io.Writer, diff *permissionsDiff) {
+	fmt.Fprintf(w, "# IAM permission changes from %s to %s (%s)\n\n", diff.BaseVersion, diff.TargetVersion, diff.Cloud)
+	if len(diff.Operators) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+
+	for _, operator := range diff.Operators {
+		fmt.Fprintf(w, "## %s (%s)\n\n", operator.CredentialsRequest, operator.Change)
+		printMarkdownList(w, "Added actions", operator.AddedActions)
+		printMarkdownList(w, "Removed actions", operator.RemovedActions)
+		printMarkdownChanges(w, "Changed resources", operator.ResourceChanges)
+		printMarkdownChanges(w, "Changed conditions", operator.ConditionChanges)
+	}
+}
+
+func printMarkdownList(w io.Writer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "### %s\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(w, "- `%s`\n", item)
+	}
+	fmt.Fprintln(w)
+}
+
+func printMarkdownChanges(w io.Writer, title string, changes []actionChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "### %s\n\n", title)
+	fmt.Fprintln(w, "| Action | Base | Target |")
+	fmt.Fprintln(w, "| --- | --- | --- |")
+	for _, change := range changes {
+		fmt.Fprintf(w, "| `%s` | %s | %s |\n", change.Action, markdownCell(change.Base), markdownCell(change.Target))
+	}
+	fmt.Fprintln(w)
+}
+
+func markdownCell(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, "`"+strings.ReplaceAll(value, "|", "\\|")+"`")
+	}
+	return strings.Join(quoted, "<br>")
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, ", ")
+}
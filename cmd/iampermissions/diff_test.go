@@ -2,33 +2,171 @@ package iampermissions
 
 import (
 	"bytes"
-	"os/exec"
+	"encoding/json"
 	"testing"
 
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	"github.com/openshift/osdctl/pkg/policies"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func TestRunSuccess(t *testing.T) {
-	mockDownload := func(version string, cloud policies.CloudSpec) (string, error) {
-		return "/mock/path/" + version, nil
-	}
+func newAWSCredentialsRequest(t *testing.T, name string, statements ...cco.StatementEntry) *cco.CredentialsRequest {
+	t.Helper()
+	providerSpec, err := cco.Codec.EncodeProviderSpec(&cco.AWSProviderSpec{
+		TypeMeta:         metav1.TypeMeta{APIVersion: "cloudcredential.openshift.io/v1", Kind: "AWSProviderSpec"},
+		StatementEntries: statements,
+	})
+	require.NoError(t, err)
 
-	mockExec := func(command string, args ...string) *exec.Cmd {
-		return exec.Command("echo", "mock diff output")
+	return &cco.CredentialsRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       cco.CredentialsRequestSpec{ProviderSpec: providerSpec},
 	}
+}
 
-	var outputBuffer bytes.Buffer
+func newGCPCredentialsRequest(t *testing.T, name string, predefinedRoles []string, permissions []string) *cco.CredentialsRequest {
+	t.Helper()
+	providerSpec, err := cco.Codec.EncodeProviderSpec(&cco.GCPProviderSpec{
+		TypeMeta:        metav1.TypeMeta{APIVersion: "cloudcredential.openshift.io/v1", Kind: "GCPProviderSpec"},
+		PredefinedRoles: predefinedRoles,
+		Permissions:     permissions,
+	})
+	require.NoError(t, err)
 
-	o := &diffOptions{
+	return &cco.CredentialsRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       cco.CredentialsRequestSpec{ProviderSpec: providerSpec},
+	}
+}
+
+func newTestDiffOptions(output string, cloud policies.CloudSpec, crsByVersion map[string][]*cco.CredentialsRequest, w *bytes.Buffer) *diffOptions {
+	return &diffOptions{
 		BaseVersion:   "v1",
 		TargetVersion: "v2",
-		Cloud:         policies.AWS,
-		downloadFunc:  mockDownload,
-		execFunc:      mockExec,
-		outputWriter:  &outputBuffer,
+		Cloud:         cloud,
+		Output:        output,
+		downloadFunc: func(version string, cloud policies.CloudSpec) (string, error) {
+			return version, nil
+		},
+		parseFunc: func(dir string) ([]*cco.CredentialsRequest, error) {
+			return crsByVersion[dir], nil
+		},
+		outputWriter: w,
+	}
+}
+
+func testAWSCredentialsRequests(t *testing.T) map[string][]*cco.CredentialsRequest {
+	condition := cco.IAMPolicyCondition{"StringEquals": cco.IAMPolicyConditionKeyValue{"aws:ResourceTag/red-hat-managed": "true"}}
+	return map[string][]*cco.CredentialsRequest{
+		"v1": {
+			newAWSCredentialsRequest(t, "openshift-ingress",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"route53:ListHostedZones", "route53:ChangeResourceRecordSets"}, Resource: "*"}),
+			newAWSCredentialsRequest(t, "openshift-machine-api-aws",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"ec2:RunInstances"}, Resource: "*"}),
+			newAWSCredentialsRequest(t, "openshift-image-registry",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"s3:CreateBucket"}, Resource: "*"}),
+			newAWSCredentialsRequest(t, "openshift-cloud-credential-operator",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"iam:GetUser"}, Resource: "*"}),
+		},
+		"v2": {
+			newAWSCredentialsRequest(t, "openshift-ingress",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"route53:ListHostedZones", "tag:GetResources"}, Resource: "*"},
+				cco.StatementEntry{Effect: "Allow", Action: []string{"route53:ChangeResourceRecordSets"}, Resource: "arn:aws:route53:::hostedzone/*"}),
+			newAWSCredentialsRequest(t, "openshift-machine-api-aws",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"ec2:RunInstances"}, Resource: "*", PolicyCondition: condition}),
+			newAWSCredentialsRequest(t, "openshift-cloud-network-config-controller-aws",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"ec2:AssignPrivateIpAddresses"}, Resource: "*"}),
+			newAWSCredentialsRequest(t, "openshift-cloud-credential-operator",
+				cco.StatementEntry{Effect: "Allow", Action: []string{"iam:GetUser"}, Resource: "*"}),
+		},
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	var outputBuffer bytes.Buffer
+	o := newTestDiffOptions(diffOutputJSON, policies.AWS, testAWSCredentialsRequests(t), &outputBuffer)
+	require.NoError(t, o.run())
+
+	diff := permissionsDiff{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &diff))
+	assert.Equal(t, "aws", diff.Cloud)
+	require.Len(t, diff.Operators, 4)
+
+	added := diff.Operators[0]
+	assert.Equal(t, "openshift-cloud-network-config-controller-aws", added.CredentialsRequest)
+	assert.Equal(t, operatorAdded, added.Change)
+	assert.Equal(t, []string{"ec2:AssignPrivateIpAddresses"}, added.AddedActions)
+
+	removed := diff.Operators[1]
+	assert.Equal(t, "openshift-image-registry", removed.CredentialsRequest)
+	assert.Equal(t, operatorRemoved, removed.Change)
+	assert.Equal(t, []string{"s3:CreateBucket"}, removed.RemovedActions)
+
+	ingress := diff.Operators[2]
+	assert.Equal(t, "openshift-ingress", ingress.CredentialsRequest)
+	assert.Equal(t, operatorChanged, ingress.Change)
+	assert.Equal(t, []string{"tag:GetResources"}, ingress.AddedActions)
+	assert.Empty(t, ingress.RemovedActions)
+	assert.Equal(t, []actionChange{{
+		Action: "route53:ChangeResourceRecordSets",
+		Base:   []string{"*"},
+		Target: []string{"arn:aws:route53:::hostedzone/*"},
+	}}, ingress.ResourceChanges)
+
+	machineAPI := diff.Operators[3]
+	assert.Equal(t, "openshift-machine-api-aws", machineAPI.CredentialsRequest)
+	require.Len(t, machineAPI.ConditionChanges, 1)
+	assert.Empty(t, machineAPI.ConditionChanges[0].Base)
+	assert.Equal(t, []string{`{"StringEquals":{"aws:ResourceTag/red-hat-managed":"true"}}`}, machineAPI.ConditionChanges[0].Target)
+}
+
+func TestRunTableAndMarkdown(t *testing.T) {
+	var tableBuffer bytes.Buffer
+	require.NoError(t, newTestDiffOptions(diffOutputTable, policies.AWS, testAWSCredentialsRequests(t), &tableBuffer).run())
+	assert.Contains(t, tableBuffer.String(), "CREDENTIALSREQUEST")
+	assert.Contains(t, tableBuffer.String(), "+ action")
+	assert.Contains(t, tableBuffer.String(), "route53:ChangeResourceRecordSets: * -> arn:aws:route53:::hostedzone/*")
+	assert.NotContains(t, tableBuffer.String(), "openshift-cloud-credential-operator")
+
+	var markdownBuffer bytes.Buffer
+	require.NoError(t, newTestDiffOptions(diffOutputMarkdown, policies.AWS, testAWSCredentialsRequests(t), &markdownBuffer).run())
+	assert.Contains(t, markdownBuffer.String(), "# IAM permission changes from v1 to v2 (aws)")
+	assert.Contains(t, markdownBuffer.String(), "## openshift-ingress (changed)")
+	assert.Contains(t, markdownBuffer.String(), "- `tag:GetResources`")
+	assert.Contains(t, markdownBuffer.String(), "| `route53:ChangeResourceRecordSets` | `*` | `arn:aws:route53:::hostedzone/*` |")
+}
+
+func TestRunGCP(t *testing.T) {
+	crs := map[string][]*cco.CredentialsRequest{
+		"v1": {newGCPCredentialsRequest(t, "openshift-gcp-ccm", []string{"roles/compute.viewer"}, []string{"compute.instances.get"})},
+		"v2": {newGCPCredentialsRequest(t, "openshift-gcp-ccm", nil, []string{"compute.instances.get", "compute.instances.list"})},
 	}
-	err := o.run()
-	assert.NoError(t, err)
-	assert.Contains(t, outputBuffer.String(), "mock diff output")
+
+	var outputBuffer bytes.Buffer
+	require.NoError(t, newTestDiffOptions(diffOutputJSON, policies.GCP, crs, &outputBuffer).run())
+
+	diff := permissionsDiff{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &diff))
+	require.Len(t, diff.Operators, 1)
+	assert.Equal(t, []string{"compute.instances.list"}, diff.Operators[0].AddedActions)
+	assert.Equal(t, []string{"roles/compute.viewer"}, diff.Operators[0].RemovedActions)
+}
+
+func TestRunNoChanges(t *testing.T) {
+	crs := map[string][]*cco.CredentialsRequest{
+		"v1": {newAWSCredentialsRequest(t, "openshift-ingress", cco.StatementEntry{Effect: "Allow", Action: []string{"route53:ListHostedZones"}, Resource: "*"})},
+		"v2": {newAWSCredentialsRequest(t, "openshift-ingress", cco.StatementEntry{Effect: "Allow", Action: []string{"route53:ListHostedZones"}, Resource: "*"})},
+	}
+
+	var outputBuffer bytes.Buffer
+	require.NoError(t, newTestDiffOptions(diffOutputTable, policies.AWS, crs, &outputBuffer).run())
+	assert.Equal(t, "No IAM permission changes between v1 and v2\n", outputBuffer.String())
+}
+
+func TestRunInvalidOutput(t *testing.T) {
+	var outputBuffer bytes.Buffer
+	err := newTestDiffOptions("xml", policies.AWS, nil, &outputBuffer).run()
+	assert.ErrorContains(t, err, "invalid output format")
 }
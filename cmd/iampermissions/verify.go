@@ -0,0 +1,570 @@
+package iampermissions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/osdctl/pkg/osdCloud"
+	"github.com/openshift/osdctl/pkg/policies"
+	"github.com/openshift/osdctl/pkg/printer"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	gcpprovider "github.com/openshift/osdctl/pkg/provider/gcp"
+	"github.com/openshift/osdctl/pkg/utils"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// Number of actions simulated per SimulatePrincipalPolicy call
+const simulateBatchSize = 50
+
+type roleStatus string
+
+const (
+	roleStatusOK                   roleStatus = "ok"
+	roleStatusMissingPermissions   roleStatus = "missing-permissions"
+	roleStatusExcessivePermissions roleStatus = "excessive-permissions"
+	roleStatusNoCredentialsRequest roleStatus = "no-credentials-request"
+	roleStatusError                roleStatus = "error"
+)
+
+// operatorRole is an IAM role of an operator of the cluster, matched to its CredentialsRequest by the secret it fills
+type operatorRole struct {
+	Namespace string
+	Name      string
+	RoleARN   string
+}
+
+// roleVerification is the result of the verification of the permissions of an operator role
+type roleVerification struct {
+	Operator           string     `json:"operator"` // <secret namespace>/<secret name>
+	RoleARN            string     `json:"roleArn,omitempty"`
+	ServiceAccount     string     `json:"serviceAccount,omitempty"` // email of the GCP service account
+	CredentialsRequest string     `json:"credentialsRequest,omitempty"`
+	Status             roleStatus `json:"status"`
+	MissingBindings    []string   `json:"missingBindings,omitempty"` // GCP roles not bound to the service account
+	// GCP roles bound to the service account only under a condition, which cannot be evaluated without the request context
+	ConditionalBindings []string `json:"conditionalBindings,omitempty"`
+	MissingActions      []string `json:"missingActions,omitempty"` // AWS actions or GCP permissions not granted
+	ExcessiveActions    []string `json:"excessiveActions,omitempty"`
+	// AWS actions required under conditions, which cannot be simulated without the request context,
+	// or GCP permissions only granted by conditional bindings
+	ConditionalActions []string `json:"conditionalActions,omitempty"`
+	Error              string   `json:"error,omitempty"`
+}
+
+type verificationReport struct {
+	ClusterID string             `json:"clusterId"`
+	Cloud     string             `json:"cloud"`
+	Version   string             `json:"version"`
+	ProjectID string             `json:"projectId,omitempty"`
+	Roles     []roleVerification `json:"roles"`
+}
+
+type verifyOptions struct {
+	ClusterID  string
+	Cloud      policies.CloudSpec
+	AWSProfile string
+	Output     string
+
+	// Injected for testability
+	getClusterFunc func(string) (*cmv1.Cluster, error)
+	awsClientFunc  func(string, string) (awsprovider.Client, error)
+	// WIF config by ID
+	getWifConfigFunc func(string) (*cmv1.WifConfig, error)
+	gcpClientFunc    func() (gcpprovider.IAMClient, error)
+	downloadFunc     func(string, policies.CloudSpec) (string, error)
+	parseFunc        func(string) ([]*cco.CredentialsRequest, error)
+	outputWriter     io.Writer
+}
+
+func newCmdVerify() *cobra.Command {
+	ops := &verifyOptions{
+		getClusterFunc:   getOCMCluster,
+		awsClientFunc:    osdCloud.GenerateAWSClientForCluster,
+		getWifConfigFunc: getOCMWifConfig,
+		gcpClientFunc: func() (gcpprovider.IAMClient, error) {
+			return gcpprovider.NewIAMClient(context.Background())
+		},
+		downloadFunc: policies.DownloadCredentialRequests,
+		parseFunc:    policies.ParseCredentialsRequestsInDir,
+		outputWriter: os.Stdout,
+	}
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the IAM permissions of the operator roles of a cluster",
+		Long: `Verify the IAM permissions of the operator roles of an STS or WIF cluster against the CredentialsRequests of its release.
+
+STS clusters (--cloud aws):
+For each operator role, the actions required by its CredentialsRequest are simulated with SimulatePrincipalPolicy,
+the denied ones being reported as missing. The actions granted by the policies of the role which no required action
+covers are reported as excessive, IAM wildcards being matched (a granted "ec2:Describe*" is covered by a required
+"ec2:*" but not by a required "ec2:DescribeInstances"). Actions required under conditions are listed separately, as they cannot be simulated
+without the context of the request.
+
+WIF clusters (--cloud wif):
+For each service account of the WIF config of the cluster, the predefined roles required by its CredentialsRequest
+and the custom roles of the WIF config must be bound to the service account in the IAM policy of the project.
+Unbound roles are reported as missing bindings, and the required permissions which none of the bound roles grant
+are reported as missing. Roles bound only under an IAM condition, and the permissions only they grant, are listed
+separately as conditional, as the condition cannot be evaluated without the context of the request. The project is read with the GCP application default credentials.
+
+The command fails if any operator role is missing permissions or could not be verified.`,
+		Example: `  # Verify the operator roles of an STS cluster
+  osdctl iampermissions verify --cluster-id ${CLUSTER_ID}
+
+  # Verify the operator roles of an STS cluster as JSON
+  osdctl iampermissions verify --cluster-id ${CLUSTER_ID} --output json
+
+  # Verify the service accounts of a WIF cluster
+  osdctl iampermissions verify --cloud wif --cluster-id ${CLUSTER_ID}`,
+		Args:              cobra.ExactArgs(0),
+		DisableAutoGenTag: true,
+		Run: func(cmd *cobra.Command, _ []string) {
+			ops.Cloud = *cmd.Flag(cloudFlagName).Value.(*policies.CloudSpec)
+			cmdutil.CheckErr(ops.run())
+		},
+	}
+
+	verifyCmd.Flags().StringVarP(&ops.ClusterID, "cluster-id", "C", "", "Internal ID, external ID or name of the cluster")
+	verifyCmd.Flags().StringVarP(&ops.AWSProfile, "profile", "p", "", "AWS profile used to access the AWS account of the cluster")
+	verifyCmd.Flags().StringVarP(&ops.Output, "output", "o", "table", "Output format: table, json")
+	_ = verifyCmd.MarkFlagRequired("cluster-id")
+
+	return verifyCmd
+}
+
+func getOCMCluster(clusterID string) (*cmv1.Cluster, error) {
+	ocmClient, err := utils.CreateConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer ocmClient.Close()
+
+	return utils.GetClusterAnyStatus(ocmClient, clusterID)
+}
+
+func (o *verifyOptions) run() error {
+	if o.Output != "table" && o.Output != "json" {
+		return fmt.Errorf("invalid output format '%s'. Valid options: table, json", o.Output)
+	}
+
+	cluster, err := o.getClusterFunc(o.ClusterID)
+	if err != nil {
+		return err
+	}
+
+	var report *verificationReport
+	switch o.Cloud {
+	case policies.AWS:
+		report, err = o.verifyAWS(cluster)
+	case policies.GCP:
+		report, err = o.verifyWIF(cluster)
+	default:
+		return fmt.Errorf("verifying the permissions of %s clusters is not supported", o.Cloud.String())
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.Output == "json" {
+		encoder := json.NewEncoder(o.outputWriter)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else if err := printVerificationTable(o.outputWriter, report); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, role := range report.Roles {
+		if role.Status == roleStatusMissingPermissions || role.Status == roleStatusError {
+			failed++
+		}
+	}
+	if failed > 0 {
+		operators := "operator roles"
+		if o.Cloud == policies.GCP {
+			operators = "service accounts"
+		}
+		return fmt.Errorf("%d of %d %s are missing permissions or could not be verified", failed, len(report.Roles), operators)
+	}
+
+	return nil
+}
+
+// releaseCredentialsRequests returns the CredentialsRequests of the release of the cluster, by secret
+func (o *verifyOptions) releaseCredentialsRequests(version string) (map[string]*cco.CredentialsRequest, error) {
+	fmt.Fprintf(os.Stderr, "Downloading Credential Requests for %s\n", version)
+	dir, err := o.downloadFunc(version, o.Cloud)
+	if err != nil {
+		return nil, err
+	}
+
+	crs, err := o.parseFunc(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	crsBySecret := make(map[string]*cco.CredentialsRequest, len(crs))
+	for _, cr := range crs {
+		crsBySecret[cr.Spec.SecretRef.Namespace+"/"+cr.Spec.SecretRef.Name] = cr
+	}
+
+	return crsBySecret, nil
+}
+
+func (o *verifyOptions) verifyAWS(cluster *cmv1.Cluster) (*verificationReport, error) {
+	if cluster.AWS().STS().RoleARN() == "" {
+		return nil, fmt.Errorf("cluster %s is not an STS cluster", cluster.ID())
+	}
+
+	report := &verificationReport{
+		ClusterID: cluster.ID(),
+		Cloud:     o.Cloud.String(),
+		Version:   cluster.Version().RawID(),
+		Roles:     []roleVerification{},
+	}
+
+	crsBySecret, err := o.releaseCredentialsRequests(report.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	awsClient, err := o.awsClientFunc(o.AWSProfile, cluster.ID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client for cluster %s: %w", cluster.ID(), err)
+	}
+
+	for _, iamRole := range cluster.AWS().STS().OperatorIAMRoles() {
+		role := operatorRole{Namespace: iamRole.Namespace(), Name: iamRole.Name(), RoleARN: iamRole.RoleARN()}
+		report.Roles = append(report.Roles, verifyAWSOperatorRole(awsClient, role, crsBySecret[role.Namespace+"/"+role.Name]))
+	}
+
+	return report, nil
+}
+
+// verifyAWSOperatorRole compares the effective permissions of an operator role against its CredentialsRequest
+func verifyAWSOperatorRole(awsClient awsprovider.Client, role operatorRole, cr *cco.CredentialsRequest) roleVerification {
+	verification := roleVerification{
+		Operator: role.Namespace + "/" + role.Name,
+		RoleARN:  role.RoleARN,
+		Status:   roleStatusOK,
+	}
+	if cr == nil {
+		verification.Status = roleStatusNoCredentialsRequest
+		return verification
+	}
+	verification.CredentialsRequest = cr.Name
+
+	expected, err := policies.AWSOperatorPermissions(cr)
+	if err != nil {
+		verification.Status = roleStatusError
+		verification.Error = fmt.Sprintf("failed to parse CredentialsRequest: %v", err)
+		return verification
+	}
+
+	// Actions are simulated together when they are required on the same resources
+	actionsByResources := map[string][]string{}
+	for _, action := range expected.Actions() {
+		grant := expected[action]
+		if strings.HasPrefix(action, policies.DenyActionPrefix) {
+			continue
+		}
+		if len(grant.Conditions) > 0 {
+			verification.ConditionalActions = append(verification.ConditionalActions, action)
+			continue
+		}
+		resources := strings.Join(grant.Resources, ",")
+		actionsByResources[resources] = append(actionsByResources[resources], action)
+	}
+
+	for resources, actions := range actionsByResources {
+		denied, err := simulateRoleActions(awsClient, role.RoleARN, actions, resources)
+		if err != nil {
+			verification.Status = roleStatusError
+			verification.Error = fmt.Sprintf("failed to simulate the permissions of the role: %v", err)
+			return verification
+		}
+		verification.MissingActions = append(verification.MissingActions, denied...)
+	}
+	slices.Sort(verification.MissingActions)
+
+	granted, err := roleAllowedActions(awsClient, role.RoleARN)
+	if err != nil {
+		verification.Status = roleStatusError
+		verification.Error = fmt.Sprintf("failed to read the policies of the role: %v", err)
+		return verification
+	}
+	for _, action := range granted {
+		if !slices.ContainsFunc(expected.Actions(), func(expectedAction string) bool { return iamActionMatches(expectedAction, action) }) {
+			verification.ExcessiveActions = append(verification.ExcessiveActions, action)
+		}
+	}
+
+	switch {
+	case len(verification.MissingActions) > 0:
+		verification.Status = roleStatusMissingPermissions
+	case len(verification.ExcessiveActions) > 0:
+		verification.Status = roleStatusExcessivePermissions
+	}
+
+	return verification
+}
+
+// iamActionMatches reports whether the IAM action pattern, which may contain the "*" and "?" wildcards,
+// matches the action. Actions are case-insensitive. The wildcards of the action are matched literally, so
+// that a granted "ec2:Describe*" is covered by a required "ec2:*" or "ec2:Describe*", but not by a required
+// "ec2:DescribeInstances".
+func iamActionMatches(pattern string, action string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	matched, err := regexp.MatchString("(?i)^"+expr+"$", action)
+	return err == nil && matched
+}
+
+// simulateRoleActions returns the actions denied to the role on the comma-separated resources, on any resource if "*"
+func simulateRoleActions(awsClient awsprovider.Client, roleARN string, actions []string, resources string) ([]string, error) {
+	var resourceARNs []string
+	for _, resource := range strings.Split(resources, ",") {
+		if resource != "" && resource != "*" {
+			resourceARNs = append(resourceARNs, resource)
+		}
+	}
+
+	var denied []string
+	for start := 0; start < len(actions); start += simulateBatchSize {
+		batch := actions[start:min(start+simulateBatchSize, len(actions))]
+
+		input := &iam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: awsSdk.String(roleARN),
+			ActionNames:     batch,
+			ResourceArns:    resourceARNs,
+		}
+		for {
+			output, err := awsClient.SimulatePrincipalPolicy(input)
+			if err != nil {
+				return nil, err
+			}
+			for _, result := range output.EvaluationResults {
+				if result.EvalActionName != nil && result.EvalDecision != iamTypes.PolicyEvaluationDecisionTypeAllowed {
+					denied = append(denied, *result.EvalActionName)
+				}
+			}
+			if !output.IsTruncated || output.Marker == nil {
+				break
+			}
+			input.Marker = output.Marker
+		}
+	}
+
+	return denied, nil
+}
+
+// roleAllowedActions returns the sorted actions allowed by the managed and inline policies of the role
+func roleAllowedActions(awsClient awsprovider.Client, roleARN string) ([]string, error) {
+	roleName := roleARN[strings.LastIndex(roleARN, "/")+1:]
+
+	var documents []string
+
+	attachedInput := &iam.ListAttachedRolePoliciesInput{RoleName: awsSdk.String(roleName)}
+	for {
+		attached, err := awsClient.ListAttachedRolePolicies(attachedInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, attachedPolicy := range attached.AttachedPolicies {
+			policy, err := awsClient.GetPolicy(&iam.GetPolicyInput{PolicyArn: attachedPolicy.PolicyArn})
+			if err != nil {
+				return nil, err
+			}
+			version, err := awsClient.GetPolicyVersion(&iam.GetPolicyVersionInput{
+				PolicyArn: attachedPolicy.PolicyArn,
+				VersionId: policy.Policy.DefaultVersionId,
+			})
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, awsSdk.ToString(version.PolicyVersion.Document))
+		}
+		if !attached.IsTruncated || attached.Marker == nil {
+			break
+		}
+		attachedInput.Marker = attached.Marker
+	}
+
+	inlineInput := &iam.ListRolePoliciesInput{RoleName: awsSdk.String(roleName)}
+	for {
+		inline, err := awsClient.ListRolePolicies(inlineInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, policyName := range inline.PolicyNames {
+			policy, err := awsClient.GetRolePolicy(&iam.GetRolePolicyInput{RoleName: awsSdk.String(roleName), PolicyName: awsSdk.String(policyName)})
+			if err != nil {
+				return nil, err
+			}
+			documents = append(documents, awsSdk.ToString(policy.PolicyDocument))
+		}
+		if !inline.IsTruncated || inline.Marker == nil {
+			break
+		}
+		inlineInput.Marker = inline.Marker
+	}
+
+	var actions []string
+	for _, document := range documents {
+		documentActions, err := allowedActionsInPolicyDocument(document)
+		if err != nil {
+			return nil, err
+		}
+		for _, action := range documentActions {
+			if !slices.Contains(actions, action) {
+				actions = append(actions, action)
+			}
+		}
+	}
+	slices.Sort(actions)
+
+	return actions, nil
+}
+
+// stringOrSlice is an IAM policy element which is either a string or a list of strings
+type stringOrSlice []string
+
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = []string{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*s = multiple
+	return nil
+}
+
+type iamPolicyStatement struct {
+	Effect string        `json:"Effect"`
+	Action stringOrSlice `json:"Action"`
+}
+
+// iamPolicyStatements is the Statement element of an IAM policy, which is either a statement or a list of statements
+type iamPolicyStatements []iamPolicyStatement
+
+func (s *iamPolicyStatements) UnmarshalJSON(data []byte) error {
+	var single iamPolicyStatement
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = []iamPolicyStatement{single}
+		return nil
+	}
+	var multiple []iamPolicyStatement
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*s = multiple
+	return nil
+}
+
+// allowedActionsInPolicyDocument returns the actions of the Allow statements of an URL-encoded IAM policy document
+func allowedActionsInPolicyDocument(document string) ([]string, error) {
+	decoded, err := url.QueryUnescape(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode policy document: %w", err)
+	}
+
+	var policy struct {
+		Statement iamPolicyStatements `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(decoded), &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy document: %w", err)
+	}
+
+	var actions []string
+	for _, statement := range policy.Statement {
+		if statement.Effect == "Allow" {
+			actions = append(actions, statement.Action...)
+		}
+	}
+
+	return actions, nil
+}
+
+func printVerificationTable(w io.Writer, report *verificationReport) error {
+	if report.ProjectID != "" {
+		fmt.Fprintf(w, "Cluster %s (%s %s, project %s)\n\n", report.ClusterID, report.Cloud, report.Version, report.ProjectID)
+	} else {
+		fmt.Fprintf(w, "Cluster %s (%s %s)\n\n", report.ClusterID, report.Cloud, report.Version)
+	}
+
+	p := printer.NewTablePrinter(w, 20, 1, 3, ' ')
+	if report.ProjectID != "" {
+		p.AddRow([]string{"OPERATOR", "CREDENTIALSREQUEST", "STATUS", "MISSING BINDINGS", "MISSING PERMISSIONS", "CONDITIONAL BINDINGS"})
+	} else {
+		p.AddRow([]string{"OPERATOR", "CREDENTIALSREQUEST", "STATUS", "MISSING", "EXCESSIVE", "CONDITIONAL"})
+	}
+	for _, role := range report.Roles {
+		row := []string{role.Operator, valueOrDash(role.CredentialsRequest), string(role.Status)}
+		if report.ProjectID != "" {
+			row = append(row, strconv.Itoa(len(role.MissingBindings)), strconv.Itoa(len(role.MissingActions)), strconv.Itoa(len(role.ConditionalBindings)))
+		} else {
+			row = append(row, strconv.Itoa(len(role.MissingActions)), strconv.Itoa(len(role.ExcessiveActions)), strconv.Itoa(len(role.ConditionalActions)))
+		}
+		p.AddRow(row)
+	}
+	if err := p.Flush(); err != nil {
+		return err
+	}
+
+	for _, role := range report.Roles {
+		if role.Error == "" && len(role.MissingBindings) == 0 && len(role.MissingActions) == 0 && len(role.ExcessiveActions) == 0 && len(role.ConditionalBindings) == 0 {
+			continue
+		}
+		identity := role.RoleARN
+		if role.ServiceAccount != "" {
+			identity = role.ServiceAccount
+		}
+		fmt.Fprintf(w, "\n%s (%s):\n", role.Operator, identity)
+		if role.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", role.Error)
+		}
+		for _, binding := range role.MissingBindings {
+			fmt.Fprintf(w, "  - binding:   %s\n", binding)
+		}
+		for _, binding := range role.ConditionalBindings {
+			fmt.Fprintf(w, "  ? binding:   %s (conditional)\n", binding)
+		}
+		for _, action := range role.MissingActions {
+			fmt.Fprintf(w, "  - missing:   %s\n", action)
+		}
+		for _, action := range role.ExcessiveActions {
+			fmt.Fprintf(w, "  + excessive: %s\n", action)
+		}
+	}
+
+	return nil
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
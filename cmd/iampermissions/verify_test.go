@@ -0,0 +1,208 @@
+package iampermissions
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/osdctl/pkg/policies"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+	"github.com/openshift/osdctl/pkg/provider/aws/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const ingressRoleARN = "arn:aws:iam::123456789012:role/my-cluster-openshift-ingress-operator-cloud-credentials"
+
+func newIngressCredentialsRequest(t *testing.T) *cco.CredentialsRequest {
+	condition := cco.IAMPolicyCondition{"StringEquals": cco.IAMPolicyConditionKeyValue{"aws:ResourceTag/red-hat-managed": "true"}}
+	cr := newAWSCredentialsRequest(t, "openshift-ingress",
+		cco.StatementEntry{Effect: "Allow", Action: []string{"route53:ListHostedZones", "tag:GetResources"}, Resource: "*"},
+		cco.StatementEntry{Effect: "Allow", Action: []string{"route53:ChangeResourceRecordSets"}, Resource: "*", PolicyCondition: condition})
+	cr.Spec.SecretRef.Namespace = "openshift-ingress-operator"
+	cr.Spec.SecretRef.Name = "cloud-credentials"
+	return cr
+}
+
+func newSTSCluster(t *testing.T, roles ...*cmv1.OperatorIAMRoleBuilder) *cmv1.Cluster {
+	cluster, err := cmv1.NewCluster().
+		ID("abc123").
+		Version(cmv1.NewVersion().RawID("4.15.3")).
+		AWS(cmv1.NewAWS().STS(cmv1.NewSTS().
+			RoleARN("arn:aws:iam::123456789012:role/ManagedOpenShift-Installer-Role").
+			OperatorIAMRoles(roles...))).
+		Build()
+	require.NoError(t, err)
+	return cluster
+}
+
+// expectRolePolicy makes the role have a single inline policy with the given document
+func expectRolePolicy(mockClient *mock.MockClient, document string) {
+	mockClient.EXPECT().ListAttachedRolePolicies(gomock.Any()).Return(&iam.ListAttachedRolePoliciesOutput{}, nil)
+	mockClient.EXPECT().ListRolePolicies(gomock.Any()).Return(&iam.ListRolePoliciesOutput{PolicyNames: []string{"inline"}}, nil)
+	mockClient.EXPECT().GetRolePolicy(gomock.Any()).Return(&iam.GetRolePolicyOutput{PolicyDocument: awsSdk.String(url.QueryEscape(document))}, nil)
+}
+
+func TestVerifyAWSOperatorRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockClient(ctrl)
+
+	mockClient.EXPECT().SimulatePrincipalPolicy(gomock.Any()).DoAndReturn(func(input *iam.SimulatePrincipalPolicyInput) (*iam.SimulatePrincipalPolicyOutput, error) {
+		assert.Equal(t, ingressRoleARN, *input.PolicySourceArn)
+		assert.Equal(t, []string{"route53:ListHostedZones", "tag:GetResources"}, input.ActionNames)
+		assert.Empty(t, input.ResourceArns)
+		return &iam.SimulatePrincipalPolicyOutput{EvaluationResults: []iamTypes.EvaluationResult{
+			{EvalActionName: awsSdk.String("route53:ListHostedZones"), EvalDecision: iamTypes.PolicyEvaluationDecisionTypeAllowed},
+			{EvalActionName: awsSdk.String("tag:GetResources"), EvalDecision: iamTypes.PolicyEvaluationDecisionTypeImplicitDeny},
+		}}, nil
+	})
+	expectRolePolicy(mockClient, `{"Version":"2012-10-17","Statement":[`+
+		`{"Effect":"Allow","Action":["route53:ListHostedZones","route53:ChangeResourceRecordSets"],"Resource":"*"},`+
+		`{"Effect":"Allow","Action":"ec2:*","Resource":"*"},`+
+		`{"Effect":"Deny","Action":"iam:*","Resource":"*"}]}`)
+
+	role := operatorRole{Namespace: "openshift-ingress-operator", Name: "cloud-credentials", RoleARN: ingressRoleARN}
+	verification := verifyAWSOperatorRole(mockClient, role, newIngressCredentialsRequest(t))
+
+	assert.Equal(t, roleStatusMissingPermissions, verification.Status)
+	assert.Equal(t, "openshift-ingress", verification.CredentialsRequest)
+	assert.Equal(t, []string{"tag:GetResources"}, verification.MissingActions)
+	assert.Equal(t, []string{"ec2:*"}, verification.ExcessiveActions)
+	assert.Equal(t, []string{"route53:ChangeResourceRecordSets"}, verification.ConditionalActions)
+}
+
+func TestIAMActionMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		action  string
+		want    bool
+	}{
+		{pattern: "ec2:DescribeInstances", action: "ec2:DescribeInstances", want: true},
+		{pattern: "ec2:DescribeInstances", action: "EC2:describeinstances", want: true},
+		{pattern: "ec2:*", action: "ec2:DescribeInstances", want: true},
+		{pattern: "ec2:*", action: "ec2:Describe*", want: true},
+		{pattern: "ec2:Describe*", action: "ec2:Describe*", want: true},
+		{pattern: "*", action: "iam:*", want: true},
+		{pattern: "ec2:Describe?nstances", action: "ec2:DescribeInstances", want: true},
+		{pattern: "ec2:DescribeInstances", action: "ec2:Describe*", want: false},
+		{pattern: "ec2:Describe*", action: "ec2:*", want: false},
+		{pattern: "ec2:*", action: "iam:GetRole", want: false},
+		{pattern: "ec2:Describe.*", action: "ec2:DescribeInstances", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.action, func(t *testing.T) {
+			assert.Equal(t, tt.want, iamActionMatches(tt.pattern, tt.action))
+		})
+	}
+}
+
+func TestVerifyAWSOperatorRole_NoCredentialsRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockClient(ctrl)
+
+	role := operatorRole{Namespace: "kube-system", Name: "capa-controller-manager-credentials", RoleARN: ingressRoleARN}
+	verification := verifyAWSOperatorRole(mockClient, role, nil)
+	assert.Equal(t, roleStatusNoCredentialsRequest, verification.Status)
+}
+
+func TestAllowedActionsInPolicyDocument(t *testing.T) {
+	actions, err := allowedActionsInPolicyDocument(url.QueryEscape(`{"Statement":{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s3:GetObject"}, actions)
+
+	_, err = allowedActionsInPolicyDocument("not-json")
+	assert.Error(t, err)
+}
+
+func newTestVerifyOptions(t *testing.T, cluster *cmv1.Cluster, awsClient awsprovider.Client, w *bytes.Buffer) *verifyOptions {
+	return &verifyOptions{
+		ClusterID: "abc123",
+		Cloud:     policies.AWS,
+		Output:    "json",
+		getClusterFunc: func(string) (*cmv1.Cluster, error) {
+			return cluster, nil
+		},
+		awsClientFunc: func(string, string) (awsprovider.Client, error) {
+			return awsClient, nil
+		},
+		downloadFunc: func(version string, cloud policies.CloudSpec) (string, error) {
+			assert.Equal(t, "4.15.3", version)
+			return "/crs", nil
+		},
+		parseFunc: func(string) ([]*cco.CredentialsRequest, error) {
+			return []*cco.CredentialsRequest{newIngressCredentialsRequest(t)}, nil
+		},
+		outputWriter: w,
+	}
+}
+
+func TestVerifyRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockClient(ctrl)
+
+	mockClient.EXPECT().SimulatePrincipalPolicy(gomock.Any()).Return(&iam.SimulatePrincipalPolicyOutput{EvaluationResults: []iamTypes.EvaluationResult{
+		{EvalActionName: awsSdk.String("route53:ListHostedZones"), EvalDecision: iamTypes.PolicyEvaluationDecisionTypeAllowed},
+		{EvalActionName: awsSdk.String("tag:GetResources"), EvalDecision: iamTypes.PolicyEvaluationDecisionTypeAllowed},
+	}}, nil)
+	mockClient.EXPECT().ListAttachedRolePolicies(gomock.Any()).Return(&iam.ListAttachedRolePoliciesOutput{
+		AttachedPolicies: []iamTypes.AttachedPolicy{{PolicyArn: awsSdk.String("arn:aws:iam::123456789012:policy/ingress")}},
+	}, nil)
+	mockClient.EXPECT().GetPolicy(gomock.Any()).Return(&iam.GetPolicyOutput{Policy: &iamTypes.Policy{DefaultVersionId: awsSdk.String("v2")}}, nil)
+	mockClient.EXPECT().GetPolicyVersion(gomock.Any()).DoAndReturn(func(input *iam.GetPolicyVersionInput) (*iam.GetPolicyVersionOutput, error) {
+		assert.Equal(t, "v2", *input.VersionId)
+		document := `{"Statement":[{"Effect":"Allow","Action":["route53:ListHostedZones","tag:GetResources","route53:ChangeResourceRecordSets"],"Resource":"*"}]}`
+		return &iam.GetPolicyVersionOutput{PolicyVersion: &iamTypes.PolicyVersion{Document: awsSdk.String(url.QueryEscape(document))}}, nil
+	})
+	mockClient.EXPECT().ListRolePolicies(gomock.Any()).Return(&iam.ListRolePoliciesOutput{}, nil)
+
+	cluster := newSTSCluster(t,
+		cmv1.NewOperatorIAMRole().Namespace("openshift-ingress-operator").Name("cloud-credentials").RoleARN(ingressRoleARN),
+		cmv1.NewOperatorIAMRole().Namespace("kube-system").Name("unknown").RoleARN("arn:aws:iam::123456789012:role/unknown"))
+
+	var outputBuffer bytes.Buffer
+	require.NoError(t, newTestVerifyOptions(t, cluster, mockClient, &outputBuffer).run())
+
+	report := verificationReport{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &report))
+	assert.Equal(t, "4.15.3", report.Version)
+	require.Len(t, report.Roles, 2)
+	assert.Equal(t, roleStatusOK, report.Roles[0].Status)
+	assert.Equal(t, roleStatusNoCredentialsRequest, report.Roles[1].Status)
+}
+
+func TestVerifyRun_MissingPermissionsFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockClient(ctrl)
+
+	mockClient.EXPECT().SimulatePrincipalPolicy(gomock.Any()).Return(&iam.SimulatePrincipalPolicyOutput{EvaluationResults: []iamTypes.EvaluationResult{
+		{EvalActionName: awsSdk.String("route53:ListHostedZones"), EvalDecision: iamTypes.PolicyEvaluationDecisionTypeExplicitDeny},
+	}}, nil)
+	expectRolePolicy(mockClient, `{"Statement":[]}`)
+
+	cluster := newSTSCluster(t, cmv1.NewOperatorIAMRole().Namespace("openshift-ingress-operator").Name("cloud-credentials").RoleARN(ingressRoleARN))
+
+	var outputBuffer bytes.Buffer
+	o := newTestVerifyOptions(t, cluster, mockClient, &outputBuffer)
+	o.Output = "table"
+	err := o.run()
+	assert.EqualError(t, err, "1 of 1 operator roles are missing permissions or could not be verified")
+	assert.Contains(t, outputBuffer.String(), "missing-permissions")
+	assert.Contains(t, outputBuffer.String(), "- missing:   route53:ListHostedZones")
+}
+
+func TestVerifyRun_NotSTS(t *testing.T) {
+	cluster, err := cmv1.NewCluster().ID("abc123").Build()
+	require.NoError(t, err)
+
+	var outputBuffer bytes.Buffer
+	err = newTestVerifyOptions(t, cluster, nil, &outputBuffer).run()
+	assert.EqualError(t, err, "cluster abc123 is not an STS cluster")
+}
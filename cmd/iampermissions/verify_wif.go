@@ -0,0 +1,212 @@
+package iampermissions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/osdctl/pkg/policies"
+	gcpprovider "github.com/openshift/osdctl/pkg/provider/gcp"
+	"github.com/openshift/osdctl/pkg/utils"
+)
+
+func getOCMWifConfig(id string) (*cmv1.WifConfig, error) {
+	ocmClient, err := utils.CreateConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer ocmClient.Close()
+
+	response, err := ocmClient.ClustersMgmt().V1().GCP().WifConfigs().WifConfig(id).Get().Send()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get WIF config %s: %w", id, err)
+	}
+
+	return response.Body(), nil
+}
+
+func (o *verifyOptions) verifyWIF(cluster *cmv1.Cluster) (*verificationReport, error) {
+	wifConfigID := cluster.GCP().Authentication().Id()
+	if wifConfigID == "" {
+		return nil, fmt.Errorf("cluster %s is not a WIF cluster", cluster.ID())
+	}
+
+	wifConfig, err := o.getWifConfigFunc(wifConfigID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &verificationReport{
+		ClusterID: cluster.ID(),
+		Cloud:     o.Cloud.String(),
+		Version:   cluster.Version().RawID(),
+		ProjectID: wifConfig.Gcp().ProjectId(),
+		Roles:     []roleVerification{},
+	}
+
+	crsBySecret, err := o.releaseCredentialsRequests(report.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	gcpClient, err := o.gcpClientFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	bindings, err := gcpClient.GetProjectIAMBindings(report.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the IAM policy of project %s: %w", report.ProjectID, err)
+	}
+
+	rolePermissions := newRolePermissionsCache(gcpClient)
+	for _, sa := range wifConfig.Gcp().ServiceAccounts() {
+		secretRef := sa.CredentialRequest().SecretRef()
+		cr := crsBySecret[secretRef.Namespace()+"/"+secretRef.Name()]
+		report.Roles = append(report.Roles, verifyWIFServiceAccount(report.ProjectID, bindings, rolePermissions, sa, cr))
+	}
+
+	return report, nil
+}
+
+// rolePermissionsCache caches the permissions of the roles, which are often bound to several service accounts
+type rolePermissionsCache struct {
+	gcpClient   gcpprovider.IAMClient
+	permissions map[string][]string
+}
+
+func newRolePermissionsCache(gcpClient gcpprovider.IAMClient) *rolePermissionsCache {
+	return &rolePermissionsCache{gcpClient: gcpClient, permissions: map[string][]string{}}
+}
+
+func (c *rolePermissionsCache) get(roleName string) ([]string, error) {
+	if permissions, ok := c.permissions[roleName]; ok {
+		return permissions, nil
+	}
+	permissions, err := c.gcpClient.GetRolePermissions(roleName)
+	if err != nil {
+		return nil, err
+	}
+	c.permissions[roleName] = permissions
+	return permissions, nil
+}
+
+// verifyWIFServiceAccount compares the roles bound to a service account of the WIF config against its CredentialsRequest
+func verifyWIFServiceAccount(projectID string, bindings []gcpprovider.Binding, rolePermissions *rolePermissionsCache, sa *cmv1.WifServiceAccount, cr *cco.CredentialsRequest) roleVerification {
+	secretRef := sa.CredentialRequest().SecretRef()
+	verification := roleVerification{
+		Operator:       secretRef.Namespace() + "/" + secretRef.Name(),
+		ServiceAccount: fmt.Sprintf("%s@%s.iam.gserviceaccount.com", sa.ServiceAccountId(), projectID),
+		Status:         roleStatusOK,
+	}
+	if cr == nil {
+		verification.Status = roleStatusNoCredentialsRequest
+		return verification
+	}
+	verification.CredentialsRequest = cr.Name
+
+	expected, err := policies.GCPOperatorPermissions(cr)
+	if err != nil {
+		verification.Status = roleStatusError
+		verification.Error = fmt.Sprintf("failed to parse CredentialsRequest: %v", err)
+		return verification
+	}
+
+	// The predefined roles of the CredentialsRequest and the roles of the WIF config must be bound on the project.
+	// Roles bound on other resources, e.g. to impersonate service accounts, are not part of the project policy.
+	var requiredRoles, requiredPermissions []string
+	for _, action := range expected.Actions() {
+		if strings.HasPrefix(action, policies.GCPRoleIDPrefix) {
+			requiredRoles = append(requiredRoles, action)
+		} else {
+			requiredPermissions = append(requiredPermissions, action)
+		}
+	}
+	for _, role := range sa.Roles() {
+		if len(role.ResourceBindings()) > 0 {
+			continue
+		}
+		roleName := fmt.Sprintf("projects/%s/roles/%s", projectID, role.RoleId())
+		if role.Predefined() {
+			roleName = policies.GCPRoleIDPrefix + role.RoleId()
+		}
+		if !slices.Contains(requiredRoles, roleName) {
+			requiredRoles = append(requiredRoles, roleName)
+		}
+	}
+
+	// Conditional bindings only grant their role when the condition holds, which cannot be evaluated here:
+	// the roles bound only conditionally and the permissions only they grant are reported separately.
+	member := "serviceAccount:" + verification.ServiceAccount
+	var boundRoles, conditionalRoles []string
+	for _, binding := range bindings {
+		if !slices.Contains(binding.Members, member) {
+			continue
+		}
+		if binding.Condition == "" {
+			if !slices.Contains(boundRoles, binding.Role) {
+				boundRoles = append(boundRoles, binding.Role)
+			}
+		} else if !slices.Contains(conditionalRoles, binding.Role) {
+			conditionalRoles = append(conditionalRoles, binding.Role)
+		}
+	}
+	conditionalRoles = slices.DeleteFunc(conditionalRoles, func(role string) bool { return slices.Contains(boundRoles, role) })
+
+	for _, role := range requiredRoles {
+		switch {
+		case slices.Contains(boundRoles, role):
+		case slices.Contains(conditionalRoles, role):
+			verification.ConditionalBindings = append(verification.ConditionalBindings, role)
+		default:
+			verification.MissingBindings = append(verification.MissingBindings, role)
+		}
+	}
+	slices.Sort(verification.MissingBindings)
+	slices.Sort(verification.ConditionalBindings)
+
+	granted, err := rolesPermissions(rolePermissions, boundRoles)
+	if err != nil {
+		verification.Status = roleStatusError
+		verification.Error = err.Error()
+		return verification
+	}
+	conditionallyGranted, err := rolesPermissions(rolePermissions, conditionalRoles)
+	if err != nil {
+		verification.Status = roleStatusError
+		verification.Error = err.Error()
+		return verification
+	}
+	for _, permission := range requiredPermissions {
+		switch {
+		case granted[permission]:
+		case conditionallyGranted[permission]:
+			verification.ConditionalActions = append(verification.ConditionalActions, permission)
+		default:
+			verification.MissingActions = append(verification.MissingActions, permission)
+		}
+	}
+
+	if len(verification.MissingBindings) > 0 || len(verification.MissingActions) > 0 {
+		verification.Status = roleStatusMissingPermissions
+	}
+
+	return verification
+}
+
+// rolesPermissions returns the set of the permissions granted by the roles
+func rolesPermissions(rolePermissions *rolePermissionsCache, roles []string) (map[string]bool, error) {
+	granted := map[string]bool{}
+	for _, role := range roles {
+		permissions, err := rolePermissions.get(role)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the permissions of role %s: %v", role, err)
+		}
+		for _, permission := range permissions {
+			granted[permission] = true
+		}
+	}
+	return granted, nil
+}
@@ -0,0 +1,227 @@
+package iampermissions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"github.com/openshift/osdctl/pkg/policies"
+	gcpprovider "github.com/openshift/osdctl/pkg/provider/gcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ccmServiceAccount = "serviceAccount:my-wif-openshift-gcp-ccm@my-project.iam.gserviceaccount.com"
+
+type fakeIAMClient struct {
+	bindings    []gcpprovider.Binding
+	roles       map[string][]string
+	roleLookups map[string]int
+}
+
+func (f *fakeIAMClient) GetProjectIAMBindings(projectID string) ([]gcpprovider.Binding, error) {
+	if projectID != "my-project" {
+		return nil, fmt.Errorf("unexpected project %s", projectID)
+	}
+	return f.bindings, nil
+}
+
+func (f *fakeIAMClient) GetRolePermissions(roleName string) ([]string, error) {
+	if f.roleLookups == nil {
+		f.roleLookups = map[string]int{}
+	}
+	f.roleLookups[roleName]++
+	permissions, ok := f.roles[roleName]
+	if !ok {
+		return nil, fmt.Errorf("role %s not found", roleName)
+	}
+	return permissions, nil
+}
+
+func newCCMCredentialsRequest(t *testing.T) *cco.CredentialsRequest {
+	cr := newGCPCredentialsRequest(t, "openshift-gcp-ccm", []string{"roles/compute.viewer"}, []string{"compute.instances.get", "compute.instances.list"})
+	cr.Spec.SecretRef.Namespace = "openshift-cloud-controller-manager"
+	cr.Spec.SecretRef.Name = "gcp-ccm-cloud-credentials"
+	return cr
+}
+
+func newTestWifConfig(t *testing.T) *cmv1.WifConfig {
+	wifConfig, err := cmv1.NewWifConfig().
+		ID("wif123").
+		Gcp(cmv1.NewWifGcp().ProjectId("my-project").ServiceAccounts(
+			cmv1.NewWifServiceAccount().
+				ServiceAccountId("my-wif-openshift-gcp-ccm").
+				CredentialRequest(cmv1.NewWifCredentialRequest().SecretRef(cmv1.NewWifSecretRef().
+					Namespace("openshift-cloud-controller-manager").Name("gcp-ccm-cloud-credentials"))).
+				Roles(
+					cmv1.NewWifRole().RoleId("compute.viewer").Predefined(true),
+					cmv1.NewWifRole().RoleId("my_wif_openshift_gcp_ccm").Permissions("compute.instances.get", "compute.instances.list"),
+					cmv1.NewWifRole().RoleId("iam.serviceAccountUser").Predefined(true).
+						ResourceBindings(cmv1.NewWifResourceBinding().Type("iam.serviceAccounts").Name("other"))),
+			cmv1.NewWifServiceAccount().
+				ServiceAccountId("my-wif-unknown").
+				CredentialRequest(cmv1.NewWifCredentialRequest().SecretRef(cmv1.NewWifSecretRef().
+					Namespace("openshift-unknown").Name("cloud-credentials"))))).
+		Build()
+	require.NoError(t, err)
+	return wifConfig
+}
+
+func newTestWIFVerifyOptions(t *testing.T, gcpClient gcpprovider.IAMClient, w *bytes.Buffer) *verifyOptions {
+	cluster, err := cmv1.NewCluster().
+		ID("abc123").
+		Version(cmv1.NewVersion().RawID("4.16.2")).
+		GCP(cmv1.NewGCP().Authentication(cmv1.NewGcpAuthentication().Id("wif123"))).
+		Build()
+	require.NoError(t, err)
+
+	return &verifyOptions{
+		ClusterID: "abc123",
+		Cloud:     policies.GCP,
+		Output:    "json",
+		getClusterFunc: func(string) (*cmv1.Cluster, error) {
+			return cluster, nil
+		},
+		getWifConfigFunc: func(id string) (*cmv1.WifConfig, error) {
+			assert.Equal(t, "wif123", id)
+			return newTestWifConfig(t), nil
+		},
+		gcpClientFunc: func() (gcpprovider.IAMClient, error) {
+			return gcpClient, nil
+		},
+		downloadFunc: func(version string, cloud policies.CloudSpec) (string, error) {
+			assert.Equal(t, policies.GCP, cloud)
+			return "/crs", nil
+		},
+		parseFunc: func(string) ([]*cco.CredentialsRequest, error) {
+			return []*cco.CredentialsRequest{newCCMCredentialsRequest(t)}, nil
+		},
+		outputWriter: w,
+	}
+}
+
+func TestVerifyWIFRun(t *testing.T) {
+	gcpClient := &fakeIAMClient{
+		bindings: []gcpprovider.Binding{
+			{Role: "roles/compute.viewer", Members: []string{ccmServiceAccount, "user:someone@example.com"}},
+			{Role: "projects/my-project/roles/my_wif_openshift_gcp_ccm", Members: []string{ccmServiceAccount}},
+		},
+		roles: map[string][]string{
+			"roles/compute.viewer":                               {"compute.instances.list"},
+			"projects/my-project/roles/my_wif_openshift_gcp_ccm": {"compute.instances.get", "compute.instances.list"},
+		},
+	}
+
+	var outputBuffer bytes.Buffer
+	require.NoError(t, newTestWIFVerifyOptions(t, gcpClient, &outputBuffer).run())
+
+	report := verificationReport{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &report))
+	assert.Equal(t, "my-project", report.ProjectID)
+	require.Len(t, report.Roles, 2)
+
+	ccm := report.Roles[0]
+	assert.Equal(t, "openshift-cloud-controller-manager/gcp-ccm-cloud-credentials", ccm.Operator)
+	assert.Equal(t, "my-wif-openshift-gcp-ccm@my-project.iam.gserviceaccount.com", ccm.ServiceAccount)
+	assert.Equal(t, "openshift-gcp-ccm", ccm.CredentialsRequest)
+	assert.Equal(t, roleStatusOK, ccm.Status)
+	assert.Equal(t, roleStatusNoCredentialsRequest, report.Roles[1].Status)
+}
+
+func TestVerifyWIFRun_MissingBindings(t *testing.T) {
+	gcpClient := &fakeIAMClient{
+		bindings: []gcpprovider.Binding{
+			{Role: "roles/compute.viewer", Members: []string{ccmServiceAccount}},
+			{Role: "projects/my-project/roles/my_wif_openshift_gcp_ccm", Members: []string{"serviceAccount:other@my-project.iam.gserviceaccount.com"}},
+		},
+		roles: map[string][]string{
+			"roles/compute.viewer": {"compute.instances.list"},
+		},
+	}
+
+	var outputBuffer bytes.Buffer
+	o := newTestWIFVerifyOptions(t, gcpClient, &outputBuffer)
+	o.Output = "table"
+	err := o.run()
+	assert.EqualError(t, err, "1 of 2 service accounts are missing permissions or could not be verified")
+
+	output := outputBuffer.String()
+	assert.Contains(t, output, "project my-project")
+	assert.Contains(t, output, "MISSING BINDINGS")
+	assert.Contains(t, output, "my-wif-openshift-gcp-ccm@my-project.iam.gserviceaccount.com")
+	assert.Contains(t, output, "- binding:   projects/my-project/roles/my_wif_openshift_gcp_ccm")
+	assert.Contains(t, output, "- missing:   compute.instances.get")
+	assert.NotContains(t, output, "iam.serviceAccountUser")
+}
+
+func TestVerifyWIFRun_ConditionalBindings(t *testing.T) {
+	gcpClient := &fakeIAMClient{
+		bindings: []gcpprovider.Binding{
+			{Role: "roles/compute.viewer", Members: []string{ccmServiceAccount}},
+			// Bound both conditionally and unconditionally, the unconditional binding wins
+			{Role: "roles/compute.viewer", Members: []string{ccmServiceAccount}, Condition: `request.time < timestamp("2020-01-01T00:00:00Z")`},
+			{Role: "projects/my-project/roles/my_wif_openshift_gcp_ccm", Members: []string{ccmServiceAccount}, Condition: `request.time < timestamp("2020-01-01T00:00:00Z")`},
+		},
+		roles: map[string][]string{
+			"roles/compute.viewer":                               {"compute.instances.list"},
+			"projects/my-project/roles/my_wif_openshift_gcp_ccm": {"compute.instances.get", "compute.instances.list"},
+		},
+	}
+
+	var outputBuffer bytes.Buffer
+	require.NoError(t, newTestWIFVerifyOptions(t, gcpClient, &outputBuffer).run())
+
+	report := verificationReport{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &report))
+	ccm := report.Roles[0]
+	assert.Equal(t, roleStatusOK, ccm.Status)
+	assert.Empty(t, ccm.MissingBindings)
+	assert.Empty(t, ccm.MissingActions)
+	assert.Equal(t, []string{"projects/my-project/roles/my_wif_openshift_gcp_ccm"}, ccm.ConditionalBindings)
+	assert.Equal(t, []string{"compute.instances.get"}, ccm.ConditionalActions)
+
+	outputBuffer.Reset()
+	o := newTestWIFVerifyOptions(t, gcpClient, &outputBuffer)
+	o.Output = "table"
+	require.NoError(t, o.run())
+	assert.Contains(t, outputBuffer.String(), "? binding:   projects/my-project/roles/my_wif_openshift_gcp_ccm (conditional)")
+}
+
+func TestVerifyWIFRun_RoleError(t *testing.T) {
+	gcpClient := &fakeIAMClient{
+		bindings: []gcpprovider.Binding{{Role: "roles/compute.viewer", Members: []string{ccmServiceAccount}}},
+	}
+
+	var outputBuffer bytes.Buffer
+	err := newTestWIFVerifyOptions(t, gcpClient, &outputBuffer).run()
+	require.Error(t, err)
+
+	report := verificationReport{}
+	require.NoError(t, json.Unmarshal(outputBuffer.Bytes(), &report))
+	assert.Equal(t, roleStatusError, report.Roles[0].Status)
+	assert.Contains(t, report.Roles[0].Error, "role roles/compute.viewer not found")
+}
+
+func TestRolePermissionsCache(t *testing.T) {
+	gcpClient := &fakeIAMClient{roles: map[string][]string{"roles/compute.viewer": {"compute.instances.list"}}}
+	cache := newRolePermissionsCache(gcpClient)
+
+	for range 2 {
+		permissions, err := cache.get("roles/compute.viewer")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compute.instances.list"}, permissions)
+	}
+	assert.Equal(t, 1, gcpClient.roleLookups["roles/compute.viewer"])
+}
+
+func TestVerifyWIFRun_NotWIF(t *testing.T) {
+	var outputBuffer bytes.Buffer
+	o := newTestWIFVerifyOptions(t, &fakeIAMClient{}, &outputBuffer)
+	o.getClusterFunc = func(string) (*cmv1.Cluster, error) {
+		return cmv1.NewCluster().ID("abc123").Build()
+	}
+	assert.EqualError(t, o.run(), "cluster abc123 is not a WIF cluster")
+}
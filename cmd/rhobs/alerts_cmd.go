@@ -517,6 +517,40 @@ func (f *RhobsFetcher) PrintAlerts(ctx context.Context, format AlertsFormat, isP
 	return nil
 }
 
+// FiringAlert is the summary of an alert firing for a cluster
+type FiringAlert struct {
+	Name     string
+	Severity string
+	Summary  string
+	StartsAt time.Time
+}
+
+// QueryFiringAlerts returns the active alerts of the cluster of the fetcher, only the ones with the given severity if not empty.
+func (f *RhobsFetcher) QueryFiringAlerts(ctx context.Context, severity string) ([]FiringAlert, error) {
+	alerts, err := f.queryAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var firingAlerts []FiringAlert
+	for _, alert := range *filterMetricsResults(f, alerts, true) {
+		if alert.decoded.Status.State != rhobsmodels.AlertStatusStateActive {
+			continue
+		}
+		if severity != "" && alert.decoded.Labels["severity"] != severity {
+			continue
+		}
+		firingAlerts = append(firingAlerts, FiringAlert{
+			Name:     alert.decoded.Labels["alertname"],
+			Severity: alert.decoded.Labels["severity"],
+			Summary:  alert.decoded.Annotations["summary"],
+			StartsAt: alert.decoded.StartsAt,
+		})
+	}
+
+	return firingAlerts, nil
+}
+
 func (f *RhobsFetcher) QueryRules(ctx context.Context, ruleType string) (json.RawMessage, error) {
 	client, err := f.getClient()
 	if err != nil {
@@ -55,6 +55,11 @@ func newCmdLogs() *cobra.Command {
 	var outputFormatStr string
 	var isPrintingTimestamp bool
 	var printedFields []string
+	var clustersFile string
+	var orgId string
+	var isSummarizing bool
+	var topPatternsCount int
+	var inputFile string
 
 	cmd := &cobra.Command{
 		Use:   "logs [pod]",
@@ -64,7 +69,11 @@ func newCmdLogs() *cobra.Command {
 			"the command works as if the management cluster ID was passed if given a hosted cluster (HCP) ID. " +
 			"By default, logs from all the pods in the given namespace are returned but it is possible to specify " +
 			"a single pod as an argument or filter pods using their labels. Logs themselves can be also filtered " +
-			"to only keep the ones containing a given regexp (--contain-regex option) or a given log level (--level option).",
+			"to only keep the ones containing a given regexp (--contain-regex option) or a given log level (--level option). " +
+			"Logs of many clusters can be searched at once with the --clusters-file or --org option: " +
+			"clusters are grouped by RHOBS cell, one query is run per cell and the merged results get a cluster column. " +
+			"Instead of printing raw logs, the --summarize option groups similar log messages into patterns and prints the most frequent ones; " +
+			"it can also summarize logs previously saved with the json output format (--input-file option) without querying RHOBS.",
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = false
@@ -72,6 +81,17 @@ func newCmdLogs() *cobra.Command {
 			if isOpeningGrafanaUrl && !isComputingGrafanaUrl {
 				return fmt.Errorf("--browser can only be set if --url is set")
 			}
+			if !isSummarizing {
+				if cmd.Flags().Changed("top") {
+					return fmt.Errorf("--top can only be set if --summarize is set")
+				}
+				if cmd.Flags().Changed("input-file") {
+					return fmt.Errorf("--input-file can only be set if --summarize is set")
+				}
+			}
+			if topPatternsCount < 1 {
+				return fmt.Errorf("invalid value for --top flag: %d, it must be greater than 0", topPatternsCount)
+			}
 
 			if cmd.Flags().Changed("query") {
 				if len(args) > 0 {
@@ -231,6 +251,48 @@ func newCmdLogs() *cobra.Command {
 				}
 			}
 
+			if cmd.Flags().Changed("input-file") {
+				if cmd.Flags().Changed("cluster-id") {
+					return fmt.Errorf("--cluster-id cannot be used with --input-file")
+				}
+
+				cmd.SilenceUsage = true
+
+				results, err := readLogsFile(inputFile)
+				if err != nil {
+					return err
+				}
+				summarizeLogResults(results, outputFormat, topPatternsCount)
+				return nil
+			}
+
+			if isMultiClusterLogsCmd(cmd) {
+				if cmd.Flags().Changed("cluster-id") {
+					return fmt.Errorf("--cluster-id cannot be used with --clusters-file or --org")
+				}
+
+				cmd.SilenceUsage = true
+
+				clusterIds, err := resolveLogsClusterIds(clustersFile, orgId)
+				if err != nil {
+					return err
+				}
+				targets, err := createLogsTargets(cmd.Context(), clusterIds, commonOptions.hiveOcmUrl, namespace, cmd.Flags().Changed("namespace"))
+				if err != nil {
+					return err
+				}
+				results, err := queryMultiClusterLogs(cmd.Context(), targets, lokiExpr, namespace, cmd.Flags().Changed("query"), startTime, endTime, logsCount, isGoingForward)
+				if isSummarizing {
+					summarizeLogResults(results, outputFormat, topPatternsCount)
+				} else {
+					printLogResults(results, outputFormat, isPrintingTimestamp, true, printedFields)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to query logs: %v", err)
+				}
+				return nil
+			}
+
 			cmd.SilenceUsage = true
 
 			rhobsFetcher, err := CreateRhobsFetcher(cmd.Context(), commonOptions.clusterId, RhobsFetchForLogs, commonOptions.hiveOcmUrl)
@@ -270,6 +332,8 @@ func newCmdLogs() *cobra.Command {
 			} else {
 				if isFollowing {
 					err = rhobsFetcher.StreamLogs(lokiExpr, outputFormat, isPrintingTimestamp, printedFields)
+				} else if isSummarizing {
+					err = rhobsFetcher.SummarizeLogs(cmd.Context(), lokiExpr, startTime, endTime, logsCount, isGoingForward, outputFormat, topPatternsCount)
 				} else {
 					err = rhobsFetcher.PrintLogs(cmd.Context(), lokiExpr, startTime, endTime, logsCount, isGoingForward, outputFormat, isPrintingTimestamp, printedFields)
 				}
@@ -338,9 +402,40 @@ func newCmdLogs() *cobra.Command {
 		`use the "json" output format to know about all possible fields - exclusive with --url`)
 	cmd.MarkFlagsMutuallyExclusive("field", "url")
 
+	cmd.Flags().StringVar(&clustersFile, "clusters-file", "", `Search the logs of all the clusters listed in the given JSON file (format: {"clusters":["$CLUSTERID1", "$CLUSTERID2"]}) - exclusive with --cluster-id, --org, --url & --follow`)
+	cmd.Flags().StringVar(&orgId, "org", "", "Search the logs of all the active managed clusters of the given organization ID - exclusive with --cluster-id, --clusters-file, --url & --follow")
+	cmd.MarkFlagsMutuallyExclusive("clusters-file", "org")
+	cmd.MarkFlagsMutuallyExclusive("clusters-file", "url")
+	cmd.MarkFlagsMutuallyExclusive("clusters-file", "follow")
+	cmd.MarkFlagsMutuallyExclusive("org", "url")
+	cmd.MarkFlagsMutuallyExclusive("org", "follow")
+
+	cmd.Flags().BoolVar(&isSummarizing, "summarize", false, "Group similar log messages into patterns (timestamps, UUIDs, IPs & numbers being ignored) and print the most frequent ones "+
+		"with their count, first/last occurrence and an example - exclusive with --url, --follow, --ts & --field")
+	cmd.Flags().IntVar(&topPatternsCount, "top", 20, "Number of patterns to print - only applicable if --summarize is set")
+	cmd.Flags().StringVar(&inputFile, "input-file", "", "Summarize the logs saved in the given file (produced with the json output format) instead of querying RHOBS - "+
+		"only applicable if --summarize is set - exclusive with --clusters-file, --org & --query")
+	cmd.MarkFlagsMutuallyExclusive("summarize", "url")
+	cmd.MarkFlagsMutuallyExclusive("summarize", "follow")
+	cmd.MarkFlagsMutuallyExclusive("summarize", "ts")
+	cmd.MarkFlagsMutuallyExclusive("summarize", "field")
+	cmd.MarkFlagsMutuallyExclusive("input-file", "clusters-file")
+	cmd.MarkFlagsMutuallyExclusive("input-file", "org")
+	cmd.MarkFlagsMutuallyExclusive("input-file", "query")
+
 	return cmd
 }
 
+// isMultiClusterLogsCmd tells whether the given command searches the logs of several clusters at once.
+func isMultiClusterLogsCmd(cmd *cobra.Command) bool {
+	return cmd.Name() == "logs" && (cmd.Flags().Changed("clusters-file") || cmd.Flags().Changed("org"))
+}
+
+// isClusterlessLogsCmd tells whether the given command does not target a single cluster, so that the current cluster is not needed.
+func isClusterlessLogsCmd(cmd *cobra.Command) bool {
+	return isMultiClusterLogsCmd(cmd) || (cmd.Name() == "logs" && cmd.Flags().Changed("input-file"))
+}
+
 type LogsFormat string
 
 const (
@@ -439,6 +534,7 @@ type streamLogsResponse struct {
 }
 
 type logResult struct {
+	Cluster   string             `json:"cluster,omitempty"` // Only set when querying multiple clusters at once
 	Stream    *map[string]string `json:"stream"`
 	Values    []*[]string        `json:"values"`
 	timeStamp int64              `json:"-"`
@@ -487,6 +583,7 @@ type logsPrinter interface {
 
 type textLogsPrinter struct {
 	isPrintingTimeValue bool
+	isPrintingCluster   bool
 	fieldNames          []string
 }
 
@@ -500,6 +597,10 @@ func (p *textLogsPrinter) PrintResult(result *logResult) {
 		sb.WriteString(result.getHumanReadableTime())
 		sb.WriteString(" ")
 	}
+	if p.isPrintingCluster {
+		sb.WriteString(result.Cluster)
+		sb.WriteString(" ")
+	}
 	for _, fieldName := range p.fieldNames {
 		sb.WriteString((*result.Stream)[fieldName])
 		sb.WriteString(" ")
@@ -515,6 +616,7 @@ func (p *textLogsPrinter) PrintTrailer() {
 type csvLogsPrinter struct {
 	writer              *csv.Writer
 	isPrintingTimeValue bool
+	isPrintingCluster   bool
 	fieldNames          []string
 }
 
@@ -523,6 +625,9 @@ func (p *csvLogsPrinter) PrintHeader() {
 	if p.isPrintingTimeValue {
 		header = append(header, "TIME")
 	}
+	if p.isPrintingCluster {
+		header = append(header, "CLUSTER")
+	}
 	header = append(header, p.fieldNames...)
 	header = append(header, "MESSAGE")
 
@@ -538,6 +643,9 @@ func (p *csvLogsPrinter) PrintResult(result *logResult) {
 	if p.isPrintingTimeValue {
 		row = append(row, result.getHumanReadableTime())
 	}
+	if p.isPrintingCluster {
+		row = append(row, result.Cluster)
+	}
 	for _, fieldName := range p.fieldNames {
 		row = append(row, (*result.Stream)[fieldName])
 	}
@@ -583,14 +691,14 @@ func (p *jsonLogsPrinter) PrintTrailer() {
 	fmt.Println("]")
 }
 
-func createLogsPrinter(format LogsFormat, isPrintingTimeValue bool, fieldNames []string) logsPrinter {
+func createLogsPrinter(format LogsFormat, isPrintingTimeValue bool, isPrintingCluster bool, fieldNames []string) logsPrinter {
 	switch format {
 	case LogsFormatCsv:
-		return &csvLogsPrinter{writer: csv.NewWriter(os.Stdout), isPrintingTimeValue: isPrintingTimeValue, fieldNames: fieldNames}
+		return &csvLogsPrinter{writer: csv.NewWriter(os.Stdout), isPrintingTimeValue: isPrintingTimeValue, isPrintingCluster: isPrintingCluster, fieldNames: fieldNames}
 	case LogsFormatJson:
 		return &jsonLogsPrinter{}
 	default:
-		return &textLogsPrinter{isPrintingTimeValue: isPrintingTimeValue, fieldNames: fieldNames}
+		return &textLogsPrinter{isPrintingTimeValue: isPrintingTimeValue, isPrintingCluster: isPrintingCluster, fieldNames: fieldNames}
 	}
 }
 
@@ -720,7 +828,7 @@ func (f *RhobsFetcher) queryLogs(ctx context.Context, lokiExpr string, startTime
 }
 
 func (f *RhobsFetcher) PrintLogs(ctx context.Context, lokiExpr string, startTime, endTime time.Time, logsCount int, isGoingForward bool, format LogsFormat, isPrintingTimeValue bool, fieldNames []string) error {
-	logsPrinter := createLogsPrinter(format, isPrintingTimeValue, fieldNames)
+	logsPrinter := createLogsPrinter(format, isPrintingTimeValue, false, fieldNames)
 	logsPrinter.PrintHeader()
 	defer logsPrinter.PrintTrailer()
 
@@ -749,7 +857,7 @@ func (f *RhobsFetcher) StreamLogs(lokiExpr string, format LogsFormat, isPrinting
 	log.Infoln("RHOBS cell:", f.RhobsCell)
 	log.Infoln("Loki query:", lokiExpr)
 
-	logsPrinter := createLogsPrinter(format, isPrintingTimeValue, fieldNames)
+	logsPrinter := createLogsPrinter(format, isPrintingTimeValue, false, fieldNames)
 	logsPrinter.PrintHeader()
 	defer logsPrinter.PrintTrailer()
 
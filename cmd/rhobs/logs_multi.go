@@ -0,0 +1,254 @@
+package rhobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift/osdctl/cmd/org"
+	"github.com/openshift/osdctl/internal/io"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// logsTarget is a cluster whose logs are searched as part of a multi-cluster query.
+type logsTarget struct {
+	fetcher   *RhobsFetcher
+	namespace string // Namespace in which the logs of the cluster are looked for
+}
+
+func (t *logsTarget) clusterId() string {
+	return t.fetcher.clusterId
+}
+
+// logsCellGroup gathers the targets sharing the same RHOBS cell so that they can be searched with a single query.
+type logsCellGroup struct {
+	rhobsCell string
+	targets   []*logsTarget
+}
+
+func resolveLogsClusterIds(clustersFile string, orgId string) ([]string, error) {
+	if clustersFile != "" {
+		clusterIds, err := io.ParseAndValidateClustersFile(clustersFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(clusterIds) == 0 {
+			return nil, fmt.Errorf("clusters file contains no cluster IDs - the 'clusters' array is empty")
+		}
+		return clusterIds, nil
+	}
+
+	subscriptions, err := org.SearchAllSubscriptionsByOrg(orgId, org.StatusActive, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cluster subscriptions for org '%s': %v", orgId, err)
+	}
+	if len(subscriptions) == 0 {
+		return nil, fmt.Errorf("no active managed cluster found for org '%s'", orgId)
+	}
+
+	var clusterIds []string
+	for _, subscription := range subscriptions {
+		if subscription.ClusterID() != "" {
+			clusterIds = append(clusterIds, subscription.ClusterID())
+		}
+	}
+
+	return clusterIds, nil
+}
+
+// createLogsTargets resolves the RHOBS cell of every given cluster.
+// Clusters for which the resolution fails are skipped with a warning as long as at least one cluster can be searched.
+func createLogsTargets(ctx context.Context, clusterIds []string, hiveOcmUrl string, namespace string, isNamespaceExplicitlySet bool) ([]*logsTarget, error) {
+	var targets []*logsTarget
+
+	for idx, clusterId := range clusterIds {
+		log.Infof("[%d/%d] Resolving RHOBS cell for cluster %s\n", idx+1, len(clusterIds), clusterId)
+
+		fetcher, err := CreateRhobsFetcher(ctx, clusterId, RhobsFetchForLogs, hiveOcmUrl)
+		if err != nil || fetcher == nil {
+			log.Warnf("Skipping cluster %s: %v\n", clusterId, err)
+			continue
+		}
+
+		targets = append(targets, &logsTarget{
+			fetcher:   fetcher,
+			namespace: resolveLogsNamespace(fetcher, isNamespaceExplicitlySet, namespace),
+		})
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("failed to resolve the RHOBS cell of any of the %d given clusters", len(clusterIds))
+	}
+
+	return targets, nil
+}
+
+// groupLogsTargetsByCell returns the targets grouped by RHOBS cell, sorted by cell URL.
+func groupLogsTargetsByCell(targets []*logsTarget) []*logsCellGroup {
+	cellToGroup := map[string]*logsCellGroup{}
+	var groups []*logsCellGroup
+
+	for _, target := range targets {
+		group, ok := cellToGroup[target.fetcher.RhobsCell]
+		if !ok {
+			group = &logsCellGroup{rhobsCell: target.fetcher.RhobsCell}
+			cellToGroup[target.fetcher.RhobsCell] = group
+			groups = append(groups, group)
+		}
+		group.targets = append(group.targets, target)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].rhobsCell < groups[j].rhobsCell
+	})
+
+	return groups
+}
+
+func uniqueSortedValues(values []string) []string {
+	isSeen := map[string]bool{}
+	var uniqueValues []string
+
+	for _, value := range values {
+		if !isSeen[value] {
+			isSeen[value] = true
+			uniqueValues = append(uniqueValues, value)
+		}
+	}
+	sort.Strings(uniqueValues)
+
+	return uniqueValues
+}
+
+func lokiLabelMatcher(label string, values []string) string {
+	if len(values) == 1 {
+		return fmt.Sprintf(`%s="%s"`, label, values[0])
+	}
+	return fmt.Sprintf(`%s=~"%s"`, label, strings.Join(values, "|"))
+}
+
+// buildLokiExpr adapts the given single-cluster LogQL expression so that it covers all the targets of the group.
+// When the expression was built from the command flags, the namespace stream selector is widened to the namespaces of all the targets.
+func (g *logsCellGroup) buildLokiExpr(lokiExpr string, defaultNamespace string, isRawQuery bool) string {
+	var namespaces, clusterExtIds []string
+	for _, target := range g.targets {
+		namespaces = append(namespaces, target.namespace)
+		clusterExtIds = append(clusterExtIds, target.fetcher.logsClusterExtId())
+	}
+
+	if !isRawQuery {
+		lokiExpr = strings.Replace(lokiExpr,
+			fmt.Sprintf(`{k8s_namespace_name="%s"}`, defaultNamespace),
+			"{"+lokiLabelMatcher("k8s_namespace_name", uniqueSortedValues(namespaces))+"}",
+			1)
+	}
+
+	return lokiExpr + " | " + lokiLabelMatcher("openshift_cluster_id", uniqueSortedValues(clusterExtIds))
+}
+
+// findClusterId returns the ID of the cluster the given log belongs to, or the empty string if it does not belong to any target.
+// HCP clusters share the external ID of their MC, so their logs are attributed using the HCP namespace they come from,
+// which does not depend on the namespace being searched.
+func (g *logsCellGroup) findClusterId(result *logResult) string {
+	if result.Stream == nil {
+		return ""
+	}
+	clusterExtId := (*result.Stream)["openshift_cluster_id"]
+	namespace := (*result.Stream)["k8s_namespace_name"]
+
+	var candidates []*logsTarget
+	for _, target := range g.targets {
+		if target.fetcher.logsClusterExtId() == clusterExtId {
+			candidates = append(candidates, target)
+		}
+	}
+
+	for _, candidate := range candidates {
+		if candidate.fetcher.IsHostedCluster && candidate.isHcpNamespace(namespace) {
+			return candidate.clusterId()
+		}
+	}
+	// Logs outside of any HCP namespace of the MC can only be attributed when a single cluster was searched on it
+	if len(candidates) == 1 {
+		return candidates[0].clusterId()
+	}
+
+	return ""
+}
+
+// isHcpNamespace reports whether the namespace belongs to the control plane of the HCP cluster of the target:
+// its HCP namespace (ocm-<env>-<cluster ID>-<cluster name>) or the parent namespace (ocm-<env>-<cluster ID>).
+func (t *logsTarget) isHcpNamespace(namespace string) bool {
+	if t.fetcher.HcpNamespace != "" && namespace == t.fetcher.HcpNamespace {
+		return true
+	}
+	return strings.HasPrefix(namespace, "ocm-") && strings.Contains(namespace+"-", "-"+t.clusterId()+"-")
+}
+
+// mergeLogResults merges the logs of several queries into a single list ordered by time, truncated to logsCount if positive.
+func mergeLogResults(resultsList [][]*logResult, logsCount int, isGoingForward bool) []*logResult {
+	var merged []*logResult
+	for _, results := range resultsList {
+		merged = append(merged, results...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if isGoingForward {
+			return merged[i].getTimeStamp() < merged[j].getTimeStamp()
+		}
+		return merged[i].getTimeStamp() > merged[j].getTimeStamp()
+	})
+
+	if 0 < logsCount && logsCount < len(merged) {
+		merged = merged[:logsCount]
+	}
+
+	return merged
+}
+
+// queryMultiClusterLogs runs one query per RHOBS cell concurrently and returns the merged logs, tagged with their cluster.
+// Logs of the cells which could be queried are returned even if other cells failed.
+func queryMultiClusterLogs(ctx context.Context, targets []*logsTarget, lokiExpr string, defaultNamespace string, isRawQuery bool, startTime, endTime time.Time, logsCount int, isGoingForward bool) ([]*logResult, error) {
+	groups := groupLogsTargetsByCell(targets)
+	log.Infof("Searching logs of %d clusters across %d RHOBS cells\n", len(targets), len(groups))
+
+	resultsList := make([][]*logResult, len(groups))
+	errs := make([]error, len(groups))
+
+	var wg sync.WaitGroup
+	for groupIdx, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// All the fetchers of a group share the same cell & token provider configuration
+			fetcher := group.targets[0].fetcher
+			err := fetcher.queryLogs(ctx, group.buildLokiExpr(lokiExpr, defaultNamespace, isRawQuery), startTime, endTime, logsCount, isGoingForward, func(result *logResult) {
+				result.Cluster = group.findClusterId(result)
+				if result.Cluster != "" {
+					resultsList[groupIdx] = append(resultsList[groupIdx], result)
+				}
+			})
+			if err != nil {
+				errs[groupIdx] = fmt.Errorf("failed to query logs from RHOBS cell '%s': %v", group.rhobsCell, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return mergeLogResults(resultsList, logsCount, isGoingForward), errors.Join(errs...)
+}
+
+func printLogResults(results []*logResult, format LogsFormat, isPrintingTimeValue bool, isPrintingCluster bool, fieldNames []string) {
+	logsPrinter := createLogsPrinter(format, isPrintingTimeValue, isPrintingCluster, fieldNames)
+	logsPrinter.PrintHeader()
+	for _, result := range results {
+		logsPrinter.PrintResult(result)
+	}
+	logsPrinter.PrintTrailer()
+}
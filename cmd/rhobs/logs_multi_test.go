@@ -0,0 +1,184 @@
+package rhobs
+
+import (
+	"testing"
+)
+
+func newTestLogsTarget(clusterId, rhobsCell, clusterExtId, mcExtId, namespace string) *logsTarget {
+	return &logsTarget{
+		fetcher: &RhobsFetcher{
+			clusterId:         clusterId,
+			clusterExternalId: clusterExtId,
+			mcExternalId:      mcExtId,
+			IsHostedCluster:   mcExtId != "",
+			RhobsCell:         rhobsCell,
+		},
+		namespace: namespace,
+	}
+}
+
+func newTestLogResult(ts string, clusterExtId, namespace string) *logResult {
+	return &logResult{
+		Stream: &map[string]string{
+			"openshift_cluster_id": clusterExtId,
+			"k8s_namespace_name":   namespace,
+		},
+		Values: []*[]string{{ts, "message " + ts}},
+	}
+}
+
+func TestGroupLogsTargetsByCell(t *testing.T) {
+	targets := []*logsTarget{
+		newTestLogsTarget("c1", "https://us-east-1-0.rhobs.api.openshift.com", "ext1", "", "default"),
+		newTestLogsTarget("c2", "https://eu-west-1-0.rhobs.api.openshift.com", "ext2", "", "default"),
+		newTestLogsTarget("c3", "https://us-east-1-0.rhobs.api.openshift.com", "ext3", "mc-ext", "ocm-production-c3"),
+	}
+
+	groups := groupLogsTargetsByCell(targets)
+
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+	if groups[0].rhobsCell != "https://eu-west-1-0.rhobs.api.openshift.com" || len(groups[0].targets) != 1 {
+		t.Errorf("unexpected first group: %s with %d targets", groups[0].rhobsCell, len(groups[0].targets))
+	}
+	if groups[1].rhobsCell != "https://us-east-1-0.rhobs.api.openshift.com" || len(groups[1].targets) != 2 {
+		t.Errorf("unexpected second group: %s with %d targets", groups[1].rhobsCell, len(groups[1].targets))
+	}
+}
+
+func TestLogsCellGroupBuildLokiExpr(t *testing.T) {
+	tests := []struct {
+		name       string
+		targets    []*logsTarget
+		lokiExpr   string
+		isRawQuery bool
+		want       string
+	}{
+		{
+			name: "single cluster keeps exact matchers",
+			targets: []*logsTarget{
+				newTestLogsTarget("c1", "cell", "ext1", "", "default"),
+			},
+			lokiExpr: `{k8s_namespace_name="default"} |= "error"`,
+			want:     `{k8s_namespace_name="default"} |= "error" | openshift_cluster_id="ext1"`,
+		},
+		{
+			name: "HCP clusters on the same MC share the MC external ID",
+			targets: []*logsTarget{
+				newTestLogsTarget("c2", "cell", "ext2", "mc-ext", "ocm-production-c2"),
+				newTestLogsTarget("c1", "cell", "ext1", "mc-ext", "ocm-production-c1"),
+			},
+			lokiExpr: `{k8s_namespace_name="default"} |= "error"`,
+			want:     `{k8s_namespace_name=~"ocm-production-c1|ocm-production-c2"} |= "error" | openshift_cluster_id="mc-ext"`,
+		},
+		{
+			name: "raw query only gets the cluster filter",
+			targets: []*logsTarget{
+				newTestLogsTarget("c1", "cell", "ext1", "", "default"),
+				newTestLogsTarget("c2", "cell", "ext2", "", "default"),
+			},
+			lokiExpr:   `{k8s_namespace_name="custom"}`,
+			isRawQuery: true,
+			want:       `{k8s_namespace_name="custom"} | openshift_cluster_id=~"ext1|ext2"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &logsCellGroup{rhobsCell: "cell", targets: tt.targets}
+			got := group.buildLokiExpr(tt.lokiExpr, "default", tt.isRawQuery)
+			if got != tt.want {
+				t.Errorf("buildLokiExpr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogsCellGroupFindClusterId(t *testing.T) {
+	group := &logsCellGroup{
+		rhobsCell: "cell",
+		targets: []*logsTarget{
+			newTestLogsTarget("classic", "cell", "ext-classic", "", "default"),
+			newTestLogsTarget("hcp1", "cell", "ext-hcp1", "mc-ext", "ocm-production-hcp1"),
+			newTestLogsTarget("hcp2", "cell", "ext-hcp2", "mc-ext", "ocm-production-hcp2"),
+		},
+	}
+
+	tests := []struct {
+		name   string
+		result *logResult
+		want   string
+	}{
+		{"classic cluster matched by external ID", newTestLogResult("1", "ext-classic", "other"), "classic"},
+		{"HCP cluster matched by namespace", newTestLogResult("1", "mc-ext", "ocm-production-hcp2"), "hcp2"},
+		{"HCP cluster matched by HCP namespace", newTestLogResult("1", "mc-ext", "ocm-production-hcp2-my-cluster"), "hcp2"},
+		{"ambiguous HCP log is dropped", newTestLogResult("1", "mc-ext", "openshift-monitoring"), ""},
+		{"unknown cluster is dropped", newTestLogResult("1", "unknown", "default"), ""},
+		{"log without stream is dropped", &logResult{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := group.findClusterId(tt.result)
+			if got != tt.want {
+				t.Errorf("findClusterId() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogsCellGroupFindClusterId_ExplicitNamespace(t *testing.T) {
+	// With an explicit --namespace, all the targets search the same namespace
+	hcp1 := newTestLogsTarget("hcp1", "cell", "ext-hcp1", "mc-ext", "default")
+	hcp1.fetcher.HcpNamespace = "ocm-production-hcp1-first"
+	hcp2 := newTestLogsTarget("hcp2", "cell", "ext-hcp2", "mc-ext", "default")
+	hcp2.fetcher.HcpNamespace = "ocm-production-hcp2-second"
+	group := &logsCellGroup{rhobsCell: "cell", targets: []*logsTarget{hcp1, hcp2}}
+
+	tests := []struct {
+		name   string
+		result *logResult
+		want   string
+	}{
+		{"first HCP cluster", newTestLogResult("1", "mc-ext", "ocm-production-hcp1-first"), "hcp1"},
+		{"second HCP cluster", newTestLogResult("1", "mc-ext", "ocm-production-hcp2-second"), "hcp2"},
+		{"shared namespace of the MC is dropped", newTestLogResult("1", "mc-ext", "default"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := group.findClusterId(tt.result)
+			if got != tt.want {
+				t.Errorf("findClusterId() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeLogResults(t *testing.T) {
+	resultsList := [][]*logResult{
+		{newTestLogResult("5", "a", "ns"), newTestLogResult("1", "a", "ns")},
+		{newTestLogResult("4", "b", "ns"), newTestLogResult("2", "b", "ns")},
+	}
+
+	backward := mergeLogResults(resultsList, 3, false)
+	if len(backward) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(backward))
+	}
+	for idx, want := range []int64{5, 4, 2} {
+		if backward[idx].getTimeStamp() != want {
+			t.Errorf("backward[%d] timestamp = %d, want %d", idx, backward[idx].getTimeStamp(), want)
+		}
+	}
+
+	forward := mergeLogResults(resultsList, -1, true)
+	if len(forward) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(forward))
+	}
+	for idx, want := range []int64{1, 2, 4, 5} {
+		if forward[idx].getTimeStamp() != want {
+			t.Errorf("forward[%d] timestamp = %d, want %d", idx, forward[idx].getTimeStamp(), want)
+		}
+	}
+}
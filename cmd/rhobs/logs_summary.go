@@ -0,0 +1,275 @@
+package rhobs
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	logPatternWildcard = "<*>"
+
+	// Minimum ratio of identical tokens for a log message to be considered as matching an existing pattern
+	logPatternSimilarityThreshold = 0.6
+)
+
+// Order matters: the most specific expressions must be applied first so that e.g. the digits of a UUID are not turned into numbers
+var logMessageNormalizers = []struct {
+	regex       *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<TS>"},
+	{regexp.MustCompile(`\b[IWEF]\d{4} \d{2}:\d{2}:\d{2}(\.\d+)?`), "<TS>"}, // klog header
+	{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`), "<TS>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<UUID>"},
+	{regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), "<IP>"},
+	{regexp.MustCompile(`(?i)\b([0-9a-f]{1,4}:){7}[0-9a-f]{1,4}\b`), "<IP>"},
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), "<NUM>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8,}\b`), "<HEX>"},
+	{regexp.MustCompile(`-?\b\d+(\.\d+)?`), "<NUM>"},
+}
+
+var logMessageSpacesRegex = regexp.MustCompile(`\s+`)
+
+// extractLogMessage returns the human message of structured (JSON) logs, or the log line itself otherwise.
+func extractLogMessage(line string) string {
+	trimmedLine := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmedLine, "{") {
+		var structuredLine map[string]interface{}
+		if json.Unmarshal([]byte(trimmedLine), &structuredLine) == nil {
+			for _, key := range []string{"msg", "message", "log"} {
+				if message, ok := structuredLine[key].(string); ok && message != "" {
+					if errMessage, ok := structuredLine["error"].(string); ok && errMessage != "" {
+						return message + " error: " + errMessage
+					}
+					return message
+				}
+			}
+		}
+	}
+
+	return trimmedLine
+}
+
+// normalizeLogMessage strips the variable parts of a log message (timestamps, UUIDs, IPs, numbers...) so that similar messages look the same.
+func normalizeLogMessage(line string) string {
+	message := extractLogMessage(line)
+	for _, normalizer := range logMessageNormalizers {
+		message = normalizer.regex.ReplaceAllString(message, normalizer.replacement)
+	}
+
+	return strings.TrimSpace(logMessageSpacesRegex.ReplaceAllString(message, " "))
+}
+
+type logPattern struct {
+	Template  string    `json:"template"`
+	Count     int       `json:"count"`
+	Clusters  []string  `json:"clusters,omitempty"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Example   string    `json:"example"`
+
+	tokens     []string
+	clusterSet map[string]struct{}
+}
+
+// matchScore returns the ratio of tokens shared with the pattern, or -1 if the tokens cannot belong to the pattern.
+func (p *logPattern) matchScore(tokens []string) float64 {
+	if len(tokens) != len(p.tokens) {
+		return -1
+	}
+	if len(tokens) == 0 {
+		return 1
+	}
+
+	sameTokensCount := 0
+	for idx, token := range tokens {
+		if p.tokens[idx] == token || p.tokens[idx] == logPatternWildcard {
+			sameTokensCount++
+		}
+	}
+
+	return float64(sameTokensCount) / float64(len(tokens))
+}
+
+func (p *logPattern) add(tokens []string, result *logResult) {
+	for idx, token := range tokens {
+		if p.tokens[idx] != token {
+			p.tokens[idx] = logPatternWildcard
+		}
+	}
+	p.Template = strings.Join(p.tokens, " ")
+	p.Count++
+
+	logTime := result.getTime()
+	if p.FirstSeen.IsZero() || logTime.Before(p.FirstSeen) {
+		p.FirstSeen = logTime
+	}
+	if logTime.After(p.LastSeen) {
+		p.LastSeen = logTime
+	}
+
+	if result.Cluster != "" {
+		if _, exists := p.clusterSet[result.Cluster]; !exists {
+			p.clusterSet[result.Cluster] = struct{}{}
+			p.Clusters = append(p.Clusters, result.Cluster)
+			sort.Strings(p.Clusters)
+		}
+	}
+}
+
+// logsSummarizer clusters log messages into templates, in the spirit of the Drain algorithm:
+// messages are normalized, then grouped with the most similar pattern having the same number of tokens and enough identical tokens,
+// the differing tokens being replaced with a wildcard.
+type logsSummarizer struct {
+	tokensCountToPatterns map[int][]*logPattern
+	patterns              []*logPattern
+	logsCount             int
+}
+
+func newLogsSummarizer() *logsSummarizer {
+	return &logsSummarizer{tokensCountToPatterns: map[int][]*logPattern{}}
+}
+
+func (s *logsSummarizer) Add(result *logResult) {
+	s.logsCount++
+	tokens := strings.Fields(normalizeLogMessage(result.getMessage()))
+
+	var bestPattern *logPattern
+	bestScore := logPatternSimilarityThreshold
+	for _, pattern := range s.tokensCountToPatterns[len(tokens)] {
+		if score := pattern.matchScore(tokens); score >= bestScore {
+			bestPattern, bestScore = pattern, score
+		}
+	}
+
+	if bestPattern == nil {
+		bestPattern = &logPattern{
+			tokens:     append([]string{}, tokens...),
+			Example:    result.getMessage(),
+			clusterSet: map[string]struct{}{},
+		}
+		s.tokensCountToPatterns[len(tokens)] = append(s.tokensCountToPatterns[len(tokens)], bestPattern)
+		s.patterns = append(s.patterns, bestPattern)
+	}
+
+	bestPattern.add(tokens, result)
+}
+
+// TopPatterns returns the topCount most frequent patterns - all of them if topCount is not positive.
+func (s *logsSummarizer) TopPatterns(topCount int) []*logPattern {
+	patterns := append([]*logPattern{}, s.patterns...)
+	sort.SliceStable(patterns, func(i, j int) bool {
+		if patterns[i].Count != patterns[j].Count {
+			return patterns[i].Count > patterns[j].Count
+		}
+		return patterns[i].FirstSeen.Before(patterns[j].FirstSeen)
+	})
+
+	if 0 < topCount && topCount < len(patterns) {
+		patterns = patterns[:topCount]
+	}
+
+	return patterns
+}
+
+func (s *logsSummarizer) Print(format LogsFormat, topCount int) {
+	patterns := s.TopPatterns(topCount)
+
+	switch format {
+	case LogsFormatCsv:
+		printLogPatternsAsCsv(patterns)
+	case LogsFormatJson:
+		printAsJson(patterns)
+	default:
+		fmt.Printf("%d logs grouped into %d patterns - showing the top %d\n\n", s.logsCount, len(s.patterns), len(patterns))
+		printLogPatternsAsText(patterns)
+	}
+}
+
+func printLogPatternsAsText(patterns []*logPattern) {
+	for idx, pattern := range patterns {
+		fmt.Printf("#%d - %d occurrences - %s\n", idx+1, pattern.Count, pattern.Template)
+		if len(pattern.Clusters) > 0 {
+			fmt.Printf("  Clusters  : %s\n", strings.Join(pattern.Clusters, ", "))
+		}
+		fmt.Printf("  First seen: %s\n", pattern.FirstSeen)
+		fmt.Printf("  Last seen : %s\n", pattern.LastSeen)
+		fmt.Printf("  Example   : %s\n", pattern.Example)
+		fmt.Println()
+	}
+}
+
+func printLogPatternsAsCsv(patterns []*logPattern) {
+	writer := csv.NewWriter(os.Stdout)
+
+	err := writer.Write([]string{"RANK", "COUNT", "CLUSTERS", "FIRST_SEEN", "LAST_SEEN", "TEMPLATE", "EXAMPLE"})
+	if err != nil {
+		log.Warnln("Failed to write CSV header:", err)
+		return
+	}
+
+	for idx, pattern := range patterns {
+		err := writer.Write([]string{
+			strconv.Itoa(idx + 1),
+			strconv.Itoa(pattern.Count),
+			strings.Join(pattern.Clusters, " "),
+			pattern.FirstSeen.Format(time.RFC3339Nano),
+			pattern.LastSeen.Format(time.RFC3339Nano),
+			pattern.Template,
+			pattern.Example,
+		})
+		if err != nil {
+			log.Warnln("Failed to write CSV row:", err)
+			return
+		}
+	}
+
+	writer.Flush()
+}
+
+func summarizeLogResults(results []*logResult, format LogsFormat, topCount int) {
+	summarizer := newLogsSummarizer()
+	for _, result := range results {
+		summarizer.Add(result)
+	}
+	summarizer.Print(format, topCount)
+}
+
+func (f *RhobsFetcher) SummarizeLogs(ctx context.Context, lokiExpr string, startTime, endTime time.Time, logsCount int, isGoingForward bool, format LogsFormat, topCount int) error {
+	summarizer := newLogsSummarizer()
+
+	err := f.queryLogs(ctx, lokiExpr, startTime, endTime, logsCount, isGoingForward, summarizer.Add)
+	if err != nil {
+		return err
+	}
+
+	summarizer.Print(format, topCount)
+
+	return nil
+}
+
+// readLogsFile reads logs previously saved with the json output format.
+func readLogsFile(filePath string) ([]*logResult, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read logs file: %v", err)
+	}
+
+	var results []*logResult
+	err = json.Unmarshal(content, &results)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse logs file '%s' - it is expected to be produced with the json output format: %v", filePath, err)
+	}
+
+	return results, nil
+}
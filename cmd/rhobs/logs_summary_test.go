@@ -0,0 +1,136 @@
+package rhobs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeLogMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "RFC3339 timestamp and numbers",
+			message: "2024-05-02T10:11:12.345Z reconciled 42 objects in 1.5s",
+			want:    "<TS> reconciled <NUM> objects in <NUM>s",
+		},
+		{
+			name:    "klog header",
+			message: `I0502 10:11:12.345678       1 controller.go:123] "Reconciling"`,
+			want:    `<TS> <NUM> controller.go:<NUM>] "Reconciling"`,
+		},
+		{
+			name:    "UUID and IP",
+			message: "cluster 2f1dc975-c715-457d-b451-414be4ef1c5f unreachable at 10.0.12.4:6443",
+			want:    "cluster <UUID> unreachable at <IP>",
+		},
+		{
+			name:    "structured log message and error",
+			message: `{"level":"error","ts":"2024-05-02T10:11:12Z","msg":"failed to sync   nodepool","error":"timeout after 30s"}`,
+			want:    "failed to sync nodepool error: timeout after <NUM>s",
+		},
+		{
+			name:    "long hexadecimal identifiers",
+			message: "image digest deadbeef0123 pulled",
+			want:    "image digest <HEX> pulled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeLogMessage(tt.message)
+			if got != tt.want {
+				t.Errorf("normalizeLogMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogsSummarizer(t *testing.T) {
+	summarizer := newLogsSummarizer()
+
+	addLog := func(ts string, cluster string, message string) {
+		summarizer.Add(&logResult{
+			Cluster: cluster,
+			Stream:  &map[string]string{},
+			Values:  []*[]string{{ts, message}},
+		})
+	}
+
+	addLog("3000", "c1", "failed to reconcile nodepool workers-a")
+	addLog("1000", "c2", "failed to reconcile nodepool workers-b")
+	addLog("2000", "c1", "failed to reconcile nodepool workers-c")
+	addLog("1500", "c1", "etcd leader changed to member 12")
+	addLog("2500", "", "totally unrelated message here")
+
+	patterns := summarizer.TopPatterns(2)
+
+	if len(patterns) != 2 {
+		t.Fatalf("expected 2 patterns, got %d", len(patterns))
+	}
+
+	top := patterns[0]
+	if top.Template != "failed to reconcile nodepool <*>" {
+		t.Errorf("unexpected top template: %q", top.Template)
+	}
+	if top.Count != 3 {
+		t.Errorf("unexpected top count: %d", top.Count)
+	}
+	if top.FirstSeen.UnixNano() != 1000 || top.LastSeen.UnixNano() != 3000 {
+		t.Errorf("unexpected first/last seen: %d/%d", top.FirstSeen.UnixNano(), top.LastSeen.UnixNano())
+	}
+	if top.Example != "failed to reconcile nodepool workers-a" {
+		t.Errorf("unexpected example: %q", top.Example)
+	}
+	if len(top.Clusters) != 2 || top.Clusters[0] != "c1" || top.Clusters[1] != "c2" {
+		t.Errorf("unexpected clusters: %v", top.Clusters)
+	}
+
+	// Ties are broken by first occurrence
+	if patterns[1].Template != "etcd leader changed to member <NUM>" {
+		t.Errorf("unexpected second template: %q", patterns[1].Template)
+	}
+
+	if len(summarizer.TopPatterns(0)) != 3 {
+		t.Errorf("expected all 3 patterns when not limiting the count")
+	}
+}
+
+func TestReadLogsFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "logs.json")
+	content := `[
+  {
+    "stream": {"k8s_pod_name": "pod-1"},
+    "values": [["1700000000000000000", "hello 1"]]
+  },
+  {
+    "cluster": "c1",
+    "stream": {"k8s_pod_name": "pod-2"},
+    "values": [["1700000000000000001", "hello 2"]]
+  }
+]`
+	if err := os.WriteFile(filePath, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	results, err := readLogsFile(filePath)
+	if err != nil {
+		t.Fatalf("readLogsFile returned error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[1].Cluster != "c1" || results[1].getMessage() != "hello 2" || results[1].getTimeStamp() != 1700000000000000001 {
+		t.Errorf("unexpected second result: %+v", results[1])
+	}
+
+	if err := os.WriteFile(filePath, []byte("not json"), 0600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := readLogsFile(filePath); err == nil {
+		t.Error("expected an error for an invalid logs file")
+	}
+}
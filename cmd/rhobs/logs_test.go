@@ -224,3 +224,33 @@ func TestHcpLogsQueryDiffersFromStandard(t *testing.T) {
 		t.Error("broken and fixed Loki expressions should produce different Grafana URLs")
 	}
 }
+
+// --- logs flag groups ---
+
+func TestLogsCmdFlagGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "clusters file with raw query", args: []string{"--clusters-file", "clusters.json", "--query", `{k8s_namespace_name="ns"}`}},
+		{name: "org with raw query", args: []string{"--org", "org-id", "--query", `{k8s_namespace_name="ns"}`}},
+		{name: "clusters file with org", args: []string{"--clusters-file", "clusters.json", "--org", "org-id"}, wantErr: true},
+		{name: "input file with clusters file", args: []string{"--summarize", "--input-file", "logs.json", "--clusters-file", "clusters.json"}, wantErr: true},
+		{name: "input file with org", args: []string{"--summarize", "--input-file", "logs.json", "--org", "org-id"}, wantErr: true},
+		{name: "input file with raw query", args: []string{"--summarize", "--input-file", "logs.json", "--query", `{k8s_namespace_name="ns"}`}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCmdLogs()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			err := cmd.ValidateFlagGroups()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFlagGroups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -530,6 +531,39 @@ func (f *RhobsFetcher) PrintInstantMetrics(ctx context.Context, promExpr string,
 	return nil
 }
 
+// MetricSample is a value of an instant metrics query with its labels
+type MetricSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// QueryInstantMetricSamples evaluates the PromQL expression at evalTime, now if zero, and returns the samples of the
+// cluster of the fetcher. Samples whose value is not a number, e.g. NaN, are skipped.
+func (f *RhobsFetcher) QueryInstantMetricSamples(ctx context.Context, promExpr string, evalTime time.Time) ([]MetricSample, error) {
+	results, err := f.queryInstantMetrics(ctx, promExpr, evalTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []MetricSample
+	for _, result := range *filterMetricsResults(f, results, true) {
+		if !result.decoded.Value.isValid() {
+			continue
+		}
+		valueStr, ok := result.decoded.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+		samples = append(samples, MetricSample{Labels: result.decoded.Metric, Value: value})
+	}
+
+	return samples, nil
+}
+
 type MetricsTimeRange struct {
 	rawStartTime    string
 	rawEndTime      string
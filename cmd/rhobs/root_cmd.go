@@ -24,7 +24,7 @@ func NewCmdRhobs() *cobra.Command {
 					return nil
 				}
 			}
-			if commonOptions.clusterId == "" {
+			if commonOptions.clusterId == "" && !isClusterlessLogsCmd(cmd) {
 				var err error
 
 				commonOptions.clusterId, err = k8s.GetCurrentCluster()
@@ -0,0 +1,251 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// Action is a mutating OCM call recorded in a journal
+type Action string
+
+const (
+	ActionDeleteUpgradePolicy  Action = "delete-upgrade-policy"
+	ActionCreateUpgradePolicy  Action = "create-upgrade-policy"
+	ActionRestoreUpgradePolicy Action = "restore-upgrade-policy"
+	// Deletion of a restored upgrade policy, when restoring the other policies of the cluster failed
+	ActionRollbackUpgradePolicy Action = "rollback-upgrade-policy"
+	ActionUpdateChannel         Action = "update-channel"
+	ActionSendServiceLog        Action = "send-service-log"
+)
+
+// Entry is a mutating OCM call made on a cluster by a batch command
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Operation  string    `json:"operation"`
+	ClusterID  string    `json:"clusterId"`
+	ExternalID string    `json:"externalId,omitempty"`
+	Action     Action    `json:"action"`
+	Error      string    `json:"error,omitempty"` // Empty if the call succeeded
+	// OCM representation of the upgrade policy which was deleted, created or restored
+	Policy json.RawMessage `json:"policy,omitempty"`
+	// ID of the deleted upgrade policy a restored policy recreates
+	SourcePolicyID string            `json:"sourcePolicyId,omitempty"`
+	Details        map[string]string `json:"details,omitempty"`
+}
+
+// Journal records the mutating OCM calls of a batch command as JSON lines. Every entry is synced to disk as soon as it
+// is recorded, so that nothing is lost if the command is interrupted.
+// A nil Journal records nothing, e.g. in dry-run mode.
+type Journal struct {
+	operation string
+	path      string
+	file      *os.File
+	mutex     sync.Mutex
+}
+
+// DefaultPath returns the path of the journal of an operation started now, in the current directory
+func DefaultPath(operation string) string {
+	return fmt.Sprintf("%s-journal-%s.jsonl", operation, time.Now().Format("20060102_150405"))
+}
+
+// Open returns a journal appending the entries of the given operation to the file at path
+func Open(path string, operation string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	return &Journal{operation: operation, path: path, file: file}, nil
+}
+
+func (j *Journal) Path() string {
+	if j == nil {
+		return ""
+	}
+	return j.path
+}
+
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// Record writes the entry of a call made on the cluster, with the error of the call if it failed.
+// Failures to write the journal are reported but do not interrupt the command, which may be in the middle of
+// mutating a cluster.
+func (j *Journal) Record(cluster *v1.Cluster, action Action, callErr error, entry Entry) {
+	if j == nil {
+		return
+	}
+
+	entry.Time = time.Now().UTC()
+	entry.Operation = j.operation
+	entry.ClusterID = cluster.ID()
+	entry.ExternalID = cluster.ExternalID()
+	entry.Action = action
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+
+	if err := j.write(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "  ⚠️  Warning: failed to write %s of cluster %s to journal %s: %v\n", action, cluster.ID(), j.path, err)
+	}
+}
+
+// RecordPolicy writes the entry of a call on an upgrade policy of the cluster, with the full policy.
+func (j *Journal) RecordPolicy(cluster *v1.Cluster, action Action, policy *v1.ControlPlaneUpgradePolicy, sourcePolicyID string, callErr error) {
+	if j == nil {
+		return
+	}
+
+	entry := Entry{SourcePolicyID: sourcePolicyID}
+	if policy != nil {
+		policyJSON, err := MarshalPolicy(policy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  ⚠️  Warning: failed to marshal upgrade policy %s for journal: %v\n", policy.ID(), err)
+		}
+		entry.Policy = policyJSON
+	}
+
+	j.Record(cluster, action, callErr, entry)
+}
+
+func (j *Journal) write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+// MarshalPolicy returns the OCM JSON representation of an upgrade policy
+func MarshalPolicy(policy *v1.ControlPlaneUpgradePolicy) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := v1.MarshalControlPlaneUpgradePolicy(policy, &buf); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// Read returns the entries of the journal at path, in order
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse line %d of journal '%s': %w", lineNumber, path, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal '%s': %w", path, err)
+	}
+
+	return entries, nil
+}
+
+// DeletedPolicy is an upgrade policy deleted by a batch command
+type DeletedPolicy struct {
+	ClusterID  string
+	ExternalID string
+	DeletedAt  time.Time
+	Operation  string
+	Policy     *v1.ControlPlaneUpgradePolicy
+}
+
+// UnrestoredPolicies returns the upgrade policies successfully deleted according to the journal entries, and not
+// successfully restored afterwards, in the order they were deleted.
+func UnrestoredPolicies(entries []Entry) ([]DeletedPolicy, error) {
+	var deletedPolicies []DeletedPolicy
+	isRestored := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Error != "" {
+			continue
+		}
+
+		switch entry.Action {
+		case ActionDeleteUpgradePolicy:
+			if len(entry.Policy) == 0 {
+				continue
+			}
+			policy, err := v1.UnmarshalControlPlaneUpgradePolicy([]byte(entry.Policy))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse upgrade policy deleted from cluster %s: %w", entry.ClusterID, err)
+			}
+			deletedPolicies = append(deletedPolicies, DeletedPolicy{
+				ClusterID:  entry.ClusterID,
+				ExternalID: entry.ExternalID,
+				DeletedAt:  entry.Time,
+				Operation:  entry.Operation,
+				Policy:     policy,
+			})
+			isRestored[policyKey(entry.ClusterID, policy.ID())] = false
+		case ActionRestoreUpgradePolicy:
+			isRestored[policyKey(entry.ClusterID, entry.SourcePolicyID)] = true
+		case ActionRollbackUpgradePolicy:
+			isRestored[policyKey(entry.ClusterID, entry.SourcePolicyID)] = false
+		}
+	}
+
+	var unrestoredPolicies []DeletedPolicy
+	for _, deletedPolicy := range deletedPolicies {
+		if !isRestored[policyKey(deletedPolicy.ClusterID, deletedPolicy.Policy.ID())] {
+			unrestoredPolicies = append(unrestoredPolicies, deletedPolicy)
+		}
+	}
+
+	return unrestoredPolicies, nil
+}
+
+func policyKey(clusterID string, policyID string) string {
+	return clusterID + "/" + policyID
+}
+
+// NewRestoredPolicy returns a policy recreating the deleted one. Only the settings are kept, the ID and the next run
+// being set by OCM.
+func NewRestoredPolicy(original *v1.ControlPlaneUpgradePolicy) (*v1.ControlPlaneUpgradePolicy, error) {
+	policyBuilder := v1.NewControlPlaneUpgradePolicy().
+		Schedule(original.Schedule()).
+		ScheduleType(original.ScheduleType()).
+		UpgradeType(original.UpgradeType()).
+		EnableMinorVersionUpgrades(original.EnableMinorVersionUpgrades())
+
+	// Add version only for manual policies - automatic policies must not have version set
+	// Manual policies target a specific version, automatic policies use the latest available
+	if original.Version() != "" && original.ScheduleType() == v1.ScheduleTypeManual {
+		policyBuilder = policyBuilder.Version(original.Version())
+	}
+
+	return policyBuilder.Build()
+}
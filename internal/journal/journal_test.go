@@ -0,0 +1,268 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func buildCluster(t *testing.T, id string) *v1.Cluster {
+	t.Helper()
+	cluster, err := v1.NewCluster().ID(id).ExternalID("ext-" + id).Build()
+	if err != nil {
+		t.Fatalf("failed to build cluster: %v", err)
+	}
+	return cluster
+}
+
+func buildPolicy(t *testing.T, id string) *v1.ControlPlaneUpgradePolicy {
+	t.Helper()
+	policy, err := v1.NewControlPlaneUpgradePolicy().
+		ID(id).
+		Schedule("0 2 * * 1").
+		ScheduleType(v1.ScheduleTypeAutomatic).
+		UpgradeType(v1.UpgradeTypeControlPlane).
+		EnableMinorVersionUpgrades(true).
+		Build()
+	if err != nil {
+		t.Fatalf("failed to build policy: %v", err)
+	}
+	return policy
+}
+
+func policyEntry(t *testing.T, clusterID string, action Action, policy *v1.ControlPlaneUpgradePolicy, sourcePolicyID string, errMessage string) Entry {
+	t.Helper()
+	entry := Entry{ClusterID: clusterID, Action: action, SourcePolicyID: sourcePolicyID, Error: errMessage}
+	if policy != nil {
+		policyJSON, err := MarshalPolicy(policy)
+		if err != nil {
+			t.Fatalf("failed to marshal policy: %v", err)
+		}
+		entry.Policy = policyJSON
+	}
+	return entry
+}
+
+func TestJournalRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := Open(path, "transition-to-eus")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	cluster := buildCluster(t, "cluster-1")
+	policy := buildPolicy(t, "policy-1")
+	journal.RecordPolicy(cluster, ActionDeleteUpgradePolicy, policy, "", nil)
+	journal.Record(cluster, ActionUpdateChannel, errors.New("bad request"), Entry{Details: map[string]string{"channel": "eus"}})
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Operation != "transition-to-eus" || entries[0].ClusterID != "cluster-1" || entries[0].ExternalID != "ext-cluster-1" {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[0].Action != ActionDeleteUpgradePolicy || entries[0].Error != "" || entries[0].Time.IsZero() {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	readPolicy, err := v1.UnmarshalControlPlaneUpgradePolicy([]byte(entries[0].Policy))
+	if err != nil {
+		t.Fatalf("failed to unmarshal journaled policy: %v", err)
+	}
+	if readPolicy.ID() != "policy-1" || readPolicy.Schedule() != "0 2 * * 1" || !readPolicy.EnableMinorVersionUpgrades() {
+		t.Errorf("journaled policy does not match the original one: %+v", readPolicy)
+	}
+
+	if entries[1].Action != ActionUpdateChannel || entries[1].Error != "bad request" || entries[1].Details["channel"] != "eus" {
+		t.Errorf("unexpected second entry: %+v", entries[1])
+	}
+}
+
+func TestJournalAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	cluster := buildCluster(t, "cluster-1")
+
+	for _, operation := range []string{"force-upgrade", "journal-restore"} {
+		journal, err := Open(path, operation)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		journal.Record(cluster, ActionSendServiceLog, nil, Entry{})
+		_ = journal.Close()
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Operation != "force-upgrade" || entries[1].Operation != "journal-restore" {
+		t.Errorf("expected the entries of both operations in order, got %+v", entries)
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	var journal *Journal
+	cluster := buildCluster(t, "cluster-1")
+
+	// A nil journal is used in dry-run mode and must record nothing without failing
+	journal.Record(cluster, ActionUpdateChannel, nil, Entry{})
+	journal.RecordPolicy(cluster, ActionDeleteUpgradePolicy, buildPolicy(t, "policy-1"), "", nil)
+	if journal.Path() != "" {
+		t.Errorf("expected no path, got %s", journal.Path())
+	}
+	if err := journal.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestReadInvalidJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	if err := os.WriteFile(path, []byte("{\"action\":\"update-channel\"}\n\nnot json\n"), 0600); err != nil {
+		t.Fatalf("failed to write journal: %v", err)
+	}
+
+	if _, err := Read(path); err == nil {
+		t.Error("expected an error for an invalid line")
+	}
+}
+
+func TestUnrestoredPolicies(t *testing.T) {
+	policy1 := buildPolicy(t, "policy-1")
+	policy2 := buildPolicy(t, "policy-2")
+	restoredPolicy := buildPolicy(t, "policy-3")
+
+	tests := []struct {
+		name     string
+		entries  []Entry
+		expected []string // <cluster ID>/<policy ID>
+	}{
+		{
+			name:     "no entries",
+			entries:  nil,
+			expected: nil,
+		},
+		{
+			name: "deleted policy not restored",
+			entries: []Entry{
+				policyEntry(t, "cluster-1", ActionDeleteUpgradePolicy, policy1, "", ""),
+				{ClusterID: "cluster-1", Action: ActionUpdateChannel},
+			},
+			expected: []string{"cluster-1/policy-1"},
+		},
+		{
+			name: "failed deletion",
+			entries: []Entry{
+				policyEntry(t, "cluster-1", ActionDeleteUpgradePolicy, policy1, "", "not found"),
+			},
+			expected: nil,
+		},
+		{
+			name: "deleted policy restored",
+			entries: []Entry{
+				policyEntry(t, "cluster-1", ActionDeleteUpgradePolicy, policy1, "", ""),
+				policyEntry(t, "cluster-1", ActionRestoreUpgradePolicy, restoredPolicy, "policy-1", ""),
+			},
+			expected: nil,
+		},
+		{
+			name: "failed restore",
+			entries: []Entry{
+				policyEntry(t, "cluster-1", ActionDeleteUpgradePolicy, policy1, "", ""),
+				policyEntry(t, "cluster-1", ActionRestoreUpgradePolicy, restoredPolicy, "policy-1", "bad request"),
+			},
+			expected: []string{"cluster-1/policy-1"},
+		},
+		{
+			name: "restored policy rolled back",
+			entries: []Entry{
+				policyEntry(t, "cluster-1", ActionDeleteUpgradePolicy, policy1, "", ""),
+				policyEntry(t, "cluster-1", ActionDeleteUpgradePolicy, policy2, "", ""),
+				policyEntry(t, "cluster-1", ActionRestoreUpgradePolicy, restoredPolicy, "policy-1", ""),
+				policyEntry(t, "cluster-1", ActionRestoreUpgradePolicy, nil, "policy-2", "bad request"),
+				{ClusterID: "cluster-1", Action: ActionRollbackUpgradePolicy, SourcePolicyID: "policy-1"},
+			},
+			expected: []string{"cluster-1/policy-1", "cluster-1/policy-2"},
+		},
+		{
+			name: "restore of another cluster",
+			entries: []Entry{
+				policyEntry(t, "cluster-1", ActionDeleteUpgradePolicy, policy1, "", ""),
+				policyEntry(t, "cluster-2", ActionDeleteUpgradePolicy, policy1, "", ""),
+				policyEntry(t, "cluster-2", ActionRestoreUpgradePolicy, restoredPolicy, "policy-1", ""),
+			},
+			expected: []string{"cluster-1/policy-1"},
+		},
+		{
+			name: "created policy is not a deletion",
+			entries: []Entry{
+				policyEntry(t, "cluster-1", ActionCreateUpgradePolicy, policy1, "", ""),
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unrestored, err := UnrestoredPolicies(tt.entries)
+			if err != nil {
+				t.Fatalf("UnrestoredPolicies() error = %v", err)
+			}
+
+			var actual []string
+			for _, deletedPolicy := range unrestored {
+				actual = append(actual, deletedPolicy.ClusterID+"/"+deletedPolicy.Policy.ID())
+			}
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, actual)
+			}
+			for idx := range actual {
+				if actual[idx] != tt.expected[idx] {
+					t.Errorf("expected %v, got %v", tt.expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestUnrestoredPoliciesInvalidPolicy(t *testing.T) {
+	entries := []Entry{{ClusterID: "cluster-1", Action: ActionDeleteUpgradePolicy, Policy: json.RawMessage(`"not a policy"`)}}
+
+	if _, err := UnrestoredPolicies(entries); err == nil {
+		t.Error("expected an error for an invalid policy")
+	}
+}
+
+func TestNewRestoredPolicy(t *testing.T) {
+	automatic := buildPolicy(t, "policy-1")
+	restored, err := NewRestoredPolicy(automatic)
+	if err != nil {
+		t.Fatalf("NewRestoredPolicy() error = %v", err)
+	}
+	if restored.ID() != "" || restored.Schedule() != automatic.Schedule() || restored.UpgradeType() != automatic.UpgradeType() ||
+		restored.EnableMinorVersionUpgrades() != automatic.EnableMinorVersionUpgrades() {
+		t.Errorf("restored policy does not match the original one: %+v", restored)
+	}
+
+	manual, err := v1.NewControlPlaneUpgradePolicy().ID("policy-2").ScheduleType(v1.ScheduleTypeManual).Version("4.16.5").Build()
+	if err != nil {
+		t.Fatalf("failed to build policy: %v", err)
+	}
+	restored, err = NewRestoredPolicy(manual)
+	if err != nil {
+		t.Fatalf("NewRestoredPolicy() error = %v", err)
+	}
+	if restored.Version() != "4.16.5" {
+		t.Errorf("expected the version of the manual policy to be kept, got %q", restored.Version())
+	}
+}
@@ -0,0 +1,285 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// ApplyFunc performs the operation on a cluster
+type ApplyFunc func(cluster *v1.Cluster) error
+
+// ReachedFunc tells from the latest state of a cluster in OCM whether it reached the target state of the operation
+type ReachedFunc func(cluster *v1.Cluster) (bool, error)
+
+// Executor rolls an operation out to clusters wave by wave. Before starting a wave, it waits for the clusters of the
+// previous one to reach the target state, and it stops when too many clusters failed.
+type Executor struct {
+	operation string
+	opts      Options
+	dryRun    bool
+	apply     ApplyFunc
+	reached   ReachedFunc
+
+	conn         *sdk.Connection
+	fetchCluster func(ctx context.Context, clusterID string) (*v1.Cluster, error)
+}
+
+// NewExecutor returns an executor applying the operation with the given name to the clusters. Clusters are not waited
+// for when reached is nil or in dry-run mode.
+func NewExecutor(conn *sdk.Connection, operation string, opts Options, dryRun bool, apply ApplyFunc, reached ReachedFunc) *Executor {
+	return &Executor{
+		operation: operation,
+		opts:      opts,
+		dryRun:    dryRun,
+		apply:     apply,
+		reached:   reached,
+		conn:      conn,
+		fetchCluster: func(ctx context.Context, clusterID string) (*v1.Cluster, error) {
+			response, err := conn.ClustersMgmt().V1().Clusters().Cluster(clusterID).Get().SendContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return response.Body(), nil
+		},
+	}
+}
+
+// Result is the outcome of a rollout, the clusters being identified by their external ID
+type Result struct {
+	Succeeded    []string
+	Failed       []string // "<external ID>: <error>"
+	Skipped      []string // Completed by a previous run
+	NotProcessed []string // Left out because the rollout was aborted
+	AbortReason  string
+}
+
+// Run rolls the operation out to the clusters. Errors are only returned when the rollout cannot run or is interrupted,
+// failures of clusters are reported in the result.
+func (e *Executor) Run(ctx context.Context, clusters []*v1.Cluster) (*Result, error) {
+	state, err := e.initState()
+	if err != nil {
+		return nil, err
+	}
+
+	waves, err := PlanWaves(e.conn, clusters, e.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(waves) > 1 {
+		fmt.Printf("\nRolling out to %d clusters in %d waves:\n", len(clusters), len(waves))
+		for idx, wave := range waves {
+			fmt.Printf("  %d. %s (%d clusters)\n", idx+1, wave.Name, len(wave.Clusters))
+		}
+	}
+
+	result := &Result{}
+	processedCount, failedCount, clusterIdx := 0, 0, 0
+	for waveIdx, wave := range waves {
+		if len(waves) > 1 {
+			fmt.Printf("\n=== Wave %d/%d: %s (%d clusters) ===\n", waveIdx+1, len(waves), wave.Name, len(wave.Clusters))
+		}
+
+		// The clusters must reach the target state before the next wave starts
+		isWaiting := e.reached != nil && !e.dryRun && waveIdx < len(waves)-1
+
+		var pending []*ClusterState
+		for _, cluster := range wave.Clusters {
+			clusterIdx++
+
+			previousState := state.get(cluster.ID())
+			if previousState != nil && previousState.Status == ClusterStatusCompleted {
+				fmt.Printf("\n[%d/%d] Skipping cluster completed by a previous run: %s (%s)\n", clusterIdx, len(clusters), cluster.ID(), cluster.Name())
+				result.Skipped = append(result.Skipped, cluster.ExternalID())
+				continue
+			}
+
+			processedCount++
+			clusterState := &ClusterState{
+				ClusterID:  cluster.ID(),
+				ExternalID: cluster.ExternalID(),
+				Name:       cluster.Name(),
+				Wave:       wave.Name,
+			}
+
+			if previousState != nil && previousState.Status == ClusterStatusApplied {
+				fmt.Printf("\n[%d/%d] Operation already applied by a previous run: %s (%s)\n", clusterIdx, len(clusters), cluster.ID(), cluster.Name())
+			} else {
+				fmt.Printf("\n[%d/%d] Processing cluster: %s (%s)\n", clusterIdx, len(clusters), cluster.ID(), cluster.Name())
+				if err := e.apply(cluster); err != nil {
+					failedCount++
+					result.Failed = append(result.Failed, fmt.Sprintf("%s: %s", cluster.ExternalID(), err.Error()))
+					clusterState.Status = ClusterStatusFailed
+					clusterState.Error = err.Error()
+					if err := state.record(clusterState); err != nil {
+						return result, err
+					}
+					continue
+				}
+			}
+
+			if !isWaiting {
+				result.Succeeded = append(result.Succeeded, cluster.ExternalID())
+				clusterState.Status = ClusterStatusCompleted
+			} else {
+				pending = append(pending, clusterState)
+				clusterState.Status = ClusterStatusApplied
+			}
+			if err := state.record(clusterState); err != nil {
+				return result, err
+			}
+		}
+
+		if len(pending) > 0 {
+			waitFailures, err := e.waitForTargetState(ctx, state, wave, pending, result)
+			failedCount += waitFailures
+			if err != nil {
+				return result, err
+			}
+		}
+
+		if waveIdx < len(waves)-1 && processedCount > 0 && failedCount*100 > e.opts.FailureThreshold*processedCount {
+			result.AbortReason = fmt.Sprintf("%d of the %d processed clusters failed, above the failure threshold of %d%%",
+				failedCount, processedCount, e.opts.FailureThreshold)
+			for _, remainingWave := range waves[waveIdx+1:] {
+				for _, cluster := range remainingWave.Clusters {
+					result.NotProcessed = append(result.NotProcessed, cluster.ExternalID())
+				}
+			}
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (e *Executor) initState() (*State, error) {
+	if e.opts.StateFile == "" || e.dryRun {
+		return newState("", e.operation), nil
+	}
+
+	state, err := loadState(e.opts.StateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.opts.Resume {
+		if state != nil {
+			return nil, fmt.Errorf("rollout state '%s' already exists, use --resume to resume the rollout or remove it to start a new one", e.opts.StateFile)
+		}
+		state = newState(e.opts.StateFile, e.operation)
+		return state, state.save()
+	}
+
+	if state == nil {
+		return nil, fmt.Errorf("no rollout state found at '%s' to resume", e.opts.StateFile)
+	}
+	if state.Operation != e.operation {
+		return nil, fmt.Errorf("rollout state '%s' is for operation '%s', not '%s'", e.opts.StateFile, state.Operation, e.operation)
+	}
+
+	return state, nil
+}
+
+// waitForTargetState polls the pending clusters of the wave until they all reached the target state or the wave times out.
+// It returns the number of clusters which failed to reach the target state.
+func (e *Executor) waitForTargetState(ctx context.Context, state *State, wave Wave, pending []*ClusterState, result *Result) (int, error) {
+	fmt.Printf("\nWaiting for the %d clusters of wave '%s' to reach the target state (timeout: %s)\n", len(pending), wave.Name, e.opts.WaveTimeout)
+
+	failedCount := 0
+	deadline := time.Now().Add(e.opts.WaveTimeout)
+	for {
+		var stillPending []*ClusterState
+		for _, clusterState := range pending {
+			cluster, err := e.fetchCluster(ctx, clusterState.ClusterID)
+			if err != nil {
+				// OCM may be briefly unavailable, the cluster is checked again at the next poll
+				fmt.Fprintf(os.Stderr, "Warning: failed to get cluster %s: %v\n", clusterState.ClusterID, err)
+				stillPending = append(stillPending, clusterState)
+				continue
+			}
+
+			isReached, err := e.reached(cluster)
+			switch {
+			case err != nil:
+				clusterState.Status = ClusterStatusFailed
+				clusterState.Error = err.Error()
+			case isReached:
+				clusterState.Status = ClusterStatusCompleted
+			default:
+				stillPending = append(stillPending, clusterState)
+				continue
+			}
+
+			if err := e.recordWaitResult(state, clusterState, result); err != nil {
+				return failedCount, err
+			}
+			if clusterState.Status == ClusterStatusFailed {
+				failedCount++
+			}
+		}
+
+		pending = stillPending
+		if len(pending) == 0 {
+			fmt.Printf("All the clusters of wave '%s' reached the target state\n", wave.Name)
+			return failedCount, nil
+		}
+
+		if time.Now().After(deadline) {
+			for _, clusterState := range pending {
+				clusterState.Status = ClusterStatusFailed
+				clusterState.Error = fmt.Sprintf("did not reach the target state within %s", e.opts.WaveTimeout)
+				if err := e.recordWaitResult(state, clusterState, result); err != nil {
+					return failedCount, err
+				}
+				failedCount++
+			}
+			return failedCount, nil
+		}
+
+		var pendingIDs []string
+		for _, clusterState := range pending {
+			pendingIDs = append(pendingIDs, clusterState.ExternalID)
+		}
+		fmt.Printf("  %s: %d clusters pending: %s\n", time.Now().UTC().Format(time.RFC3339), len(pending), strings.Join(pendingIDs, ", "))
+
+		select {
+		case <-ctx.Done():
+			return failedCount, ctx.Err()
+		case <-time.After(e.opts.PollInterval):
+		}
+	}
+}
+
+func (e *Executor) recordWaitResult(state *State, clusterState *ClusterState, result *Result) error {
+	if clusterState.Status == ClusterStatusFailed {
+		fmt.Printf("  ⚠️  %s (%s): %s\n", clusterState.ExternalID, clusterState.Name, clusterState.Error)
+		result.Failed = append(result.Failed, fmt.Sprintf("%s: %s", clusterState.ExternalID, clusterState.Error))
+	} else {
+		fmt.Printf("  ✅ %s (%s) reached the target state\n", clusterState.ExternalID, clusterState.Name)
+		result.Succeeded = append(result.Succeeded, clusterState.ExternalID)
+	}
+
+	return state.record(clusterState)
+}
+
+// PrintSummary displays the clusters skipped or left out by the rollout and why it was aborted, if it was.
+func (r *Result) PrintSummary() {
+	if len(r.Skipped) > 0 {
+		fmt.Printf("\nSkipped %d clusters completed by a previous run\n", len(r.Skipped))
+	}
+
+	if r.AbortReason != "" {
+		fmt.Printf("\n⚠️  Rollout aborted: %s\n", r.AbortReason)
+		fmt.Printf("The following %d clusters were not processed:\n", len(r.NotProcessed))
+		for _, externalID := range r.NotProcessed {
+			fmt.Printf("  - %s\n", externalID)
+		}
+	}
+}
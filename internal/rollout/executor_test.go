@@ -0,0 +1,242 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+func newTestCluster(t *testing.T, id string, region string) *v1.Cluster {
+	cluster, err := v1.NewCluster().ID(id).ExternalID("ext-" + id).Name("name-" + id).
+		Region(v1.NewCloudRegion().ID(region)).Build()
+	if err != nil {
+		t.Fatalf("failed to build cluster: %v", err)
+	}
+	return cluster
+}
+
+func waveClusterIDs(waves []Wave) [][]string {
+	var ids [][]string
+	for _, wave := range waves {
+		var waveIDs []string
+		for _, cluster := range wave.Clusters {
+			waveIDs = append(waveIDs, cluster.ID())
+		}
+		ids = append(ids, waveIDs)
+	}
+	return ids
+}
+
+func TestPlanWaves(t *testing.T) {
+	clusters := []*v1.Cluster{
+		newTestCluster(t, "1", "us-east-1"),
+		newTestCluster(t, "2", "eu-west-1"),
+		newTestCluster(t, "3", "us-east-1"),
+		newTestCluster(t, "4", "eu-west-1"),
+		newTestCluster(t, "5", "ap-south-1"),
+	}
+
+	tests := []struct {
+		name          string
+		opts          Options
+		expectedWaves [][]string
+		expectedNames []string
+	}{
+		{
+			name:          "Single wave",
+			opts:          Options{},
+			expectedWaves: [][]string{{"1", "2", "3", "4", "5"}},
+			expectedNames: []string{"all clusters"},
+		},
+		{
+			name:          "By percentage rounded up",
+			opts:          Options{WaveBy: WaveByPercentage, WaveSize: 30},
+			expectedWaves: [][]string{{"1", "2"}, {"3", "4"}, {"5"}},
+			expectedNames: []string{"clusters 1-2", "clusters 3-4", "clusters 5-5"},
+		},
+		{
+			name:          "By region",
+			opts:          Options{WaveBy: WaveByRegion},
+			expectedWaves: [][]string{{"5"}, {"2", "4"}, {"1", "3"}},
+			expectedNames: []string{"ap-south-1", "eu-west-1", "us-east-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waves, err := PlanWaves(nil, clusters, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ids := waveClusterIDs(waves); !reflect.DeepEqual(ids, tt.expectedWaves) {
+				t.Errorf("expected waves %v, got %v", tt.expectedWaves, ids)
+			}
+			var names []string
+			for _, wave := range waves {
+				names = append(names, wave.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expectedNames) {
+				t.Errorf("expected wave names %v, got %v", tt.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "Disabled", opts: Options{}},
+		{name: "Invalid wave-by", opts: Options{WaveBy: "az"}, wantErr: true},
+		{name: "Invalid wave size", opts: Options{WaveBy: WaveByPercentage, WaveSize: 0, WaveTimeout: time.Hour, PollInterval: time.Minute}, wantErr: true},
+		{name: "Invalid threshold", opts: Options{WaveBy: WaveByRegion, FailureThreshold: 101, WaveTimeout: time.Hour, PollInterval: time.Minute}, wantErr: true},
+		{name: "Resume without state", opts: Options{Resume: true}, wantErr: true},
+		{name: "Valid", opts: Options{WaveBy: WaveByPercentage, WaveSize: 10, FailureThreshold: 10, WaveTimeout: time.Hour, PollInterval: time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestExecutor returns an executor whose clusters reach the target state after the given number of polls
+func newTestExecutor(opts Options, failingIDs map[string]bool, pollsToReach int, applied *[]string) *Executor {
+	polls := map[string]int{}
+	executor := NewExecutor(nil, "test-op", opts, false,
+		func(cluster *v1.Cluster) error {
+			*applied = append(*applied, cluster.ID())
+			if failingIDs[cluster.ID()] {
+				return errors.New("apply failed")
+			}
+			return nil
+		},
+		func(cluster *v1.Cluster) (bool, error) {
+			polls[cluster.ID()]++
+			return polls[cluster.ID()] > pollsToReach, nil
+		})
+	executor.fetchCluster = func(ctx context.Context, clusterID string) (*v1.Cluster, error) {
+		return v1.NewCluster().ID(clusterID).Build()
+	}
+	return executor
+}
+
+func TestExecutorRun(t *testing.T) {
+	clusters := []*v1.Cluster{
+		newTestCluster(t, "1", "r"),
+		newTestCluster(t, "2", "r"),
+		newTestCluster(t, "3", "r"),
+		newTestCluster(t, "4", "r"),
+	}
+	opts := Options{WaveBy: WaveByPercentage, WaveSize: 50, FailureThreshold: 10, WaveTimeout: time.Minute, PollInterval: time.Millisecond}
+
+	t.Run("Waits for each wave", func(t *testing.T) {
+		var applied []string
+		result, err := newTestExecutor(opts, nil, 2, &applied).Run(context.Background(), clusters)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(result.Succeeded, []string{"ext-1", "ext-2", "ext-3", "ext-4"}) || len(result.Failed) != 0 || result.AbortReason != "" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("Aborts above the failure threshold", func(t *testing.T) {
+		var applied []string
+		result, err := newTestExecutor(opts, map[string]bool{"2": true}, 0, &applied).Run(context.Background(), clusters)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(applied, []string{"1", "2"}) {
+			t.Errorf("expected only the first wave to be applied, got %v", applied)
+		}
+		if !reflect.DeepEqual(result.NotProcessed, []string{"ext-3", "ext-4"}) || result.AbortReason == "" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		if !reflect.DeepEqual(result.Failed, []string{"ext-2: apply failed"}) {
+			t.Errorf("unexpected failures: %v", result.Failed)
+		}
+	})
+
+	t.Run("Times out waiting for the target state", func(t *testing.T) {
+		timeoutOpts := opts
+		timeoutOpts.WaveTimeout = 5 * time.Millisecond
+		timeoutOpts.FailureThreshold = 100
+
+		var applied []string
+		result, err := newTestExecutor(timeoutOpts, nil, 1000000, &applied).Run(context.Background(), clusters)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// The last wave is not waited for
+		if !reflect.DeepEqual(result.Succeeded, []string{"ext-3", "ext-4"}) || len(result.Failed) != 2 {
+			t.Errorf("unexpected result: %+v", result)
+		}
+	})
+}
+
+func TestExecutorResume(t *testing.T) {
+	clusters := []*v1.Cluster{
+		newTestCluster(t, "1", "r"),
+		newTestCluster(t, "2", "r"),
+		newTestCluster(t, "3", "r"),
+	}
+	stateFile := filepath.Join(t.TempDir(), "rollout.json")
+	opts := Options{WaveBy: WaveByPercentage, WaveSize: 34, FailureThreshold: 0, WaveTimeout: time.Minute, PollInterval: time.Millisecond, StateFile: stateFile}
+
+	var applied []string
+	result, err := newTestExecutor(opts, map[string]bool{"2": true}, 0, &applied).Run(context.Background(), clusters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.NotProcessed) != 1 {
+		t.Fatalf("expected the rollout to be aborted, got %+v", result)
+	}
+
+	// A new rollout must not overwrite the state of the previous one
+	if _, err := newTestExecutor(opts, nil, 0, &applied).Run(context.Background(), clusters); err == nil {
+		t.Errorf("expected an error when the rollout state already exists")
+	}
+
+	opts.Resume = true
+	applied = nil
+	result, err = newTestExecutor(opts, nil, 0, &applied).Run(context.Background(), clusters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(applied, []string{"2", "3"}) {
+		t.Errorf("expected the failed and remaining clusters to be applied, got %v", applied)
+	}
+	if !reflect.DeepEqual(result.Skipped, []string{"ext-1"}) || !reflect.DeepEqual(result.Succeeded, []string{"ext-2", "ext-3"}) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	state, err := loadState(stateFile)
+	if err != nil || state == nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	for _, clusterState := range state.Clusters {
+		if clusterState.Status != ClusterStatusCompleted {
+			t.Errorf("expected cluster %s to be completed, got %s", clusterState.ClusterID, clusterState.Status)
+		}
+	}
+
+	opts.StateFile = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := newTestExecutor(opts, nil, 0, &applied).Run(context.Background(), clusters); err == nil {
+		t.Errorf("expected an error when resuming without state")
+	}
+	if _, err := os.Stat(opts.StateFile); err == nil {
+		t.Errorf("expected no state to be created when resuming without state")
+	}
+}
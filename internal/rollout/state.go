@@ -0,0 +1,115 @@
+package rollout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openshift/osdctl/pkg/utils"
+)
+
+type ClusterStatus string
+
+const (
+	// The operation was applied but the cluster did not reach the target state yet
+	ClusterStatusApplied   ClusterStatus = "applied"
+	ClusterStatusCompleted ClusterStatus = "completed"
+	ClusterStatusFailed    ClusterStatus = "failed"
+)
+
+// ClusterState is the progress of the rollout on a cluster
+type ClusterState struct {
+	ClusterID  string        `json:"clusterId"`
+	ExternalID string        `json:"externalId"`
+	Name       string        `json:"name"`
+	Wave       string        `json:"wave"`
+	Status     ClusterStatus `json:"status"`
+	Error      string        `json:"error,omitempty"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+// State is the progress of a rollout. It is rewritten after every change when persisted, so that an interrupted rollout can be resumed.
+type State struct {
+	Operation string          `json:"operation"`
+	StartedAt time.Time       `json:"startedAt"`
+	Clusters  []*ClusterState `json:"clusters"`
+
+	path string // Not persisted if empty
+}
+
+func newState(path string, operation string) *State {
+	return &State{
+		Operation: operation,
+		StartedAt: time.Now().UTC(),
+		Clusters:  []*ClusterState{},
+		path:      path,
+	}
+}
+
+// loadState returns the state stored at the given path, or nil if there is none.
+func loadState(path string) (*State, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout state: %v", err)
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse rollout state '%s': %v", path, err)
+	}
+	state.path = path
+
+	return state, nil
+}
+
+// get returns the state of the given cluster, or nil if the rollout did not reach it yet.
+func (s *State) get(clusterID string) *ClusterState {
+	for _, clusterState := range s.Clusters {
+		if clusterState.ClusterID == clusterID {
+			return clusterState
+		}
+	}
+
+	return nil
+}
+
+// record adds or replaces the state of the same cluster, then saves the state.
+func (s *State) record(clusterState *ClusterState) error {
+	clusterState.UpdatedAt = time.Now().UTC()
+
+	isReplaced := false
+	for idx, existingState := range s.Clusters {
+		if existingState.ClusterID == clusterState.ClusterID {
+			s.Clusters[idx] = clusterState
+			isReplaced = true
+			break
+		}
+	}
+	if !isReplaced {
+		s.Clusters = append(s.Clusters, clusterState)
+	}
+
+	return s.save()
+}
+
+// save writes the state, if it is persisted to a file.
+func (s *State) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout state: %v", err)
+	}
+
+	if err := utils.WriteFileAtomic(s.path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write rollout state: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package rollout
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	v1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"github.com/spf13/pflag"
+)
+
+// Ways to split clusters into waves
+const (
+	WaveByNone       = ""
+	WaveByPercentage = "percentage"
+	WaveByMC         = "mc"
+	WaveByRegion     = "region"
+)
+
+const (
+	DefaultWaveSize         = 25
+	DefaultFailureThreshold = 10
+	DefaultWaveTimeout      = 2 * time.Hour
+	DefaultWavePollInterval = time.Minute
+)
+
+// Options configures how a multi-cluster operation is rolled out
+type Options struct {
+	WaveBy           string        // How to split the clusters into waves, a single wave with all clusters if empty
+	WaveSize         int           // Percentage of the clusters per wave when splitting by percentage
+	FailureThreshold int           // Percentage of failed clusters above which the rollout is aborted
+	WaveTimeout      time.Duration // How long to wait for the clusters of a wave to reach the target state
+	PollInterval     time.Duration // Interval between two checks of the state of the clusters of a wave
+	StateFile        string        // File the progress of the rollout is persisted to
+	Resume           bool          // Skip the clusters completed according to StateFile
+}
+
+// AddFlags registers the rollout flags of a multi-cluster command
+func AddFlags(flags *pflag.FlagSet, opts *Options) {
+	flags.StringVar(&opts.WaveBy, "wave-by", WaveByNone, "Split the clusters into waves by 'percentage', management cluster ('mc') or 'region', waiting for each wave to complete before starting the next one")
+	flags.IntVar(&opts.WaveSize, "wave-size", DefaultWaveSize, "Percentage of the clusters per wave with --wave-by percentage")
+	flags.IntVar(&opts.FailureThreshold, "failure-threshold", DefaultFailureThreshold, "Abort the rollout when more than this percentage of the processed clusters failed")
+	flags.DurationVar(&opts.WaveTimeout, "wave-timeout", DefaultWaveTimeout, "Maximum time to wait for the clusters of a wave to reach the target state")
+	flags.DurationVar(&opts.PollInterval, "wave-poll-interval", DefaultWavePollInterval, "Interval between two checks of the state of the clusters of a wave")
+	flags.StringVar(&opts.StateFile, "rollout-state", "", "File to persist the progress of the rollout to, required to --resume it")
+	flags.BoolVar(&opts.Resume, "resume", false, "Resume an interrupted rollout, skipping the clusters completed according to --rollout-state")
+}
+
+// Validate checks the consistency of the rollout options
+func (o *Options) Validate() error {
+	switch o.WaveBy {
+	case WaveByNone, WaveByPercentage, WaveByMC, WaveByRegion:
+	default:
+		return fmt.Errorf("invalid --wave-by value '%s'. Valid options: %s, %s, %s", o.WaveBy, WaveByPercentage, WaveByMC, WaveByRegion)
+	}
+
+	if o.WaveBy == WaveByPercentage && (o.WaveSize < 1 || o.WaveSize > 100) {
+		return fmt.Errorf("--wave-size must be between 1 and 100")
+	}
+
+	if o.WaveBy != WaveByNone {
+		if o.FailureThreshold < 0 || o.FailureThreshold > 100 {
+			return fmt.Errorf("--failure-threshold must be between 0 and 100")
+		}
+		if o.WaveTimeout <= 0 || o.PollInterval <= 0 {
+			return fmt.Errorf("--wave-timeout and --wave-poll-interval must be positive")
+		}
+	}
+
+	if o.Resume && o.StateFile == "" {
+		return fmt.Errorf("--resume requires --rollout-state")
+	}
+
+	return nil
+}
+
+// Wave is a group of clusters processed together
+type Wave struct {
+	Name     string
+	Clusters []*v1.Cluster
+}
+
+// groupKeyFunc returns the key of the group a cluster belongs to
+type groupKeyFunc func(cluster *v1.Cluster) (string, error)
+
+// PlanWaves splits the clusters into waves according to the options. The order of the clusters is kept within the waves.
+func PlanWaves(conn *sdk.Connection, clusters []*v1.Cluster, opts Options) ([]Wave, error) {
+	switch opts.WaveBy {
+	case WaveByPercentage:
+		return wavesByPercentage(clusters, opts.WaveSize), nil
+	case WaveByMC:
+		return wavesByGroup(clusters, func(cluster *v1.Cluster) (string, error) {
+			return managementClusterName(conn, cluster)
+		})
+	case WaveByRegion:
+		return wavesByGroup(clusters, func(cluster *v1.Cluster) (string, error) {
+			return cluster.Region().ID(), nil
+		})
+	default:
+		return []Wave{{Name: "all clusters", Clusters: clusters}}, nil
+	}
+}
+
+// wavesByPercentage splits the clusters into waves of the given percentage of the clusters, rounded up
+func wavesByPercentage(clusters []*v1.Cluster, percentage int) []Wave {
+	waveSize := (len(clusters)*percentage + 99) / 100
+	if waveSize < 1 {
+		waveSize = 1
+	}
+
+	var waves []Wave
+	for start := 0; start < len(clusters); start += waveSize {
+		end := min(start+waveSize, len(clusters))
+		waves = append(waves, Wave{
+			Name:     fmt.Sprintf("clusters %d-%d", start+1, end),
+			Clusters: clusters[start:end],
+		})
+	}
+
+	return waves
+}
+
+// wavesByGroup makes a wave per group of clusters, the groups being ordered by name
+func wavesByGroup(clusters []*v1.Cluster, groupKey groupKeyFunc) ([]Wave, error) {
+	groups := map[string][]*v1.Cluster{}
+	for _, cluster := range clusters {
+		key, err := groupKey(cluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan the wave of cluster %s: %w", cluster.ID(), err)
+		}
+		if key == "" {
+			key = "unknown"
+		}
+		groups[key] = append(groups[key], cluster)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	waves := make([]Wave, 0, len(keys))
+	for _, key := range keys {
+		waves = append(waves, Wave{Name: key, Clusters: groups[key]})
+	}
+
+	return waves, nil
+}
+
+func managementClusterName(conn *sdk.Connection, cluster *v1.Cluster) (string, error) {
+	if !cluster.Hypershift().Enabled() {
+		return "", fmt.Errorf("cluster is not an HCP cluster")
+	}
+
+	response, err := conn.ClustersMgmt().V1().Clusters().Cluster(cluster.ID()).Hypershift().Get().Send()
+	if err != nil {
+		return "", fmt.Errorf("failed to get management cluster: %w", err)
+	}
+
+	return response.Body().ManagementCluster(), nil
+}
@@ -23,6 +23,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 )
 
 // AWSCredsInput holds all resolved dependencies needed for both diagnostics
@@ -45,71 +46,100 @@ type AWSCredsInput struct {
 }
 
 type KeyStatus struct {
-	UserName    string
-	AccessKeyID string
-	Age         time.Duration
-	CreateDate  time.Time
-	LastUsed    string
-	Status      string
-	HiveMatch   bool
+	UserName    string        `json:"userName"`
+	AccessKeyID string        `json:"accessKeyID"`
+	Age         time.Duration `json:"-"`
+	CreateDate  time.Time     `json:"createDate"`
+	LastUsed    string        `json:"lastUsed"`
+	Status      string        `json:"status"`
+	HiveMatch   bool          `json:"hiveMatch"`
+}
+
+// MarshalJSON adds the age of the key in seconds, as durations have no readable JSON form.
+func (k KeyStatus) MarshalJSON() ([]byte, error) {
+	type keyStatus KeyStatus
+	return json.Marshal(struct {
+		keyStatus
+		AgeSeconds int64 `json:"ageSeconds"`
+	}{keyStatus(k), int64(k.Age.Seconds())})
 }
 
 type SecretStatus struct {
-	SecretName   string
-	Namespace    string
-	AccessKeyID  string
-	MatchesAWS   bool
-	Exists       bool
-	ErrorMessage string
+	SecretName   string `json:"secretName"`
+	Namespace    string `json:"namespace"`
+	AccessKeyID  string `json:"accessKeyID,omitempty"`
+	MatchesAWS   bool   `json:"matchesAWS"`
+	Exists       bool   `json:"exists"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
 }
 
 type CredRequestStatus struct {
-	CredRequestName string
-	SecretName      string
-	Namespace       string
-	Age             time.Duration
-	Exists          bool
-	NeedsRecreation bool
-	ErrorMessage    string
+	CredRequestName string        `json:"credRequestName"`
+	SecretName      string        `json:"secretName"`
+	Namespace       string        `json:"namespace"`
+	Age             time.Duration `json:"-"`
+	Exists          bool          `json:"exists"`
+	NeedsRecreation bool          `json:"needsRecreation"`
+	ErrorMessage    string        `json:"errorMessage,omitempty"`
+}
+
+// MarshalJSON adds the age of the secret in seconds, as durations have no readable JSON form.
+func (c CredRequestStatus) MarshalJSON() ([]byte, error) {
+	type credRequestStatus CredRequestStatus
+	return json.Marshal(struct {
+		credRequestStatus
+		AgeSeconds int64 `json:"ageSeconds"`
+	}{credRequestStatus(c), int64(c.Age.Seconds())})
 }
 
 type PermissionResult struct {
-	Action      string
-	Allowed     bool
-	Category    string   // "rotation" or "credreq"
-	RequestedBy []string // CR names that request this action (credreq category only)
+	Action      string   `json:"action"`
+	Allowed     bool     `json:"allowed"`
+	Category    string   `json:"category"`              // "rotation" or "credreq"
+	RequestedBy []string `json:"requestedBy,omitempty"` // CR names that request this action (credreq category only)
 }
 
 type Finding struct {
-	Severity string // "OK", "WARN", "FAIL"
-	Message  string
-	Guidance string
+	Severity string `json:"severity"` // "OK", "INFO", "WARN", "FAIL"
+	Message  string `json:"message"`
+	Guidance string `json:"guidance,omitempty"`
 }
 
 type DiagnosticReport struct {
-	ClusterID         string
-	ClusterName       string
-	ClusterExternalID string
-	IsCCS             bool
-	AWSAccountID      string
-	AccountCRName     string
+	ClusterID         string `json:"clusterID,omitempty"`
+	ClusterName       string `json:"clusterName,omitempty"`
+	ClusterExternalID string `json:"clusterExternalID,omitempty"`
+	IsCCS             bool   `json:"isCCS"`
+	AWSAccountID      string `json:"awsAccountID"`
+	AccountCRName     string `json:"accountCRName"`
+
+	ManagedAdminUser string `json:"managedAdminUser,omitempty"`
+	CcsAdminUser     string `json:"ccsAdminUser,omitempty"`
+	CallerARN        string `json:"callerARN,omitempty"`
+	CallerAccount    string `json:"callerAccount,omitempty"`
+
+	Keys         []KeyStatus         `json:"keys"`
+	Secrets      []SecretStatus      `json:"secrets"`
+	CredRequests []CredRequestStatus `json:"credRequests"`
+	Permissions  []PermissionResult  `json:"permissions"`
+	Findings     []Finding           `json:"findings"`
+
+	AllPermissionsOK bool   `json:"allPermissionsOK"`
+	AllSecretsInSync bool   `json:"allSecretsInSync"`
+	RootKeyInSync    bool   `json:"rootKeyInSync"`
+	ClusterRootKeyID string `json:"clusterRootKeyID,omitempty"`
+	HiveAccountKeyID string `json:"hiveAccountKeyID,omitempty"`
+}
 
-	ManagedAdminUser string
-	CcsAdminUser     string
-	CallerARN        string
-	CallerAccount    string
-
-	Keys         []KeyStatus
-	Secrets      []SecretStatus
-	CredRequests []CredRequestStatus
-	Permissions  []PermissionResult
-	Findings     []Finding
-
-	AllPermissionsOK bool
-	AllSecretsInSync bool
-	RootKeyInSync    bool
-	ClusterRootKeyID string
-	HiveAccountKeyID string
+// FailedFindings returns the number of error-level findings of the report.
+func (r *DiagnosticReport) FailedFindings() int {
+	failed := 0
+	for _, f := range r.Findings {
+		if f.Severity == "FAIL" {
+			failed++
+		}
+	}
+	return failed
 }
 
 // DiagnoseCredentials runs a full read-only diagnostic of IAM keys, Hive secrets,
@@ -701,6 +731,33 @@ func RenderReport(report *DiagnosticReport, out io.Writer) {
 	fmt.Fprintf(out, "%s\n\n", div)
 }
 
+// WriteReport serializes the diagnostic report to out in the given machine-readable format, "json" or "yaml".
+func WriteReport(report *DiagnosticReport, format string, out io.Writer) error {
+	return writeStructured(report, format, out)
+}
+
+func writeStructured(v any, format string, out io.Writer) error {
+	var data []byte
+	var err error
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(v, "", "  ")
+		if err == nil {
+			data = append(data, '\n')
+		}
+	case "yaml":
+		data, err = yaml.Marshal(v)
+	default:
+		return fmt.Errorf("unsupported output format '%s': valid formats are json, yaml", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to serialize the report: %w", err)
+	}
+
+	_, err = out.Write(data)
+	return err
+}
+
 func underline(width int) string {
 	return strings.Repeat("-", width)
 }
@@ -627,6 +627,82 @@ func TestRenderReport_WithFindings(t *testing.T) {
 	assert.Contains(t, output, "2 access keys (max 2)")
 }
 
+func TestWriteReport_JSON(t *testing.T) {
+	report := &DiagnosticReport{
+		ClusterID:        "abc123",
+		AWSAccountID:     "123456789012",
+		AccountCRName:    "test-account",
+		ManagedAdminUser: "osdManagedAdmin-abcd",
+		Keys: []KeyStatus{
+			{UserName: "osdManagedAdmin-abcd", AccessKeyID: "AKIAEXAMPLE1234", Age: 48 * time.Hour, Status: "Active", HiveMatch: true},
+		},
+		CredRequests: []CredRequestStatus{
+			{CredRequestName: "openshift-image-registry", SecretName: "cloud-creds", Namespace: "openshift-image-registry", Age: time.Hour, Exists: true},
+		},
+		Permissions: []PermissionResult{{Action: "iam:CreateAccessKey", Allowed: false, Category: "rotation"}},
+		Findings: []Finding{
+			{Severity: "FAIL", Message: "iam:CreateAccessKey is denied", Guidance: "Check the SCPs"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(report, "json", &buf))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "123456789012", decoded["awsAccountID"])
+
+	keys := decoded["keys"].([]any)
+	require.Len(t, keys, 1)
+	key := keys[0].(map[string]any)
+	assert.Equal(t, "AKIAEXAMPLE1234", key["accessKeyID"])
+	assert.Equal(t, float64(48*60*60), key["ageSeconds"])
+	assert.Equal(t, true, key["hiveMatch"])
+	assert.NotContains(t, key, "Age")
+
+	credRequest := decoded["credRequests"].([]any)[0].(map[string]any)
+	assert.Equal(t, float64(60*60), credRequest["ageSeconds"])
+
+	finding := decoded["findings"].([]any)[0].(map[string]any)
+	assert.Equal(t, "FAIL", finding["severity"])
+	assert.Equal(t, "Check the SCPs", finding["guidance"])
+}
+
+func TestWriteReport_YAML(t *testing.T) {
+	report := &DiagnosticReport{
+		AWSAccountID:  "123456789012",
+		AccountCRName: "test-account",
+		Findings:      []Finding{{Severity: "WARN", Message: "osdManagedAdmin-abcd has 2 access keys (max 2)"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(report, "yaml", &buf))
+	output := buf.String()
+
+	assert.Contains(t, output, "awsAccountID: \"123456789012\"")
+	assert.Contains(t, output, "severity: WARN")
+	assert.Contains(t, output, "message: osdManagedAdmin-abcd has 2 access keys (max 2)")
+}
+
+func TestWriteReport_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, WriteReport(&DiagnosticReport{}, "table", &buf))
+	assert.Empty(t, buf.String())
+}
+
+func TestFailedFindings(t *testing.T) {
+	report := &DiagnosticReport{
+		Findings: []Finding{
+			{Severity: "FAIL", Message: "Hive secret aws/uhc-production-test not found"},
+			{Severity: "WARN", Message: "osdManagedAdmin-abcd has 2 access keys (max 2)"},
+			{Severity: "INFO", Message: "osdCcsAdmin has no access key"},
+			{Severity: "FAIL", Message: "iam:CreateAccessKey is denied"},
+		},
+	}
+	assert.Equal(t, 2, report.FailedFindings())
+	assert.Equal(t, 0, (&DiagnosticReport{}).FailedFindings())
+}
+
 func TestFormatAge(t *testing.T) {
 	tests := []struct {
 		duration time.Duration
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	awsv1alpha1 "github.com/openshift/aws-account-operator/api/v1alpha1"
+	awsprovider "github.com/openshift/osdctl/pkg/provider/aws"
+)
+
+const (
+	osdManagedAdminSREIAM = "osdManagedAdminSRE"
+	osdCcsAdminIAM        = "osdCcsAdmin"
+)
+
+// AccessKeyAge is the age and last use of an IAM access key managed for an Account CR.
+type AccessKeyAge struct {
+	AccountCRName   string        `json:"accountCRName"`
+	AWSAccountID    string        `json:"awsAccountID"`
+	UserName        string        `json:"userName"`
+	AccessKeyID     string        `json:"accessKeyID"`
+	Status          string        `json:"status"`
+	CreateDate      time.Time     `json:"createDate"`
+	Age             time.Duration `json:"-"`
+	LastUsedDate    time.Time     `json:"lastUsedDate"` // zero if the key was never used
+	LastUsedService string        `json:"lastUsedService,omitempty"`
+}
+
+// accessKeyAgeReport is the machine-readable form of an access key in the age report.
+type accessKeyAgeReport struct {
+	AccessKeyAge
+	AgeSeconds int64 `json:"ageSeconds"`
+	// LastUsedDate shadows the one of the access key, to be omitted if the key was never used
+	LastUsedDate *time.Time `json:"lastUsedDate,omitempty"`
+	Rotate       bool       `json:"rotate"`
+}
+
+// CollectAccessKeyAges lists the access keys of the IAM users managed for an Account CR: osdManagedAdmin,
+// osdManagedAdminSRE and, on CCS accounts, osdCcsAdmin. The awsClient must be authenticated in the AWS account
+// of the Account CR. Users which do not exist in the account are skipped.
+func CollectAccessKeyAges(ctx context.Context, awsClient awsprovider.Client, account *awsv1alpha1.Account, now time.Time) ([]AccessKeyAge, error) {
+	suffix := account.Labels["iamUserId"]
+	if suffix == "" {
+		return nil, fmt.Errorf("no iamUserId label on Account CR %s", account.Name)
+	}
+
+	// Older accounts have an unsuffixed osdManagedAdmin user
+	users := [][]string{
+		{osdManagedAdminIAM + "-" + suffix, osdManagedAdminIAM},
+		{osdManagedAdminSREIAM + "-" + suffix},
+	}
+	if account.Spec.BYOC {
+		users = append(users, []string{osdCcsAdminIAM})
+	}
+
+	var keys []AccessKeyAge
+	for _, candidates := range users {
+		for _, username := range candidates {
+			keyList, err := awsClient.ListAccessKeys(&iam.ListAccessKeysInput{UserName: awsSdk.String(username)})
+			if isNoSuchEntity(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list access keys for %s: %w", username, err)
+			}
+
+			for _, key := range keyList.AccessKeyMetadata {
+				if key.AccessKeyId == nil {
+					continue
+				}
+				keyAge := AccessKeyAge{
+					AccountCRName: account.Name,
+					AWSAccountID:  account.Spec.AwsAccountID,
+					UserName:      username,
+					AccessKeyID:   *key.AccessKeyId,
+					Status:        string(key.Status),
+				}
+				if key.CreateDate != nil {
+					keyAge.CreateDate = *key.CreateDate
+					keyAge.Age = now.Sub(*key.CreateDate)
+				}
+
+				lastUsed, err := awsClient.GetAccessKeyLastUsed(ctx, &iam.GetAccessKeyLastUsedInput{AccessKeyId: key.AccessKeyId})
+				if err != nil {
+					return nil, fmt.Errorf("failed to get the last use of access key %s: %w", truncateKeyID(*key.AccessKeyId), err)
+				}
+				if lastUsed.AccessKeyLastUsed != nil {
+					if lastUsed.AccessKeyLastUsed.LastUsedDate != nil {
+						keyAge.LastUsedDate = *lastUsed.AccessKeyLastUsed.LastUsedDate
+					}
+					keyAge.LastUsedService = awsSdk.ToString(lastUsed.AccessKeyLastUsed.ServiceName)
+				}
+				keys = append(keys, keyAge)
+			}
+			break
+		}
+	}
+
+	return keys, nil
+}
+
+// RenderAgeReport prints the access keys, oldest first, flagging the ones older than maxAge which are due for
+// rotation. A zero maxAge flags no key.
+func RenderAgeReport(keys []AccessKeyAge, maxAge time.Duration, now time.Time, out io.Writer) {
+	sorted := sortByAge(keys)
+
+	fmt.Fprintf(out, "\nIAM Access Key Ages\n")
+	if len(sorted) == 0 {
+		fmt.Fprintf(out, "  (no keys found)\n")
+		return
+	}
+
+	fmt.Fprintf(out, "  %-32s %-12s %-24s %-16s %-8s %-6s %-10s %s\n", "ACCOUNT CR", "AWS ACCOUNT", "USER", "KEY ID", "STATUS", "AGE", "LAST USED", "ROTATE")
+	fmt.Fprintf(out, "  %-32s %-12s %-24s %-16s %-8s %-6s %-10s %s\n", underline(32), underline(12), underline(24), underline(16), underline(8), underline(6), underline(10), underline(6))
+
+	due := 0
+	for _, k := range sorted {
+		lastUsed := "never"
+		if !k.LastUsedDate.IsZero() {
+			lastUsed = formatAge(now.Sub(k.LastUsedDate)) + " ago"
+		}
+		rotate := colorGreen("no")
+		if rotationDue(k, maxAge) {
+			rotate = colorRed("yes")
+			due++
+		}
+		fmt.Fprintf(out, "  %-32s %-12s %-24s %-16s %-8s %-6s %-10s %s\n",
+			truncate(k.AccountCRName, 32),
+			k.AWSAccountID,
+			truncate(k.UserName, 24),
+			truncateKeyID(k.AccessKeyID),
+			k.Status,
+			formatAge(k.Age),
+			lastUsed,
+			rotate,
+		)
+	}
+
+	if maxAge > 0 {
+		fmt.Fprintf(out, "\n  %d of %d access keys are older than %s\n", due, len(sorted), formatAge(maxAge))
+	}
+}
+
+// WriteAgeReport serializes the access keys, oldest first, to out in the given machine-readable format, "json" or
+// "yaml", flagging the ones older than maxAge which are due for rotation.
+func WriteAgeReport(keys []AccessKeyAge, maxAge time.Duration, format string, out io.Writer) error {
+	report := []accessKeyAgeReport{}
+	for _, k := range sortByAge(keys) {
+		key := accessKeyAgeReport{AccessKeyAge: k, AgeSeconds: int64(k.Age.Seconds()), Rotate: rotationDue(k, maxAge)}
+		if !k.LastUsedDate.IsZero() {
+			key.LastUsedDate = &k.LastUsedDate
+		}
+		report = append(report, key)
+	}
+	return writeStructured(report, format, out)
+}
+
+func sortByAge(keys []AccessKeyAge) []AccessKeyAge {
+	sorted := make([]AccessKeyAge, len(keys))
+	copy(sorted, keys)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Age > sorted[j].Age
+	})
+	return sorted
+}
+
+func rotationDue(k AccessKeyAge, maxAge time.Duration) bool {
+	return maxAge > 0 && k.Age > maxAge
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	mock_aws "github.com/openshift/osdctl/pkg/provider/aws/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var ageReportNow = time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+func accessKeyMetadata(username, keyID string, age time.Duration) iamTypes.AccessKeyMetadata {
+	return iamTypes.AccessKeyMetadata{
+		UserName:    awsSdk.String(username),
+		AccessKeyId: awsSdk.String(keyID),
+		Status:      iamTypes.StatusTypeActive,
+		CreateDate:  awsSdk.Time(ageReportNow.Add(-age)),
+	}
+}
+
+func TestCollectAccessKeyAges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_aws.NewMockClient(ctrl)
+	day := 24 * time.Hour
+
+	mockClient.EXPECT().ListAccessKeys(&iam.ListAccessKeysInput{UserName: awsSdk.String("osdManagedAdmin-abcd")}).Return(nil, &iamTypes.NoSuchEntityException{})
+	mockClient.EXPECT().ListAccessKeys(&iam.ListAccessKeysInput{UserName: awsSdk.String("osdManagedAdmin")}).Return(&iam.ListAccessKeysOutput{
+		AccessKeyMetadata: []iamTypes.AccessKeyMetadata{accessKeyMetadata("osdManagedAdmin", "AKIAMANAGED0001", 120*day)},
+	}, nil)
+	mockClient.EXPECT().ListAccessKeys(&iam.ListAccessKeysInput{UserName: awsSdk.String("osdManagedAdminSRE-abcd")}).Return(&iam.ListAccessKeysOutput{
+		AccessKeyMetadata: []iamTypes.AccessKeyMetadata{accessKeyMetadata("osdManagedAdminSRE-abcd", "AKIASRE000000001", 10*day)},
+	}, nil)
+	mockClient.EXPECT().ListAccessKeys(&iam.ListAccessKeysInput{UserName: awsSdk.String("osdCcsAdmin")}).Return(&iam.ListAccessKeysOutput{}, nil)
+
+	mockClient.EXPECT().GetAccessKeyLastUsed(gomock.Any(), &iam.GetAccessKeyLastUsedInput{AccessKeyId: awsSdk.String("AKIAMANAGED0001")}).Return(&iam.GetAccessKeyLastUsedOutput{
+		AccessKeyLastUsed: &iamTypes.AccessKeyLastUsed{LastUsedDate: awsSdk.Time(ageReportNow.Add(-2 * time.Hour)), ServiceName: awsSdk.String("iam")},
+	}, nil)
+	mockClient.EXPECT().GetAccessKeyLastUsed(gomock.Any(), &iam.GetAccessKeyLastUsedInput{AccessKeyId: awsSdk.String("AKIASRE000000001")}).Return(&iam.GetAccessKeyLastUsedOutput{
+		AccessKeyLastUsed: &iamTypes.AccessKeyLastUsed{ServiceName: awsSdk.String("N/A")},
+	}, nil)
+
+	keys, err := CollectAccessKeyAges(context.TODO(), mockClient, testAccount(true, false), ageReportNow)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	assert.Equal(t, "test-account", keys[0].AccountCRName)
+	assert.Equal(t, "123456789012", keys[0].AWSAccountID)
+	assert.Equal(t, "osdManagedAdmin", keys[0].UserName)
+	assert.Equal(t, 120*day, keys[0].Age)
+	assert.Equal(t, ageReportNow.Add(-2*time.Hour), keys[0].LastUsedDate)
+	assert.Equal(t, "iam", keys[0].LastUsedService)
+
+	assert.Equal(t, "osdManagedAdminSRE-abcd", keys[1].UserName)
+	assert.True(t, keys[1].LastUsedDate.IsZero())
+
+	var out bytes.Buffer
+	RenderAgeReport(keys, 90*day, ageReportNow, &out)
+	output := out.String()
+	assert.Contains(t, output, "AKIA...0001")
+	assert.Contains(t, output, "2h ago")
+	assert.Contains(t, output, "never")
+	assert.Contains(t, output, "1 of 2 access keys are older than 90d")
+	assert.Less(t, bytes.Index(out.Bytes(), []byte("osdManagedAdmin ")), bytes.Index(out.Bytes(), []byte("osdManagedAdminSRE-abcd")))
+}
+
+func TestCollectAccessKeyAges_ListError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mock_aws.NewMockClient(ctrl)
+	mockClient.EXPECT().ListAccessKeys(gomock.Any()).Return(nil, fmt.Errorf("access denied"))
+
+	_, err := CollectAccessKeyAges(context.TODO(), mockClient, testAccount(false, false), ageReportNow)
+	assert.EqualError(t, err, "failed to list access keys for osdManagedAdmin-abcd: access denied")
+}
+
+func TestCollectAccessKeyAges_NoIAMUserID(t *testing.T) {
+	account := testAccount(false, false)
+	account.Labels = nil
+
+	_, err := CollectAccessKeyAges(context.TODO(), nil, account, ageReportNow)
+	assert.EqualError(t, err, "no iamUserId label on Account CR test-account")
+}
+
+func TestRenderAgeReport_NoKeys(t *testing.T) {
+	var out bytes.Buffer
+	RenderAgeReport(nil, 0, ageReportNow, &out)
+	assert.Contains(t, out.String(), "(no keys found)")
+}
+
+func TestWriteAgeReport(t *testing.T) {
+	keys := []AccessKeyAge{
+		{AccountCRName: "osd-new", AWSAccountID: "111111111111", UserName: "osdManagedAdmin-abcd", AccessKeyID: "AKIANEWKEY0001", Status: "Active", Age: 10 * 24 * time.Hour},
+		{AccountCRName: "osd-old", AWSAccountID: "222222222222", UserName: "osdManagedAdmin-efgh", AccessKeyID: "AKIAOLDKEY0001", Status: "Active", Age: 120 * 24 * time.Hour,
+			LastUsedDate: ageReportNow.Add(-time.Hour), LastUsedService: "iam"},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, WriteAgeReport(keys, 90*24*time.Hour, "json", &out))
+
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+
+	assert.Equal(t, "AKIAOLDKEY0001", decoded[0]["accessKeyID"])
+	assert.Equal(t, float64(120*24*60*60), decoded[0]["ageSeconds"])
+	assert.Equal(t, true, decoded[0]["rotate"])
+	assert.Equal(t, ageReportNow.Add(-time.Hour).Format(time.RFC3339), decoded[0]["lastUsedDate"])
+
+	assert.Equal(t, "AKIANEWKEY0001", decoded[1]["accessKeyID"])
+	assert.Equal(t, false, decoded[1]["rotate"])
+	assert.NotContains(t, decoded[1], "lastUsedDate")
+}
+
+func TestWriteAgeReport_NoKeys(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteAgeReport(nil, 0, "json", &out))
+	assert.Equal(t, "[]\n", out.String())
+}
@@ -0,0 +1,113 @@
+package policies
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+// DenyActionPrefix prefixes the actions of Deny statements, which must not be mistaken for the allowed ones
+const DenyActionPrefix = "deny:"
+
+// ActionGrant holds the resources and conditions an action is granted on by a CredentialsRequest
+type ActionGrant struct {
+	Resources  []string `json:"resources,omitempty"`
+	Conditions []string `json:"conditions,omitempty"` // JSON encoded IAM policy conditions
+}
+
+// OperatorPermissions are the permissions required by a CredentialsRequest, by action. GCP predefined roles are
+// listed as actions along with the permissions, prefixed with GCPRoleIDPrefix.
+type OperatorPermissions map[string]*ActionGrant
+
+// Actions returns the sorted actions of the permissions
+func (p OperatorPermissions) Actions() []string {
+	actions := make([]string, 0, len(p))
+	for action := range p {
+		actions = append(actions, action)
+	}
+	slices.Sort(actions)
+	return actions
+}
+
+func (p OperatorPermissions) grant(action string) *ActionGrant {
+	if p[action] == nil {
+		p[action] = &ActionGrant{}
+	}
+	return p[action]
+}
+
+// AWSOperatorPermissions returns the permissions required by an AWS CredentialsRequest
+func AWSOperatorPermissions(credReq *cco.CredentialsRequest) (OperatorPermissions, error) {
+	doc, err := AWSCredentialsRequestToPolicyDocument(credReq)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := OperatorPermissions{}
+	for _, statement := range doc.Statement {
+		var condition string
+		if len(statement.PolicyCondition) > 0 {
+			conditionJSON, err := json.Marshal(statement.PolicyCondition)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't marshal policy condition of '%s': %w", credReq.Name, err)
+			}
+			condition = string(conditionJSON)
+		}
+
+		for _, action := range statement.Action {
+			if statement.Effect == "Deny" {
+				action = DenyActionPrefix + action
+			}
+
+			grant := permissions.grant(action)
+			if statement.Resource != "" && !slices.Contains(grant.Resources, statement.Resource) {
+				grant.Resources = append(grant.Resources, statement.Resource)
+			}
+			if condition != "" && !slices.Contains(grant.Conditions, condition) {
+				grant.Conditions = append(grant.Conditions, condition)
+			}
+		}
+	}
+
+	for _, grant := range permissions {
+		slices.Sort(grant.Resources)
+		slices.Sort(grant.Conditions)
+	}
+
+	return permissions, nil
+}
+
+// GCPOperatorPermissions returns the permissions and predefined roles required by a GCP CredentialsRequest
+func GCPOperatorPermissions(credReq *cco.CredentialsRequest) (OperatorPermissions, error) {
+	sa, err := CredentialsRequestToWifServiceAccount(credReq)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := OperatorPermissions{}
+	for _, role := range sa.Roles {
+		if role.Predefined {
+			permissions.grant(GCPRoleIDPrefix + role.Id)
+			continue
+		}
+		for _, permission := range role.Permissions {
+			permissions.grant(permission)
+		}
+	}
+
+	return permissions, nil
+}
+
+// CredentialsRequestPermissions returns the permissions required by a CredentialsRequest of the given cloud
+func CredentialsRequestPermissions(credReq *cco.CredentialsRequest, cloud CloudSpec) (OperatorPermissions, error) {
+	switch cloud {
+	case AWS:
+		return AWSOperatorPermissions(credReq)
+	case GCP:
+		return GCPOperatorPermissions(credReq)
+	default:
+		return nil, fmt.Errorf("unsupported cloud: %s", cloud.String())
+	}
+}
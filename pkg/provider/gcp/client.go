@@ -0,0 +1,82 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/iam/v1"
+)
+
+// Binding is a binding of the IAM policy of a project, granting a role to members
+type Binding struct {
+	Role    string
+	Members []string
+	// Condition is the CEL expression restricting the binding, empty if the binding is unconditional
+	Condition string
+}
+
+// IAMClient reads the IAM configuration of GCP projects
+type IAMClient interface {
+	// GetProjectIAMBindings returns the bindings of the IAM policy of the project
+	GetProjectIAMBindings(projectID string) ([]Binding, error)
+	// GetRolePermissions returns the permissions of a predefined (roles/<id>) or custom (projects/<project>/roles/<id>) role
+	GetRolePermissions(roleName string) ([]string, error)
+}
+
+type iamClient struct {
+	ctx             context.Context
+	resourceManager *cloudresourcemanager.Service
+	iamService      *iam.Service
+}
+
+// NewIAMClient creates an IAMClient using the application default credentials
+func NewIAMClient(ctx context.Context) (IAMClient, error) {
+	resourceManager, err := cloudresourcemanager.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the resource manager client: %w", err)
+	}
+	iamService, err := iam.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the IAM client: %w", err)
+	}
+
+	return &iamClient{ctx: ctx, resourceManager: resourceManager, iamService: iamService}, nil
+}
+
+func (c *iamClient) GetProjectIAMBindings(projectID string) ([]Binding, error) {
+	// Version 3 is required to get the conditional bindings
+	policy, err := c.resourceManager.Projects.GetIamPolicy(projectID, &cloudresourcemanager.GetIamPolicyRequest{
+		Options: &cloudresourcemanager.GetPolicyOptions{RequestedPolicyVersion: 3},
+	}).Context(c.ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	bindings := make([]Binding, 0, len(policy.Bindings))
+	for _, binding := range policy.Bindings {
+		b := Binding{Role: binding.Role, Members: binding.Members}
+		if binding.Condition != nil {
+			b.Condition = binding.Condition.Expression
+		}
+		bindings = append(bindings, b)
+	}
+
+	return bindings, nil
+}
+
+func (c *iamClient) GetRolePermissions(roleName string) ([]string, error) {
+	var role *iam.Role
+	var err error
+	if strings.HasPrefix(roleName, "projects/") {
+		role, err = c.iamService.Projects.Roles.Get(roleName).Context(c.ctx).Do()
+	} else {
+		role, err = c.iamService.Roles.Get(roleName).Context(c.ctx).Do()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return role.IncludedPermissions, nil
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"os"
+)
+
+// WriteFileAtomic writes content to a temporary file next to path, then renames it to path, so that path is never left
+// truncated if the command is interrupted while writing it.
+func WriteFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, perm); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -13,6 +13,16 @@ type responseError struct {
 	Records json.RawMessage `json:"error"`
 }
 
+// RequestError is returned by Requester.Send when the response status code is not the expected one
+type RequestError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
 type Requester struct {
 	Method      string
 	Url         string
@@ -58,10 +68,10 @@ func (rh *Requester) Send() (string, error) {
 		var respErr responseError
 		err = json.Unmarshal(body, &respErr)
 		if err != nil || len(respErr.Records) == 0 {
-			return "", fmt.Errorf("request failed: %s: %s", resp.Status, body)
+			return "", &RequestError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("request failed: %s: %s", resp.Status, body)}
 		}
 
-		return "", fmt.Errorf("request failed: %s %s", resp.Status, respErr.Records)
+		return "", &RequestError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("request failed: %s %s", resp.Status, respErr.Records)}
 	}
 
 	return string(body), nil
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -16,8 +17,9 @@ type AccessTokenProvider interface {
 }
 
 // cachedTokenProvider caches an OAuth access token and transparently refreshes
-// it when it is close to expiring.
+// it when it is close to expiring. It is safe for concurrent use.
 type cachedTokenProvider struct {
+	mutex     sync.Mutex
 	token     string
 	expiresAt time.Time
 	fetchFunc func() (string, int, error)
@@ -35,6 +37,9 @@ func newCachedTokenProvider(fetchFunc func() (string, int, error)) *cachedTokenP
 
 // Token returns a valid access token, refreshing it if necessary.
 func (p *cachedTokenProvider) Token() (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
 	if p.token != "" && time.Now().Before(p.expiresAt.Add(-p.margin)) {
 		return p.token, nil
 	}